@@ -2,40 +2,173 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	// Embedded so WORKSHOP_TIMEZONE works in images without zoneinfo
+	_ "time/tzdata"
 
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/botguard"
+	"ai-india-workshop-backend/internal/config"
 	"ai-india-workshop-backend/internal/handlers"
+	"ai-india-workshop-backend/internal/jobs"
+	"ai-india-workshop-backend/internal/logging"
+	"ai-india-workshop-backend/internal/metrics"
 	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/oidc"
+	"ai-india-workshop-backend/internal/openapi"
+	"ai-india-workshop-backend/internal/problem"
+	"ai-india-workshop-backend/internal/ratelimit"
 	"ai-india-workshop-backend/internal/repository"
+	"ai-india-workshop-backend/internal/sessionstore"
+	"ai-india-workshop-backend/internal/tracing"
+	"ai-india-workshop-backend/internal/webhook"
+	"ai-india-workshop-backend/internal/worker"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/sessions"
-	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"go.opentelemetry.io/otel"
+)
+
+// Server timeouts. Writes allow for large CSV exports. Cloud Run kills the
+// container 10 seconds after SIGTERM, so in-flight requests get a little
+// less than that to finish.
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+	writeTimeout      = 60 * time.Second
+	idleTimeout       = 120 * time.Second
+	shutdownTimeout   = 8 * time.Second
 )
 
 func main() {
-	// Load environment variables - try project root first, then current directory
-	if err := godotenv.Load("../.env"); err != nil {
-		if err2 := godotenv.Load(".env"); err2 != nil {
-			log.Println("No .env file found, using environment variables")
-		}
+	// Settings come from the environment, a .env file and the YAML file
+	// named by CONFIG_FILE, and are all checked before anything starts
+	cfg, err := config.Load()
+	if err != nil {
+		fatalf("Invalid configuration:\n%v", err)
+	}
+	gin.SetMode(cfg.Mode)
+
+	// Logs are JSON on stdout, with email addresses and credentials redacted
+	slog.SetDefault(logging.New(os.Stdout, cfg.Level))
+	if cfg.EnvFile == "" {
+		slog.Info("No .env file found, using environment variables")
+	}
+	for _, warning := range cfg.Warnings {
+		slog.Warn(warning)
+	}
+
+	// ctx is cancelled on SIGINT or SIGTERM, which stops the server and the
+	// background workers
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Traces go to the exporter named by OTEL_TRACES_EXPORTER; by default
+	// none are recorded
+	shutdownTracing, err := tracing.Setup(ctx, cfg.TracesExporter)
+	if err != nil {
+		fatalf("Failed to set up tracing: %v", err)
 	}
 
-	// Initialize Firestore repository
-	ctx := context.Background()
-	repo, err := repository.NewRepository(ctx)
+	// Initialize Firestore repository. Its operations, and the documents the
+	// Firestore client reads for each, are recorded for /metrics and traced.
+	appMetrics := metrics.New()
+	firestoreRepo, err := repository.NewRepository(ctx, repository.Config{
+		SubcollectionID: cfg.Firestore.SubcollectionID,
+		CredentialsFile: cfg.Firestore.CredentialsFile,
+		ProjectID:       cfg.Firestore.ProjectID,
+	}, appMetrics.FirestoreOptions()...)
 	if err != nil {
-		log.Fatalf("Failed to initialize repository: %v", err)
+		fatalf("Failed to initialize repository: %v", err)
+	}
+	repo := appMetrics.InstrumentRepository(tracing.InstrumentRepository(firestoreRepo, otel.GetTracerProvider()))
+	appMetrics.RegisterAttendees(repo, time.Minute)
+
+	// Create the first admin account from ADMIN_EMAIL/ADMIN_PASSWORD on a fresh install
+	if err := bootstrapAdmin(ctx, repo, cfg.Admin); err != nil {
+		fatalf("Failed to create initial admin user: %v", err)
+	}
+
+	// Permanently purge trashed records once the retention period has passed
+	purger := worker.NewTrashPurger(repo, time.Duration(cfg.TrashRetention)*24*time.Hour)
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		purger.Run(ctx)
+	}()
+
+	// Run the jobs in the outbox. Registrations, check-ins and agenda changes
+	// each write one, which queues deliveries for the webhooks subscribed
+	// to them.
+	runner := jobs.NewRunner(repo, jobs.Config{
+		Workers:      cfg.Jobs.Workers,
+		PollInterval: cfg.Jobs.PollInterval,
+		Lease:        cfg.Jobs.Lease,
+		MaxAttempts:  cfg.Jobs.MaxAttempts,
+	})
+	runner.Handle(models.JobPublishEvent, webhook.NewPublisher(repo).Handle)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		runner.Run(ctx)
+	}()
+
+	// Send queued webhook deliveries, retrying failed ones with backoff
+	dispatcher := webhook.NewDispatcher(repo, webhook.Config{
+		PollInterval: cfg.Webhooks.PollInterval,
+		Timeout:      cfg.Webhooks.Timeout,
+		MaxAttempts:  cfg.Webhooks.MaxAttempts,
+	})
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		dispatcher.Run(ctx)
+	}()
+
+	// Roles listed in ADMIN_2FA_REQUIRED_ROLES must enrol in 2FA before using the admin panel
+	twoFactorPolicy := cfg.TwoFactor.Policy
+
+	// Failed admin logins are counted in Firestore so every Cloud Run
+	// instance enforces the same lockouts; LOGIN_LIMITER_STORE=memory keeps
+	// the counts per process instead
+	var ipStore, accountStore ratelimit.Store
+	if cfg.Login.LimiterStore == config.StoreMemory {
+		ipStore = ratelimit.NewMemoryStore()
+		accountStore = ratelimit.NewMemoryStore()
+	} else {
+		ipStore = firestoreRepo.NewLimiterStore("loginLimiterIP")
+		accountStore = firestoreRepo.NewLimiterStore("loginLimiterAccount")
 	}
+	ipLimiter := ratelimit.NewLimiter(ipStore, ratelimit.LoginIPPolicy)
+	accountLimiter := ratelimit.NewLimiter(accountStore, ratelimit.LoginAccountPolicy)
+
+	// Initialize Gin router. Every request except metrics scrapes and health
+	// probes is traced, and gets an ID that is added to the logs written while
+	// handling it.
+	r := gin.New()
+	r.Use(tracing.Middleware("/metrics", "/healthz", "/readyz"), middleware.RequestID(), middleware.RequestLogger(), appMetrics.Middleware(), middleware.Recover())
 
-	// Initialize Gin router
-	r := gin.Default()
+	// Only take the client IP from X-Forwarded-For when the request came
+	// through a trusted proxy, otherwise the login limiter could be evaded by
+	// sending a forged header. Cloud Run's front end connects from a private
+	// address, so private ranges are trusted by default.
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Serve static files (frontend) if STATIC_DIR is set (for production)
-	staticDir := os.Getenv("STATIC_DIR")
+	staticDir := cfg.StaticDir
 	if staticDir != "" {
 		// Serve static assets
 		r.Static("/assets", staticDir+"/assets")
@@ -43,7 +176,9 @@ func main() {
 		r.NoRoute(func(c *gin.Context) {
 			// Don't serve index.html for API routes
 			path := c.Request.URL.Path
-			if len(path) >= 4 && path[:4] == "/api" {
+			if strings.HasPrefix(path, apiPrefix+"/") {
+				problem.Write(c, problem.New(c, http.StatusNotFound, "Not found"))
+			} else if len(path) >= 4 && path[:4] == "/api" {
 				c.JSON(404, gin.H{"error": "Not found"})
 			} else {
 				c.File(staticDir + "/index.html")
@@ -51,91 +186,244 @@ func main() {
 		})
 	}
 
-	// CORS configuration
-	frontendURL := os.Getenv("FRONTEND_URL")
-	if frontendURL == "" {
-		frontendURL = "http://localhost:5173"
-	}
-
+	// CORS configuration. The frontend's origin is allowed unless
+	// CORS_ALLOWED_ORIGINS lists others.
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{frontendURL},
+		AllowOrigins:     cfg.CORSOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", middleware.CSRFHeader},
 		AllowCredentials: true,
 	}))
 
-	// Session store
-	sessionSecret := os.Getenv("SESSION_SECRET")
-	if sessionSecret == "" {
-		sessionSecret = "default-secret-change-in-production"
+	// Session store. Sessions live on the server and the cookie only holds a
+	// signed session ID, so logging out or revoking a session really ends it.
+	sessionSecret := cfg.Session.Secret
+	var sessionBackend sessionstore.Backend
+	if cfg.Session.Store == config.StoreMemory {
+		sessionBackend = sessionstore.NewMemoryBackend()
+	} else {
+		sessionBackend = firestoreRepo.NewSessionBackend("adminSessions")
+	}
+	sessionConfig := sessionstore.Config{
+		UserIDKey:       middleware.SessionUserIDKey,
+		IdleTimeout:     cfg.Session.IdleTimeout,
+		AbsoluteTimeout: cfg.Session.AbsoluteTimeout,
 	}
-	store := cookie.NewStore([]byte(sessionSecret))
-	r.Use(sessions.Sessions("admin-session", store))
+	store := sessionstore.New(sessionBackend, sessionConfig, []byte(sessionSecret))
 
-	// Initialize handlers
-	attendeeHandler := handlers.NewAttendeeHandler(repo)
-	speakerHandler := handlers.NewSpeakerHandler(repo)
-	sessionHandler := handlers.NewSessionHandler(repo)
-	adminHandler := handlers.NewAdminHandler(repo)
+	// The cookie is never readable from JavaScript and, in production, only
+	// sent over HTTPS. SameSite=Lax keeps it off cross-site subrequests; use
+	// SESSION_COOKIE_SAMESITE=none only if the frontend is on another site.
+	store.Options(sessions.Options{
+		Path:     "/",
+		MaxAge:   int(sessionConfig.AbsoluteTimeout.Seconds()),
+		HttpOnly: true,
+		Secure:   *cfg.Session.CookieSecure,
+		SameSite: cfg.Session.SameSite,
+	})
 
-	// Public routes
-	api := r.Group("/api")
-	{
-		// Attendee routes
-		api.POST("/attendees", attendeeHandler.Register)
-		api.GET("/attendees/count", attendeeHandler.GetCount)
+	// The API's OpenAPI document, served at /api/v1/openapi.json
+	spec, err := openapi.Load()
+	if err != nil {
+		fatalf("Failed to load OpenAPI document: %v", err)
+	}
 
-		// Speaker routes
-		api.GET("/speakers", speakerHandler.GetAll)
+	// Liveness and readiness probes. Readiness needs Firestore to answer and
+	// fails once shutdown has begun.
+	healthHandler := handlers.NewHealthHandler(repo, handlers.DefaultReadinessTimeout)
+	r.GET("/healthz", healthHandler.Live)
+	r.GET("/readyz", healthHandler.Ready)
 
-		// Session routes
-		api.GET("/sessions", sessionHandler.GetAll)
+	// Prometheus metrics. They include attendee numbers, so in production
+	// they are only served to scrapers sending METRICS_TOKEN as a bearer token.
+	metricsHandler := gin.WrapH(appMetrics.Handler())
+	if cfg.MetricsToken != "" {
+		r.GET("/metrics", middleware.RequireBearerToken(cfg.MetricsToken), metricsHandler)
+	} else if cfg.Release() {
+		slog.Warn("METRICS_TOKEN is not set, /metrics is disabled")
+	} else {
+		r.GET("/metrics", metricsHandler)
+	}
 
-		// Admin auth routes (public, must be registered here before protected routes)
-		api.POST("/admin/login", adminHandler.Login)
-		api.POST("/admin/logout", adminHandler.Logout)
+	// API routes. Only these load the admin session from its cookie.
+	var sso *handlers.SSOConfig
+	if cfg.OIDC.Enabled() {
+		sso = ssoConfig(cfg.OIDC, cfg.FrontendURL)
 	}
+	registerAPIRoutes(r, apiConfig{
+		repo:             repo,
+		workshop:         cfg.Firestore.SubcollectionID,
+		sessions:         store,
+		ipLimiter:        ipLimiter,
+		accountLimiter:   accountLimiter,
+		allowedOrigins:   cfg.CORSOrigins,
+		twoFactorPolicy:  twoFactorPolicy,
+		totpIssuer:       cfg.TwoFactor.Issuer,
+		location:         cfg.Location,
+		sso:              sso,
+		protection:       registrationProtection(firestoreRepo, cfg.Registration, sessionSecret),
+		spec:             spec,
+		validateRequests: cfg.ValidateRequests,
+	})
+
+	// Start server
+	port := cfg.Port
 
-	// Protected admin routes
-	admin := api.Group("/admin")
-	admin.Use(middleware.RequireAdmin())
-	{
-		admin.GET("/stats", adminHandler.GetStats)
+	for _, route := range r.Routes() {
+		slog.Debug("Registered route", "method", route.Method, "path", route.Path)
 	}
 
-	adminProtected := api.Group("")
-	adminProtected.Use(middleware.RequireAdmin())
-	{
-		// Attendee admin routes
-		adminProtected.GET("/attendees", attendeeHandler.GetAll)
-		adminProtected.DELETE("/attendees/:id", attendeeHandler.Delete)
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           r,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- server.ListenAndServe() }()
+	slog.Info("Server starting", "port", port)
 
-		// Speaker admin routes
-		adminProtected.POST("/speakers", speakerHandler.Create)
-		adminProtected.PUT("/speakers/:id", speakerHandler.Update)
-		adminProtected.DELETE("/speakers/:id", speakerHandler.Delete)
+	select {
+	case err := <-serverErr:
+		fatalf("Failed to start server: %v", err)
+	case <-ctx.Done():
+	}
+	// A second signal kills the process straight away
+	stop()
 
-		// Session admin routes
-		adminProtected.POST("/sessions", sessionHandler.Create)
-		adminProtected.PUT("/sessions/:id", sessionHandler.Update)
-		adminProtected.DELETE("/sessions/:id", sessionHandler.Delete)
+	// Stop taking new requests and let in-flight ones finish, then wait for
+	// the workers before closing the Firestore client they use
+	slog.Info("Shutting down")
+	healthHandler.Drain()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error draining requests", "error", err)
 	}
+	workers.Wait()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Error flushing traces", "error", err)
+	}
+	if err := firestoreRepo.Close(); err != nil {
+		slog.Error("Error closing Firestore client", "error", err)
+	}
+	slog.Info("Server stopped")
+}
 
-	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+// fatalf logs an error that prevents the server from starting and exits
+func fatalf(format string, args ...any) {
+	slog.Error(fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+// bootstrapAdmin creates an initial owner account when none exist yet so a
+// new deployment can be signed in to. Accounts created before roles existed
+// have no permissions, so if nobody is an owner the ADMIN_EMAIL account is
+// promoted.
+func bootstrapAdmin(ctx context.Context, repo repository.RepositoryInterface, admin config.Admin) error {
+	users, err := repo.GetAllAdminUsers(ctx)
+	if err != nil {
+		return err
 	}
 
-	// Debug: Print all routes
-	log.Println("Registered routes:")
-	for _, route := range r.Routes() {
-		log.Printf("  %s %s", route.Method, route.Path)
+	email := auth.NormalizeEmail(admin.Email)
+	password := admin.Password
+
+	if len(users) > 0 {
+		for _, user := range users {
+			if user.Role == auth.RoleOwner {
+				return nil
+			}
+		}
+		for _, user := range users {
+			if user.Email == email {
+				user.Role = auth.RoleOwner
+				user.UpdatedAt = time.Now()
+				slog.Warn("Promoting admin to owner because no owner exists", "email", user.Email)
+				return repo.UpdateAdminUser(ctx, user.ID, user)
+			}
+		}
+		slog.Warn("No admin user has the owner role; set ADMIN_EMAIL to an existing admin to promote them")
+		return nil
+	}
+
+	if email == "" || password == "" {
+		slog.Warn("No admin users exist; set ADMIN_EMAIL and ADMIN_PASSWORD to create the first one")
+		return nil
 	}
 
-	log.Printf("Server starting on port %s", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
 	}
+
+	now := time.Now()
+	user := &models.AdminUser{
+		Email:        email,
+		DisplayName:  "Administrator",
+		Role:         auth.RoleOwner,
+		PasswordHash: hash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := repo.CreateAdminUser(ctx, user); err != nil {
+		return err
+	}
+
+	slog.Info("Created initial admin user", "email", user.Email)
+	return nil
 }
 
+// ssoConfig sets up single sign-on with the OpenID Connect provider
+func ssoConfig(cfg config.OIDC, frontendURL string) *handlers.SSOConfig {
+	return &handlers.SSOConfig{
+		Provider: oidc.NewProvider(oidc.Config{
+			IssuerURL:    cfg.IssuerURL,
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       cfg.Scopes,
+			GroupsClaim:  cfg.GroupsClaim,
+		}),
+		Roles:       cfg.Roles,
+		DisplayName: cfg.ProviderName,
+		FrontendURL: frontendURL,
+	}
+}
+
+// registrationProtection configures the rate limits and bot checks on public
+// registration. The form tokens are signed with a key derived from the
+// session secret.
+func registrationProtection(repo *repository.Repository, cfg config.Registration, sessionSecret string) *handlers.RegistrationProtection {
+	ipPolicy, emailPolicy := ratelimit.RegistrationIPPolicy, ratelimit.RegistrationEmailPolicy
+	ipPolicy.FreeAttempts = cfg.IPLimit
+	emailPolicy.FreeAttempts = cfg.EmailLimit
+
+	var ipStore, emailStore ratelimit.Store
+	if cfg.LimiterStore == config.StoreMemory {
+		ipStore = ratelimit.NewMemoryStore()
+		emailStore = ratelimit.NewMemoryStore()
+	} else {
+		ipStore = repo.NewLimiterStore("registrationLimiterIP")
+		emailStore = repo.NewLimiterStore("registrationLimiterEmail")
+	}
+
+	protection := &handlers.RegistrationProtection{
+		IPLimiter:    ratelimit.NewLimiter(ipStore, ipPolicy),
+		EmailLimiter: ratelimit.NewLimiter(emailStore, emailPolicy),
+		FormTokens:   botguard.NewFormTokens([]byte(sessionSecret), cfg.MinFillTime, 12*time.Hour),
+	}
+
+	switch cfg.Challenge {
+	case config.ChallengePoW:
+		protection.Verifier = botguard.ProofOfWork{Difficulty: cfg.PoWDifficulty}
+	case config.ChallengeCaptcha:
+		protection.Verifier = botguard.NewCaptchaVerifier(cfg.Captcha.VerifyURL, cfg.Captcha.SiteKey, cfg.Captcha.Secret)
+	case config.ChallengeFake:
+		protection.Verifier = botguard.FakeVerifier{}
+	}
+
+	return protection
+}
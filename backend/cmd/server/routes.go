@@ -0,0 +1,231 @@
+package main
+
+import (
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/handlers"
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/openapi"
+	"ai-india-workshop-backend/internal/problem"
+	"ai-india-workshop-backend/internal/ratelimit"
+	"ai-india-workshop-backend/internal/repository"
+	"ai-india-workshop-backend/internal/sessionstore"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// sessionCookieName is the admin session cookie
+const sessionCookieName = "admin-session"
+
+const (
+	// apiPrefix is the current version of the API
+	apiPrefix = "/api/v1"
+	// legacyAPIPrefix serves the same routes as before versioning
+	legacyAPIPrefix = "/api"
+)
+
+// legacyAPIDeprecatedAt is when /api/v1 replaced /api
+var legacyAPIDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// apiConfig is everything the /api routes are built from
+type apiConfig struct {
+	repo           repository.RepositoryInterface
+	workshop       string
+	sessions       *sessionstore.Store
+	ipLimiter      *ratelimit.Limiter
+	accountLimiter *ratelimit.Limiter
+
+	// allowedOrigins may send cookie-authenticated writes
+	allowedOrigins  []string
+	twoFactorPolicy auth.TwoFactorPolicy
+	totpIssuer      string
+	location        *time.Location
+	// sso and protection are optional
+	sso        *handlers.SSOConfig
+	protection *handlers.RegistrationProtection
+
+	// spec is served at /api/v1/openapi.json and, with validateRequests,
+	// checked against every request
+	spec             *openapi.Spec
+	validateRequests bool
+}
+
+// registerAPIRoutes adds the /api/v1 routes, which are all documented in
+// internal/openapi/openapi.yaml, and their deprecated /api aliases
+func registerAPIRoutes(r *gin.Engine, cfg apiConfig) {
+	repo := cfg.repo
+
+	// Initialize handlers
+	attendeeHandler := handlers.NewAttendeeHandler(repo)
+	speakerHandler := handlers.NewSpeakerHandler(repo)
+	sessionHandler := handlers.NewSessionHandler(repo)
+	adminHandler := handlers.NewAdminHandler(repo, cfg.ipLimiter, cfg.accountLimiter)
+	trashHandler := handlers.NewTrashHandler(repo)
+	auditHandler := handlers.NewAuditHandler(repo)
+	adminUserHandler := handlers.NewAdminUserHandler(repo)
+	twoFactorHandler := handlers.NewTwoFactorHandler(repo, cfg.twoFactorPolicy, cfg.totpIssuer)
+	adminSessionHandler := handlers.NewAdminSessionHandler(cfg.sessions)
+	apiTokenHandler := handlers.NewAPITokenHandler(repo)
+	registrationFormHandler := handlers.NewRegistrationFormHandler(repo)
+	designationHandler := handlers.NewDesignationHandler(repo)
+	analyticsHandler := handlers.NewAnalyticsHandler(repo, cfg.location)
+	webhookHandler := handlers.NewWebhookHandler(repo)
+	jobHandler := handlers.NewJobHandler(repo)
+	backupHandler := handlers.NewBackupHandler(repo, cfg.workshop)
+	if cfg.sso != nil {
+		adminHandler.EnableSSO(cfg.sso)
+	}
+	if cfg.protection != nil {
+		attendeeHandler.EnableProtection(cfg.protection)
+	}
+	audit := middleware.Audit(repo)
+
+	// Cookie-authenticated writes must come from the admin panel: login and
+	// logout check the Origin, everything behind RequireAdmin also needs the
+	// session's CSRF token
+	checkOrigin := middleware.CheckOrigin(cfg.allowedOrigins)
+	csrf := middleware.RequireCSRFToken(cfg.allowedOrigins)
+
+	// register adds the routes to an API version's group. Requests that do
+	// not match the OpenAPI document are rejected before they reach the
+	// handlers when OPENAPI_VALIDATE_REQUESTS is set.
+	register := func(api *gin.RouterGroup) {
+		if cfg.validateRequests {
+			api.Use(cfg.spec.Middleware())
+		}
+		api.Use(sessionstore.RecordClientIP(), sessions.Sessions(sessionCookieName, cfg.sessions))
+
+		// Public routes
+		{
+			// API description
+			api.GET("/openapi.json", cfg.spec.Handler)
+
+			// Attendee routes
+			api.GET("/attendees/form", attendeeHandler.GetForm)
+			api.POST("/attendees", attendeeHandler.Register)
+			api.GET("/attendees/count", attendeeHandler.GetCount)
+
+			// Speaker routes
+			api.GET("/speakers", speakerHandler.GetAll)
+
+			// Session routes
+			api.GET("/sessions", sessionHandler.GetAll)
+
+			// Admin auth routes (public, must be registered here before protected routes)
+			api.POST("/admin/login", audit, checkOrigin, adminHandler.Login)
+			api.POST("/admin/login/2fa", audit, checkOrigin, adminHandler.VerifyTwoFactor)
+			api.POST("/admin/logout", audit, checkOrigin, adminHandler.Logout)
+			api.GET("/admin/sso", adminHandler.SSOStatus)
+			api.GET("/admin/sso/login", adminHandler.SSOLogin)
+			api.GET("/admin/sso/callback", audit, adminHandler.SSOCallback)
+		}
+
+		// Own account routes (any signed-in admin). These stay reachable before
+		// enrolling in 2FA so that admins whose role requires it can enrol. API
+		// tokens cannot be used here, so a leaked token cannot change the
+		// password or mint more tokens.
+		account := api.Group("/admin/me")
+		account.Use(middleware.RequireAdmin(repo), middleware.RequireSession(), audit, csrf)
+		{
+			account.GET("", adminUserHandler.Me)
+			account.PUT("/password", adminUserHandler.ChangeOwnPassword)
+			account.GET("/2fa", twoFactorHandler.Status)
+			account.POST("/2fa/setup", twoFactorHandler.Setup)
+			account.POST("/2fa/enable", twoFactorHandler.Enable)
+			account.POST("/2fa/disable", twoFactorHandler.Disable)
+			account.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+			account.GET("/sessions", adminSessionHandler.GetAll)
+			account.DELETE("/sessions", adminSessionHandler.RevokeAll)
+			account.DELETE("/sessions/:id", adminSessionHandler.Revoke)
+			account.GET("/tokens", apiTokenHandler.GetOwn)
+			account.POST("/tokens", apiTokenHandler.CreateOwn)
+			account.DELETE("/tokens/:id", apiTokenHandler.RevokeOwn)
+		}
+
+		// Protected admin routes. Each route also requires a permission from the
+		// caller's role (see internal/auth/rbac.go). These routes also accept an
+		// API token in an Authorization: Bearer header, limited to its scopes.
+		requirePermission := middleware.RequirePermission
+		admin := api.Group("/admin")
+		admin.Use(middleware.RequireAdmin(repo), middleware.RequireTwoFactor(cfg.twoFactorPolicy), audit, csrf)
+		{
+			admin.GET("/stats", requirePermission(auth.PermStatsRead), adminHandler.GetStats)
+			admin.GET("/analytics", requirePermission(auth.PermStatsRead), analyticsHandler.Get)
+			admin.GET("/audit", requirePermission(auth.PermAuditRead), auditHandler.GetAll)
+			admin.GET("/login-attempts", requirePermission(auth.PermAuditRead), auditHandler.GetLoginAttempts)
+
+			// Custom registration questions
+			admin.GET("/registration-form", requirePermission(auth.PermFormManage), registrationFormHandler.Get)
+			admin.PUT("/registration-form", requirePermission(auth.PermFormManage), registrationFormHandler.Update)
+
+			// Designation taxonomy
+			admin.GET("/designations", requirePermission(auth.PermFormManage), designationHandler.Get)
+			admin.PUT("/designations", requirePermission(auth.PermFormManage), designationHandler.Update)
+			admin.POST("/designations/remap", requirePermission(auth.PermFormManage), designationHandler.Remap)
+
+			// Admin account management routes
+			admin.GET("/roles", requirePermission(auth.PermUsersManage), adminUserHandler.GetRoles)
+			admin.GET("/users", requirePermission(auth.PermUsersManage), adminUserHandler.GetAll)
+			admin.POST("/users", requirePermission(auth.PermUsersManage), adminUserHandler.Create)
+			admin.PUT("/users/:id", requirePermission(auth.PermUsersManage), adminUserHandler.Update)
+			admin.PUT("/users/:id/password", requirePermission(auth.PermUsersManage), adminUserHandler.SetPassword)
+			admin.DELETE("/users/:id/2fa", requirePermission(auth.PermUsersManage), twoFactorHandler.Reset)
+
+			// API token management routes
+			admin.GET("/tokens", middleware.RequireSession(), requirePermission(auth.PermUsersManage), apiTokenHandler.GetAll)
+			admin.POST("/tokens", middleware.RequireSession(), requirePermission(auth.PermUsersManage), apiTokenHandler.CreateService)
+			admin.DELETE("/tokens/:id", middleware.RequireSession(), requirePermission(auth.PermUsersManage), apiTokenHandler.Revoke)
+
+			// Webhook routes. Secrets are shown in their responses, so API
+			// tokens cannot be used.
+			admin.GET("/webhooks", middleware.RequireSession(), requirePermission(auth.PermWebhooksManage), webhookHandler.GetAll)
+			admin.POST("/webhooks", middleware.RequireSession(), requirePermission(auth.PermWebhooksManage), webhookHandler.Create)
+			admin.PUT("/webhooks/:id", middleware.RequireSession(), requirePermission(auth.PermWebhooksManage), webhookHandler.Update)
+			admin.DELETE("/webhooks/:id", middleware.RequireSession(), requirePermission(auth.PermWebhooksManage), webhookHandler.Delete)
+			admin.GET("/webhooks/:id/deliveries", middleware.RequireSession(), requirePermission(auth.PermWebhooksManage), webhookHandler.GetDeliveries)
+			admin.POST("/webhooks/:id/deliveries/:deliveryId/replay", middleware.RequireSession(), requirePermission(auth.PermWebhooksManage), webhookHandler.Replay)
+
+			// Background job routes
+			admin.GET("/jobs", requirePermission(auth.PermJobsManage), jobHandler.GetAll)
+			admin.GET("/jobs/:id", requirePermission(auth.PermJobsManage), jobHandler.Get)
+			admin.POST("/jobs/:id/retry", requirePermission(auth.PermJobsManage), jobHandler.Retry)
+
+			// Backup routes
+			admin.GET("/backup", requirePermission(auth.PermBackupManage), backupHandler.Download)
+			admin.POST("/restore", requirePermission(auth.PermBackupManage), backupHandler.Restore)
+
+			// Trash routes
+			admin.GET("/trash", requirePermission(auth.PermTrashRead), trashHandler.GetAll)
+			admin.POST("/trash/:type/:id/restore", requirePermission(auth.PermTrashManage), trashHandler.Restore)
+			admin.DELETE("/trash/:type/:id", requirePermission(auth.PermTrashManage), trashHandler.Purge)
+		}
+
+		adminProtected := api.Group("")
+		adminProtected.Use(middleware.RequireAdmin(repo), middleware.RequireTwoFactor(cfg.twoFactorPolicy), audit, csrf)
+		{
+			// Attendee admin routes
+			adminProtected.GET("/attendees", requirePermission(auth.PermAttendeesRead), attendeeHandler.GetAll)
+			adminProtected.GET("/attendees/export", requirePermission(auth.PermAttendeesRead), attendeeHandler.Export)
+			adminProtected.DELETE("/attendees/:id", requirePermission(auth.PermAttendeesDelete), attendeeHandler.Delete)
+			adminProtected.POST("/attendees/:id/checkin", requirePermission(auth.PermAttendeesCheckIn), attendeeHandler.CheckIn)
+
+			// Speaker admin routes
+			adminProtected.POST("/speakers", requirePermission(auth.PermSpeakersWrite), speakerHandler.Create)
+			adminProtected.PUT("/speakers/:id", requirePermission(auth.PermSpeakersWrite), speakerHandler.Update)
+			adminProtected.DELETE("/speakers/:id", requirePermission(auth.PermSpeakersWrite), speakerHandler.Delete)
+
+			// Session admin routes
+			adminProtected.POST("/sessions", requirePermission(auth.PermSessionsWrite), sessionHandler.Create)
+			adminProtected.PUT("/sessions/:id", requirePermission(auth.PermSessionsWrite), sessionHandler.Update)
+			adminProtected.DELETE("/sessions/:id", requirePermission(auth.PermSessionsWrite), sessionHandler.Delete)
+		}
+	}
+
+	// /api/v1 answers errors with RFC 7807 problem details. /api serves the
+	// same routes with the old error bodies for existing clients, marked as
+	// deprecated.
+	register(r.Group(apiPrefix, problem.Envelope()))
+	register(r.Group(legacyAPIPrefix, middleware.Deprecated(legacyAPIPrefix, apiPrefix, legacyAPIDeprecatedAt)))
+}
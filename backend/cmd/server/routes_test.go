@@ -0,0 +1,464 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/botguard"
+	"ai-india-workshop-backend/internal/handlers"
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/openapi"
+	"ai-india-workshop-backend/internal/problem"
+	"ai-india-workshop-backend/internal/ratelimit"
+	"ai-india-workshop-backend/internal/repository"
+	"ai-india-workshop-backend/internal/sessionstore"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOrigin   = "http://localhost:5173"
+	testPassword = "correct horse battery staple"
+)
+
+func setupAPIRouter(t *testing.T, repo *repository.MockRepository, validateRequests bool) (*gin.Engine, *openapi.Spec) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	secret := []byte("test-session-secret-with-enough-bytes")
+	store := sessionstore.New(sessionstore.NewMemoryBackend(), sessionstore.Config{UserIDKey: middleware.SessionUserIDKey}, secret)
+
+	r := gin.New()
+	registerAPIRoutes(r, apiConfig{
+		repo:            repo,
+		workshop:        "contract-workshop",
+		sessions:        store,
+		ipLimiter:       ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.LoginIPPolicy),
+		accountLimiter:  ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.LoginAccountPolicy),
+		allowedOrigins:  []string{testOrigin},
+		twoFactorPolicy: auth.TwoFactorPolicy{},
+		totpIssuer:      "AI India Workshop",
+		location:        time.UTC,
+		protection: &handlers.RegistrationProtection{
+			IPLimiter:    ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.RegistrationIPPolicy),
+			EmailLimiter: ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.RegistrationEmailPolicy),
+			FormTokens:   botguard.NewFormTokens(secret, 0, time.Hour),
+			Verifier:     botguard.FakeVerifier{},
+		},
+		spec:             spec,
+		validateRequests: validateRequests,
+	})
+	return r, spec
+}
+
+// apiClient sends requests the way the admin panel does, keeping the
+// session cookie and CSRF token, and checks every response against the
+// OpenAPI document
+type apiClient struct {
+	t         *testing.T
+	router    *gin.Engine
+	spec      *openapi.Spec
+	cookies   map[string]*http.Cookie
+	csrfToken string
+}
+
+func (c *apiClient) do(method, path, body string) *httptest.ResponseRecorder {
+	c.t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Origin", testOrigin)
+	if c.csrfToken != "" {
+		req.Header.Set(middleware.CSRFHeader, c.csrfToken)
+	}
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	c.router.ServeHTTP(w, req)
+	for _, cookie := range w.Result().Cookies() {
+		c.cookies[cookie.Name] = cookie
+	}
+
+	err := c.spec.ValidateResponse(method, req.URL.Path, w.Code, w.Header(), w.Body.Bytes())
+	assert.NoError(c.t, err, "response body: %s", w.Body.String())
+	return w
+}
+
+func testOwner(t *testing.T) *models.AdminUser {
+	t.Helper()
+	hash, err := auth.HashPassword(testPassword)
+	require.NoError(t, err)
+	return &models.AdminUser{
+		ID:           "admin-1",
+		Email:        "owner@example.com",
+		DisplayName:  "Owner",
+		Role:         auth.RoleOwner,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+}
+
+// TestAPIContract sends a representative request to every kind of route and
+// checks that the status codes and bodies are the ones the OpenAPI document
+// promises the frontend
+func TestAPIContract(t *testing.T) {
+	now := time.Now().UTC()
+	owner := testOwner(t)
+	editor := &models.AdminUser{ID: "admin-2", Email: "editor@example.com", DisplayName: "Editor", Role: auth.RoleContentEditor, CreatedAt: now, UpdatedAt: now}
+	attendee := &models.Attendee{ID: "a1", Name: "Test User", Email: "test@example.com", Designation: "Engineer", CreatedAt: now, Answers: map[string]interface{}{"track": "ml"}}
+	speaker := &models.Speaker{ID: "s1", Name: "Speaker", Bio: "Bio", LinkedIn: "https://linkedin.com/in/speaker"}
+	session := &models.Session{ID: "t1", Title: "Keynote", Description: "Opening", Time: "10:00 AM", Speakers: []string{"s1"}}
+	form := &models.RegistrationForm{Fields: []models.FormField{{ID: "track", Label: "Track", Type: "select", Required: true, Options: []string{"ml", "web"}}}}
+	taxonomy := &models.DesignationTaxonomy{Designations: []models.Designation{{Name: "Engineer", Aliases: []string{"SDE"}}, {Name: "Student"}}}
+	token := &models.APIToken{ID: "tok-1", Name: "CI", Kind: models.APITokenKindService, Scopes: []string{auth.PermAttendeesRead}, CreatedBy: owner.ID, CreatedAt: now, ExpiresAt: now.Add(24 * time.Hour)}
+
+	repo := new(repository.MockRepository)
+	repo.On("CreateAuditEntry", mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("CreateLoginAttempt", mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("GetAdminUserByEmail", mock.Anything, owner.Email).Return(owner, nil).Maybe()
+	repo.On("GetAdminUser", mock.Anything, owner.ID).Return(owner, nil).Maybe()
+	repo.On("GetAdminUser", mock.Anything, editor.ID).Return(editor, nil).Maybe()
+	repo.On("UpdateAdminUser", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("GetRegistrationForm", mock.Anything).Return(form, nil).Maybe()
+	repo.On("GetDesignationTaxonomy", mock.Anything).Return(taxonomy, nil).Maybe()
+	repo.On("GetAttendee", mock.Anything, attendee.ID).Return(attendee, nil).Maybe()
+	repo.On("GetSpeaker", mock.Anything, speaker.ID).Return(speaker, nil).Maybe()
+	repo.On("GetSession", mock.Anything, session.ID).Return(session, nil).Maybe()
+	repo.On("GetAllAttendees", mock.Anything).Return([]*models.Attendee{attendee}, nil).Maybe()
+	repo.On("GetAllSpeakers", mock.Anything).Return([]*models.Speaker{speaker}, nil).Maybe()
+	repo.On("GetAllSessions", mock.Anything).Return([]*models.Session{session}, nil).Maybe()
+	repo.On("GetAPIToken", mock.Anything, token.ID).Return(token, nil).Maybe()
+
+	router, spec := setupAPIRouter(t, repo, true)
+	client := &apiClient{t: t, router: router, spec: spec, cookies: map[string]*http.Cookie{}}
+
+	// Public routes
+	client.do("GET", "/api/v1/openapi.json", "")
+	w := client.do("GET", "/api/v1/attendees/form", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	formToken := stringField(t, w.Body.Bytes(), "formToken")
+
+	repo.On("CreateAttendee", mock.Anything, mock.AnythingOfType("*models.Attendee")).Return(nil).Once()
+	w = client.do("POST", "/api/v1/attendees", `{"name":"New User","email":"new@example.com","designation":"SDE","answers":{"track":"web"},"formToken":"`+formToken+`","challengeResponse":"`+botguard.FakeVerifierPass+`"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = client.do("POST", "/api/v1/attendees", `{"name":"New User","designation":"Engineer"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	repo.On("GetAttendeeCount", mock.Anything).Return(42, nil).Once()
+	client.do("GET", "/api/v1/attendees/count", "")
+	client.do("GET", "/api/v1/speakers", "")
+	client.do("GET", "/api/v1/sessions", "")
+	client.do("GET", "/api/v1/admin/sso", "")
+	w = client.do("GET", "/api/v1/admin/sso/login", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Signing in
+	w = client.do("GET", "/api/v1/attendees", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = client.do("POST", "/api/v1/admin/login", `{"email":"owner@example.com","password":"wrong password"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = client.do("POST", "/api/v1/admin/login", `{"email":"owner@example.com","password":"`+testPassword+`"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	client.csrfToken = stringField(t, w.Body.Bytes(), "csrfToken")
+
+	// Own account
+	client.do("GET", "/api/v1/admin/me", "")
+	client.do("GET", "/api/v1/admin/me/2fa", "")
+	w = client.do("POST", "/api/v1/admin/me/2fa/setup", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = client.do("POST", "/api/v1/admin/me/2fa/enable", `{"code":"000000"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = client.do("PUT", "/api/v1/admin/me/password", `{"currentPassword":"wrong password","newPassword":"another long password"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	client.do("GET", "/api/v1/admin/me/sessions", "")
+	repo.On("GetAPITokens", mock.Anything, mock.Anything).Return([]*models.APIToken{token}, nil).Times(2)
+	client.do("GET", "/api/v1/admin/me/tokens", "")
+	repo.On("CreateAPIToken", mock.Anything, mock.Anything).Return(nil).Times(2)
+	w = client.do("POST", "/api/v1/admin/me/tokens", `{"name":"Laptop","scopes":["attendees:read"],"expiresInDays":30}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Admin routes
+	repo.On("GetDesignationBreakdown", mock.Anything).Return([]models.DesignationCount{{Designation: "Engineer", Count: 1}}, nil).Once()
+	client.do("GET", "/api/v1/admin/stats", "")
+	repo.On("GetAnalyticsDays", mock.Anything).Return([]*models.AnalyticsDay{{Date: now.Format("2006-01-02"), Registrations: map[string]int{"10": 1}}}, nil).Once()
+	client.do("GET", "/api/v1/admin/analytics?granularity=hour", "")
+	repo.On("GetAuditEntries", mock.Anything, mock.Anything).Return([]*models.AuditEntry{{ID: "e1", Timestamp: now, Actor: owner.Email, Action: "DELETE /api/speakers/:id", ResourceType: "speakers", ResourceID: "s1", Before: map[string]interface{}{"id": "s1"}, Status: 200, IP: "192.0.2.1"}}, nil).Once()
+	client.do("GET", "/api/v1/admin/audit?limit=10", "")
+	repo.On("GetLoginAttempts", mock.Anything, mock.Anything).Return([]*models.LoginAttempt{{ID: "l1", Timestamp: now, Email: owner.Email, IP: "192.0.2.1", Reason: "invalid_password"}}, nil).Once()
+	client.do("GET", "/api/v1/admin/login-attempts?failed=true", "")
+
+	client.do("GET", "/api/v1/admin/registration-form", "")
+	repo.On("SaveRegistrationForm", mock.Anything, mock.Anything).Return(nil).Once()
+	client.do("PUT", "/api/v1/admin/registration-form", `{"fields":[{"id":"track","label":"Track","type":"select","required":true,"options":["ml","web"]}]}`)
+	client.do("GET", "/api/v1/admin/designations", "")
+	repo.On("SaveDesignationTaxonomy", mock.Anything, mock.Anything).Return(nil).Once()
+	client.do("PUT", "/api/v1/admin/designations", `{"designations":[{"name":"Engineer","aliases":["SDE"]},{"name":"Student","aliases":[]}]}`)
+	repo.On("GetTrash", mock.Anything).Return(&models.Trash{}, nil).Once()
+	client.do("POST", "/api/v1/admin/designations/remap?dryRun=true", "")
+
+	client.do("GET", "/api/v1/admin/roles", "")
+	repo.On("GetAllAdminUsers", mock.Anything).Return([]*models.AdminUser{owner, editor}, nil).Once()
+	client.do("GET", "/api/v1/admin/users", "")
+	repo.On("CreateAdminUser", mock.Anything, mock.Anything).Return(nil).Once()
+	w = client.do("POST", "/api/v1/admin/users", `{"email":"new-admin@example.com","displayName":"New Admin","role":"analyst"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	client.do("PUT", "/api/v1/admin/users/admin-2", `{"role":"organiser"}`)
+	client.do("PUT", "/api/v1/admin/users/admin-2/password", `{"password":"a brand new password"}`)
+	client.do("DELETE", "/api/v1/admin/users/admin-2/2fa", "")
+
+	client.do("GET", "/api/v1/admin/tokens", "")
+	w = client.do("POST", "/api/v1/admin/tokens", `{"name":"Export job","scopes":["attendees:read"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	repo.On("RevokeAPIToken", mock.Anything, token.ID, mock.Anything).Return(nil).Once()
+	client.do("DELETE", "/api/v1/admin/tokens/tok-1", "")
+
+	hook := &models.Webhook{ID: "wh1", URL: "https://crm.example.com/hooks", Events: []string{models.WebhookAttendeeRegistered}, Secret: "whsec_contract-test", Active: true, CreatedBy: owner.Email, CreatedAt: now, UpdatedAt: now}
+	delivery := &models.WebhookDelivery{ID: "d1", WebhookID: hook.ID, EventID: "evt_1", Event: models.WebhookAttendeeRegistered, Payload: `{"id":"evt_1"}`, Status: models.WebhookDeliveryFailed, Attempts: 10, ResponseStatus: 500, Error: "500 Internal Server Error", CreatedAt: now, LastAttemptAt: &now, CompletedAt: &now}
+	repo.On("GetWebhook", mock.Anything, hook.ID).Return(hook, nil).Maybe()
+	repo.On("GetAllWebhooks", mock.Anything).Return([]*models.Webhook{hook}, nil).Once()
+	client.do("GET", "/api/v1/admin/webhooks", "")
+	repo.On("CreateWebhook", mock.Anything, mock.Anything).Return(nil).Once()
+	w = client.do("POST", "/api/v1/admin/webhooks", `{"url":"https://hooks.slack.com/services/T0/B0/X","events":["agenda.changed"],"description":"#workshop channel"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = client.do("POST", "/api/v1/admin/webhooks", `{"url":"http://crm.example.com/hooks","events":["attendee.registered"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	repo.On("UpdateWebhook", mock.Anything, hook.ID, mock.Anything).Return(nil).Once()
+	client.do("PUT", "/api/v1/admin/webhooks/wh1", `{"url":"https://crm.example.com/hooks","events":["attendee.registered","attendee.cancelled"],"rotateSecret":true}`)
+	repo.On("GetWebhookDeliveries", mock.Anything, mock.Anything).Return([]*models.WebhookDelivery{delivery}, nil).Once()
+	client.do("GET", "/api/v1/admin/webhooks/wh1/deliveries?status=failed", "")
+	repo.On("GetWebhookDelivery", mock.Anything, delivery.ID).Return(delivery, nil).Once()
+	repo.On("CreateWebhookDeliveries", mock.Anything, mock.Anything).Return(nil).Once()
+	w = client.do("POST", "/api/v1/admin/webhooks/wh1/deliveries/d1/replay", "")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	repo.On("DeleteWebhook", mock.Anything, hook.ID).Return(nil).Once()
+	client.do("DELETE", "/api/v1/admin/webhooks/wh1", "")
+
+	failedJob := &models.Job{ID: "j1", Type: models.JobPublishEvent, Payload: `{"type":"attendee.registered"}`, Status: models.JobFailed, Attempts: 8, LastError: "loading webhooks: unavailable", CreatedAt: now, UpdatedAt: now, CompletedAt: &now}
+	retriedJob := &models.Job{ID: "j1", Type: models.JobPublishEvent, Payload: failedJob.Payload, Status: models.JobPending, RunAt: &now, LastError: failedJob.LastError, CreatedAt: now, UpdatedAt: now}
+	repo.On("GetJobs", mock.Anything, mock.Anything).Return([]*models.Job{failedJob}, nil).Once()
+	client.do("GET", "/api/v1/admin/jobs?status=failed", "")
+	repo.On("GetJob", mock.Anything, failedJob.ID).Return(failedJob, nil).Once()
+	client.do("GET", "/api/v1/admin/jobs/j1", "")
+	repo.On("RetryJob", mock.Anything, failedJob.ID, mock.Anything).Return(retriedJob, nil).Once()
+	w = client.do("POST", "/api/v1/admin/jobs/j1/retry", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	repo.On("RetryJob", mock.Anything, "j2", mock.Anything).Return(nil, repository.ErrJobNotFailed).Once()
+	w = client.do("POST", "/api/v1/admin/jobs/j2/retry", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	repo.On("GetWorkshopData", mock.Anything).Return(&models.WorkshopData{Speakers: []*models.Speaker{speaker}, Sessions: []*models.Session{session}, Attendees: []*models.Attendee{attendee}}, nil).Times(3)
+	w = client.do("GET", "/api/v1/admin/backup", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	archive := w.Body.String()
+	w = client.do("POST", "/api/v1/admin/restore", archive)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	repo.On("ReplaceWorkshopData", mock.Anything, mock.Anything).Return(nil).Once()
+	repo.On("RebuildAnalytics", mock.Anything).Return(nil).Once()
+	w = client.do("POST", "/api/v1/admin/restore?replace=true", archive)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = client.do("POST", "/api/v1/admin/restore", archive[:len(archive)/2])
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	repo.On("GetTrash", mock.Anything).Return(&models.Trash{Attendees: []*models.Attendee{attendee}}, nil).Once()
+	client.do("GET", "/api/v1/admin/trash", "")
+	repo.On("RestoreFromTrash", mock.Anything, models.ResourceAttendees, attendee.ID).Return(nil).Once()
+	client.do("POST", "/api/v1/admin/trash/attendees/a1/restore", "")
+	repo.On("GetSpeaker", mock.Anything, "s9").Return(nil, repository.ErrNotFound).Once()
+	repo.On("PurgeFromTrash", mock.Anything, models.ResourceSpeakers, "s9").Return(repository.ErrNotFound).Once()
+	w = client.do("DELETE", "/api/v1/admin/trash/speakers/s9", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Attendee, speaker and session administration
+	client.do("GET", "/api/v1/attendees", "")
+	w = client.do("GET", "/api/v1/attendees/export", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	checkedIn := *attendee
+	checkedIn.CheckedInAt = &now
+	repo.On("CheckInAttendee", mock.Anything, attendee.ID).Return(&checkedIn, nil).Once()
+	client.do("POST", "/api/v1/attendees/a1/checkin", "")
+	repo.On("CheckInAttendee", mock.Anything, attendee.ID).Return(&checkedIn, repository.ErrAlreadyCheckedIn).Once()
+	w = client.do("POST", "/api/v1/attendees/a1/checkin", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	repo.On("DeleteAttendee", mock.Anything, attendee.ID).Return(nil).Once()
+	client.do("DELETE", "/api/v1/attendees/a1", "")
+
+	repo.On("CreateSpeaker", mock.Anything, mock.Anything).Return(nil).Once()
+	w = client.do("POST", "/api/v1/speakers", `{"name":"New Speaker","bio":"Bio"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	repo.On("UpdateSpeaker", mock.Anything, speaker.ID, mock.Anything).Return(nil).Once()
+	client.do("PUT", "/api/v1/speakers/s1", `{"name":"Speaker","bio":"New bio"}`)
+	repo.On("DeleteSpeaker", mock.Anything, speaker.ID).Return(nil).Once()
+	client.do("DELETE", "/api/v1/speakers/s1", "")
+
+	repo.On("CreateSession", mock.Anything, mock.Anything).Return(nil).Once()
+	w = client.do("POST", "/api/v1/sessions", `{"title":"Workshop","description":"Hands on","time":"2:00 PM","speakers":["s1"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	repo.On("UpdateSession", mock.Anything, session.ID, mock.Anything).Return(nil).Once()
+	client.do("PUT", "/api/v1/sessions/t1", `{"title":"Keynote","description":"Opening","time":"9:00 AM","speakers":[]}`)
+	repo.On("DeleteSession", mock.Anything, session.ID).Return(nil).Once()
+	client.do("DELETE", "/api/v1/sessions/t1", "")
+
+	// Writes without the CSRF token are refused
+	csrfToken := client.csrfToken
+	client.csrfToken = ""
+	w = client.do("DELETE", "/api/v1/speakers/s1", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	client.csrfToken = csrfToken
+
+	w = client.do("POST", "/api/v1/admin/logout", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = client.do("GET", "/api/v1/admin/me", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	repo.AssertExpectations(t)
+}
+
+// TestAPIRoutesDocumented checks that the router and the OpenAPI document
+// list the same operations, under /api/v1 and its deprecated /api alias
+func TestAPIRoutesDocumented(t *testing.T) {
+	router, spec := setupAPIRouter(t, new(repository.MockRepository), false)
+
+	registered := map[string][]string{}
+	for _, route := range router.Routes() {
+		prefix := legacyAPIPrefix
+		if strings.HasPrefix(route.Path, apiPrefix+"/") {
+			prefix = apiPrefix
+		}
+		segments := strings.Split(strings.TrimPrefix(route.Path, prefix), "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, ":") {
+				segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+			}
+		}
+		registered[prefix] = append(registered[prefix], route.Method+" "+strings.Join(segments, "/"))
+	}
+
+	documented := map[string][]string{}
+	for _, op := range spec.Operations() {
+		for _, server := range op.Servers {
+			if server == apiPrefix || server == legacyAPIPrefix {
+				documented[server] = append(documented[server], op.Method+" "+op.Path)
+			}
+		}
+	}
+
+	for _, prefix := range []string{apiPrefix, legacyAPIPrefix} {
+		sort.Strings(registered[prefix])
+		sort.Strings(documented[prefix])
+		assert.Equal(t, documented[prefix], registered[prefix], prefix)
+	}
+}
+
+func TestRequestValidation(t *testing.T) {
+	repo := new(repository.MockRepository)
+	router, spec := setupAPIRouter(t, repo, true)
+	client := &apiClient{t: t, router: router, spec: spec, cookies: map[string]*http.Cookie{}}
+
+	// Rejected before the handler looks the user up
+	w := client.do("POST", "/api/v1/admin/login", `{"email":"owner@example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"code": "validation_failed",
+		"detail": "password is required",
+		"instance": "/api/v1/admin/login",
+		"errors": [{"field": "password", "code": "invalid", "message": "password is required"}]
+	}`, w.Body.String())
+
+	repo.AssertExpectations(t)
+}
+
+// TestLoginAuditRedactsCSRFToken checks that the CSRF token returned on
+// sign-in is not copied into the audit log, which other admins can read
+func TestLoginAuditRedactsCSRFToken(t *testing.T) {
+	owner := testOwner(t)
+	repo := new(repository.MockRepository)
+	var logins []*models.AuditEntry
+	repo.On("CreateAuditEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if entry := args.Get(1).(*models.AuditEntry); entry.Action == "POST /api/v1/admin/login" {
+			logins = append(logins, entry)
+		}
+	}).Return(nil)
+	repo.On("CreateLoginAttempt", mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("GetAdminUserByEmail", mock.Anything, owner.Email).Return(owner, nil)
+	repo.On("UpdateAdminUser", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	router, spec := setupAPIRouter(t, repo, false)
+	client := &apiClient{t: t, router: router, spec: spec, cookies: map[string]*http.Cookie{}}
+
+	w := client.do("POST", "/api/v1/admin/login", `{"email":"owner@example.com","password":"`+testPassword+`"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	csrfToken := stringField(t, w.Body.Bytes(), "csrfToken")
+
+	require.Len(t, logins, 1)
+	assert.Equal(t, "[redacted]", logins[0].After["csrfToken"])
+	after, err := json.Marshal(logins[0].After)
+	require.NoError(t, err)
+	assert.NotContains(t, string(after), csrfToken)
+}
+
+// TestLegacyAPI checks that /api keeps its old error and login bodies and
+// points clients at /api/v1
+func TestLegacyAPI(t *testing.T) {
+	owner := testOwner(t)
+	repo := new(repository.MockRepository)
+	repo.On("CreateAuditEntry", mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("GetAttendeeCount", mock.Anything).Return(3, nil).Once()
+	repo.On("CreateLoginAttempt", mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("GetAdminUserByEmail", mock.Anything, owner.Email).Return(owner, nil)
+	repo.On("UpdateAdminUser", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	router, _ := setupAPIRouter(t, repo, false)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/attendees/count", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"count":3}`, w.Body.String())
+	assert.Equal(t, "@1792281600", w.Header().Get("Deprecation"))
+	assert.Equal(t, `</api/v1/attendees/count>; rel="successor-version"`, w.Header().Get("Link"))
+
+	req := httptest.NewRequest("POST", "/api/admin/login", strings.NewReader(`{"email":"owner@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"password is required","field":"password"}`, w.Body.String())
+
+	req = httptest.NewRequest("POST", "/api/admin/login", strings.NewReader(`{"email":"owner@example.com","password":"`+testPassword+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"success":true`)
+
+	repo.AssertExpectations(t)
+}
+
+// stringField reads a top-level string property from a JSON object
+func stringField(t *testing.T, body []byte, key string) string {
+	t.Helper()
+	var object map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &object))
+	value, ok := object[key].(string)
+	require.True(t, ok, "%s missing from %s", key, body)
+	return value
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+)
+
+func runAdmin(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "admin")
+	if len(args) == 0 || args[0] != "create" {
+		return usageError(fs, "admin needs create")
+	}
+	return createAdmin(ctx, a, args[1:])
+}
+
+// createAdmin adds an admin user, like an invitation from the admin panel
+// but without needing an owner to sign in first. Without -password a
+// temporary one is generated and printed, for the user to change.
+func createAdmin(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "admin create")
+	email := fs.String("email", "", "email address to sign in with")
+	role := fs.String("role", auth.RoleOrganiser, "role: "+strings.Join(auth.Roles(), ", "))
+	name := fs.String("name", "", "display name (default the email address)")
+	password := fs.String("password", "", "password (default a generated one, which is printed)")
+	if rest, err := parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageError(fs, "admin create takes no arguments")
+	}
+
+	*email = auth.NormalizeEmail(*email)
+	if _, err := mail.ParseAddress(*email); err != nil {
+		return usageError(fs, "invalid -email %q", *email)
+	}
+	if !auth.ValidRole(*role) {
+		return usageError(fs, "invalid -role %q", *role)
+	}
+	if *name == "" {
+		*name = *email
+	}
+	generated := *password == ""
+	if generated {
+		var err error
+		if *password, err = auth.GeneratePassword(); err != nil {
+			return err
+		}
+	}
+	hash, err := auth.HashPassword(*password)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user := &models.AdminUser{
+		Email:        *email,
+		DisplayName:  *name,
+		Role:         *role,
+		PasswordHash: hash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := a.repo.CreateAdminUser(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return fmt.Errorf("an admin user with email %s already exists", *email)
+		}
+		return err
+	}
+
+	fmt.Fprintf(a.stdout, "Created %s %s (%s)\n", *role, *email, user.ID)
+	if generated {
+		fmt.Fprintf(a.stdout, "Temporary password: %s\n", *password)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/models"
+)
+
+// maxAttendeeLine bounds one line of an import, which holds an attendee and
+// their answers
+const maxAttendeeLine = 1 << 20
+
+func runAttendees(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "attendees")
+	if len(args) == 0 {
+		return usageError(fs, "attendees needs export or import")
+	}
+	switch args[0] {
+	case "export":
+		return exportAttendees(ctx, a, args[1:])
+	case "import":
+		return importAttendees(ctx, a, args[1:])
+	default:
+		return usageError(fs, "unknown attendees command %q", args[0])
+	}
+}
+
+// exportAttendees writes every attendee, newest first, as one JSON object
+// per line with the same fields as the API. The admin panel's CSV export
+// is meant for spreadsheets; this one is meant for importing again.
+func exportAttendees(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "attendees export")
+	out := fs.String("out", "", "file to write (default standard output)")
+	if rest, err := parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageError(fs, "attendees export takes no arguments")
+	}
+
+	attendees, err := a.repo.GetAllAttendees(ctx)
+	if err != nil {
+		return fmt.Errorf("loading attendees: %w", err)
+	}
+
+	w := a.stdout
+	if *out != "" {
+		f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	for _, attendee := range attendees {
+		if err := encoder.Encode(attendee); err != nil {
+			return err
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+
+	if *out != "" {
+		fmt.Fprintf(a.stdout, "Exported %d attendees to %s\n", len(attendees), *out)
+	}
+	return nil
+}
+
+// importAttendees adds the attendees in a file written by export, keeping
+// when they registered and checked in. Attendees whose email is already
+// registered are skipped, so a file can be imported again after a failure
+// part way through. The attendees are written without publishing webhook
+// events, which were sent when they first registered, and the analytics are
+// recounted afterwards to include them and their check-ins.
+func importAttendees(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "attendees import")
+	dryRun := fs.Bool("dry-run", false, "check the file and print what would be imported without importing it")
+	files, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return usageError(fs, "attendees import takes one file, or - for standard input")
+	}
+
+	var r io.Reader = a.stdin
+	if files[0] != "-" {
+		f, err := os.Open(files[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	attendees, err := readAttendees(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", files[0], err)
+	}
+
+	existing, err := a.repo.GetAllAttendees(ctx)
+	if err != nil {
+		return fmt.Errorf("loading attendees: %w", err)
+	}
+	registered := map[string]bool{}
+	for _, attendee := range existing {
+		registered[auth.NormalizeEmail(attendee.Email)] = true
+	}
+
+	var toImport []*models.Attendee
+	for _, attendee := range attendees {
+		email := auth.NormalizeEmail(attendee.Email)
+		if registered[email] {
+			continue
+		}
+		registered[email] = true
+		toImport = append(toImport, attendee)
+	}
+	skipped := len(attendees) - len(toImport)
+
+	if *dryRun {
+		fmt.Fprintf(a.stdout, "Dry run: %d attendees would be imported, %d already registered\n", len(toImport), skipped)
+		return nil
+	}
+	if len(toImport) == 0 {
+		fmt.Fprintf(a.stdout, "Imported 0 attendees, %d already registered\n", skipped)
+		return nil
+	}
+	if err := a.repo.ImportAttendees(ctx, toImport); err != nil {
+		imported := 0
+		for _, attendee := range toImport {
+			if attendee.ID != "" {
+				imported++
+			}
+		}
+		return fmt.Errorf("importing attendees, %d of %d imported: %w", imported, len(toImport), err)
+	}
+	if err := a.repo.RebuildAnalytics(ctx); err != nil {
+		return fmt.Errorf("recounting analytics: %w", err)
+	}
+	fmt.Fprintf(a.stdout, "Imported %d attendees, %d already registered\n", len(toImport), skipped)
+	return nil
+}
+
+// readAttendees reads one attendee per line, skipping blank lines. Every
+// attendee needs a name, an email and a registration time; IDs are dropped
+// as the store assigns new ones, and so are cancellations.
+func readAttendees(r io.Reader) ([]*models.Attendee, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxAttendeeLine)
+
+	var attendees []*models.Attendee
+	var problems []string
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var attendee models.Attendee
+		if err := json.Unmarshal([]byte(text), &attendee); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		switch {
+		case strings.TrimSpace(attendee.Name) == "":
+			problems = append(problems, fmt.Sprintf("line %d: no name", line))
+		case !strings.Contains(attendee.Email, "@"):
+			problems = append(problems, fmt.Sprintf("line %d: invalid email %q", line, attendee.Email))
+		case attendee.CreatedAt.IsZero():
+			problems = append(problems, fmt.Sprintf("line %d: no createdAt", line))
+		}
+		attendee.ID = ""
+		attendee.DeletedAt = nil
+		attendees = append(attendees, &attendee)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("\n  %s", strings.Join(problems, "\n  "))
+	}
+	return attendees, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"ai-india-workshop-backend/internal/backup"
+)
+
+// runBackup writes an archive of every attendee, speaker and session,
+// including those in the trash, to take before a risky change
+func runBackup(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "backup")
+	out := fs.String("out", "", "file to write (default standard output)")
+	if rest, err := parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageError(fs, "backup takes no arguments")
+	}
+
+	w := a.stdout
+	if *out != "" {
+		f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	buffered := bufio.NewWriter(w)
+	footer, err := backup.Create(ctx, a.repo, a.workshop, buffered)
+	if err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+
+	if *out != "" {
+		counts := footer.Counts
+		fmt.Fprintf(a.stdout, "Backed up %d speakers, %d sessions and %d attendees of %s to %s\n", counts.Speakers, counts.Sessions, counts.Attendees, a.workshop, *out)
+	}
+	return nil
+}
+
+// runRestore writes an archive into the workshop, which must be empty
+// unless -replace is given. An archive taken from another workshop gets new
+// IDs, with sessions pointed at their speakers' new IDs.
+func runRestore(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "restore")
+	replace := fs.Bool("replace", false, "permanently remove the workshop's attendees, speakers and sessions first")
+	dryRun := fs.Bool("dry-run", false, "check the archive and print what it holds without restoring it")
+	files, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return usageError(fs, "restore takes one file, or - for standard input")
+	}
+
+	var r io.Reader = a.stdin
+	if files[0] != "-" {
+		f, err := os.Open(files[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	archive, err := backup.Read(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", files[0], err)
+	}
+
+	counts := archive.Footer.Counts
+	if *dryRun {
+		fmt.Fprintf(a.stdout, "Dry run: %s holds %d speakers, %d sessions and %d attendees of %s, backed up at %s\n",
+			files[0], counts.Speakers, counts.Sessions, counts.Attendees, archive.Header.Workshop, archive.Header.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+		if archive.Header.Workshop != a.workshop {
+			fmt.Fprintf(a.stdout, "Restored into %s, the records would get new IDs\n", a.workshop)
+		}
+		return nil
+	}
+
+	result, err := backup.Restore(ctx, a.repo, archive, backup.RestoreOptions{Workshop: a.workshop, Replace: *replace})
+	if err != nil {
+		if errors.Is(err, backup.ErrWorkshopNotEmpty) {
+			return fmt.Errorf("%s already has attendees, speakers or sessions; run again with -replace to remove them", a.workshop)
+		}
+		return err
+	}
+
+	if result.Replaced != (backup.Counts{}) {
+		fmt.Fprintf(a.stdout, "Removed %d speakers, %d sessions and %d attendees\n", result.Replaced.Speakers, result.Replaced.Sessions, result.Replaced.Attendees)
+	}
+	fmt.Fprintf(a.stdout, "Restored %d speakers, %d sessions and %d attendees of %s into %s\n", counts.Speakers, counts.Sessions, counts.Attendees, result.Source, result.Workshop)
+	if result.Remapped {
+		fmt.Fprintln(a.stdout, "The records were given new IDs")
+	}
+	if result.DroppedSpeakerRefs > 0 {
+		fmt.Fprintf(a.stdout, "Left out %d session speakers that were not in the archive\n", result.DroppedSpeakerRefs)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+// Command workshopctl runs operations tasks against a workshop's data
+// without the HTTP server: seeding the agenda, moving attendees in and out,
+// recounting analytics, creating admin users, purging test data, printing
+// stats and backing up and restoring the workshop. It uses the same repository as the server, so it works
+// against Firestore, the Firestore emulator or a local file.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"ai-india-workshop-backend/internal/config"
+	"ai-india-workshop-backend/internal/repository"
+)
+
+// Backends workshopctl can work against
+const (
+	backendFirestore = "firestore"
+	backendEmulator  = "emulator"
+	backendLocal     = "local"
+)
+
+const (
+	// defaultEmulatorHost is where `gcloud emulators firestore start` listens
+	// unless told otherwise
+	defaultEmulatorHost = "localhost:8081"
+	// emulatorProjectID is used with the emulator when no project is set.
+	// Project IDs starting with demo- never reach real Google services.
+	emulatorProjectID = "demo-workshop"
+	// defaultDataDir holds the local backend's files
+	defaultDataDir = ".workshopctl"
+)
+
+// app is what a command works with
+type app struct {
+	repo     repository.RepositoryInterface
+	workshop string
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+}
+
+// command is one of workshopctl's subcommands. run gets the arguments after
+// the command's name.
+type command struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, a *app, args []string) error
+}
+
+// commands is filled in by init because the commands refer back to it for
+// their usage
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"seed":            {usage: "seed [-dry-run] <file.yaml>", help: "add speakers and sessions from a YAML file", run: runSeed},
+		"attendees":       {usage: "attendees export [-out file] | import [-dry-run] <file>", help: "export or import attendees as NDJSON", run: runAttendees},
+		"recount":         {usage: "recount", help: "recompute the analytics counters from the attendees", run: runRecount},
+		"admin":           {usage: "admin create -email <email> [-role role] [-name name] [-password password]", help: "create an admin user", run: runAdmin},
+		"purge-test-data": {usage: "purge-test-data [-domain example.com]... [-yes]", help: "permanently remove attendees with test email addresses", run: runPurgeTestData},
+		"stats":           {usage: "stats", help: "print counts of attendees, the agenda, the trash and jobs", run: runStats},
+		"backup":          {usage: "backup [-out file]", help: "back up the attendees, speakers and sessions to an archive", run: runBackup},
+		"restore":         {usage: "restore [-replace] [-dry-run] <file>", help: "restore a backup archive into the workshop", run: runRestore},
+	}
+}
+
+// errUsage is returned for a command line that cannot be run; the usage has
+// already been printed
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "workshopctl:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// options are the flags that come before the command
+type options struct {
+	backend      string
+	workshop     string
+	dataDir      string
+	emulatorHost string
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var opts options
+	fs := flag.NewFlagSet("workshopctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&opts.backend, "backend", backendFirestore, "where the data is: firestore, emulator or local")
+	fs.StringVar(&opts.workshop, "workshop", "", "workshop ID (default FIRESTORE_SUBCOLLECTION_ID)")
+	fs.StringVar(&opts.dataDir, "data-dir", defaultDataDir, "directory of the local backend's files")
+	fs.StringVar(&opts.emulatorHost, "emulator-host", "", "Firestore emulator address (default FIRESTORE_EMULATOR_HOST or "+defaultEmulatorHost+")")
+	fs.Usage = func() { printUsage(fs) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return errUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return errUsage
+	}
+
+	repo, workshop, closeRepo, err := openRepository(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+
+	return cmd.run(ctx, &app{repo: repo, workshop: workshop, stdin: stdin, stdout: stdout, stderr: stderr}, fs.Args()[1:])
+}
+
+func printUsage(fs *flag.FlagSet) {
+	out := fs.Output()
+	fmt.Fprintln(out, "Usage: workshopctl [flags] <command> [arguments]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-16s %s\n", name, commands[name].help)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Flags:")
+	fs.PrintDefaults()
+}
+
+// openRepository connects to the backend and returns the ID of its workshop.
+// Firestore and the emulator are configured like the server, from the
+// environment, .env and CONFIG_FILE.
+func openRepository(ctx context.Context, opts options) (repository.RepositoryInterface, string, func(), error) {
+	if opts.workshop != "" {
+		os.Setenv("FIRESTORE_SUBCOLLECTION_ID", opts.workshop)
+	}
+
+	switch opts.backend {
+	case backendLocal:
+		workshop := opts.workshop
+		if workshop == "" {
+			workshop = os.Getenv("FIRESTORE_SUBCOLLECTION_ID")
+		}
+		repo, err := repository.NewLocalRepository(opts.dataDir, workshop)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return repo, workshop, func() { repo.Close() }, nil
+
+	case backendFirestore, backendEmulator:
+		if opts.backend == backendEmulator {
+			host := opts.emulatorHost
+			if host == "" {
+				host = os.Getenv("FIRESTORE_EMULATOR_HOST")
+			}
+			if host == "" {
+				host = defaultEmulatorHost
+			}
+			// The Firestore client connects to the emulator, without
+			// credentials, whenever this is set
+			os.Setenv("FIRESTORE_EMULATOR_HOST", host)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("invalid configuration:\n%w", err)
+		}
+		repoCfg := repository.Config{
+			SubcollectionID: cfg.Firestore.SubcollectionID,
+			CredentialsFile: cfg.Firestore.CredentialsFile,
+			ProjectID:       cfg.Firestore.ProjectID,
+		}
+		if opts.backend == backendEmulator {
+			repoCfg.CredentialsFile = ""
+			if repoCfg.ProjectID == "" {
+				repoCfg.ProjectID = emulatorProjectID
+			}
+		}
+		repo, err := repository.NewRepository(ctx, repoCfg)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("connecting to Firestore: %w", err)
+		}
+		return repo, repoCfg.SubcollectionID, func() { repo.Close() }, nil
+
+	default:
+		return nil, "", nil, fmt.Errorf("unknown backend %q, must be %s", opts.backend, strings.Join([]string{backendFirestore, backendEmulator, backendLocal}, ", "))
+	}
+}
+
+// newFlagSet returns the flag set of a command, printing its usage line on errors
+func newFlagSet(a *app, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(a.stderr, "Usage: workshopctl", commands[strings.Fields(name)[0]].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses a command's flags, which may come before or after its
+// positional arguments, and returns the positional arguments
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// usageError prints a command's usage after a problem with its arguments
+func usageError(fs *flag.FlagSet, format string, args ...any) error {
+	fmt.Fprintf(fs.Output(), format+"\n", args...)
+	fs.Usage()
+	return errUsage
+}
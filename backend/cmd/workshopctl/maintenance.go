@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"ai-india-workshop-backend/internal/models"
+)
+
+// testDomains are the domains reserved for documentation and testing (RFC
+// 2606), which no real attendee registers with
+var testDomains = []string{"example.com", "example.org", "example.net", "test", "invalid", "example", "localhost"}
+
+// runRecount rebuilds the analytics counters from the attendees, for when
+// they have drifted or data was changed outside the server
+func runRecount(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "recount")
+	if rest, err := parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageError(fs, "recount takes no arguments")
+	}
+
+	if err := a.repo.RebuildAnalytics(ctx); err != nil {
+		return fmt.Errorf("recounting analytics: %w", err)
+	}
+	fmt.Fprintln(a.stdout, "Analytics recounted")
+	return nil
+}
+
+// domainList collects repeated -domain flags
+type domainList []string
+
+func (d *domainList) String() string { return strings.Join(*d, ",") }
+
+func (d *domainList) Set(value string) error {
+	*d = append(*d, strings.ToLower(strings.TrimPrefix(strings.TrimSpace(value), "@")))
+	return nil
+}
+
+// runPurgeTestData permanently removes the attendees registered with test
+// email addresses, including those already in the trash, then recounts
+// the analytics without them. No webhook events are published for them.
+// It only lists them unless -yes is given.
+func runPurgeTestData(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "purge-test-data")
+	var domains domainList
+	fs.Var(&domains, "domain", "email domain of test attendees, repeatable (default the reserved example and .test domains)")
+	yes := fs.Bool("yes", false, "remove them rather than only listing them")
+	if rest, err := parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageError(fs, "purge-test-data takes no arguments")
+	}
+	if len(domains) == 0 {
+		domains = testDomains
+	}
+
+	live, err := a.repo.GetAllAttendees(ctx)
+	if err != nil {
+		return fmt.Errorf("loading attendees: %w", err)
+	}
+	trash, err := a.repo.GetTrash(ctx)
+	if err != nil {
+		return fmt.Errorf("loading trash: %w", err)
+	}
+
+	var matched []*models.Attendee
+	for _, attendee := range append(live, trash.Attendees...) {
+		if isTestEmail(attendee.Email, domains) {
+			matched = append(matched, attendee)
+		}
+	}
+	for _, attendee := range matched {
+		fmt.Fprintf(a.stdout, "%s\t%s\t%s\n", attendee.ID, attendee.Email, attendee.Name)
+	}
+	if !*yes {
+		fmt.Fprintf(a.stdout, "%d test attendees found; run again with -yes to remove them\n", len(matched))
+		return nil
+	}
+
+	if len(matched) > 0 {
+		ids := make([]string, len(matched))
+		for i, attendee := range matched {
+			ids[i] = attendee.ID
+		}
+		if err := a.repo.PurgeAttendees(ctx, ids); err != nil {
+			return fmt.Errorf("removing test attendees: %w", err)
+		}
+		if err := a.repo.RebuildAnalytics(ctx); err != nil {
+			return fmt.Errorf("recounting analytics: %w", err)
+		}
+	}
+	fmt.Fprintf(a.stdout, "Removed %d test attendees\n", len(matched))
+	return nil
+}
+
+// isTestEmail reports whether an email is at one of the domains or their
+// subdomains
+func isTestEmail(email string, domains []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// runStats prints a summary of the workshop
+func runStats(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "stats")
+	if rest, err := parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usageError(fs, "stats takes no arguments")
+	}
+
+	attendees, err := a.repo.GetAllAttendees(ctx)
+	if err != nil {
+		return fmt.Errorf("loading attendees: %w", err)
+	}
+	breakdown, err := a.repo.GetDesignationBreakdown(ctx)
+	if err != nil {
+		return fmt.Errorf("loading designations: %w", err)
+	}
+	speakers, err := a.repo.GetAllSpeakers(ctx)
+	if err != nil {
+		return fmt.Errorf("loading speakers: %w", err)
+	}
+	sessions, err := a.repo.GetAllSessions(ctx)
+	if err != nil {
+		return fmt.Errorf("loading sessions: %w", err)
+	}
+	trash, err := a.repo.GetTrash(ctx)
+	if err != nil {
+		return fmt.Errorf("loading trash: %w", err)
+	}
+	pending, err := a.repo.GetJobs(ctx, models.JobQuery{Status: models.JobPending})
+	if err != nil {
+		return fmt.Errorf("loading jobs: %w", err)
+	}
+	failed, err := a.repo.GetJobs(ctx, models.JobQuery{Status: models.JobFailed})
+	if err != nil {
+		return fmt.Errorf("loading jobs: %w", err)
+	}
+
+	checkedIn := 0
+	for _, attendee := range attendees {
+		if attendee.CheckedInAt != nil {
+			checkedIn++
+		}
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Attendees\t%d\n", len(attendees))
+	fmt.Fprintf(w, "Checked in\t%d\n", checkedIn)
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Count != breakdown[j].Count {
+			return breakdown[i].Count > breakdown[j].Count
+		}
+		return breakdown[i].Designation < breakdown[j].Designation
+	})
+	for _, count := range breakdown {
+		designation := count.Designation
+		if designation == "" {
+			designation = "(none)"
+		}
+		fmt.Fprintf(w, "  %s\t%d\n", designation, count.Count)
+	}
+	fmt.Fprintf(w, "Speakers\t%d\n", len(speakers))
+	fmt.Fprintf(w, "Sessions\t%d\n", len(sessions))
+	fmt.Fprintf(w, "In the trash\t%d attendees, %d speakers, %d sessions\n", len(trash.Attendees), len(trash.Speakers), len(trash.Sessions))
+	fmt.Fprintf(w, "Jobs\t%d pending, %d failed\n", len(pending), len(failed))
+	return w.Flush()
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"ai-india-workshop-backend/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// seedFile is the agenda read by the seed command. Sessions name their
+// speakers by key, or by name for speakers without one:
+//
+//	speakers:
+//	  - key: asha
+//	    name: Asha Rao
+//	    bio: Builds search at Example Corp
+//	sessions:
+//	  - title: Keynote
+//	    time: "09:30"
+//	    speakers: [asha]
+type seedFile struct {
+	Speakers []seedSpeaker `yaml:"speakers"`
+	Sessions []seedSession `yaml:"sessions"`
+}
+
+type seedSpeaker struct {
+	Key      string `yaml:"key"`
+	Name     string `yaml:"name"`
+	Bio      string `yaml:"bio"`
+	Avatar   string `yaml:"avatar"`
+	LinkedIn string `yaml:"linkedin"`
+	Twitter  string `yaml:"twitter"`
+}
+
+type seedSession struct {
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Time        string   `yaml:"time"`
+	Speakers    []string `yaml:"speakers"`
+}
+
+// runSeed adds the speakers and sessions in a YAML file. Speakers already in
+// the workshop are matched by name and sessions by title and left as they
+// are, so seeding the same file twice adds nothing the second time.
+func runSeed(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet(a, "seed")
+	dryRun := fs.Bool("dry-run", false, "print what would be added without adding it")
+	files, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return usageError(fs, "seed takes one file")
+	}
+
+	seed, err := readSeedFile(files[0])
+	if err != nil {
+		return err
+	}
+
+	existingSpeakers, err := a.repo.GetAllSpeakers(ctx)
+	if err != nil {
+		return fmt.Errorf("loading speakers: %w", err)
+	}
+	speakerIDs := map[string]string{}
+	for _, speaker := range existingSpeakers {
+		speakerIDs[speaker.Name] = speaker.ID
+	}
+	if err := checkSpeakerRefs(seed, speakerIDs); err != nil {
+		return fmt.Errorf("%s: %w", files[0], err)
+	}
+
+	// keys maps the references sessions may use to speaker IDs
+	keys := map[string]string{}
+	added, skipped := 0, 0
+	for _, s := range seed.Speakers {
+		id, exists := speakerIDs[s.Name]
+		if exists {
+			skipped++
+			fmt.Fprintf(a.stdout, "speaker %q exists, skipped\n", s.Name)
+		} else {
+			speaker := &models.Speaker{Name: s.Name, Bio: s.Bio, Avatar: s.Avatar, LinkedIn: s.LinkedIn, Twitter: s.Twitter}
+			if !*dryRun {
+				if err := a.repo.CreateSpeaker(ctx, speaker); err != nil {
+					return fmt.Errorf("adding speaker %q: %w", s.Name, err)
+				}
+			}
+			id = speaker.ID
+			added++
+			fmt.Fprintf(a.stdout, "speaker %q added\n", s.Name)
+		}
+		keys[s.Name] = id
+		if s.Key != "" {
+			keys[s.Key] = id
+		}
+	}
+	// Sessions may also name speakers added before, and left out of the file
+	for name, id := range speakerIDs {
+		if _, ok := keys[name]; !ok {
+			keys[name] = id
+		}
+	}
+
+	existingSessions, err := a.repo.GetAllSessions(ctx)
+	if err != nil {
+		return fmt.Errorf("loading sessions: %w", err)
+	}
+	titles := map[string]bool{}
+	for _, session := range existingSessions {
+		titles[session.Title] = true
+	}
+
+	for _, s := range seed.Sessions {
+		if titles[s.Title] {
+			skipped++
+			fmt.Fprintf(a.stdout, "session %q exists, skipped\n", s.Title)
+			continue
+		}
+		session := &models.Session{Title: s.Title, Description: s.Description, Time: s.Time, Speakers: []string{}}
+		for _, ref := range s.Speakers {
+			session.Speakers = append(session.Speakers, keys[ref])
+		}
+		if !*dryRun {
+			if err := a.repo.CreateSession(ctx, session); err != nil {
+				return fmt.Errorf("adding session %q: %w", s.Title, err)
+			}
+		}
+		titles[s.Title] = true
+		added++
+		fmt.Fprintf(a.stdout, "session %q added\n", s.Title)
+	}
+
+	if *dryRun {
+		fmt.Fprintf(a.stdout, "Dry run: %d would be added, %d skipped\n", added, skipped)
+	} else {
+		fmt.Fprintf(a.stdout, "%d added, %d skipped\n", added, skipped)
+	}
+	return nil
+}
+
+// readSeedFile reads a seed file and checks that every speaker has a name
+// and every session a title
+func readSeedFile(path string) (*seedFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var seed seedFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&seed); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	var problems []string
+	keys := map[string]bool{}
+	for i := range seed.Speakers {
+		s := &seed.Speakers[i]
+		s.Name = strings.TrimSpace(s.Name)
+		if s.Name == "" {
+			problems = append(problems, fmt.Sprintf("speaker %d has no name", i+1))
+		}
+		if s.Key != "" && keys[s.Key] {
+			problems = append(problems, fmt.Sprintf("speaker key %q is used twice", s.Key))
+		}
+		if s.Key != "" {
+			keys[s.Key] = true
+		}
+	}
+	for i := range seed.Sessions {
+		s := &seed.Sessions[i]
+		s.Title = strings.TrimSpace(s.Title)
+		if s.Title == "" {
+			problems = append(problems, fmt.Sprintf("session %d has no title", i+1))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s:\n  %s", path, strings.Join(problems, "\n  "))
+	}
+	return &seed, nil
+}
+
+// checkSpeakerRefs makes sure every speaker a session names is in the file,
+// by key or name, or already in the workshop, before anything is added
+func checkSpeakerRefs(seed *seedFile, existing map[string]string) error {
+	known := map[string]bool{}
+	for name := range existing {
+		known[name] = true
+	}
+	for _, s := range seed.Speakers {
+		known[s.Name] = true
+		if s.Key != "" {
+			known[s.Key] = true
+		}
+	}
+
+	var unknown []string
+	for _, s := range seed.Sessions {
+		for _, ref := range s.Speakers {
+			if !known[ref] {
+				unknown = append(unknown, fmt.Sprintf("session %q names unknown speaker %q", s.Title, ref))
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		return errors.New(strings.Join(unknown, "; "))
+	}
+	return nil
+}
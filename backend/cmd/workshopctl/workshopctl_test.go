@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/backup"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workshop is a local workshop that commands are run against
+type workshop struct {
+	t       *testing.T
+	dataDir string
+}
+
+func newWorkshop(t *testing.T) *workshop {
+	// openRepository sets the workshop ID in the environment
+	t.Setenv("FIRESTORE_SUBCOLLECTION_ID", "")
+	return &workshop{t: t, dataDir: t.TempDir()}
+}
+
+// run runs workshopctl and returns what it printed
+func (w *workshop) run(stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"-backend", "local", "-data-dir", w.dataDir, "-workshop", "test-workshop"}, args...)
+	err := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String() + stderr.String(), err
+}
+
+// repo opens the workshop's data, as left by the last command
+func (w *workshop) repo() repository.RepositoryInterface {
+	repo, err := repository.NewLocalRepository(w.dataDir, "test-workshop")
+	require.NoError(w.t, err)
+	return repo
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+const agenda = `
+speakers:
+  - key: asha
+    name: Asha Rao
+    bio: Builds search
+  - name: Ravi Kumar
+sessions:
+  - title: Keynote
+    time: "09:30"
+    speakers: [asha]
+  - title: Panel
+    time: "11:00"
+    speakers: [asha, Ravi Kumar]
+`
+
+func TestSeed(t *testing.T) {
+	w := newWorkshop(t)
+	path := writeFile(t, "agenda.yaml", agenda)
+	ctx := context.Background()
+
+	out, err := w.run("", "seed", "-dry-run", path)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Dry run: 4 would be added, 0 skipped")
+	speakers, err := w.repo().GetAllSpeakers(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, speakers)
+
+	out, err = w.run("", "seed", path)
+	require.NoError(t, err)
+	assert.Contains(t, out, "4 added, 0 skipped")
+
+	speakers, err = w.repo().GetAllSpeakers(ctx)
+	require.NoError(t, err)
+	require.Len(t, speakers, 2)
+	ids := map[string]string{}
+	for _, speaker := range speakers {
+		ids[speaker.Name] = speaker.ID
+	}
+	sessions, err := w.repo().GetAllSessions(ctx)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	for _, session := range sessions {
+		if session.Title == "Panel" {
+			assert.Equal(t, []string{ids["Asha Rao"], ids["Ravi Kumar"]}, session.Speakers)
+		}
+	}
+
+	// Seeding again adds nothing
+	out, err = w.run("", "seed", path)
+	require.NoError(t, err)
+	assert.Contains(t, out, "0 added, 4 skipped")
+}
+
+func TestSeed_RejectsBadFiles(t *testing.T) {
+	w := newWorkshop(t)
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{name: "unknown speaker", content: "sessions:\n  - title: Keynote\n    speakers: [nobody]\n", message: `unknown speaker "nobody"`},
+		{name: "no title", content: "sessions:\n  - time: \"09:30\"\n", message: "session 1 has no title"},
+		{name: "unknown key", content: "speaker:\n  - name: Asha\n", message: "field speaker not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := w.run("", "seed", writeFile(t, "agenda.yaml", tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+
+	sessions, err := w.repo().GetAllSessions(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestAttendees_ExportThenImport(t *testing.T) {
+	source := newWorkshop(t)
+	ctx := context.Background()
+	registered := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	checkedIn := registered.Add(24 * time.Hour)
+	repo := source.repo()
+	require.NoError(t, repo.CreateAttendee(ctx, &models.Attendee{Name: "Asha Rao", Email: "asha@example.com", Designation: "Engineer", CreatedAt: registered, CheckedInAt: &checkedIn}))
+	require.NoError(t, repo.CreateAttendee(ctx, &models.Attendee{Name: "Ravi Kumar", Email: "ravi@example.com", CreatedAt: registered.Add(time.Hour)}))
+
+	exported := filepath.Join(t.TempDir(), "attendees.ndjson")
+	out, err := source.run("", "attendees", "export", "-out", exported)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Exported 2 attendees")
+
+	target := newWorkshop(t)
+	require.NoError(t, target.repo().CreateAttendee(ctx, &models.Attendee{Name: "Asha", Email: "ASHA@example.com", CreatedAt: time.Now()}))
+
+	out, err = target.run("", "attendees", "import", exported)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Imported 1 attendees, 1 already registered")
+
+	attendees, err := target.repo().GetAllAttendees(ctx)
+	require.NoError(t, err)
+	require.Len(t, attendees, 2)
+	assert.Equal(t, "ravi@example.com", attendees[1].Email)
+	assert.True(t, registered.Add(time.Hour).Equal(attendees[1].CreatedAt))
+
+	// Importing again changes nothing
+	out, err = target.run("", "attendees", "import", exported)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Imported 0 attendees, 2 already registered")
+
+	// Only the attendee who registered in the target is published
+	jobs, err := target.repo().GetJobs(ctx, models.JobQuery{})
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func TestAttendees_ImportFromStdinKeepsCheckIns(t *testing.T) {
+	w := newWorkshop(t)
+	line, err := json.Marshal(models.Attendee{
+		Name:        "Asha Rao",
+		Email:       "asha@example.com",
+		CreatedAt:   time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		CheckedInAt: &[]time.Time{time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC)}[0],
+	})
+	require.NoError(t, err)
+
+	_, err = w.run(string(line)+"\n\n", "attendees", "import", "-")
+	require.NoError(t, err)
+
+	attendees, err := w.repo().GetAllAttendees(context.Background())
+	require.NoError(t, err)
+	require.Len(t, attendees, 1)
+	require.NotNil(t, attendees[0].CheckedInAt)
+
+	// The imported check-in is counted
+	days, err := w.repo().GetAnalyticsDays(context.Background())
+	require.NoError(t, err)
+	require.Len(t, days, 2)
+	assert.Equal(t, map[string]int{"36": 1}, days[1].CheckIns)
+}
+
+func TestAttendees_ImportRejectsBadLines(t *testing.T) {
+	w := newWorkshop(t)
+	input := `{"name":"Asha Rao","email":"asha@example.com","createdAt":"2026-10-01T09:00:00Z"}
+not json
+{"name":"","email":"ravi@example.com","createdAt":"2026-10-01T09:00:00Z"}
+{"name":"Meera","email":"meera","createdAt":"2026-10-01T09:00:00Z"}
+`
+	_, err := w.run(input, "attendees", "import", "-")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+	assert.Contains(t, err.Error(), "line 3: no name")
+	assert.Contains(t, err.Error(), `line 4: invalid email "meera"`)
+
+	// Nothing is imported from a file with problems
+	count, err := w.repo().GetAttendeeCount(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestAdminCreate(t *testing.T) {
+	w := newWorkshop(t)
+	ctx := context.Background()
+
+	out, err := w.run("", "admin", "create", "-email", "Owner@Example.com", "-role", auth.RoleOwner)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Created owner owner@example.com")
+	require.Contains(t, out, "Temporary password: ")
+	password := strings.TrimSpace(out[strings.Index(out, "Temporary password: ")+len("Temporary password: "):])
+
+	user, err := w.repo().GetAdminUserByEmail(ctx, "owner@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleOwner, user.Role)
+	assert.True(t, auth.CheckPassword(user.PasswordHash, password))
+
+	_, err = w.run("", "admin", "create", "-email", "owner@example.com")
+	assert.ErrorContains(t, err, "already exists")
+
+	_, err = w.run("", "admin", "create", "-email", "editor@example.com", "-role", "superuser")
+	assert.ErrorIs(t, err, errUsage)
+}
+
+func TestPurgeTestData(t *testing.T) {
+	w := newWorkshop(t)
+	ctx := context.Background()
+	repo := w.repo()
+	for _, email := range []string{"asha@gmail.com", "test1@example.com", "test2@mail.example.org", "qa@staging.acme.io", "ravi@acme.io"} {
+		require.NoError(t, repo.CreateAttendee(ctx, &models.Attendee{Name: email, Email: email, CreatedAt: time.Now()}))
+	}
+	registrations, err := repo.GetJobs(ctx, models.JobQuery{})
+	require.NoError(t, err)
+
+	out, err := w.run("", "purge-test-data")
+	require.NoError(t, err)
+	assert.Contains(t, out, "2 test attendees found")
+	count, err := w.repo().GetAttendeeCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+
+	out, err = w.run("", "purge-test-data", "-yes")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Removed 2 test attendees")
+
+	out, err = w.run("", "purge-test-data", "-domain", "@staging.acme.io", "-yes")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Removed 1 test attendees")
+
+	attendees, err := w.repo().GetAllAttendees(ctx)
+	require.NoError(t, err)
+	var emails []string
+	for _, attendee := range attendees {
+		emails = append(emails, attendee.Email)
+	}
+	assert.ElementsMatch(t, []string{"asha@gmail.com", "ravi@acme.io"}, emails)
+
+	// They are gone for good, not in the trash
+	trash, err := w.repo().GetTrash(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, trash.Attendees)
+
+	// Removing them publishes no cancellations
+	jobs, err := w.repo().GetJobs(ctx, models.JobQuery{})
+	require.NoError(t, err)
+	assert.Len(t, jobs, len(registrations))
+}
+
+func TestStats(t *testing.T) {
+	w := newWorkshop(t)
+	ctx := context.Background()
+	repo := w.repo()
+	for _, designation := range []string{"Engineer", "Engineer", "Student"} {
+		require.NoError(t, repo.CreateAttendee(ctx, &models.Attendee{Name: "A", Email: "a@acme.io", Designation: designation, CreatedAt: time.Now()}))
+	}
+	attendees, err := repo.GetAllAttendees(ctx)
+	require.NoError(t, err)
+	_, err = repo.CheckInAttendee(ctx, attendees[0].ID)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateSpeaker(ctx, &models.Speaker{Name: "Asha Rao"}))
+
+	out, err := w.run("", "stats")
+	require.NoError(t, err)
+	assert.Regexp(t, `Attendees\s+3\n`, out)
+	assert.Regexp(t, `Checked in\s+1\n`, out)
+	assert.Regexp(t, `Engineer\s+2\n\s+Student\s+1\n`, out)
+	assert.Regexp(t, `Speakers\s+1\n`, out)
+	// Three registrations, a check-in and a new speaker are waiting in the outbox
+	assert.Regexp(t, `Jobs\s+5 pending, 0 failed\n`, out)
+}
+
+func TestBackupThenRestore(t *testing.T) {
+	w := newWorkshop(t)
+	ctx := context.Background()
+	_, err := w.run("", "seed", writeFile(t, "agenda.yaml", agenda))
+	require.NoError(t, err)
+	repo := w.repo()
+	require.NoError(t, repo.CreateAttendee(ctx, &models.Attendee{Name: "Meera", Email: "meera@example.com", CreatedAt: time.Now()}))
+
+	archive := filepath.Join(t.TempDir(), "backup.ndjson")
+	out, err := w.run("", "backup", "-out", archive)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Backed up 2 speakers, 2 sessions and 1 attendees of test-workshop")
+
+	before, err := w.repo().GetWorkshopData(ctx)
+	require.NoError(t, err)
+	sessions := before.Sessions
+
+	// A risky change goes wrong
+	repo = w.repo()
+	require.NoError(t, repo.DeleteSession(ctx, sessions[0].ID))
+	require.NoError(t, repo.DeleteSpeaker(ctx, before.Speakers[0].ID))
+
+	_, err = w.run("", "restore", archive)
+	require.ErrorContains(t, err, "run again with -replace")
+
+	out, err = w.run("", "restore", "-dry-run", archive)
+	require.NoError(t, err)
+	assert.Contains(t, out, "holds 2 speakers, 2 sessions and 1 attendees of test-workshop")
+
+	out, err = w.run("", "restore", "-replace", archive)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Removed 2 speakers, 2 sessions and 1 attendees")
+	assert.NotContains(t, out, "new IDs")
+
+	after, err := w.repo().GetWorkshopData(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, before.Sessions, after.Sessions)
+	assert.Equal(t, before.Speakers, after.Speakers)
+	live, err := w.repo().GetAllSessions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, live, 2)
+}
+
+func TestRestore_IntoAnotherWorkshop(t *testing.T) {
+	w := newWorkshop(t)
+	ctx := context.Background()
+	_, err := w.run("", "seed", writeFile(t, "agenda.yaml", agenda))
+	require.NoError(t, err)
+
+	archive, err := w.run("", "backup")
+	require.NoError(t, err)
+
+	out, err := w.run(archive, "-workshop", "next-year", "restore", "-")
+	require.NoError(t, err)
+	assert.Contains(t, out, "of test-workshop into next-year")
+	assert.Contains(t, out, "new IDs")
+
+	source, err := w.repo().GetWorkshopData(ctx)
+	require.NoError(t, err)
+	copied, err := repository.NewLocalRepository(w.dataDir, "next-year")
+	require.NoError(t, err)
+	data, err := copied.GetWorkshopData(ctx)
+	require.NoError(t, err)
+	require.Len(t, data.Speakers, len(source.Speakers))
+	speakers := map[string]string{}
+	for _, speaker := range data.Speakers {
+		speakers[speaker.ID] = speaker.Name
+	}
+	for _, session := range data.Sessions {
+		assert.NotContains(t, []string{source.Sessions[0].ID, source.Sessions[1].ID}, session.ID)
+		for _, id := range session.Speakers {
+			assert.Contains(t, speakers, id, "session %q points at a speaker that was not restored", session.Title)
+		}
+	}
+}
+
+func TestRestore_RejectsDamagedArchives(t *testing.T) {
+	w := newWorkshop(t)
+	_, err := w.run("", "seed", writeFile(t, "agenda.yaml", agenda))
+	require.NoError(t, err)
+	archive, err := w.run("", "backup")
+	require.NoError(t, err)
+
+	target := newWorkshop(t)
+	_, err = target.run(archive[:len(archive)-10], "restore", "-")
+	require.ErrorIs(t, err, backup.ErrInvalidArchive)
+
+	_, err = target.run(archive, "restore")
+	assert.ErrorIs(t, err, errUsage)
+
+	data, err := target.repo().GetWorkshopData(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, data.Speakers)
+}
+
+func TestRun_Usage(t *testing.T) {
+	w := newWorkshop(t)
+
+	out, err := w.run("", "frobnicate")
+	assert.ErrorIs(t, err, errUsage)
+	assert.Contains(t, out, `unknown command "frobnicate"`)
+	assert.Contains(t, out, "purge-test-data")
+
+	_, err = w.run("", "seed")
+	assert.ErrorIs(t, err, errUsage)
+
+	var stdout, stderr bytes.Buffer
+	err = run(context.Background(), []string{"-backend", "mainframe", "stats"}, nil, &stdout, &stderr)
+	assert.ErrorContains(t, err, `unknown backend "mainframe"`)
+}
+
+func TestIsTestEmail(t *testing.T) {
+	assert.True(t, isTestEmail("a@example.com", testDomains))
+	assert.True(t, isTestEmail("a@EXAMPLE.NET", testDomains))
+	assert.True(t, isTestEmail("a@qa.test", testDomains))
+	assert.False(t, isTestEmail("a@example.company.com", testDomains))
+	assert.False(t, isTestEmail("a@notexample.com", testDomains))
+	assert.False(t, isTestEmail("no-at-sign", testDomains))
+}
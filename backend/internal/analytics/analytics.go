@@ -0,0 +1,194 @@
+// Package analytics turns attendee events into the time series on the admin
+// dashboard. Events are counted as they happen in one small document per day
+// (see models.AnalyticsDay), so a series never has to read the attendees.
+package analytics
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+)
+
+// Series granularities
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+)
+
+// SlotDuration is the resolution events are counted at. Every timezone
+// offset is a whole number of quarter hours, so slots never straddle the
+// start of a local hour.
+const SlotDuration = 15 * time.Minute
+
+// DateLayout formats AnalyticsDay.Date, which doubles as the document ID
+const DateLayout = "2006-01-02"
+
+var events = []string{models.AnalyticsRegistrations, models.AnalyticsCancellations, models.AnalyticsCheckIns}
+
+// ValidGranularity reports whether granularity is one Series supports
+func ValidGranularity(granularity string) bool {
+	return granularity == GranularityHour || granularity == GranularityDay
+}
+
+// Slot returns the day and the slot within it that an event at t is counted in
+func Slot(t time.Time) (date, slot string) {
+	t = t.UTC()
+	dayStart := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return t.Format(DateLayout), strconv.Itoa(int(t.Sub(dayStart) / SlotDuration))
+}
+
+// counts returns the map an event is counted in
+func counts(day *models.AnalyticsDay, event string) *map[string]int {
+	switch event {
+	case models.AnalyticsRegistrations:
+		return &day.Registrations
+	case models.AnalyticsCancellations:
+		return &day.Cancellations
+	default:
+		return &day.CheckIns
+	}
+}
+
+// Build counts the events of every attendee from scratch. Cancelled
+// attendees must be included so their registration is still counted. Days
+// are returned in order.
+func Build(attendees []*models.Attendee) []*models.AnalyticsDay {
+	byDate := map[string]*models.AnalyticsDay{}
+	add := func(event string, t time.Time) {
+		date, slot := Slot(t)
+		day, ok := byDate[date]
+		if !ok {
+			day = &models.AnalyticsDay{Date: date}
+			byDate[date] = day
+		}
+		m := counts(day, event)
+		if *m == nil {
+			*m = map[string]int{}
+		}
+		(*m)[slot]++
+	}
+
+	for _, attendee := range attendees {
+		add(models.AnalyticsRegistrations, attendee.CreatedAt)
+		if attendee.DeletedAt != nil {
+			add(models.AnalyticsCancellations, *attendee.DeletedAt)
+		}
+		if attendee.CheckedInAt != nil {
+			add(models.AnalyticsCheckIns, *attendee.CheckedInAt)
+		}
+	}
+
+	days := make([]*models.AnalyticsDay, 0, len(byDate))
+	for _, day := range byDate {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days
+}
+
+// Series buckets the counted events between start and end in loc. start
+// must be the start of an hour or day in loc; end is exclusive. Events
+// outside the range still count towards the totals and the cumulative curve.
+func Series(days []*models.AnalyticsDay, granularity string, start, end time.Time, loc *time.Location) *models.Analytics {
+	buckets := []models.AnalyticsBucket{}
+	for t := start.In(loc); t.Before(end); t = next(t, granularity) {
+		buckets = append(buckets, models.AnalyticsBucket{Start: t})
+	}
+
+	result := &models.Analytics{Granularity: granularity, Timezone: loc.String(), Buckets: buckets}
+	activeBefore := 0
+	for _, day := range days {
+		dayStart, err := time.Parse(DateLayout, day.Date)
+		if err != nil {
+			continue
+		}
+		for _, event := range events {
+			for slot, count := range *counts(day, event) {
+				n, err := strconv.Atoi(slot)
+				if err != nil {
+					continue
+				}
+				at := dayStart.Add(time.Duration(n) * SlotDuration)
+				if at.Before(start) {
+					activeBefore += activeDelta(event, count)
+					continue
+				}
+				if !at.Before(end) {
+					continue
+				}
+				i := sort.Search(len(buckets), func(i int) bool { return buckets[i].Start.After(at) }) - 1
+				if i >= 0 {
+					addToBucket(&buckets[i], event, count)
+				}
+			}
+		}
+	}
+
+	active := activeBefore
+	for i := range buckets {
+		active += buckets[i].Registrations - buckets[i].Cancellations
+		buckets[i].Cumulative = active
+	}
+
+	result.Totals = Totals(days)
+	return result
+}
+
+// Totals adds up every counted event
+func Totals(days []*models.AnalyticsDay) models.AnalyticsTotals {
+	var totals models.AnalyticsTotals
+	for _, day := range days {
+		for _, event := range events {
+			for _, count := range *counts(day, event) {
+				add(&totals, event, count)
+			}
+		}
+	}
+
+	totals.Active = totals.Registrations - totals.Cancellations
+	if totals.Active > 0 {
+		totals.ConversionRate = min(1, float64(totals.CheckIns)/float64(totals.Active))
+	}
+	return totals
+}
+
+func next(t time.Time, granularity string) time.Time {
+	if granularity == GranularityHour {
+		return t.Add(time.Hour)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+func activeDelta(event string, count int) int {
+	switch event {
+	case models.AnalyticsRegistrations:
+		return count
+	case models.AnalyticsCancellations:
+		return -count
+	}
+	return 0
+}
+
+func add(totals *models.AnalyticsTotals, event string, count int) {
+	switch event {
+	case models.AnalyticsRegistrations:
+		totals.Registrations += count
+	case models.AnalyticsCancellations:
+		totals.Cancellations += count
+	case models.AnalyticsCheckIns:
+		totals.CheckIns += count
+	}
+}
+
+func addToBucket(bucket *models.AnalyticsBucket, event string, count int) {
+	switch event {
+	case models.AnalyticsRegistrations:
+		bucket.Registrations += count
+	case models.AnalyticsCancellations:
+		bucket.Cancellations += count
+	case models.AnalyticsCheckIns:
+		bucket.CheckIns += count
+	}
+}
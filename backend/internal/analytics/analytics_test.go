@@ -0,0 +1,125 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ist has a half hour offset, so its hours start at half past in UTC
+var ist = time.FixedZone("IST", 5*60*60+30*60)
+
+func at(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestSlot(t *testing.T) {
+	tests := []struct {
+		at   string
+		date string
+		slot string
+	}{
+		{at: "2026-03-01T00:00:00Z", date: "2026-03-01", slot: "0"},
+		{at: "2026-03-01T00:14:59Z", date: "2026-03-01", slot: "0"},
+		{at: "2026-03-01T09:45:00Z", date: "2026-03-01", slot: "39"},
+		{at: "2026-03-01T23:59:59Z", date: "2026-03-01", slot: "95"},
+		// Slots are always UTC
+		{at: "2026-03-02T03:00:00+05:30", date: "2026-03-01", slot: "86"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.at, func(t *testing.T) {
+			date, slot := Slot(at(tt.at))
+			assert.Equal(t, tt.date, date)
+			assert.Equal(t, tt.slot, slot)
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	attendees := []*models.Attendee{
+		{CreatedAt: at("2026-03-02T10:00:00Z"), CheckedInAt: ptr(at("2026-03-05T04:00:00Z"))},
+		{CreatedAt: at("2026-03-01T10:05:00Z"), DeletedAt: ptr(at("2026-03-02T11:00:00Z"))},
+		{CreatedAt: at("2026-03-01T10:10:00Z")},
+	}
+
+	assert.Equal(t, []*models.AnalyticsDay{
+		{Date: "2026-03-01", Registrations: map[string]int{"40": 2}},
+		{Date: "2026-03-02", Registrations: map[string]int{"40": 1}, Cancellations: map[string]int{"44": 1}},
+		{Date: "2026-03-05", CheckIns: map[string]int{"16": 1}},
+	}, Build(attendees))
+}
+
+func TestSeries_Day(t *testing.T) {
+	attendees := []*models.Attendee{
+		// Before the range: counted in the totals and the starting cumulative
+		{CreatedAt: at("2026-02-20T10:00:00Z")},
+		// 23:00 UTC on 1 March is 04:30 on 2 March in IST
+		{CreatedAt: at("2026-03-01T23:00:00Z"), CheckedInAt: ptr(at("2026-03-03T04:00:00Z"))},
+		{CreatedAt: at("2026-03-02T10:00:00Z"), DeletedAt: ptr(at("2026-03-03T10:00:00Z"))},
+		{CreatedAt: at("2026-03-03T10:00:00Z")},
+		// After the range
+		{CreatedAt: at("2026-03-10T10:00:00Z")},
+	}
+
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, ist)
+	series := Series(Build(attendees), GranularityDay, start, start.AddDate(0, 0, 3), ist)
+
+	assert.Equal(t, GranularityDay, series.Granularity)
+	assert.Equal(t, "IST", series.Timezone)
+	require.Len(t, series.Buckets, 3)
+	assert.True(t, series.Buckets[0].Start.Equal(start))
+	assert.Equal(t, models.AnalyticsBucket{Start: series.Buckets[0].Start, Registrations: 2, Cumulative: 3}, series.Buckets[0])
+	assert.Equal(t, models.AnalyticsBucket{Start: series.Buckets[1].Start, Registrations: 1, Cancellations: 1, CheckIns: 1, Cumulative: 3}, series.Buckets[1])
+	assert.Equal(t, models.AnalyticsBucket{Start: series.Buckets[2].Start, Cumulative: 3}, series.Buckets[2])
+
+	assert.Equal(t, models.AnalyticsTotals{
+		Registrations:  5,
+		Cancellations:  1,
+		CheckIns:       1,
+		Active:         4,
+		ConversionRate: 0.25,
+	}, series.Totals)
+}
+
+func TestSeries_Hour(t *testing.T) {
+	attendees := []*models.Attendee{
+		// 09:30 to 10:29 UTC is the 15:00 hour in IST
+		{CreatedAt: at("2026-03-01T09:29:59Z")},
+		{CreatedAt: at("2026-03-01T09:30:00Z")},
+		{CreatedAt: at("2026-03-01T10:29:00Z")},
+		{CreatedAt: at("2026-03-01T10:30:00Z")},
+	}
+
+	start := time.Date(2026, 3, 1, 14, 0, 0, 0, ist)
+	series := Series(Build(attendees), GranularityHour, start, start.Add(3*time.Hour), ist)
+
+	require.Len(t, series.Buckets, 3)
+	var registrations, cumulative []int
+	for _, bucket := range series.Buckets {
+		registrations = append(registrations, bucket.Registrations)
+		cumulative = append(cumulative, bucket.Cumulative)
+	}
+	assert.Equal(t, []int{1, 2, 1}, registrations)
+	assert.Equal(t, []int{1, 3, 4}, cumulative)
+}
+
+func TestSeries_Empty(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	series := Series(nil, GranularityDay, start, start.AddDate(0, 0, 2), time.UTC)
+
+	assert.Len(t, series.Buckets, 2)
+	assert.Equal(t, models.AnalyticsTotals{}, series.Totals)
+}
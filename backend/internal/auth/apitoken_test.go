@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIToken(t *testing.T) {
+	token, id, err := GenerateAPIToken()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(token, APITokenPrefix))
+	assert.True(t, IsAPIToken(token))
+	assert.Equal(t, APITokenID(token), id)
+	assert.NotContains(t, id, token)
+	assert.Len(t, APITokenDisplayPrefix(token), len(APITokenPrefix)+6)
+
+	other, otherID, err := GenerateAPIToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+	assert.NotEqual(t, id, otherID)
+}
+
+func TestIsAPIToken(t *testing.T) {
+	assert.False(t, IsAPIToken(""))
+	assert.False(t, IsAPIToken(APITokenPrefix))
+	assert.False(t, IsAPIToken("some-session-id"))
+}
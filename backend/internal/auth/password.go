@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the shortest password accepted for an admin account
+const MinPasswordLength = 12
+
+// ErrPasswordTooShort is returned when a new password is below MinPasswordLength
+var ErrPasswordTooShort = errors.New("password must be at least 12 characters")
+
+// HashPassword returns the bcrypt hash of a password
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", ErrPasswordTooShort
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches the stored bcrypt hash
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// dummyHash is compared against when an account does not exist so that the
+// response takes as long as a real password check
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), bcrypt.DefaultCost)
+	return hash
+})
+
+// CheckDummyPassword does the same work as CheckPassword and always fails.
+// Call it for unknown emails so they cannot be told apart by response time.
+func CheckDummyPassword(password string) bool {
+	_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+	return false
+}
+
+// GeneratePassword creates a random temporary password for invited admins
+func GeneratePassword() (string, error) {
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// NormalizeEmail lower-cases and trims an email so lookups are case-insensitive
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse battery")
+	require.NoError(t, err)
+	assert.NotEqual(t, "correct horse battery", hash)
+
+	assert.True(t, CheckPassword(hash, "correct horse battery"))
+	assert.False(t, CheckPassword(hash, "wrong horse battery"))
+	assert.False(t, CheckPassword("", "correct horse battery"))
+}
+
+func TestHashPassword_TooShort(t *testing.T) {
+	_, err := HashPassword("short")
+	assert.ErrorIs(t, err, ErrPasswordTooShort)
+}
+
+func TestGeneratePassword(t *testing.T) {
+	first, err := GeneratePassword()
+	require.NoError(t, err)
+	second, err := GeneratePassword()
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, len(first), MinPasswordLength)
+	assert.NotEqual(t, first, second)
+}
+
+func TestCheckDummyPassword(t *testing.T) {
+	assert.False(t, CheckDummyPassword("dummy-password-for-timing"))
+	assert.False(t, CheckDummyPassword("anything"))
+}
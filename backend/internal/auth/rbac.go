@@ -0,0 +1,91 @@
+package auth
+
+// Admin roles, from most to least privileged
+const (
+	RoleOwner            = "owner"
+	RoleOrganiser        = "organiser"
+	RoleContentEditor    = "content_editor"
+	RoleCheckInVolunteer = "checkin_volunteer"
+	RoleAnalyst          = "analyst"
+)
+
+// Permissions checked by middleware.RequirePermission
+const (
+	PermAttendeesRead    = "attendees:read"
+	PermAttendeesDelete  = "attendees:delete"
+	PermAttendeesCheckIn = "attendees:checkin"
+	PermSpeakersWrite    = "speakers:write"
+	PermSessionsWrite    = "sessions:write"
+	PermFormManage       = "form:manage"
+	PermStatsRead        = "stats:read"
+	PermTrashRead        = "trash:read"
+	PermTrashManage      = "trash:manage"
+	PermAuditRead        = "audit:read"
+	PermUsersManage      = "users:manage"
+	PermWebhooksManage   = "webhooks:manage"
+	PermJobsManage       = "jobs:manage"
+	PermBackupManage     = "backup:manage"
+)
+
+// rolePermissions is the permission matrix. Roles not listed have no permissions.
+var rolePermissions = map[string][]string{
+	RoleOwner: {
+		PermAttendeesRead, PermAttendeesDelete, PermAttendeesCheckIn,
+		PermSpeakersWrite, PermSessionsWrite, PermFormManage,
+		PermStatsRead, PermTrashRead, PermTrashManage, PermAuditRead,
+		PermUsersManage, PermWebhooksManage, PermJobsManage, PermBackupManage,
+	},
+	RoleOrganiser: {
+		PermAttendeesRead, PermAttendeesDelete, PermAttendeesCheckIn,
+		PermSpeakersWrite, PermSessionsWrite, PermFormManage,
+		PermStatsRead, PermTrashRead, PermTrashManage, PermAuditRead,
+		PermJobsManage,
+	},
+	RoleContentEditor: {
+		PermSpeakersWrite, PermSessionsWrite,
+	},
+	RoleCheckInVolunteer: {
+		PermAttendeesRead, PermAttendeesCheckIn,
+	},
+	RoleAnalyst: {
+		PermAttendeesRead, PermStatsRead,
+	},
+}
+
+// Roles lists every assignable role
+func Roles() []string {
+	return []string{RoleOwner, RoleOrganiser, RoleContentEditor, RoleCheckInVolunteer, RoleAnalyst}
+}
+
+// ValidRole reports whether role is one of Roles
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// PermissionsFor returns the permissions granted to a role
+func PermissionsFor(role string) []string {
+	return append([]string{}, rolePermissions[role]...)
+}
+
+// Can reports whether a role has been granted a permission
+func Can(role, permission string) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidPermission reports whether permission is granted by any role
+func ValidPermission(permission string) bool {
+	for _, permissions := range rolePermissions {
+		for _, granted := range permissions {
+			if granted == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		role       string
+		permission string
+		expected   bool
+	}{
+		{role: RoleOwner, permission: PermUsersManage, expected: true},
+		{role: RoleOrganiser, permission: PermAttendeesDelete, expected: true},
+		{role: RoleOrganiser, permission: PermUsersManage, expected: false},
+		{role: RoleOrganiser, permission: PermFormManage, expected: true},
+		{role: RoleOwner, permission: PermWebhooksManage, expected: true},
+		{role: RoleOrganiser, permission: PermWebhooksManage, expected: false},
+		{role: RoleOrganiser, permission: PermJobsManage, expected: true},
+		{role: RoleContentEditor, permission: PermJobsManage, expected: false},
+		{role: RoleOwner, permission: PermBackupManage, expected: true},
+		{role: RoleOrganiser, permission: PermBackupManage, expected: false},
+		{role: RoleContentEditor, permission: PermSessionsWrite, expected: true},
+		{role: RoleContentEditor, permission: PermFormManage, expected: false},
+		{role: RoleContentEditor, permission: PermAttendeesRead, expected: false},
+		{role: RoleCheckInVolunteer, permission: PermAttendeesCheckIn, expected: true},
+		{role: RoleCheckInVolunteer, permission: PermAttendeesDelete, expected: false},
+		{role: RoleAnalyst, permission: PermStatsRead, expected: true},
+		{role: RoleAnalyst, permission: PermSpeakersWrite, expected: false},
+		{role: "", permission: PermAttendeesRead, expected: false},
+		{role: "superuser", permission: PermAttendeesRead, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+" "+tt.permission, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Can(tt.role, tt.permission))
+		})
+	}
+}
+
+func TestRoles(t *testing.T) {
+	for _, role := range Roles() {
+		assert.True(t, ValidRole(role), role)
+		assert.NotEmpty(t, PermissionsFor(role), role)
+	}
+	assert.False(t, ValidRole("superuser"))
+}
+
+func TestPermissionsFor_ReturnsCopy(t *testing.T) {
+	permissions := PermissionsFor(RoleAnalyst)
+	permissions[0] = PermUsersManage
+	assert.False(t, Can(RoleAnalyst, PermUsersManage))
+}
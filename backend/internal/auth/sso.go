@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SSORoleMapping decides which role, if any, an admin signing in through
+// single sign-on gets from their email address and provider groups
+type SSORoleMapping struct {
+	rules []ssoRule
+}
+
+type ssoRule struct {
+	kind  string
+	value string
+	role  string
+}
+
+// ParseSSORoleMapping reads comma separated kind:value=role rules, e.g. the
+// OIDC_ROLE_MAPPING setting:
+//
+//	group:workshop-organisers=organiser,domain:appdirect.com=analyst,email:jane@appdirect.com=owner
+//
+// Domain and email rules match the verified email address; group rules
+// match the groups claim of the ID token.
+func ParseSSORoleMapping(value string) (SSORoleMapping, error) {
+	var mapping SSORoleMapping
+	for _, rule := range strings.Split(value, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		match, role, ok := strings.Cut(rule, "=")
+		if !ok {
+			return SSORoleMapping{}, fmt.Errorf("rule %q must look like kind:value=role", rule)
+		}
+		kind, matchValue, ok := strings.Cut(strings.TrimSpace(match), ":")
+		if !ok || matchValue == "" {
+			return SSORoleMapping{}, fmt.Errorf("rule %q must look like kind:value=role", rule)
+		}
+		switch kind {
+		case "domain", "email":
+			matchValue = strings.ToLower(strings.TrimPrefix(matchValue, "@"))
+		case "group":
+		default:
+			return SSORoleMapping{}, fmt.Errorf("rule %q: unknown kind %q", rule, kind)
+		}
+		role = strings.TrimSpace(role)
+		if !ValidRole(role) {
+			return SSORoleMapping{}, fmt.Errorf("rule %q: unknown role %q", rule, role)
+		}
+
+		mapping.rules = append(mapping.rules, ssoRule{kind: kind, value: matchValue, role: role})
+	}
+	return mapping, nil
+}
+
+// RoleFor returns the most privileged role granted by a matching rule, or
+// false if no rule matches and the user may not sign in
+func (m SSORoleMapping) RoleFor(email string, groups []string) (string, bool) {
+	email = NormalizeEmail(email)
+	_, domain, _ := strings.Cut(email, "@")
+
+	matched := map[string]bool{}
+	for _, rule := range m.rules {
+		switch rule.kind {
+		case "email":
+			matched[rule.role] = matched[rule.role] || rule.value == email
+		case "domain":
+			matched[rule.role] = matched[rule.role] || rule.value == domain
+		case "group":
+			for _, group := range groups {
+				if group == rule.value {
+					matched[rule.role] = true
+				}
+			}
+		}
+	}
+
+	for _, role := range Roles() {
+		if matched[role] {
+			return role, true
+		}
+	}
+	return "", false
+}
+
+// Empty reports whether the mapping has no rules, so nobody can sign in
+func (m SSORoleMapping) Empty() bool {
+	return len(m.rules) == 0
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSORoleMapping_RoleFor(t *testing.T) {
+	mapping, err := ParseSSORoleMapping("group:workshop-organisers=organiser, domain:@AppDirect.com=analyst,email:jane@appdirect.com=owner")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		email        string
+		groups       []string
+		expectedRole string
+		allowed      bool
+	}{
+		{name: "domain match", email: "sam@appdirect.com", expectedRole: RoleAnalyst, allowed: true},
+		{name: "domain match is case insensitive", email: "Sam@APPDIRECT.com", expectedRole: RoleAnalyst, allowed: true},
+		{name: "group beats domain", email: "sam@appdirect.com", groups: []string{"workshop-organisers"}, expectedRole: RoleOrganiser, allowed: true},
+		{name: "email beats group", email: "jane@appdirect.com", groups: []string{"workshop-organisers"}, expectedRole: RoleOwner, allowed: true},
+		{name: "group from another domain", email: "guest@example.com", groups: []string{"workshop-organisers"}, expectedRole: RoleOrganiser, allowed: true},
+		{name: "subdomain does not match", email: "sam@mail.appdirect.com"},
+		{name: "no match", email: "someone@example.com", groups: []string{"other"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, ok := mapping.RoleFor(tt.email, tt.groups)
+			assert.Equal(t, tt.allowed, ok)
+			assert.Equal(t, tt.expectedRole, role)
+		})
+	}
+}
+
+func TestParseSSORoleMapping_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"appdirect.com=analyst",
+		"domain:appdirect.com",
+		"domain:=analyst",
+		"team:ops=analyst",
+		"domain:appdirect.com=superuser",
+	} {
+		_, err := ParseSSORoleMapping(value)
+		assert.Error(t, err, value)
+	}
+
+	mapping, err := ParseSSORoleMapping("")
+	require.NoError(t, err)
+	assert.True(t, mapping.Empty())
+}
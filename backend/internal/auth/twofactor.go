@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/totp"
+)
+
+// RecoveryCodeCount is how many single-use recovery codes are issued at a time
+const RecoveryCodeCount = 10
+
+// recoveryAlphabet avoids characters that are easy to misread (0/O, 1/I/L)
+const recoveryAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+
+// GenerateRecoveryCodes returns a fresh set of recovery codes formatted as
+// xxxxx-xxxxx, and the hashes to store in their place
+func GenerateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	hashes := make([]string, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		chars, err := randomChars(10)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := chars[:5] + "-" + chars[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// randomChars picks n characters from recoveryAlphabet, discarding bytes that
+// would bias the result towards the start of the alphabet
+func randomChars(n int) (string, error) {
+	limit := 256 - 256%len(recoveryAlphabet)
+	out := make([]byte, 0, n)
+	buf := make([]byte, n)
+	for len(out) < n {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) < limit && len(out) < n {
+				out = append(out, recoveryAlphabet[int(b)%len(recoveryAlphabet)])
+			}
+		}
+	}
+	return string(out), nil
+}
+
+// HashRecoveryCode hashes a recovery code, ignoring case, spaces and dashes.
+// Codes are random enough that a fast hash is sufficient.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifySecondFactor checks a TOTP or recovery code for a user with 2FA
+// enabled. On success the user is updated so the code cannot be reused; the
+// caller must persist the change.
+func VerifySecondFactor(user *models.AdminUser, code string, now time.Time) bool {
+	if !user.TwoFactorEnabled || user.TOTPSecret == "" {
+		return false
+	}
+
+	if step, ok := totp.Validate(user.TOTPSecret, code, now); ok {
+		if step <= user.LastTOTPStep {
+			return false
+		}
+		user.LastTOTPStep = step
+		return true
+	}
+
+	hash := HashRecoveryCode(code)
+	for i, stored := range user.RecoveryCodeHashes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			remaining := make([]string, 0, len(user.RecoveryCodeHashes)-1)
+			remaining = append(remaining, user.RecoveryCodeHashes[:i]...)
+			remaining = append(remaining, user.RecoveryCodeHashes[i+1:]...)
+			user.RecoveryCodeHashes = remaining
+			return true
+		}
+	}
+	return false
+}
+
+// TwoFactorPolicy lists the roles that must have 2FA enabled before they
+// can use the admin panel
+type TwoFactorPolicy struct {
+	requiredRoles map[string]bool
+}
+
+// ParseTwoFactorPolicy reads a comma separated list of roles, e.g. the
+// ADMIN_2FA_REQUIRED_ROLES setting. An empty value requires nobody to enrol.
+func ParseTwoFactorPolicy(value string) (TwoFactorPolicy, error) {
+	policy := TwoFactorPolicy{requiredRoles: map[string]bool{}}
+	for _, role := range strings.Split(value, ",") {
+		role = strings.TrimSpace(role)
+		if role == "" {
+			continue
+		}
+		if !ValidRole(role) {
+			return TwoFactorPolicy{}, fmt.Errorf("unknown role %q", role)
+		}
+		policy.requiredRoles[role] = true
+	}
+	return policy, nil
+}
+
+// Required reports whether admins with the role must use 2FA
+func (p TwoFactorPolicy) Required(role string) bool {
+	return p.requiredRoles[role]
+}
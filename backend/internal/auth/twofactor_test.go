@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+	require.Len(t, hashes, RecoveryCodeCount)
+
+	seen := map[string]bool{}
+	for i, code := range codes {
+		assert.Regexp(t, `^[a-z2-9]{5}-[a-z2-9]{5}$`, code)
+		assert.Equal(t, HashRecoveryCode(code), hashes[i])
+		assert.False(t, seen[code], "duplicate code %s", code)
+		seen[code] = true
+	}
+}
+
+func TestHashRecoveryCode_IgnoresFormatting(t *testing.T) {
+	assert.Equal(t, HashRecoveryCode("abcde-fghjk"), HashRecoveryCode("ABCDE FGHJK"))
+	assert.NotEqual(t, HashRecoveryCode("abcde-fghjk"), HashRecoveryCode("abcde-fghjm"))
+}
+
+func TestVerifySecondFactor(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := totp.Code(secret, now)
+	require.NoError(t, err)
+
+	t.Run("TOTP code cannot be replayed", func(t *testing.T) {
+		user := &models.AdminUser{TwoFactorEnabled: true, TOTPSecret: secret}
+		assert.True(t, VerifySecondFactor(user, code, now))
+		assert.Equal(t, totp.Step(now), user.LastTOTPStep)
+		assert.False(t, VerifySecondFactor(user, code, now))
+	})
+
+	t.Run("recovery code is consumed", func(t *testing.T) {
+		user := &models.AdminUser{
+			TwoFactorEnabled:   true,
+			TOTPSecret:         secret,
+			RecoveryCodeHashes: []string{HashRecoveryCode("aaaaa-bbbbb"), HashRecoveryCode("ccccc-ddddd")},
+		}
+		assert.True(t, VerifySecondFactor(user, "CCCCC-DDDDD", now))
+		assert.Equal(t, []string{HashRecoveryCode("aaaaa-bbbbb")}, user.RecoveryCodeHashes)
+		assert.False(t, VerifySecondFactor(user, "ccccc-ddddd", now))
+	})
+
+	t.Run("rejected when 2FA is not enabled", func(t *testing.T) {
+		user := &models.AdminUser{TOTPSecret: secret}
+		assert.False(t, VerifySecondFactor(user, code, now))
+	})
+
+	t.Run("wrong code", func(t *testing.T) {
+		user := &models.AdminUser{TwoFactorEnabled: true, TOTPSecret: secret}
+		assert.False(t, VerifySecondFactor(user, "not-a-code", now))
+	})
+}
+
+func TestParseTwoFactorPolicy(t *testing.T) {
+	policy, err := ParseTwoFactorPolicy(" owner, organiser ,")
+	require.NoError(t, err)
+	assert.True(t, policy.Required(RoleOwner))
+	assert.True(t, policy.Required(RoleOrganiser))
+	assert.False(t, policy.Required(RoleAnalyst))
+
+	empty, err := ParseTwoFactorPolicy("")
+	require.NoError(t, err)
+	assert.False(t, empty.Required(RoleOwner))
+
+	_, err = ParseTwoFactorPolicy("owner,superuser")
+	assert.Error(t, err)
+}
@@ -0,0 +1,440 @@
+// Package backup snapshots a workshop's attendees, speakers and sessions,
+// including those in the trash, into a single archive, and restores an
+// archive into the same workshop or another one.
+//
+// An archive is newline-delimited JSON. The first line is a header naming
+// the format, its version and the workshop backed up; each following line
+// is one record; the last line is a footer with the number of records of
+// each kind, a SHA-256 checksum of the lines of each kind and one of
+// everything before the footer. A truncated or edited archive is rejected
+// before anything is restored.
+package backup
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	"ai-india-workshop-backend/internal/ids"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+)
+
+const (
+	// Format identifies a workshop backup archive
+	Format = "workshop-backup"
+	// Version is the archive version written. Read accepts this version
+	// and older ones.
+	Version = 1
+	// ContentType is the media type of an archive
+	ContentType = "application/x-ndjson"
+
+	// maxLine bounds one line of an archive, which holds one record
+	maxLine = 1 << 20
+)
+
+// Line types
+const (
+	typeHeader   = "header"
+	typeFooter   = "footer"
+	typeSpeaker  = "speaker"
+	typeSession  = "session"
+	typeAttendee = "attendee"
+)
+
+var (
+	// ErrInvalidArchive is returned for an archive that cannot be read, is
+	// incomplete or does not match its checksums
+	ErrInvalidArchive = errors.New("invalid backup archive")
+	// ErrWorkshopNotEmpty is returned when restoring into a workshop that
+	// has data without asking to replace it
+	ErrWorkshopNotEmpty = errors.New("workshop already has attendees, speakers or sessions")
+)
+
+// Header is the first line of an archive
+type Header struct {
+	Type      string    `json:"type"`
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	Workshop  string    `json:"workshop"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Counts is the number of records of each kind
+type Counts struct {
+	Speakers  int `json:"speakers"`
+	Sessions  int `json:"sessions"`
+	Attendees int `json:"attendees"`
+}
+
+// Footer is the last line of an archive. Checksums are keyed by resource
+// type and cover that type's lines, newlines included; SHA256 covers every
+// line before the footer. All are hex encoded.
+type Footer struct {
+	Type      string            `json:"type"`
+	Counts    Counts            `json:"counts"`
+	Checksums map[string]string `json:"checksums"`
+	SHA256    string            `json:"sha256"`
+}
+
+// line is a record line
+type line struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Archive is a backup that has been read and checked
+type Archive struct {
+	Header Header
+	Footer Footer
+	Data   *models.WorkshopData
+}
+
+// checksums hashes an archive as it is written or read
+type checksums struct {
+	all      hash.Hash
+	sections map[string]hash.Hash
+}
+
+func newChecksums() *checksums {
+	c := &checksums{all: sha256.New(), sections: map[string]hash.Hash{}}
+	for _, section := range []string{models.ResourceSpeakers, models.ResourceSessions, models.ResourceAttendees} {
+		c.sections[section] = sha256.New()
+	}
+	return c
+}
+
+// add hashes one line, without its newline, into the section if it has one
+func (c *checksums) add(section string, text []byte) {
+	c.all.Write(text)
+	c.all.Write([]byte{'\n'})
+	if h, ok := c.sections[section]; ok {
+		h.Write(text)
+		h.Write([]byte{'\n'})
+	}
+}
+
+func (c *checksums) sums() (map[string]string, string) {
+	sections := map[string]string{}
+	for section, h := range c.sections {
+		sections[section] = hex.EncodeToString(h.Sum(nil))
+	}
+	return sections, hex.EncodeToString(c.all.Sum(nil))
+}
+
+// sectionOf is the resource type a record line belongs to
+func sectionOf(recordType string) string {
+	switch recordType {
+	case typeSpeaker:
+		return models.ResourceSpeakers
+	case typeSession:
+		return models.ResourceSessions
+	case typeAttendee:
+		return models.ResourceAttendees
+	}
+	return ""
+}
+
+// Write writes data as an archive of the workshop and returns its footer
+func Write(w io.Writer, workshop string, data *models.WorkshopData, now time.Time) (*Footer, error) {
+	buffered := bufio.NewWriter(w)
+	sums := newChecksums()
+	writeLine := func(section string, v any) error {
+		text, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		sums.add(section, text)
+		buffered.Write(text)
+		return buffered.WriteByte('\n')
+	}
+	writeRecord := func(recordType string, record any) error {
+		raw, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		return writeLine(sectionOf(recordType), line{Type: recordType, Data: raw})
+	}
+
+	header := Header{Type: typeHeader, Format: Format, Version: Version, Workshop: workshop, CreatedAt: now.UTC()}
+	if err := writeLine("", header); err != nil {
+		return nil, err
+	}
+	for _, speaker := range data.Speakers {
+		if err := writeRecord(typeSpeaker, speaker); err != nil {
+			return nil, err
+		}
+	}
+	for _, session := range data.Sessions {
+		if err := writeRecord(typeSession, session); err != nil {
+			return nil, err
+		}
+	}
+	for _, attendee := range data.Attendees {
+		if err := writeRecord(typeAttendee, attendee); err != nil {
+			return nil, err
+		}
+	}
+
+	footer := &Footer{
+		Type:   typeFooter,
+		Counts: Counts{Speakers: len(data.Speakers), Sessions: len(data.Sessions), Attendees: len(data.Attendees)},
+	}
+	footer.Checksums, footer.SHA256 = sums.sums()
+	text, err := json.Marshal(footer)
+	if err != nil {
+		return nil, err
+	}
+	buffered.Write(text)
+	buffered.WriteByte('\n')
+	return footer, buffered.Flush()
+}
+
+// Create backs up the workshop in repo to w
+func Create(ctx context.Context, repo repository.RepositoryInterface, workshop string, w io.Writer) (*Footer, error) {
+	data, err := repo.GetWorkshopData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading workshop data: %w", err)
+	}
+	return Write(w, workshop, data, time.Now())
+}
+
+// Read reads an archive and checks it against its footer. Errors describing
+// the archive wrap ErrInvalidArchive.
+func Read(r io.Reader) (*Archive, error) {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidArchive, fmt.Sprintf(format, args...))
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+	sums := newChecksums()
+	archive := &Archive{Data: &models.WorkshopData{
+		Speakers:  make([]*models.Speaker, 0),
+		Sessions:  make([]*models.Session, 0),
+		Attendees: make([]*models.Attendee, 0),
+	}}
+	ids := map[string]map[string]bool{typeSpeaker: {}, typeSession: {}, typeAttendee: {}}
+
+	number := 0
+	var footer *Footer
+	for scanner.Scan() {
+		number++
+		text := scanner.Bytes()
+		if footer != nil {
+			if len(strings.TrimSpace(string(text))) > 0 {
+				return nil, invalid("line %d: content after the footer", number)
+			}
+			continue
+		}
+
+		if number == 1 {
+			if err := json.Unmarshal(text, &archive.Header); err != nil || archive.Header.Type != typeHeader || archive.Header.Format != Format {
+				return nil, invalid("not a %s archive", Format)
+			}
+			if archive.Header.Version < 1 || archive.Header.Version > Version {
+				return nil, invalid("version %d is not supported, only up to %d", archive.Header.Version, Version)
+			}
+			sums.add("", text)
+			continue
+		}
+
+		var l line
+		if err := json.Unmarshal(text, &l); err != nil {
+			return nil, invalid("line %d: %v", number, err)
+		}
+		if l.Type == typeFooter {
+			footer = &archive.Footer
+			if err := json.Unmarshal(text, footer); err != nil {
+				return nil, invalid("line %d: %v", number, err)
+			}
+			continue
+		}
+
+		var id string
+		var err error
+		switch l.Type {
+		case typeSpeaker:
+			var speaker models.Speaker
+			err = json.Unmarshal(l.Data, &speaker)
+			id = speaker.ID
+			archive.Data.Speakers = append(archive.Data.Speakers, &speaker)
+		case typeSession:
+			var session models.Session
+			err = json.Unmarshal(l.Data, &session)
+			id = session.ID
+			archive.Data.Sessions = append(archive.Data.Sessions, &session)
+		case typeAttendee:
+			var attendee models.Attendee
+			err = json.Unmarshal(l.Data, &attendee)
+			id = attendee.ID
+			archive.Data.Attendees = append(archive.Data.Attendees, &attendee)
+		default:
+			return nil, invalid("line %d: unknown record type %q", number, l.Type)
+		}
+		if err != nil {
+			return nil, invalid("line %d: %v", number, err)
+		}
+		if id == "" {
+			return nil, invalid("line %d: %s has no ID", number, l.Type)
+		}
+		if ids[l.Type][id] {
+			return nil, invalid("line %d: %s %s appears twice", number, l.Type, id)
+		}
+		ids[l.Type][id] = true
+		sums.add(sectionOf(l.Type), text)
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, invalid("line %d is too long", number+1)
+		}
+		return nil, err
+	}
+	if number == 0 {
+		return nil, invalid("empty")
+	}
+	if footer == nil {
+		return nil, invalid("no footer; the archive is incomplete")
+	}
+
+	counts := Counts{Speakers: len(archive.Data.Speakers), Sessions: len(archive.Data.Sessions), Attendees: len(archive.Data.Attendees)}
+	if counts != footer.Counts {
+		return nil, invalid("the footer lists %+v records but the archive has %+v", footer.Counts, counts)
+	}
+	sections, all := sums.sums()
+	for section, sum := range sections {
+		if footer.Checksums[section] != sum {
+			return nil, invalid("the %s do not match their checksum", section)
+		}
+	}
+	if footer.SHA256 != all {
+		return nil, invalid("the archive does not match its checksum")
+	}
+	return archive, nil
+}
+
+// RestoreOptions control a restore
+type RestoreOptions struct {
+	// Workshop is the ID of the workshop restored into. If it differs from
+	// the archive's, every record gets a new ID.
+	Workshop string
+	// Replace permanently removes the workshop's attendees, speakers and
+	// sessions first; without it, only an empty workshop can be restored into
+	Replace bool
+}
+
+// RestoreResult describes a restore
+type RestoreResult struct {
+	// Workshop is the workshop restored into, and Source the one backed up
+	Workshop string `json:"workshop"`
+	Source   string `json:"source"`
+	// Restored counts the records written, and Replaced those removed
+	Restored Counts `json:"restored"`
+	Replaced Counts `json:"replaced"`
+	// Remapped is true when the records were given new IDs
+	Remapped bool `json:"remapped"`
+	// DroppedSpeakerRefs counts the session speakers left out because they
+	// were not in the archive
+	DroppedSpeakerRefs int `json:"droppedSpeakerRefs"`
+}
+
+// Restore writes an archive's records into the workshop in repo, in place
+// of whatever it held, and recounts the analytics. Records keep their IDs
+// when restored into the workshop they came from, so links and check-in
+// codes still work; restored elsewhere they get new ones, and sessions are
+// pointed at their speakers' new IDs. Restored records are not published
+// to webhooks.
+func Restore(ctx context.Context, repo repository.RepositoryInterface, archive *Archive, opts RestoreOptions) (*RestoreResult, error) {
+	existing, err := repo.GetWorkshopData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading workshop data: %w", err)
+	}
+	replaced := Counts{Speakers: len(existing.Speakers), Sessions: len(existing.Sessions), Attendees: len(existing.Attendees)}
+	if replaced != (Counts{}) && !opts.Replace {
+		return nil, ErrWorkshopNotEmpty
+	}
+
+	result := &RestoreResult{
+		Workshop: opts.Workshop,
+		Source:   archive.Header.Workshop,
+		Restored: archive.Footer.Counts,
+		Replaced: replaced,
+	}
+	data := archive.Data
+	if archive.Header.Workshop != opts.Workshop {
+		var err error
+		if data, result.DroppedSpeakerRefs, err = remap(data); err != nil {
+			return nil, fmt.Errorf("assigning new IDs: %w", err)
+		}
+		result.Remapped = true
+	}
+
+	if err := repo.ReplaceWorkshopData(ctx, data); err != nil {
+		return nil, fmt.Errorf("writing workshop data: %w", err)
+	}
+	if err := repo.RebuildAnalytics(ctx); err != nil {
+		return nil, fmt.Errorf("recounting analytics: %w", err)
+	}
+	return result, nil
+}
+
+// remap gives every record a new ID and points sessions at their speakers'
+// new IDs, dropping references to speakers that are not in data. It
+// returns how many references were dropped.
+func remap(data *models.WorkshopData) (*models.WorkshopData, int, error) {
+	remapped := &models.WorkshopData{
+		Speakers:  make([]*models.Speaker, len(data.Speakers)),
+		Sessions:  make([]*models.Session, len(data.Sessions)),
+		Attendees: make([]*models.Attendee, len(data.Attendees)),
+	}
+
+	speakerIDs := map[string]string{}
+	for i, speaker := range data.Speakers {
+		copied := *speaker
+		id, err := ids.New()
+		if err != nil {
+			return nil, 0, err
+		}
+		copied.ID = id
+		speakerIDs[speaker.ID] = copied.ID
+		remapped.Speakers[i] = &copied
+	}
+
+	dropped := 0
+	for i, session := range data.Sessions {
+		copied := *session
+		id, err := ids.New()
+		if err != nil {
+			return nil, 0, err
+		}
+		copied.ID = id
+		copied.Speakers = make([]string, 0, len(session.Speakers))
+		for _, ref := range session.Speakers {
+			if id, ok := speakerIDs[ref]; ok {
+				copied.Speakers = append(copied.Speakers, id)
+			} else {
+				dropped++
+			}
+		}
+		remapped.Sessions[i] = &copied
+	}
+
+	for i, attendee := range data.Attendees {
+		copied := *attendee
+		id, err := ids.New()
+		if err != nil {
+			return nil, 0, err
+		}
+		copied.ID = id
+		remapped.Attendees[i] = &copied
+	}
+	return remapped, dropped, nil
+}
@@ -0,0 +1,195 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var backedUp = time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+func testData() *models.WorkshopData {
+	registered := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	cancelled := registered.Add(time.Hour)
+	return &models.WorkshopData{
+		Speakers: []*models.Speaker{
+			{ID: "sp1", Name: "Asha Rao", Bio: "Builds search"},
+			{ID: "sp2", Name: "Ravi Kumar", DeletedAt: &cancelled},
+		},
+		Sessions: []*models.Session{
+			{ID: "s1", Title: "Keynote", Time: "09:30", Speakers: []string{"sp1"}},
+			{ID: "s2", Title: "Panel", Time: "11:00", Speakers: []string{"sp1", "sp2", "purged"}},
+		},
+		Attendees: []*models.Attendee{
+			{ID: "a1", Name: "Meera", Email: "meera@example.com", CreatedAt: registered, Answers: map[string]interface{}{"track": "ml"}},
+			{ID: "a2", Name: "Vikram", Email: "vikram@example.com", CreatedAt: registered, DeletedAt: &cancelled},
+		},
+	}
+}
+
+func archiveOf(t *testing.T, workshop string, data *models.WorkshopData) []byte {
+	var buf bytes.Buffer
+	_, err := Write(&buf, workshop, data, backedUp)
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func TestWriteThenRead(t *testing.T) {
+	raw := archiveOf(t, "ws-2026", testData())
+	lines := strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n")
+	require.Len(t, lines, 8)
+	assert.Contains(t, lines[0], `"format":"workshop-backup","version":1,"workshop":"ws-2026"`)
+	assert.Contains(t, lines[7], `"counts":{"speakers":2,"sessions":2,"attendees":2}`)
+
+	archive, err := Read(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "ws-2026", archive.Header.Workshop)
+	assert.True(t, backedUp.Equal(archive.Header.CreatedAt))
+	assert.Equal(t, Counts{Speakers: 2, Sessions: 2, Attendees: 2}, archive.Footer.Counts)
+	assert.Len(t, archive.Footer.Checksums, 3)
+
+	expected := testData()
+	require.Len(t, archive.Data.Attendees, 2)
+	assert.Equal(t, expected.Sessions, archive.Data.Sessions)
+	assert.Equal(t, "ml", archive.Data.Attendees[0].Answers["track"])
+	require.NotNil(t, archive.Data.Attendees[1].DeletedAt)
+	assert.True(t, expected.Attendees[1].DeletedAt.Equal(*archive.Data.Attendees[1].DeletedAt))
+}
+
+func TestRead_RejectsDamagedArchives(t *testing.T) {
+	raw := string(archiveOf(t, "ws-2026", testData()))
+	lines := strings.SplitAfter(raw, "\n")
+
+	tests := []struct {
+		name    string
+		archive string
+		message string
+	}{
+		{name: "empty", archive: "", message: "empty"},
+		{name: "not an archive", archive: `{"name":"Meera"}` + "\n", message: "not a workshop-backup archive"},
+		{name: "newer version", archive: strings.Replace(raw, `"version":1`, `"version":2`, 1), message: "version 2 is not supported"},
+		{name: "truncated", archive: strings.Join(lines[:5], ""), message: "no footer"},
+		{name: "edited record", archive: strings.Replace(raw, "Meera", "Mira", 1), message: "the attendees do not match their checksum"},
+		{name: "edited header", archive: strings.Replace(raw, "ws-2026", "ws-2027", 1), message: "the archive does not match its checksum"},
+		{name: "record removed", archive: lines[0] + strings.Join(lines[2:], ""), message: "the footer lists"},
+		{name: "unknown record", archive: lines[0] + `{"type":"webhook","data":{}}` + "\n" + strings.Join(lines[1:], ""), message: `unknown record type "webhook"`},
+		{name: "record after footer", archive: raw + lines[1], message: "content after the footer"},
+		{name: "not json", archive: lines[0] + "{\n", message: "line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.archive))
+			require.ErrorIs(t, err, ErrInvalidArchive)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestRead_RejectsDuplicateIDs(t *testing.T) {
+	data := testData()
+	data.Speakers[1].ID = "sp1"
+	_, err := Read(bytes.NewReader(archiveOf(t, "ws-2026", data)))
+	require.ErrorIs(t, err, ErrInvalidArchive)
+	assert.Contains(t, err.Error(), "speaker sp1 appears twice")
+}
+
+func localRepo(t *testing.T, workshop string) *repository.LocalRepository {
+	repo, err := repository.NewLocalRepository(t.TempDir(), workshop)
+	require.NoError(t, err)
+	return repo
+}
+
+func TestCreateThenRestore_SameWorkshopKeepsIDs(t *testing.T) {
+	ctx := context.Background()
+	repo := localRepo(t, "ws-2026")
+	require.NoError(t, repo.ReplaceWorkshopData(ctx, testData()))
+
+	var buf bytes.Buffer
+	footer, err := Create(ctx, repo, "ws-2026", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, Counts{Speakers: 2, Sessions: 2, Attendees: 2}, footer.Counts)
+
+	// A risky change goes wrong
+	require.NoError(t, repo.DeleteSession(ctx, "s1"))
+	require.NoError(t, repo.CreateSpeaker(ctx, &models.Speaker{Name: "Added by mistake"}))
+
+	archive, err := Read(&buf)
+	require.NoError(t, err)
+	_, err = Restore(ctx, repo, archive, RestoreOptions{Workshop: "ws-2026"})
+	require.ErrorIs(t, err, ErrWorkshopNotEmpty)
+
+	result, err := Restore(ctx, repo, archive, RestoreOptions{Workshop: "ws-2026", Replace: true})
+	require.NoError(t, err)
+	assert.False(t, result.Remapped)
+	assert.Equal(t, Counts{Speakers: 3, Sessions: 2, Attendees: 2}, result.Replaced)
+
+	session, err := repo.GetSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sp1"}, session.Speakers)
+	speakers, err := repo.GetAllSpeakers(ctx)
+	require.NoError(t, err)
+	require.Len(t, speakers, 1)
+	assert.Equal(t, "Asha Rao", speakers[0].Name)
+
+	// What was in the trash is back in the trash
+	trash, err := repo.GetTrash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash.Attendees, 1)
+	assert.Equal(t, "a2", trash.Attendees[0].ID)
+
+	// The analytics count the restored attendees
+	days, err := repo.GetAnalyticsDays(ctx)
+	require.NoError(t, err)
+	require.Len(t, days, 1)
+	assert.Equal(t, map[string]int{"36": 2}, days[0].Registrations)
+}
+
+func TestRestore_OtherWorkshopRemapsIDs(t *testing.T) {
+	ctx := context.Background()
+	archive, err := Read(bytes.NewReader(archiveOf(t, "ws-2026", testData())))
+	require.NoError(t, err)
+
+	repo := localRepo(t, "ws-2027")
+	result, err := Restore(ctx, repo, archive, RestoreOptions{Workshop: "ws-2027"})
+	require.NoError(t, err)
+	assert.True(t, result.Remapped)
+	assert.Equal(t, "ws-2026", result.Source)
+	assert.Equal(t, "ws-2027", result.Workshop)
+	assert.Equal(t, 1, result.DroppedSpeakerRefs)
+
+	data, err := repo.GetWorkshopData(ctx)
+	require.NoError(t, err)
+	require.Len(t, data.Speakers, 2)
+	speakerIDs := map[string]string{}
+	for _, speaker := range data.Speakers {
+		assert.NotContains(t, []string{"sp1", "sp2"}, speaker.ID)
+		speakerIDs[speaker.Name] = speaker.ID
+	}
+	for _, session := range data.Sessions {
+		assert.NotContains(t, []string{"s1", "s2"}, session.ID)
+		if session.Title == "Panel" {
+			assert.Equal(t, []string{speakerIDs["Asha Rao"], speakerIDs["Ravi Kumar"]}, session.Speakers)
+		}
+	}
+	for _, attendee := range data.Attendees {
+		assert.NotContains(t, []string{"a1", "a2"}, attendee.ID)
+	}
+
+	// The archive itself is left as it was
+	assert.Equal(t, "s1", archive.Data.Sessions[0].ID)
+	assert.Equal(t, []string{"sp1"}, archive.Data.Sessions[0].Speakers)
+
+	// Restored records are not published
+	jobs, err := repo.GetJobs(ctx, models.JobQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
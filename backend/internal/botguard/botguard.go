@@ -0,0 +1,155 @@
+// Package botguard screens public form submissions for bots: signed form
+// tokens enforce a minimum fill time, and a pluggable Verifier checks a
+// proof-of-work or captcha challenge
+package botguard
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidFormToken is returned for missing, forged or expired form tokens
+	ErrInvalidFormToken = errors.New("invalid form token")
+	// ErrTooFast is returned when a form is submitted sooner than a person could fill it in
+	ErrTooFast = errors.New("form submitted too quickly")
+	// ErrChallengeFailed is returned when a challenge response is wrong
+	ErrChallengeFailed = errors.New("challenge failed")
+)
+
+// FormTokens issues and checks signed tokens recording when a form was
+// served, so a submission can be rejected if it came back too quickly
+type FormTokens struct {
+	key         []byte
+	minFillTime time.Duration
+	maxAge      time.Duration
+	now         func() time.Time
+}
+
+// NewFormTokens signs tokens with a key derived from secret. Submissions
+// must arrive between minFillTime and maxAge after the token was issued.
+func NewFormTokens(secret []byte, minFillTime, maxAge time.Duration) *FormTokens {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("botguard form token"))
+	return &FormTokens{key: mac.Sum(nil), minFillTime: minFillTime, maxAge: maxAge, now: time.Now}
+}
+
+// Issue returns a token for a form served now
+func (f *FormTokens) Issue() string {
+	issuedAt := strconv.FormatInt(f.now().UnixMilli(), 10)
+	return issuedAt + "." + f.sign(issuedAt)
+}
+
+// Check verifies a token and that enough time has passed since it was issued
+func (f *FormTokens) Check(token string) error {
+	issuedAt, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(f.sign(issuedAt))) {
+		return ErrInvalidFormToken
+	}
+	millis, err := strconv.ParseInt(issuedAt, 10, 64)
+	if err != nil {
+		return ErrInvalidFormToken
+	}
+
+	elapsed := f.now().Sub(time.UnixMilli(millis))
+	switch {
+	case elapsed > f.maxAge:
+		return ErrInvalidFormToken
+	case elapsed < f.minFillTime:
+		return ErrTooFast
+	}
+	return nil
+}
+
+func (f *FormTokens) sign(value string) string {
+	mac := hmac.New(sha256.New, f.key)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verifier checks the client's answer to a challenge
+type Verifier interface {
+	// Challenge returns what the client needs to solve the challenge for a
+	// form token, or nil if it needs nothing
+	Challenge(formToken string) map[string]interface{}
+	// Verify checks the response for a submission. subject identifies the
+	// submission (e.g. the email address) so one solution cannot be reused
+	// for another.
+	Verify(ctx context.Context, formToken, subject, response, remoteIP string) error
+}
+
+// ProofOfWork makes the client find a nonce such that
+// SHA-256(formToken ":" subject ":" nonce) starts with Difficulty zero bits.
+// It costs a browser a second or two and a bulk registration script much
+// more.
+type ProofOfWork struct {
+	Difficulty int
+}
+
+func (p ProofOfWork) Challenge(formToken string) map[string]interface{} {
+	return map[string]interface{}{"type": "pow", "difficulty": p.Difficulty}
+}
+
+func (p ProofOfWork) Verify(_ context.Context, formToken, subject, response, _ string) error {
+	if response == "" || len(response) > 32 {
+		return ErrChallengeFailed
+	}
+	sum := sha256.Sum256([]byte(formToken + ":" + subject + ":" + response))
+	if leadingZeroBits(sum[:]) < p.Difficulty {
+		return ErrChallengeFailed
+	}
+	return nil
+}
+
+// SolveProofOfWork finds a response to a ProofOfWork challenge. The browser
+// does the same work in JavaScript; this is used by tests and scripts.
+func SolveProofOfWork(formToken, subject string, difficulty int) string {
+	for nonce := 0; ; nonce++ {
+		response := strconv.Itoa(nonce)
+		sum := sha256.Sum256([]byte(formToken + ":" + subject + ":" + response))
+		if leadingZeroBits(sum[:]) >= difficulty {
+			return response
+		}
+	}
+}
+
+func leadingZeroBits(sum []byte) int {
+	bits := 0
+	for i := 0; i+8 <= len(sum); i += 8 {
+		word := binary.BigEndian.Uint64(sum[i:])
+		if word != 0 {
+			for word&(1<<63) == 0 {
+				bits++
+				word <<= 1
+			}
+			return bits
+		}
+		bits += 64
+	}
+	return bits
+}
+
+// FakeVerifierPass is the only response FakeVerifier accepts
+const FakeVerifierPass = "pass"
+
+// FakeVerifier stands in for a captcha during local development and tests.
+// Like the test keys of hosted captchas, it accepts a fixed response.
+type FakeVerifier struct{}
+
+func (FakeVerifier) Challenge(string) map[string]interface{} {
+	return map[string]interface{}{"type": "fake", "response": FakeVerifierPass}
+}
+
+func (FakeVerifier) Verify(_ context.Context, _, _, response, _ string) error {
+	if response != FakeVerifierPass {
+		return ErrChallengeFailed
+	}
+	return nil
+}
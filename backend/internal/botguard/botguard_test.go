@@ -0,0 +1,92 @@
+package botguard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormTokens(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tokens := NewFormTokens([]byte("secret"), 3*time.Second, time.Hour)
+	tokens.now = func() time.Time { return now }
+	token := tokens.Issue()
+
+	tests := []struct {
+		name     string
+		token    string
+		elapsed  time.Duration
+		expected error
+	}{
+		{name: "filled in normally", token: token, elapsed: 30 * time.Second},
+		{name: "too fast", token: token, elapsed: time.Second, expected: ErrTooFast},
+		{name: "expired", token: token, elapsed: 2 * time.Hour, expected: ErrInvalidFormToken},
+		{name: "missing", token: "", elapsed: time.Minute, expected: ErrInvalidFormToken},
+		{name: "backdated", token: "1000." + tokens.sign("1000")[:5], elapsed: time.Minute, expected: ErrInvalidFormToken},
+		{name: "signed with another secret", token: NewFormTokens([]byte("other"), 0, time.Hour).Issue(), elapsed: time.Minute, expected: ErrInvalidFormToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens.now = func() time.Time { return now.Add(tt.elapsed) }
+			err := tokens.Check(tt.token)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProofOfWork(t *testing.T) {
+	pow := ProofOfWork{Difficulty: 12}
+	response := SolveProofOfWork("token", "john@example.com", pow.Difficulty)
+
+	assert.NoError(t, pow.Verify(context.Background(), "token", "john@example.com", response, ""))
+	// The solution is bound to the form token and the subject
+	assert.ErrorIs(t, pow.Verify(context.Background(), "token", "jane@example.com", response, ""), ErrChallengeFailed)
+	assert.ErrorIs(t, pow.Verify(context.Background(), "other", "john@example.com", response, ""), ErrChallengeFailed)
+	assert.ErrorIs(t, pow.Verify(context.Background(), "token", "john@example.com", "", ""), ErrChallengeFailed)
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	assert.Equal(t, 0, leadingZeroBits([]byte{0x80, 0, 0, 0, 0, 0, 0, 0}))
+	assert.Equal(t, 12, leadingZeroBits([]byte{0, 0x08, 0, 0, 0, 0, 0, 0}))
+	assert.Equal(t, 65, leadingZeroBits([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0x40, 0, 0, 0, 0, 0, 0, 0}))
+}
+
+func TestFakeVerifier(t *testing.T) {
+	verifier := FakeVerifier{}
+	assert.NoError(t, verifier.Verify(context.Background(), "", "", FakeVerifierPass, ""))
+	assert.ErrorIs(t, verifier.Verify(context.Background(), "", "", "fail", ""), ErrChallengeFailed)
+}
+
+func TestCaptchaVerifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "the-secret", r.PostForm.Get("secret"))
+		assert.Equal(t, "203.0.113.1", r.PostForm.Get("remoteip"))
+		if r.PostForm.Get("response") == "good" {
+			w.Write([]byte(`{"success":true}`))
+			return
+		}
+		w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+	}))
+	defer server.Close()
+
+	verifier := NewCaptchaVerifier(server.URL, "site-key", "the-secret")
+	assert.Equal(t, "site-key", verifier.Challenge("")["siteKey"])
+	assert.NoError(t, verifier.Verify(context.Background(), "", "", "good", "203.0.113.1"))
+	assert.ErrorIs(t, verifier.Verify(context.Background(), "", "", "bad", "203.0.113.1"), ErrChallengeFailed)
+
+	server.Close()
+	err := verifier.Verify(context.Background(), "", "", "good", "203.0.113.1")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrChallengeFailed)
+}
@@ -0,0 +1,76 @@
+package botguard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Site verification endpoints of hosted captchas that share the same API
+const (
+	TurnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	HCaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+	RecaptchaVerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+)
+
+// CaptchaVerifier checks a hosted captcha (Turnstile, hCaptcha or reCAPTCHA)
+// response with the provider's siteverify endpoint
+type CaptchaVerifier struct {
+	VerifyURL string
+	SiteKey   string
+	Secret    string
+	client    *http.Client
+}
+
+func NewCaptchaVerifier(verifyURL, siteKey, secret string) *CaptchaVerifier {
+	return &CaptchaVerifier{
+		VerifyURL: verifyURL,
+		SiteKey:   siteKey,
+		Secret:    secret,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (v *CaptchaVerifier) Challenge(string) map[string]interface{} {
+	return map[string]interface{}{"type": "captcha", "siteKey": v.SiteKey}
+}
+
+func (v *CaptchaVerifier) Verify(ctx context.Context, _, _, response, remoteIP string) error {
+	if response == "" {
+		return ErrChallengeFailed
+	}
+
+	form := url.Values{"secret": {v.Secret}, "response": {response}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.VerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("captcha verification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha verification: %s", resp.Status)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("captcha verification: %w", err)
+	}
+	if !result.Success {
+		return ErrChallengeFailed
+	}
+	return nil
+}
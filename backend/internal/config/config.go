@@ -0,0 +1,342 @@
+// Package config loads the server's configuration from defaults, an optional
+// YAML file and the environment, and validates it before anything starts
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/botguard"
+	"ai-india-workshop-backend/internal/jobs"
+	"ai-india-workshop-backend/internal/ratelimit"
+	"ai-india-workshop-backend/internal/sessionstore"
+	"ai-india-workshop-backend/internal/tracing"
+	"ai-india-workshop-backend/internal/webhook"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSessionSecret is only acceptable for local development
+const DefaultSessionSecret = "default-secret-change-in-production"
+
+// Store backends for sessions and rate limits
+const (
+	StoreFirestore = "firestore"
+	StoreMemory    = "memory"
+)
+
+// Challenges registrations can be required to pass
+const (
+	ChallengeNone    = "none"
+	ChallengePoW     = "pow"
+	ChallengeCaptcha = "captcha"
+	ChallengeFake    = "fake"
+)
+
+// Config is the whole server configuration. The yaml tags name the keys of
+// the configuration file; the environment variable for each field is listed
+// in applyEnv. Fields tagged yaml:"-" are derived from the others by Load.
+type Config struct {
+	Port           string   `yaml:"port"`
+	Mode           string   `yaml:"mode"`
+	LogLevel       string   `yaml:"logLevel"`
+	StaticDir      string   `yaml:"staticDir"`
+	FrontendURL    string   `yaml:"frontendUrl"`
+	CORSOrigins    []string `yaml:"corsOrigins"`
+	TrustedProxies []string `yaml:"trustedProxies"`
+	Timezone       string   `yaml:"timezone"`
+	TrashRetention int      `yaml:"trashRetentionDays"`
+	TracesExporter string   `yaml:"tracesExporter"`
+	MetricsToken   string   `yaml:"metricsToken"`
+	// ValidateRequests rejects API requests that do not match the OpenAPI
+	// document before they reach the handlers
+	ValidateRequests bool `yaml:"validateRequests"`
+
+	Firestore    Firestore    `yaml:"firestore"`
+	Admin        Admin        `yaml:"admin"`
+	Session      Session      `yaml:"session"`
+	Login        Login        `yaml:"login"`
+	TwoFactor    TwoFactor    `yaml:"twoFactor"`
+	OIDC         OIDC         `yaml:"oidc"`
+	Registration Registration `yaml:"registration"`
+	Webhooks     Webhooks     `yaml:"webhooks"`
+	Jobs         Jobs         `yaml:"jobs"`
+
+	Level    slog.Level     `yaml:"-"`
+	Location *time.Location `yaml:"-"`
+
+	// EnvFile is the .env file that was loaded, if any
+	EnvFile string `yaml:"-"`
+	// Warnings describe settings that are allowed but unsafe in production
+	Warnings []string `yaml:"-"`
+}
+
+// Firestore locates the workshop's data
+type Firestore struct {
+	SubcollectionID string `yaml:"subcollectionId"`
+	// CredentialsFile is a service account key; without it Application
+	// Default Credentials are used, as on Cloud Run
+	CredentialsFile string `yaml:"credentialsFile"`
+	ProjectID       string `yaml:"projectId"`
+}
+
+// Admin is the first admin account, created when there are none
+type Admin struct {
+	Email    string `yaml:"email"`
+	Password string `yaml:"password"`
+}
+
+// Session configures the admin session store and cookie
+type Session struct {
+	Secret          string        `yaml:"secret"`
+	Store           string        `yaml:"store"`
+	IdleTimeout     time.Duration `yaml:"idleTimeout"`
+	AbsoluteTimeout time.Duration `yaml:"absoluteTimeout"`
+	// CookieSecure defaults to true in release mode
+	CookieSecure   *bool  `yaml:"cookieSecure"`
+	CookieSameSite string `yaml:"cookieSameSite"`
+
+	SameSite http.SameSite `yaml:"-"`
+}
+
+// Login configures where failed admin logins are counted
+type Login struct {
+	LimiterStore string `yaml:"limiterStore"`
+}
+
+// TwoFactor lists the roles that must enrol in two-factor authentication
+type TwoFactor struct {
+	RequiredRoles []string `yaml:"requiredRoles"`
+	Issuer        string   `yaml:"issuer"`
+
+	Policy auth.TwoFactorPolicy `yaml:"-"`
+}
+
+// OIDC configures single sign-on, which is off without an issuer
+type OIDC struct {
+	IssuerURL    string   `yaml:"issuerUrl"`
+	ClientID     string   `yaml:"clientId"`
+	ClientSecret string   `yaml:"clientSecret"`
+	RedirectURL  string   `yaml:"redirectUrl"`
+	Scopes       []string `yaml:"scopes"`
+	GroupsClaim  string   `yaml:"groupsClaim"`
+	RoleMapping  []string `yaml:"roleMapping"`
+	ProviderName string   `yaml:"providerName"`
+
+	Roles auth.SSORoleMapping `yaml:"-"`
+}
+
+// Enabled reports whether single sign-on is configured
+func (o OIDC) Enabled() bool {
+	return o.IssuerURL != ""
+}
+
+// Registration configures the rate limits and bot checks on registration
+type Registration struct {
+	IPLimit       int           `yaml:"ipLimit"`
+	EmailLimit    int           `yaml:"emailLimit"`
+	LimiterStore  string        `yaml:"limiterStore"`
+	MinFillTime   time.Duration `yaml:"minFillTime"`
+	Challenge     string        `yaml:"challenge"`
+	PoWDifficulty int           `yaml:"powDifficulty"`
+	Captcha       Captcha       `yaml:"captcha"`
+}
+
+// Captcha is the hosted captcha used by the captcha challenge
+type Captcha struct {
+	SiteKey   string `yaml:"siteKey"`
+	Secret    string `yaml:"secret"`
+	VerifyURL string `yaml:"verifyUrl"`
+}
+
+// Webhooks configures how queued webhook deliveries are sent
+type Webhooks struct {
+	PollInterval time.Duration `yaml:"pollInterval"`
+	Timeout      time.Duration `yaml:"timeout"`
+	MaxAttempts  int           `yaml:"maxAttempts"`
+}
+
+// Jobs configures the background job runner
+type Jobs struct {
+	Workers      int           `yaml:"workers"`
+	PollInterval time.Duration `yaml:"pollInterval"`
+	Lease        time.Duration `yaml:"lease"`
+	MaxAttempts  int           `yaml:"maxAttempts"`
+}
+
+// Release reports whether the server runs in production mode
+func (c *Config) Release() bool {
+	return c.Mode == "release"
+}
+
+// Default returns the configuration used for anything not set elsewhere
+func Default() *Config {
+	return &Config{
+		Port:        "8080",
+		Mode:        "debug",
+		FrontendURL: "http://localhost:5173",
+		// Cloud Run's front end connects from a private address
+		TrustedProxies: []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "169.254.0.0/16", "127.0.0.0/8", "::1/128", "fc00::/7"},
+		Timezone:       "Asia/Kolkata",
+		TrashRetention: 30,
+		TracesExporter: tracing.ExporterNone,
+		Session: Session{
+			Store:           StoreFirestore,
+			IdleTimeout:     sessionstore.DefaultIdleTimeout,
+			AbsoluteTimeout: sessionstore.DefaultAbsoluteTimeout,
+			CookieSameSite:  "lax",
+		},
+		Login:     Login{LimiterStore: StoreFirestore},
+		TwoFactor: TwoFactor{Issuer: "AI India Workshop"},
+		OIDC:      OIDC{ProviderName: "SSO"},
+		Registration: Registration{
+			IPLimit:       ratelimit.RegistrationIPPolicy.FreeAttempts,
+			EmailLimit:    ratelimit.RegistrationEmailPolicy.FreeAttempts,
+			LimiterStore:  StoreFirestore,
+			MinFillTime:   3 * time.Second,
+			Challenge:     ChallengeNone,
+			PoWDifficulty: 16,
+			Captcha:       Captcha{VerifyURL: botguard.TurnstileVerifyURL},
+		},
+		Webhooks: Webhooks{
+			PollInterval: webhook.DefaultPollInterval,
+			Timeout:      webhook.DefaultTimeout,
+			MaxAttempts:  webhook.DefaultMaxAttempts,
+		},
+		Jobs: Jobs{
+			Workers:      jobs.DefaultWorkers,
+			PollInterval: jobs.DefaultPollInterval,
+			Lease:        jobs.DefaultLease,
+			MaxAttempts:  jobs.DefaultMaxAttempts,
+		},
+	}
+}
+
+// Load reads the configuration and validates it. Values come from the
+// defaults, then the YAML file named by CONFIG_FILE, then the environment.
+// A .env file in the parent or working directory is added to the
+// environment first, without overriding variables that are already set.
+// Every problem found is reported in the returned error.
+func Load() (*Config, error) {
+	envFile := ""
+	for _, path := range []string{"../.env", ".env"} {
+		if godotenv.Load(path) == nil {
+			envFile = path
+			break
+		}
+	}
+
+	cfg, err := load(os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	cfg.EnvFile = envFile
+	return cfg, nil
+}
+
+func load(lookup func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+	if path, ok := lookup("CONFIG_FILE"); ok && path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	env := &envLoader{lookup: lookup}
+	cfg.applyEnv(env)
+	if err := errors.Join(env.errs...); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// readFile overlays the settings in a YAML file. Unknown keys are rejected
+// so a misspelt setting is not silently ignored.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading CONFIG_FILE: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overlays the environment variables that are set
+func (c *Config) applyEnv(env *envLoader) {
+	env.string("PORT", &c.Port)
+	env.string("GIN_MODE", &c.Mode)
+	env.string("LOG_LEVEL", &c.LogLevel)
+	env.string("STATIC_DIR", &c.StaticDir)
+	env.string("FRONTEND_URL", &c.FrontendURL)
+	env.list("CORS_ALLOWED_ORIGINS", &c.CORSOrigins)
+	env.list("TRUSTED_PROXIES", &c.TrustedProxies)
+	env.string("WORKSHOP_TIMEZONE", &c.Timezone)
+	env.int("TRASH_RETENTION_DAYS", &c.TrashRetention)
+	env.string("OTEL_TRACES_EXPORTER", &c.TracesExporter)
+	env.string("METRICS_TOKEN", &c.MetricsToken)
+	env.flag("OPENAPI_VALIDATE_REQUESTS", &c.ValidateRequests)
+
+	env.string("FIRESTORE_SUBCOLLECTION_ID", &c.Firestore.SubcollectionID)
+	env.string("FIREBASE_SERVICE_ACCOUNT_PATH", &c.Firestore.CredentialsFile)
+	env.string("GCLOUD_PROJECT", &c.Firestore.ProjectID)
+	env.string("GOOGLE_CLOUD_PROJECT", &c.Firestore.ProjectID)
+	env.string("GCP_PROJECT_ID", &c.Firestore.ProjectID)
+
+	env.string("ADMIN_EMAIL", &c.Admin.Email)
+	env.string("ADMIN_PASSWORD", &c.Admin.Password)
+
+	env.string("SESSION_SECRET", &c.Session.Secret)
+	env.string("SESSION_STORE", &c.Session.Store)
+	env.duration("SESSION_IDLE_TIMEOUT", &c.Session.IdleTimeout)
+	env.duration("SESSION_ABSOLUTE_TIMEOUT", &c.Session.AbsoluteTimeout)
+	env.bool("SESSION_COOKIE_SECURE", &c.Session.CookieSecure)
+	env.string("SESSION_COOKIE_SAMESITE", &c.Session.CookieSameSite)
+
+	env.string("LOGIN_LIMITER_STORE", &c.Login.LimiterStore)
+
+	env.list("ADMIN_2FA_REQUIRED_ROLES", &c.TwoFactor.RequiredRoles)
+	env.string("TOTP_ISSUER", &c.TwoFactor.Issuer)
+
+	env.string("OIDC_ISSUER_URL", &c.OIDC.IssuerURL)
+	env.string("OIDC_CLIENT_ID", &c.OIDC.ClientID)
+	env.string("OIDC_CLIENT_SECRET", &c.OIDC.ClientSecret)
+	env.string("OIDC_REDIRECT_URL", &c.OIDC.RedirectURL)
+	env.fields("OIDC_SCOPES", &c.OIDC.Scopes)
+	env.string("OIDC_GROUPS_CLAIM", &c.OIDC.GroupsClaim)
+	env.list("OIDC_ROLE_MAPPING", &c.OIDC.RoleMapping)
+	env.string("OIDC_PROVIDER_NAME", &c.OIDC.ProviderName)
+
+	env.int("REGISTRATION_IP_LIMIT", &c.Registration.IPLimit)
+	env.int("REGISTRATION_EMAIL_LIMIT", &c.Registration.EmailLimit)
+	env.string("REGISTRATION_LIMITER_STORE", &c.Registration.LimiterStore)
+	env.duration("REGISTRATION_MIN_FILL_TIME", &c.Registration.MinFillTime)
+	env.string("REGISTRATION_CHALLENGE", &c.Registration.Challenge)
+	env.int("REGISTRATION_POW_DIFFICULTY", &c.Registration.PoWDifficulty)
+	env.string("CAPTCHA_SITE_KEY", &c.Registration.Captcha.SiteKey)
+	env.string("CAPTCHA_SECRET", &c.Registration.Captcha.Secret)
+	env.string("CAPTCHA_VERIFY_URL", &c.Registration.Captcha.VerifyURL)
+
+	env.duration("WEBHOOK_POLL_INTERVAL", &c.Webhooks.PollInterval)
+	env.duration("WEBHOOK_TIMEOUT", &c.Webhooks.Timeout)
+	env.int("WEBHOOK_MAX_ATTEMPTS", &c.Webhooks.MaxAttempts)
+
+	env.int("JOB_WORKERS", &c.Jobs.Workers)
+	env.duration("JOB_POLL_INTERVAL", &c.Jobs.PollInterval)
+	env.duration("JOB_LEASE", &c.Jobs.Lease)
+	env.int("JOB_MAX_ATTEMPTS", &c.Jobs.MaxAttempts)
+}
@@ -0,0 +1,254 @@
+package config
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lookupIn returns an environment holding only the given variables
+func lookupIn(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := load(lookupIn(map[string]string{"FIRESTORE_SUBCOLLECTION_ID": "workshop-2026"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, "8080", cfg.Port)
+	assert.False(t, cfg.Release())
+	assert.Equal(t, slog.LevelInfo, cfg.Level)
+	assert.Equal(t, "Asia/Kolkata", cfg.Location.String())
+	assert.Equal(t, []string{"http://localhost:5173"}, cfg.CORSOrigins)
+	assert.Equal(t, DefaultSessionSecret, cfg.Session.Secret)
+	assert.False(t, *cfg.Session.CookieSecure)
+	assert.Equal(t, http.SameSiteLaxMode, cfg.Session.SameSite)
+	assert.Equal(t, ChallengeNone, cfg.Registration.Challenge)
+	assert.False(t, cfg.OIDC.Enabled())
+	assert.False(t, cfg.ValidateRequests)
+	assert.Equal(t, 10, cfg.Webhooks.MaxAttempts)
+	assert.Equal(t, 4, cfg.Jobs.Workers)
+	assert.Len(t, cfg.Warnings, 1)
+}
+
+func TestLoad_FileAndEnvironment(t *testing.T) {
+	path := writeFile(t, `
+port: "9090"
+mode: release
+frontendUrl: https://workshop.example.com/app/
+corsOrigins:
+  - https://workshop.example.com
+  - https://admin.example.com/
+firestore:
+  subcollectionId: from-file
+session:
+  secret: a-session-secret-that-is-long-enough
+  store: memory
+  idleTimeout: 15m
+twoFactor:
+  requiredRoles: [owner]
+oidc:
+  issuerUrl: https://accounts.google.com
+  clientId: client
+  redirectUrl: https://workshop.example.com/api/admin/sso/callback
+  roleMapping:
+    - domain:example.com=analyst
+registration:
+  challenge: pow
+  powDifficulty: 20
+`)
+	cfg, err := load(lookupIn(map[string]string{
+		"CONFIG_FILE":                path,
+		"PORT":                       "7070",
+		"FIRESTORE_SUBCOLLECTION_ID": "from-env",
+		"REGISTRATION_IP_LIMIT":      "5",
+		"ADMIN_2FA_REQUIRED_ROLES":   "",
+		"OPENAPI_VALIDATE_REQUESTS":  "true",
+	}))
+	require.NoError(t, err)
+
+	// The environment wins over the file, except where a variable is empty
+	assert.Equal(t, "7070", cfg.Port)
+	assert.Equal(t, "from-env", cfg.Firestore.SubcollectionID)
+	assert.True(t, cfg.TwoFactor.Policy.Required("owner"))
+
+	assert.True(t, cfg.Release())
+	assert.Equal(t, "https://workshop.example.com/app", cfg.FrontendURL)
+	assert.Equal(t, []string{"https://workshop.example.com", "https://admin.example.com"}, cfg.CORSOrigins)
+	assert.Equal(t, StoreMemory, cfg.Session.Store)
+	assert.Equal(t, 15*time.Minute, cfg.Session.IdleTimeout)
+	assert.True(t, *cfg.Session.CookieSecure)
+	assert.True(t, cfg.OIDC.Enabled())
+	assert.False(t, cfg.OIDC.Roles.Empty())
+	assert.Equal(t, 20, cfg.Registration.PoWDifficulty)
+	assert.Equal(t, 5, cfg.Registration.IPLimit)
+	assert.True(t, cfg.ValidateRequests)
+	assert.Empty(t, cfg.Warnings)
+}
+
+func TestLoad_DefaultCORSOriginIsFrontendOrigin(t *testing.T) {
+	cfg, err := load(lookupIn(map[string]string{
+		"FIRESTORE_SUBCOLLECTION_ID": "workshop",
+		"FRONTEND_URL":               "https://workshop.example.com/app/",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://workshop.example.com"}, cfg.CORSOrigins)
+}
+
+func TestLoad_UnknownFileKey(t *testing.T) {
+	path := writeFile(t, "sesion:\n  secret: misspelt\n")
+	_, err := load(lookupIn(map[string]string{"CONFIG_FILE": path}))
+	assert.ErrorContains(t, err, "field sesion not found")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	release := map[string]string{
+		"GIN_MODE":                   "release",
+		"FIRESTORE_SUBCOLLECTION_ID": "workshop",
+		"SESSION_SECRET":             "a-session-secret-that-is-long-enough",
+	}
+	with := func(base map[string]string, overrides map[string]string) map[string]string {
+		env := map[string]string{}
+		for k, v := range base {
+			env[k] = v
+		}
+		for k, v := range overrides {
+			env[k] = v
+		}
+		return env
+	}
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected []string
+	}{
+		{
+			name:     "subcollection is required",
+			env:      map[string]string{},
+			expected: []string{"FIRESTORE_SUBCOLLECTION_ID is required"},
+		},
+		{
+			name:     "session secret required in production",
+			env:      with(release, map[string]string{"SESSION_SECRET": ""}),
+			expected: []string{"SESSION_SECRET must be set in production"},
+		},
+		{
+			name:     "short session secret in production",
+			env:      with(release, map[string]string{"SESSION_SECRET": "too-short"}),
+			expected: []string{"SESSION_SECRET must be at least 32 characters"},
+		},
+		{
+			name:     "short metrics token",
+			env:      with(release, map[string]string{"METRICS_TOKEN": "abc"}),
+			expected: []string{"METRICS_TOKEN must be at least 16 characters"},
+		},
+		{
+			name:     "short admin password",
+			env:      with(release, map[string]string{"ADMIN_EMAIL": "admin@example.com", "ADMIN_PASSWORD": "short"}),
+			expected: []string{"ADMIN_PASSWORD must be at least 12 characters"},
+		},
+		{
+			name:     "frontend URL",
+			env:      with(release, map[string]string{"FRONTEND_URL": "workshop.example.com"}),
+			expected: []string{`invalid FRONTEND_URL: "workshop.example.com"`},
+		},
+		{
+			name:     "CORS origin with a path",
+			env:      with(release, map[string]string{"CORS_ALLOWED_ORIGINS": "https://a.example.com,https://b.example.com/admin"}),
+			expected: []string{`"https://b.example.com/admin" must be an origin`},
+		},
+		{
+			name:     "trusted proxy",
+			env:      with(release, map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8,proxy.internal"}),
+			expected: []string{`"proxy.internal" is not an IP address`},
+		},
+		{
+			name:     "unparseable values are all reported",
+			env:      with(release, map[string]string{"TRASH_RETENTION_DAYS": "month", "SESSION_IDLE_TIMEOUT": "30", "OPENAPI_VALIDATE_REQUESTS": "on"}),
+			expected: []string{"invalid TRASH_RETENTION_DAYS", "invalid SESSION_IDLE_TIMEOUT", "invalid OPENAPI_VALIDATE_REQUESTS"},
+		},
+		{
+			name: "store backends",
+			env:  with(release, map[string]string{"SESSION_STORE": "redis", "LOGIN_LIMITER_STORE": "redis"}),
+			expected: []string{
+				`invalid SESSION_STORE: "redis"`,
+				`invalid LOGIN_LIMITER_STORE: "redis"`,
+			},
+		},
+		{
+			name:     "SameSite none needs a secure cookie",
+			env:      with(release, map[string]string{"SESSION_COOKIE_SAMESITE": "none", "SESSION_COOKIE_SECURE": "false"}),
+			expected: []string{"SESSION_COOKIE_SAMESITE=none requires a secure cookie"},
+		},
+		{
+			name:     "OIDC needs a client and roles",
+			env:      with(release, map[string]string{"OIDC_ISSUER_URL": "https://accounts.google.com"}),
+			expected: []string{"OIDC_CLIENT_ID and OIDC_REDIRECT_URL must be set", "OIDC_ROLE_MAPPING must grant a role"},
+		},
+		{
+			name:     "captcha needs keys",
+			env:      with(release, map[string]string{"REGISTRATION_CHALLENGE": "captcha"}),
+			expected: []string{"requires CAPTCHA_SITE_KEY and CAPTCHA_SECRET"},
+		},
+		{
+			name:     "webhook delivery",
+			env:      with(release, map[string]string{"WEBHOOK_POLL_INTERVAL": "0s", "WEBHOOK_TIMEOUT": "-1s", "WEBHOOK_MAX_ATTEMPTS": "0"}),
+			expected: []string{"invalid WEBHOOK_POLL_INTERVAL", "invalid WEBHOOK_TIMEOUT", "invalid WEBHOOK_MAX_ATTEMPTS"},
+		},
+		{
+			name:     "job runner",
+			env:      with(release, map[string]string{"JOB_WORKERS": "0", "JOB_POLL_INTERVAL": "0s", "JOB_LEASE": "5s", "JOB_MAX_ATTEMPTS": "-1"}),
+			expected: []string{"invalid JOB_WORKERS", "invalid JOB_POLL_INTERVAL", "invalid JOB_LEASE", "invalid JOB_MAX_ATTEMPTS"},
+		},
+		{
+			name:     "fake challenge in production",
+			env:      with(release, map[string]string{"REGISTRATION_CHALLENGE": "fake"}),
+			expected: []string{"REGISTRATION_CHALLENGE=fake is only for development"},
+		},
+		{
+			name: "everything else",
+			env: with(release, map[string]string{
+				"LOG_LEVEL":                   "verbose",
+				"WORKSHOP_TIMEZONE":           "Mars/Olympus",
+				"OTEL_TRACES_EXPORTER":        "zipkin",
+				"ADMIN_2FA_REQUIRED_ROLES":    "owner,wizard",
+				"REGISTRATION_CHALLENGE":      "pow",
+				"REGISTRATION_POW_DIFFICULTY": "64",
+			}),
+			expected: []string{
+				"invalid LOG_LEVEL",
+				"invalid WORKSHOP_TIMEZONE",
+				"invalid OTEL_TRACES_EXPORTER",
+				"invalid ADMIN_2FA_REQUIRED_ROLES",
+				"invalid REGISTRATION_POW_DIFFICULTY",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(lookupIn(tt.env))
+			require.Error(t, err)
+			for _, expected := range tt.expected {
+				assert.ErrorContains(t, err, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envLoader overlays environment variables on the configuration. Empty
+// variables count as unset. Values that cannot be parsed are collected so
+// they are all reported together.
+type envLoader struct {
+	lookup func(string) (string, bool)
+	errs   []error
+}
+
+func (e *envLoader) value(name string) (string, bool) {
+	value, ok := e.lookup(name)
+	value = strings.TrimSpace(value)
+	return value, ok && value != ""
+}
+
+func (e *envLoader) string(name string, dst *string) {
+	if value, ok := e.value(name); ok {
+		*dst = value
+	}
+}
+
+func (e *envLoader) int(name string, dst *int) {
+	value, ok := e.value(name)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("invalid %s: %q is not a number", name, value))
+		return
+	}
+	*dst = n
+}
+
+func (e *envLoader) bool(name string, dst **bool) {
+	value, ok := e.value(name)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("invalid %s: %q is not true or false", name, value))
+		return
+	}
+	*dst = &b
+}
+
+// flag reads a boolean setting that has no unset state
+func (e *envLoader) flag(name string, dst *bool) {
+	var b *bool
+	if e.bool(name, &b); b != nil {
+		*dst = *b
+	}
+}
+
+func (e *envLoader) duration(name string, dst *time.Duration) {
+	value, ok := e.value(name)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("invalid %s: %q is not a duration like 30m", name, value))
+		return
+	}
+	*dst = d
+}
+
+// list reads a comma separated list
+func (e *envLoader) list(name string, dst *[]string) {
+	value, ok := e.value(name)
+	if !ok {
+		return
+	}
+	*dst = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*dst = append(*dst, item)
+		}
+	}
+}
+
+// fields reads a list separated by spaces or commas
+func (e *envLoader) fields(name string, dst *[]string) {
+	if value, ok := e.value(name); ok {
+		*dst = strings.Fields(strings.ReplaceAll(value, ",", " "))
+	}
+}
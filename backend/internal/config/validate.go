@@ -0,0 +1,285 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/logging"
+	"ai-india-workshop-backend/internal/tracing"
+)
+
+const (
+	// MinSessionSecretLength is the shortest session secret accepted in
+	// release mode
+	MinSessionSecretLength = 32
+	// MinMetricsTokenLength is the shortest token accepted for /metrics
+	MinMetricsTokenLength = 16
+)
+
+// validate checks every setting and fills in the fields derived from them
+func (c *Config) validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if !slices.Contains([]string{"debug", "release", "test"}, c.Mode) {
+		fail("invalid GIN_MODE: %q, must be debug, release or test", c.Mode)
+	}
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		fail("invalid PORT: %q", c.Port)
+	}
+	var err error
+	if c.Level, err = logging.ParseLevel(c.LogLevel); err != nil {
+		fail("invalid LOG_LEVEL: %v", err)
+	}
+	if c.Location, err = time.LoadLocation(c.Timezone); err != nil {
+		fail("invalid WORKSHOP_TIMEZONE: %q", c.Timezone)
+	}
+	if c.TrashRetention < 1 {
+		fail("invalid TRASH_RETENTION_DAYS: %d, must be at least 1", c.TrashRetention)
+	}
+	exporters := []string{"", tracing.ExporterNone, tracing.ExporterConsole, tracing.ExporterStdout, tracing.ExporterOTLP}
+	if !slices.Contains(exporters, strings.ToLower(c.TracesExporter)) {
+		fail("invalid OTEL_TRACES_EXPORTER: %q, must be none, console or otlp", c.TracesExporter)
+	}
+	if c.MetricsToken != "" && len(c.MetricsToken) < MinMetricsTokenLength {
+		fail("METRICS_TOKEN must be at least %d characters", MinMetricsTokenLength)
+	}
+
+	// The frontend URL is where single sign-on returns to, and its origin is
+	// allowed by CORS unless other origins are listed
+	if err := checkURL("FRONTEND_URL", c.FrontendURL); err != nil {
+		errs = append(errs, err)
+	} else {
+		c.FrontendURL = strings.TrimSuffix(c.FrontendURL, "/")
+		if len(c.CORSOrigins) == 0 {
+			u, _ := url.Parse(c.FrontendURL)
+			c.CORSOrigins = []string{u.Scheme + "://" + u.Host}
+		}
+	}
+	for i, origin := range c.CORSOrigins {
+		origin = strings.TrimSuffix(origin, "/")
+		if err := checkURL("CORS_ALLOWED_ORIGINS", origin); err != nil {
+			errs = append(errs, err)
+		} else if u, _ := url.Parse(origin); u.Path != "" || u.RawQuery != "" {
+			fail("invalid CORS_ALLOWED_ORIGINS: %q must be an origin such as https://example.com, without a path", origin)
+		}
+		c.CORSOrigins[i] = origin
+	}
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			fail("invalid TRUSTED_PROXIES: %q is not an IP address or CIDR range", proxy)
+		}
+	}
+
+	if c.Firestore.SubcollectionID == "" {
+		fail("FIRESTORE_SUBCOLLECTION_ID is required")
+	}
+	if path := c.Firestore.CredentialsFile; path != "" {
+		if _, err := os.Stat(path); err != nil {
+			fail("invalid FIREBASE_SERVICE_ACCOUNT_PATH: %v", err)
+		}
+	}
+
+	if c.Admin.Email != "" {
+		if _, err := mail.ParseAddress(c.Admin.Email); err != nil {
+			fail("invalid ADMIN_EMAIL: %q", c.Admin.Email)
+		}
+	} else if c.Admin.Password != "" {
+		fail("ADMIN_PASSWORD is set without ADMIN_EMAIL")
+	}
+	if c.Admin.Password != "" && len(c.Admin.Password) < auth.MinPasswordLength {
+		fail("ADMIN_PASSWORD must be at least %d characters", auth.MinPasswordLength)
+	}
+
+	errs = append(errs, c.validateSession()...)
+	if !validStore(c.Login.LimiterStore) {
+		fail("invalid LOGIN_LIMITER_STORE: %q, must be firestore or memory", c.Login.LimiterStore)
+	}
+	if c.TwoFactor.Policy, err = auth.ParseTwoFactorPolicy(strings.Join(c.TwoFactor.RequiredRoles, ",")); err != nil {
+		fail("invalid ADMIN_2FA_REQUIRED_ROLES: %v", err)
+	}
+	errs = append(errs, c.validateOIDC()...)
+	errs = append(errs, c.validateRegistration()...)
+	errs = append(errs, c.validateWebhooks()...)
+	errs = append(errs, c.validateJobs()...)
+
+	return errors.Join(errs...)
+}
+
+func (c *Config) validateSession() []error {
+	var errs []error
+	s := &c.Session
+
+	switch {
+	case s.Secret == "" || s.Secret == DefaultSessionSecret:
+		if c.Release() {
+			errs = append(errs, errors.New("SESSION_SECRET must be set in production"))
+		} else {
+			c.Warnings = append(c.Warnings, "SESSION_SECRET is not set; using an insecure default for development")
+			s.Secret = DefaultSessionSecret
+		}
+	case len(s.Secret) < MinSessionSecretLength:
+		if c.Release() {
+			errs = append(errs, fmt.Errorf("SESSION_SECRET must be at least %d characters", MinSessionSecretLength))
+		} else {
+			c.Warnings = append(c.Warnings, fmt.Sprintf("SESSION_SECRET is shorter than %d characters, which production requires", MinSessionSecretLength))
+		}
+	}
+	if !validStore(s.Store) {
+		errs = append(errs, fmt.Errorf("invalid SESSION_STORE: %q, must be firestore or memory", s.Store))
+	}
+	if s.IdleTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid SESSION_IDLE_TIMEOUT: %s", s.IdleTimeout))
+	}
+	if s.AbsoluteTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid SESSION_ABSOLUTE_TIMEOUT: %s", s.AbsoluteTimeout))
+	}
+
+	// In production the cookie is only sent over HTTPS unless configured
+	// otherwise
+	if s.CookieSecure == nil {
+		secure := c.Release()
+		s.CookieSecure = &secure
+	}
+	switch s.CookieSameSite {
+	case "", "lax":
+		s.SameSite = http.SameSiteLaxMode
+	case "strict":
+		s.SameSite = http.SameSiteStrictMode
+	case "none":
+		s.SameSite = http.SameSiteNoneMode
+		if !*s.CookieSecure {
+			errs = append(errs, errors.New("SESSION_COOKIE_SAMESITE=none requires a secure cookie"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid SESSION_COOKIE_SAMESITE: %q, must be lax, strict or none", s.CookieSameSite))
+	}
+	return errs
+}
+
+func (c *Config) validateOIDC() []error {
+	o := &c.OIDC
+	if !o.Enabled() {
+		return nil
+	}
+
+	var errs []error
+	if err := checkURL("OIDC_ISSUER_URL", o.IssuerURL); err != nil {
+		errs = append(errs, err)
+	}
+	if o.ClientID == "" || o.RedirectURL == "" {
+		errs = append(errs, errors.New("OIDC_CLIENT_ID and OIDC_REDIRECT_URL must be set when OIDC_ISSUER_URL is set"))
+	} else if err := checkURL("OIDC_REDIRECT_URL", o.RedirectURL); err != nil {
+		errs = append(errs, err)
+	}
+	var err error
+	if o.Roles, err = auth.ParseSSORoleMapping(strings.Join(o.RoleMapping, ",")); err != nil {
+		errs = append(errs, fmt.Errorf("invalid OIDC_ROLE_MAPPING: %w", err))
+	} else if o.Roles.Empty() {
+		errs = append(errs, errors.New("OIDC_ROLE_MAPPING must grant a role to someone when OIDC_ISSUER_URL is set"))
+	}
+	return errs
+}
+
+func (c *Config) validateRegistration() []error {
+	var errs []error
+	r := &c.Registration
+
+	if r.IPLimit < 1 {
+		errs = append(errs, fmt.Errorf("invalid REGISTRATION_IP_LIMIT: %d", r.IPLimit))
+	}
+	if r.EmailLimit < 1 {
+		errs = append(errs, fmt.Errorf("invalid REGISTRATION_EMAIL_LIMIT: %d", r.EmailLimit))
+	}
+	if !validStore(r.LimiterStore) {
+		errs = append(errs, fmt.Errorf("invalid REGISTRATION_LIMITER_STORE: %q, must be firestore or memory", r.LimiterStore))
+	}
+	if r.MinFillTime <= 0 {
+		errs = append(errs, fmt.Errorf("invalid REGISTRATION_MIN_FILL_TIME: %s", r.MinFillTime))
+	}
+
+	switch r.Challenge {
+	case "", ChallengeNone:
+		r.Challenge = ChallengeNone
+	case ChallengePoW:
+		if r.PoWDifficulty < 1 || r.PoWDifficulty > 32 {
+			errs = append(errs, fmt.Errorf("invalid REGISTRATION_POW_DIFFICULTY: %d, must be between 1 and 32", r.PoWDifficulty))
+		}
+	case ChallengeCaptcha:
+		if r.Captcha.SiteKey == "" || r.Captcha.Secret == "" {
+			errs = append(errs, errors.New("REGISTRATION_CHALLENGE=captcha requires CAPTCHA_SITE_KEY and CAPTCHA_SECRET"))
+		}
+		if err := checkURL("CAPTCHA_VERIFY_URL", r.Captcha.VerifyURL); err != nil {
+			errs = append(errs, err)
+		}
+	case ChallengeFake:
+		if c.Release() {
+			errs = append(errs, errors.New("REGISTRATION_CHALLENGE=fake is only for development"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid REGISTRATION_CHALLENGE: %q, must be none, pow, captcha or fake", r.Challenge))
+	}
+	return errs
+}
+
+func (c *Config) validateWebhooks() []error {
+	var errs []error
+	w := &c.Webhooks
+
+	if w.PollInterval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid WEBHOOK_POLL_INTERVAL: %s", w.PollInterval))
+	}
+	if w.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid WEBHOOK_TIMEOUT: %s", w.Timeout))
+	}
+	if w.MaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS: %d", w.MaxAttempts))
+	}
+	return errs
+}
+
+func (c *Config) validateJobs() []error {
+	var errs []error
+	j := &c.Jobs
+
+	if j.Workers < 1 {
+		errs = append(errs, fmt.Errorf("invalid JOB_WORKERS: %d", j.Workers))
+	}
+	if j.PollInterval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid JOB_POLL_INTERVAL: %s", j.PollInterval))
+	}
+	// Handlers get three quarters of the lease, which must leave them time
+	// to do anything
+	if j.Lease < 10*time.Second {
+		errs = append(errs, fmt.Errorf("invalid JOB_LEASE: %s, must be at least 10s", j.Lease))
+	}
+	if j.MaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("invalid JOB_MAX_ATTEMPTS: %d", j.MaxAttempts))
+	}
+	return errs
+}
+
+func validStore(store string) bool {
+	return store == StoreFirestore || store == StoreMemory
+}
+
+// checkURL requires an absolute http or https URL
+func checkURL(name, value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid %s: %q is not an http or https URL", name, value)
+	}
+	return nil
+}
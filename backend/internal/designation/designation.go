@@ -0,0 +1,161 @@
+// Package designation maps the designations people type when registering
+// onto the workshop's canonical list, so "SDE", "Software Engineer" and
+// "software engineer " are counted together
+package designation
+
+import (
+	"fmt"
+	"strings"
+
+	"ai-india-workshop-backend/internal/models"
+)
+
+// Other is the breakdown bucket for designations outside the taxonomy
+const Other = "Other"
+
+const (
+	maxDesignations = 100
+	maxAliases      = 50
+	maxNameLength   = 100
+)
+
+// Clean trims a designation and collapses runs of whitespace
+func Clean(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// key is what designations and aliases are matched on
+func key(value string) string {
+	return strings.ToLower(Clean(value))
+}
+
+// Validate checks a taxonomy, cleaning its names and aliases in place.
+// Every name and alias must match exactly one designation.
+func Validate(designations []models.Designation) error {
+	if len(designations) > maxDesignations {
+		return fmt.Errorf("a taxonomy can have at most %d designations", maxDesignations)
+	}
+
+	owner := map[string]string{}
+	claim := func(value, name string) error {
+		if value == "" || len(value) > maxNameLength {
+			return fmt.Errorf("%q: names and aliases must be between 1 and %d characters", name, maxNameLength)
+		}
+		if other, ok := owner[key(value)]; ok {
+			if other == name {
+				return fmt.Errorf("%q: %q is listed twice", name, value)
+			}
+			return fmt.Errorf("%q: %q is already used by %q", name, value, other)
+		}
+		owner[key(value)] = name
+		return nil
+	}
+
+	for i := range designations {
+		d := &designations[i]
+		d.Name = Clean(d.Name)
+		if strings.EqualFold(d.Name, Other) {
+			return fmt.Errorf("%q is reserved for designations outside the taxonomy", Other)
+		}
+		if err := claim(d.Name, d.Name); err != nil {
+			return err
+		}
+		if len(d.Aliases) > maxAliases {
+			return fmt.Errorf("%q: at most %d aliases are allowed", d.Name, maxAliases)
+		}
+		for j := range d.Aliases {
+			d.Aliases[j] = Clean(d.Aliases[j])
+			if err := claim(d.Aliases[j], d.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Matcher looks up canonical designations
+type Matcher struct {
+	canonical map[string]string
+	names     []string
+}
+
+func NewMatcher(designations []models.Designation) *Matcher {
+	m := &Matcher{canonical: map[string]string{}}
+	for _, d := range designations {
+		m.names = append(m.names, d.Name)
+		m.canonical[key(d.Name)] = d.Name
+		for _, alias := range d.Aliases {
+			m.canonical[key(alias)] = d.Name
+		}
+	}
+	return m
+}
+
+// Empty reports whether there is no taxonomy to match against
+func (m *Matcher) Empty() bool {
+	return len(m.names) == 0
+}
+
+// Normalize returns the canonical designation for a value, or the value
+// cleaned up if it is not in the taxonomy
+func (m *Matcher) Normalize(value string) string {
+	if name, ok := m.canonical[key(value)]; ok {
+		return name
+	}
+	return Clean(value)
+}
+
+// Known reports whether a value is a designation or alias in the taxonomy
+func (m *Matcher) Known(value string) bool {
+	_, ok := m.canonical[key(value)]
+	return ok
+}
+
+// Group folds raw designation counts into the canonical designations, in
+// taxonomy order, with everything else counted under Other. Without a
+// taxonomy, designations that differ only in case or spacing are merged.
+// Designations nobody has are left out.
+func (m *Matcher) Group(counts []models.DesignationCount) []models.DesignationCount {
+	totals := map[string]int{}
+	var order []string
+	add := func(name string, count int) {
+		if _, ok := totals[name]; !ok {
+			order = append(order, name)
+		}
+		totals[name] += count
+	}
+
+	if m.Empty() {
+		// The first spelling seen names the group
+		spelling := map[string]string{}
+		for _, c := range counts {
+			k := key(c.Designation)
+			if _, ok := spelling[k]; !ok {
+				spelling[k] = Clean(c.Designation)
+			}
+			add(spelling[k], c.Count)
+		}
+	} else {
+		other := 0
+		for _, c := range counts {
+			if name, ok := m.canonical[key(c.Designation)]; ok {
+				totals[name] += c.Count
+			} else {
+				other += c.Count
+			}
+		}
+		order = append(order, m.names...)
+		if other > 0 {
+			order = append(order, Other)
+			totals[Other] = other
+		}
+	}
+
+	grouped := []models.DesignationCount{}
+	for _, name := range order {
+		if totals[name] > 0 {
+			grouped = append(grouped, models.DesignationCount{Designation: name, Count: totals[name]})
+		}
+	}
+	return grouped
+}
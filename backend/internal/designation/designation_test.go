@@ -0,0 +1,121 @@
+package designation
+
+import (
+	"testing"
+
+	"ai-india-workshop-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTaxonomy() []models.Designation {
+	return []models.Designation{
+		{Name: "Software Engineer", Aliases: []string{"SDE", "SWE", "Developer"}},
+		{Name: "Product Manager", Aliases: []string{"PM"}},
+		{Name: "Student", Aliases: []string{}},
+	}
+}
+
+func TestClean(t *testing.T) {
+	assert.Equal(t, "Software Engineer", Clean("  Software \t Engineer \n"))
+	assert.Equal(t, "", Clean("   "))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		designations []models.Designation
+		expectError  bool
+	}{
+		{name: "valid taxonomy", designations: testTaxonomy()},
+		{name: "empty taxonomy", designations: nil},
+		{name: "blank name", designations: []models.Designation{{Name: "  "}}, expectError: true},
+		{
+			name:         "duplicate name ignoring case",
+			designations: []models.Designation{{Name: "Student"}, {Name: "student"}},
+			expectError:  true,
+		},
+		{
+			name: "alias used by two designations",
+			designations: []models.Designation{
+				{Name: "Software Engineer", Aliases: []string{"Engineer"}},
+				{Name: "Data Engineer", Aliases: []string{"engineer"}},
+			},
+			expectError: true,
+		},
+		{
+			name:         "alias repeats the name",
+			designations: []models.Designation{{Name: "Student", Aliases: []string{"STUDENT"}}},
+			expectError:  true,
+		},
+		{name: "reserved name", designations: []models.Designation{{Name: "other"}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.designations)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidate_CleansInPlace(t *testing.T) {
+	designations := []models.Designation{{Name: " Software  Engineer ", Aliases: []string{" SDE  "}}}
+	assert.NoError(t, Validate(designations))
+	assert.Equal(t, "Software Engineer", designations[0].Name)
+	assert.Equal(t, []string{"SDE"}, designations[0].Aliases)
+}
+
+func TestMatcher_Normalize(t *testing.T) {
+	matcher := NewMatcher(testTaxonomy())
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "Software Engineer", expected: "Software Engineer"},
+		{value: "software engineer ", expected: "Software Engineer"},
+		{value: "SDE", expected: "Software Engineer"},
+		{value: "sde", expected: "Software Engineer"},
+		{value: " pm", expected: "Product Manager"},
+		{value: "  Data   Scientist ", expected: "Data Scientist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matcher.Normalize(tt.value))
+		})
+	}
+	assert.True(t, matcher.Known("swe"))
+	assert.False(t, matcher.Known("Data Scientist"))
+}
+
+func TestMatcher_Group(t *testing.T) {
+	counts := []models.DesignationCount{
+		{Designation: "SDE", Count: 3},
+		{Designation: "Software Engineer", Count: 2},
+		{Designation: "software engineer ", Count: 1},
+		{Designation: "Data Scientist", Count: 2},
+		{Designation: "Chef", Count: 1},
+		{Designation: "PM", Count: 4},
+	}
+
+	assert.Equal(t, []models.DesignationCount{
+		{Designation: "Software Engineer", Count: 6},
+		{Designation: "Product Manager", Count: 4},
+		{Designation: Other, Count: 3},
+	}, NewMatcher(testTaxonomy()).Group(counts))
+
+	// Without a taxonomy only case and spacing are ignored
+	assert.Equal(t, []models.DesignationCount{
+		{Designation: "SDE", Count: 3},
+		{Designation: "Software Engineer", Count: 3},
+		{Designation: "Data Scientist", Count: 2},
+		{Designation: "Chef", Count: 1},
+		{Designation: "PM", Count: 4},
+	}, NewMatcher(nil).Group(counts))
+}
@@ -0,0 +1,339 @@
+// Package forms checks a workshop's custom registration questions and the
+// answers people give to them
+package forms
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ai-india-workshop-backend/internal/models"
+)
+
+// Limits on a form and its answers
+const (
+	MaxFields         = 30
+	MaxOptions        = 50
+	maxLabelLength    = 200
+	maxPatternLength  = 500
+	maxTextLength     = 200
+	maxTextareaLength = 2000
+)
+
+var fieldIDPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// ValidationError says which field was invalid and why
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+func invalid(field, format string, args ...interface{}) *ValidationError {
+	return &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// ValidateFields checks a form's questions, trimming their labels and
+// options in place
+func ValidateFields(fields []models.FormField) error {
+	if len(fields) > MaxFields {
+		return fmt.Errorf("a form can have at most %d fields", MaxFields)
+	}
+
+	seen := map[string]bool{}
+	for i := range fields {
+		field := &fields[i]
+		if !fieldIDPattern.MatchString(field.ID) {
+			return invalid(field.ID, "id must start with a letter and contain only lower-case letters, digits and underscores")
+		}
+		if seen[field.ID] {
+			return invalid(field.ID, "id is used by more than one field")
+		}
+		seen[field.ID] = true
+
+		field.Label = strings.TrimSpace(field.Label)
+		if field.Label == "" || len(field.Label) > maxLabelLength {
+			return invalid(field.ID, "label must be between 1 and %d characters", maxLabelLength)
+		}
+
+		switch field.Type {
+		case models.FieldTypeSelect, models.FieldTypeMultiSelect:
+			if err := validateOptions(field); err != nil {
+				return err
+			}
+		case models.FieldTypeText, models.FieldTypeTextarea, models.FieldTypeNumber, models.FieldTypeCheckbox:
+			if len(field.Options) > 0 {
+				return invalid(field.ID, "only select and multiselect fields have options")
+			}
+		default:
+			return invalid(field.ID, "unknown field type %q", field.Type)
+		}
+
+		if field.Pattern != "" {
+			if field.Type != models.FieldTypeText && field.Type != models.FieldTypeTextarea {
+				return invalid(field.ID, "only text fields can have a pattern")
+			}
+			if len(field.Pattern) > maxPatternLength {
+				return invalid(field.ID, "pattern must be at most %d characters", maxPatternLength)
+			}
+			if _, err := compilePattern(field.Pattern); err != nil {
+				return invalid(field.ID, "invalid pattern: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateOptions(field *models.FormField) error {
+	if len(field.Options) == 0 || len(field.Options) > MaxOptions {
+		return invalid(field.ID, "must have between 1 and %d options", MaxOptions)
+	}
+	seen := map[string]bool{}
+	for i, option := range field.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || len(option) > maxLabelLength {
+			return invalid(field.ID, "options must be between 1 and %d characters", maxLabelLength)
+		}
+		if seen[option] {
+			return invalid(field.ID, "option %q is listed twice", option)
+		}
+		seen[option] = true
+		field.Options[i] = option
+	}
+	return nil
+}
+
+// compilePattern anchors a pattern so it must match the whole answer
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+// ValidateAnswers checks submitted answers against a form's questions and
+// returns them cleaned up: text trimmed, unanswered questions and unticked
+// checkboxes left out. It returns nil if nothing was answered.
+func ValidateAnswers(fields []models.FormField, answers map[string]interface{}) (map[string]interface{}, error) {
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field.ID] = true
+	}
+	for id := range answers {
+		if !known[id] {
+			return nil, invalid(id, "unknown question")
+		}
+	}
+
+	cleaned := map[string]interface{}{}
+	for _, field := range fields {
+		value, err := validateAnswer(field, answers[field.ID])
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			if field.Required {
+				return nil, invalid(field.ID, "%s is required", field.Label)
+			}
+			continue
+		}
+		cleaned[field.ID] = value
+	}
+
+	if len(cleaned) == 0 {
+		return nil, nil
+	}
+	return cleaned, nil
+}
+
+// validateAnswer returns the cleaned answer to one question, or nil if it
+// was left unanswered
+func validateAnswer(field models.FormField, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch field.Type {
+	case models.FieldTypeText, models.FieldTypeTextarea:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid(field.ID, "must be text")
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, nil
+		}
+		limit := maxTextLength
+		if field.Type == models.FieldTypeTextarea {
+			limit = maxTextareaLength
+		}
+		if len(text) > limit {
+			return nil, invalid(field.ID, "must be at most %d characters", limit)
+		}
+		if field.Pattern != "" {
+			pattern, err := compilePattern(field.Pattern)
+			if err != nil || !pattern.MatchString(text) {
+				return nil, invalid(field.ID, "%s is not in the expected format", field.Label)
+			}
+		}
+		return text, nil
+
+	case models.FieldTypeNumber:
+		number, ok := value.(float64)
+		if !ok || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, invalid(field.ID, "must be a number")
+		}
+		return number, nil
+
+	case models.FieldTypeSelect:
+		choice, ok := value.(string)
+		if !ok {
+			return nil, invalid(field.ID, "must be one of the options")
+		}
+		if choice == "" {
+			return nil, nil
+		}
+		if !hasOption(field, choice) {
+			return nil, invalid(field.ID, "%q is not one of the options", choice)
+		}
+		return choice, nil
+
+	case models.FieldTypeMultiSelect:
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, invalid(field.ID, "must be a list of options")
+		}
+		chosen := map[string]bool{}
+		for _, item := range list {
+			choice, ok := item.(string)
+			if !ok || !hasOption(field, choice) {
+				return nil, invalid(field.ID, "%v is not one of the options", item)
+			}
+			chosen[choice] = true
+		}
+		if len(chosen) == 0 {
+			return nil, nil
+		}
+		// Keep the order the options are listed in
+		choices := make([]string, 0, len(chosen))
+		for _, option := range field.Options {
+			if chosen[option] {
+				choices = append(choices, option)
+			}
+		}
+		return choices, nil
+
+	case models.FieldTypeCheckbox:
+		checked, ok := value.(bool)
+		if !ok {
+			return nil, invalid(field.ID, "must be true or false")
+		}
+		if !checked {
+			return nil, nil
+		}
+		return true, nil
+	}
+	return nil, invalid(field.ID, "unknown field type %q", field.Type)
+}
+
+func hasOption(field models.FormField, choice string) bool {
+	for _, option := range field.Options {
+		if option == choice {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatAnswer renders a stored answer as text, for exports
+func FormatAnswer(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case []string:
+		return strings.Join(v, "; ")
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = FormatAnswer(item)
+		}
+		return strings.Join(items, "; ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// IsChoice reports whether a field's answers can be counted for stats
+func IsChoice(field models.FormField) bool {
+	switch field.Type {
+	case models.FieldTypeSelect, models.FieldTypeMultiSelect, models.FieldTypeCheckbox:
+		return true
+	}
+	return false
+}
+
+// Breakdown counts the answers to each choice question. Options nobody
+// chose are included with a count of zero, and answers to options that
+// have since been removed are listed after the current ones.
+func Breakdown(fields []models.FormField, attendees []*models.Attendee) []models.AnswerBreakdown {
+	breakdown := []models.AnswerBreakdown{}
+	for _, field := range fields {
+		if !IsChoice(field) {
+			continue
+		}
+
+		options := field.Options
+		if field.Type == models.FieldTypeCheckbox {
+			options = []string{"yes"}
+		}
+		counts := map[string]int{}
+		for _, attendee := range attendees {
+			if attendee.DeletedAt != nil {
+				continue
+			}
+			switch answer := attendee.Answers[field.ID].(type) {
+			case []interface{}:
+				for _, item := range answer {
+					counts[FormatAnswer(item)]++
+				}
+			case []string:
+				for _, item := range answer {
+					counts[item]++
+				}
+			case nil:
+			default:
+				counts[FormatAnswer(answer)]++
+			}
+		}
+
+		result := models.AnswerBreakdown{FieldID: field.ID, Label: field.Label, Counts: []models.AnswerCount{}}
+		for _, option := range options {
+			result.Counts = append(result.Counts, models.AnswerCount{Answer: option, Count: counts[option]})
+			delete(counts, option)
+		}
+		removed := make([]string, 0, len(counts))
+		for answer := range counts {
+			removed = append(removed, answer)
+		}
+		sort.Strings(removed)
+		for _, answer := range removed {
+			result.Counts = append(result.Counts, models.AnswerCount{Answer: answer, Count: counts[answer]})
+		}
+		breakdown = append(breakdown, result)
+	}
+	return breakdown
+}
@@ -0,0 +1,197 @@
+package forms
+
+import (
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFields() []models.FormField {
+	return []models.FormField{
+		{ID: "company", Label: "Company", Type: models.FieldTypeText, Required: true},
+		{ID: "github", Label: "GitHub username", Type: models.FieldTypeText, Pattern: `[A-Za-z0-9-]{1,39}`},
+		{ID: "experience", Label: "Years of experience", Type: models.FieldTypeNumber},
+		{ID: "tshirt", Label: "T-shirt size", Type: models.FieldTypeSelect, Options: []string{"S", "M", "L"}},
+		{ID: "diet", Label: "Dietary needs", Type: models.FieldTypeMultiSelect, Options: []string{"Vegetarian", "Vegan", "Gluten free"}},
+		{ID: "consent", Label: "I agree to the code of conduct", Type: models.FieldTypeCheckbox, Required: true},
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		fields      []models.FormField
+		expectError bool
+	}{
+		{name: "valid form", fields: testFields()},
+		{name: "no fields", fields: nil},
+		{
+			name:        "invalid id",
+			fields:      []models.FormField{{ID: "Company Name", Label: "Company", Type: models.FieldTypeText}},
+			expectError: true,
+		},
+		{
+			name: "duplicate id",
+			fields: []models.FormField{
+				{ID: "company", Label: "Company", Type: models.FieldTypeText},
+				{ID: "company", Label: "Employer", Type: models.FieldTypeText},
+			},
+			expectError: true,
+		},
+		{
+			name:        "missing label",
+			fields:      []models.FormField{{ID: "company", Label: " ", Type: models.FieldTypeText}},
+			expectError: true,
+		},
+		{
+			name:        "unknown type",
+			fields:      []models.FormField{{ID: "company", Label: "Company", Type: "date"}},
+			expectError: true,
+		},
+		{
+			name:        "select without options",
+			fields:      []models.FormField{{ID: "tshirt", Label: "T-shirt size", Type: models.FieldTypeSelect}},
+			expectError: true,
+		},
+		{
+			name:        "duplicate option",
+			fields:      []models.FormField{{ID: "tshirt", Label: "T-shirt size", Type: models.FieldTypeSelect, Options: []string{"S", " S"}}},
+			expectError: true,
+		},
+		{
+			name:        "options on a text field",
+			fields:      []models.FormField{{ID: "company", Label: "Company", Type: models.FieldTypeText, Options: []string{"A"}}},
+			expectError: true,
+		},
+		{
+			name:        "invalid pattern",
+			fields:      []models.FormField{{ID: "company", Label: "Company", Type: models.FieldTypeText, Pattern: "(["}},
+			expectError: true,
+		},
+		{
+			name:        "pattern on a select",
+			fields:      []models.FormField{{ID: "tshirt", Label: "T-shirt size", Type: models.FieldTypeSelect, Options: []string{"S"}, Pattern: "S"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFields(tt.fields)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateAnswers(t *testing.T) {
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"company":    "  Acme  ",
+			"github":     "octocat",
+			"experience": float64(4),
+			"tshirt":     "M",
+			"diet":       []interface{}{"Vegan", "Vegetarian", "Vegan"},
+			"consent":    true,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		modify        func(map[string]interface{})
+		expectedField string
+	}{
+		{name: "valid answers", modify: func(map[string]interface{}) {}},
+		{name: "optional answers left out", modify: func(a map[string]interface{}) {
+			delete(a, "github")
+			delete(a, "experience")
+			a["tshirt"] = ""
+			a["diet"] = []interface{}{}
+		}},
+		{name: "required answer missing", modify: func(a map[string]interface{}) { delete(a, "company") }, expectedField: "company"},
+		{name: "required answer blank", modify: func(a map[string]interface{}) { a["company"] = "   " }, expectedField: "company"},
+		{name: "required checkbox unticked", modify: func(a map[string]interface{}) { a["consent"] = false }, expectedField: "consent"},
+		{name: "pattern not matched", modify: func(a map[string]interface{}) { a["github"] = "not a username!" }, expectedField: "github"},
+		{name: "pattern matches only part", modify: func(a map[string]interface{}) { a["github"] = "octocat/hello" }, expectedField: "github"},
+		{name: "number as text", modify: func(a map[string]interface{}) { a["experience"] = "four" }, expectedField: "experience"},
+		{name: "unknown option", modify: func(a map[string]interface{}) { a["tshirt"] = "XXL" }, expectedField: "tshirt"},
+		{name: "unknown multiselect option", modify: func(a map[string]interface{}) { a["diet"] = []interface{}{"Keto"} }, expectedField: "diet"},
+		{name: "unknown question", modify: func(a map[string]interface{}) { a["shoe_size"] = "9" }, expectedField: "shoe_size"},
+		{name: "text too long", modify: func(a map[string]interface{}) { a["company"] = string(make([]byte, 201)) }, expectedField: "company"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answers := valid()
+			tt.modify(answers)
+			cleaned, err := ValidateAnswers(testFields(), answers)
+
+			if tt.expectedField != "" {
+				var validationErr *ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				assert.Equal(t, tt.expectedField, validationErr.Field)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Acme", cleaned["company"])
+			assert.Equal(t, true, cleaned["consent"])
+			if _, ok := answers["github"]; ok {
+				assert.Equal(t, []string{"Vegetarian", "Vegan"}, cleaned["diet"])
+			} else {
+				assert.NotContains(t, cleaned, "github")
+				assert.NotContains(t, cleaned, "tshirt")
+				assert.NotContains(t, cleaned, "diet")
+			}
+		})
+	}
+}
+
+func TestValidateAnswers_NoQuestions(t *testing.T) {
+	cleaned, err := ValidateAnswers(nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, cleaned)
+
+	_, err = ValidateAnswers(nil, map[string]interface{}{"company": "Acme"})
+	assert.Error(t, err)
+}
+
+func TestFormatAnswer(t *testing.T) {
+	assert.Equal(t, "", FormatAnswer(nil))
+	assert.Equal(t, "Acme", FormatAnswer("Acme"))
+	assert.Equal(t, "yes", FormatAnswer(true))
+	assert.Equal(t, "4.5", FormatAnswer(4.5))
+	assert.Equal(t, "12", FormatAnswer(int64(12)))
+	assert.Equal(t, "Vegan; Gluten free", FormatAnswer([]interface{}{"Vegan", "Gluten free"}))
+}
+
+func TestBreakdown(t *testing.T) {
+	deletedAt := time.Now()
+	attendees := []*models.Attendee{
+		{Answers: map[string]interface{}{"tshirt": "M", "diet": []interface{}{"Vegan"}, "consent": true}},
+		{Answers: map[string]interface{}{"tshirt": "M", "diet": []interface{}{"Vegan", "Gluten free"}, "consent": true}},
+		{Answers: map[string]interface{}{"tshirt": "XL", "consent": true}},
+		{Answers: map[string]interface{}{"tshirt": "S"}, DeletedAt: &deletedAt},
+		{},
+	}
+
+	breakdown := Breakdown(testFields(), attendees)
+
+	assert.Equal(t, []models.AnswerBreakdown{
+		{FieldID: "tshirt", Label: "T-shirt size", Counts: []models.AnswerCount{
+			{Answer: "S", Count: 0}, {Answer: "M", Count: 2}, {Answer: "L", Count: 0}, {Answer: "XL", Count: 1},
+		}},
+		{FieldID: "diet", Label: "Dietary needs", Counts: []models.AnswerCount{
+			{Answer: "Vegetarian", Count: 0}, {Answer: "Vegan", Count: 2}, {Answer: "Gluten free", Count: 1},
+		}},
+		{FieldID: "consent", Label: "I agree to the code of conduct", Counts: []models.AnswerCount{
+			{Answer: "yes", Count: 3},
+		}},
+	}, breakdown)
+}
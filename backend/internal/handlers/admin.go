@@ -1,52 +1,292 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
 	"net/http"
-	"os"
+	"slices"
+	"strconv"
+	"time"
 
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/designation"
+	"ai-india-workshop-backend/internal/forms"
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/problem"
+	"ai-india-workshop-backend/internal/ratelimit"
 	"ai-india-workshop-backend/internal/repository"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// pendingLoginTTL is how long a login may wait for its second factor
+	pendingLoginTTL = 5 * time.Minute
+	// maxSecondFactorAttempts limits guesses at a code before the password
+	// must be entered again
+	maxSecondFactorAttempts = 5
+)
+
 type AdminHandler struct {
-	repo repository.RepositoryInterface
+	repo           repository.RepositoryInterface
+	ipLimiter      *ratelimit.Limiter
+	accountLimiter *ratelimit.Limiter
+	sso            *SSOConfig
 }
 
-func NewAdminHandler(repo repository.RepositoryInterface) *AdminHandler {
-	return &AdminHandler{repo: repo}
+// NewAdminHandler creates the handler. Failed logins are counted per client
+// IP by ipLimiter and per email address by accountLimiter.
+func NewAdminHandler(repo repository.RepositoryInterface, ipLimiter, accountLimiter *ratelimit.Limiter) *AdminHandler {
+	return &AdminHandler{repo: repo, ipLimiter: ipLimiter, accountLimiter: accountLimiter}
 }
 
 func (h *AdminHandler) Login(c *gin.Context) {
 	var req struct {
+		Email    string `json:"email" binding:"required,email"`
 		Password string `json:"password" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.BindError(c, err)
+		return
+	}
+
+	email := auth.NormalizeEmail(req.Email)
+	if wait := h.lockedOut(c, email); wait > 0 {
+		h.recordAttempt(c, email, models.LoginReasonLockedOut)
+		tooManyAttempts(c, wait)
+		return
+	}
+
+	user, err := h.repo.GetAdminUserByEmail(c.Request.Context(), email)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load admin user"})
+		return
+	}
+	if err != nil {
+		auth.CheckDummyPassword(req.Password)
+		h.loginFailed(c, email, models.LoginReasonUnknownEmail)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+	if !auth.CheckPassword(user.PasswordHash, req.Password) {
+		h.loginFailed(c, email, models.LoginReasonInvalidPassword)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+	if user.Disabled {
+		h.recordAttempt(c, email, models.LoginReasonAccountDisabled)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled"})
+		return
+	}
+
+	session := sessions.Default(c)
+	session.Clear()
+
+	// With 2FA enabled the password only unlocks the second step; the
+	// session is not signed in until VerifyTwoFactor succeeds
+	if user.TwoFactorEnabled {
+		session.Set(middleware.SessionPendingUserIDKey, user.ID)
+		session.Set(middleware.SessionPendingAtKey, time.Now().Unix())
+		session.Set(middleware.SessionPendingAttemptsKey, 0)
+		if err := session.Save(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save session"})
+			return
+		}
+		c.Set(middleware.ActorKey, user.Email)
+		respondLogin(c, false, gin.H{"message": "Two-factor authentication code required", "twoFactorRequired": true})
+		return
+	}
+
+	csrfToken, ok := h.startSession(c, user)
+	if !ok {
+		return
+	}
+	h.loginSucceeded(c, email)
+
+	now := time.Now()
+	user.LastLoginAt = &now
+	if err := h.repo.UpdateAdminUser(c.Request.Context(), user.ID, user); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error recording last login", "adminId", user.ID, "error", err)
+	}
+
+	respondLogin(c, true, gin.H{"message": "Logged in successfully", "user": user, "csrfToken": csrfToken})
+}
+
+// VerifyTwoFactor completes a login started by Login using a TOTP code or a
+// recovery code
+func (h *AdminHandler) VerifyTwoFactor(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	session := sessions.Default(c)
+	userID, _ := session.Get(middleware.SessionPendingUserIDKey).(string)
+	startedAt, _ := session.Get(middleware.SessionPendingAtKey).(int64)
+	attempts, _ := session.Get(middleware.SessionPendingAttemptsKey).(int)
+	if userID == "" || time.Since(time.Unix(startedAt, 0)) > pendingLoginTTL || attempts >= maxSecondFactorAttempts {
+		session.Clear()
+		_ = session.Save()
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login expired, please sign in again"})
+		return
+	}
+
+	user, err := h.repo.GetAdminUser(c.Request.Context(), userID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load admin user"})
+		return
+	}
+	if err != nil || user.Disabled {
+		session.Clear()
+		_ = session.Save()
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login expired, please sign in again"})
+		return
+	}
+	c.Set(middleware.ActorKey, user.Email)
+
+	// Restarting the login for a fresh set of attempts still counts against
+	// the account
+	if wait := h.lockedOut(c, user.Email); wait > 0 {
+		h.recordAttempt(c, user.Email, models.LoginReasonLockedOut)
+		tooManyAttempts(c, wait)
+		return
+	}
+
+	if !auth.VerifySecondFactor(user, req.Code, time.Now()) {
+		h.loginFailed(c, user.Email, models.LoginReasonInvalidTwoFactor)
+		session.Set(middleware.SessionPendingAttemptsKey, attempts+1)
+		_ = session.Save()
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
 		return
 	}
 
-	adminPassword := os.Getenv("ADMIN_PASSWORD")
-	if adminPassword == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Admin password not configured"})
+	// The used code must be recorded before signing in so it cannot be replayed
+	now := time.Now()
+	user.LastLoginAt = &now
+	if err := h.repo.UpdateAdminUser(c.Request.Context(), user.ID, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin user"})
 		return
 	}
 
-	if req.Password != adminPassword {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+	session.Clear()
+	csrfToken, ok := h.startSession(c, user)
+	if !ok {
 		return
 	}
+	h.loginSucceeded(c, user.Email)
+
+	respondLogin(c, true, gin.H{"message": "Logged in successfully", "user": user, "csrfToken": csrfToken})
+}
+
+// respondLogin answers a step of the login. Clients of the deprecated /api
+// routes also get the success flag they checked before the message replaced
+// it.
+func respondLogin(c *gin.Context, success bool, body gin.H) {
+	if c.GetBool(middleware.DeprecatedKey) {
+		body["success"] = success
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// lockedOut returns how long the client must wait before trying to sign in
+// to the account again. Limiter errors are logged and the attempt allowed,
+// so an outage of the shared store cannot lock every admin out.
+func (h *AdminHandler) lockedOut(c *gin.Context, email string) time.Duration {
+	var wait time.Duration
+	for key, limiter := range h.limiterKeys(c, email) {
+		remaining, err := limiter.Check(c.Request.Context(), key)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Error checking login limiter", "key", key, "error", err)
+			continue
+		}
+		wait = max(wait, remaining)
+	}
+	return wait
+}
+
+// loginFailed counts a failed attempt against the client IP and the account
+func (h *AdminHandler) loginFailed(c *gin.Context, email, reason string) {
+	for key, limiter := range h.limiterKeys(c, email) {
+		if _, err := limiter.Fail(c.Request.Context(), key); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Error recording failed login", "key", key, "error", err)
+		}
+	}
+	h.recordAttempt(c, email, reason)
+}
+
+// loginSucceeded clears the account's failures. The IP's failures are kept
+// so that signing in to one account does not allow more guesses at others.
+func (h *AdminHandler) loginSucceeded(c *gin.Context, email string) {
+	if err := h.accountLimiter.Reset(c.Request.Context(), accountLimiterKey(email)); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error resetting login limiter", "email", email, "error", err)
+	}
+	h.recordAttempt(c, email, "")
+}
+
+func (h *AdminHandler) limiterKeys(c *gin.Context, email string) map[string]*ratelimit.Limiter {
+	return map[string]*ratelimit.Limiter{
+		"ip:" + c.ClientIP():     h.ipLimiter,
+		accountLimiterKey(email): h.accountLimiter,
+	}
+}
 
+func accountLimiterKey(email string) string {
+	return "account:" + email
+}
+
+// recordAttempt stores the attempt for admins to review; an empty reason
+// means it succeeded
+func (h *AdminHandler) recordAttempt(c *gin.Context, email, reason string) {
+	attempt := &models.LoginAttempt{
+		Timestamp: time.Now(),
+		Email:     email,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Success:   reason == "",
+		Reason:    reason,
+	}
+	if err := h.repo.CreateLoginAttempt(context.WithoutCancel(c.Request.Context()), attempt); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error recording login attempt", "email", email, "error", err)
+	}
+}
+
+// tooManyAttempts rejects a locked out login, telling the client when to retry
+func tooManyAttempts(c *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":      "Too many failed login attempts, please try again later",
+		"retryAfter": seconds,
+	})
+}
+
+// startSession signs the user in to the current session and returns the
+// CSRF token the client must send with state-changing requests
+func (h *AdminHandler) startSession(c *gin.Context, user *models.AdminUser) (string, bool) {
 	session := sessions.Default(c)
-	session.Set("isAdmin", true)
+	session.Set(middleware.SessionUserIDKey, user.ID)
+	session.Set(middleware.SessionEmailKey, user.Email)
+	csrfToken, err := middleware.IssueCSRFToken(session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create CSRF token"})
+		return "", false
+	}
 	if err := session.Save(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save session"})
-		return
+		return "", false
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true})
+	c.Set(middleware.ActorKey, user.Email)
+	return csrfToken, true
 }
 
 func (h *AdminHandler) Logout(c *gin.Context) {
@@ -60,13 +300,37 @@ func (h *AdminHandler) Logout(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
+// GetStats returns the breakdown by canonical designation and, for each
+// custom choice question, how often each answer was given
 func (h *AdminHandler) GetStats(c *gin.Context) {
-	breakdown, err := h.repo.GetDesignationBreakdown(c.Request.Context())
+	ctx := c.Request.Context()
+	breakdown, err := h.repo.GetDesignationBreakdown(ctx)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get stats"})
 		return
 	}
+	taxonomy, err := h.repo.GetDesignationTaxonomy(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get stats"})
+		return
+	}
+	breakdown = designation.NewMatcher(taxonomy.Designations).Group(breakdown)
 
-	c.JSON(http.StatusOK, gin.H{"designationBreakdown": breakdown})
-}
+	form, err := h.repo.GetRegistrationForm(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get stats"})
+		return
+	}
+	// Attendees are only loaded if there are answers to count
+	answerBreakdown := []models.AnswerBreakdown{}
+	if slices.ContainsFunc(form.Fields, forms.IsChoice) {
+		attendees, err := h.repo.GetAllAttendees(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get stats"})
+			return
+		}
+		answerBreakdown = forms.Breakdown(form.Fields, attendees)
+	}
 
+	c.JSON(http.StatusOK, gin.H{"designationBreakdown": breakdown, "answerBreakdown": answerBreakdown})
+}
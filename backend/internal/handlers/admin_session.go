@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/sessionstore"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminSessionHandler lets admins see where they are signed in and revoke
+// sessions, e.g. after losing a laptop
+type AdminSessionHandler struct {
+	store *sessionstore.Store
+}
+
+func NewAdminSessionHandler(store *sessionstore.Store) *AdminSessionHandler {
+	return &AdminSessionHandler{store: store}
+}
+
+type adminSessionResponse struct {
+	*sessionstore.Record
+	Current bool `json:"current"`
+}
+
+// GetAll lists the signed-in admin's active sessions, marking the one making the request
+func (h *AdminSessionHandler) GetAll(c *gin.Context) {
+	user := middleware.CurrentAdmin(c)
+	records, err := h.store.ListUserSessions(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	current := sessionstore.RecordID(sessions.Default(c).ID())
+	response := make([]adminSessionResponse, 0, len(records))
+	for _, record := range records {
+		response = append(response, adminSessionResponse{Record: record, Current: record.ID == current})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Revoke signs out one of the admin's own sessions
+func (h *AdminSessionHandler) Revoke(c *gin.Context) {
+	id := c.Param("id")
+	user := middleware.CurrentAdmin(c)
+	records, err := h.store.ListUserSessions(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	found := false
+	for _, record := range records {
+		if record.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := h.store.Revoke(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+	if id == sessionstore.RecordID(sessions.Default(c).ID()) {
+		clearSessionCookie(c)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// RevokeAll signs the admin out everywhere, including the current session
+func (h *AdminSessionHandler) RevokeAll(c *gin.Context) {
+	user := middleware.CurrentAdmin(c)
+	if err := h.store.RevokeUser(c.Request.Context(), user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+	clearSessionCookie(c)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Signed out of all sessions"})
+}
+
+// clearSessionCookie expires the cookie of a session that has already been revoked
+func clearSessionCookie(c *gin.Context) {
+	session := sessions.Default(c)
+	session.Clear()
+	_ = session.Save()
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/sessionstore"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAdminSessionTestRouter returns a router backed by an in-memory session
+// store with a /login/:id route that signs in as the given admin
+func setupAdminSessionTestRouter(t *testing.T) (*gin.Engine, *AdminSessionHandler) {
+	gin.SetMode(gin.TestMode)
+	store := sessionstore.New(sessionstore.NewMemoryBackend(), sessionstore.Config{UserIDKey: middleware.SessionUserIDKey}, []byte("test-secret-key"))
+	handler := NewAdminSessionHandler(store)
+
+	r := gin.New()
+	r.Use(sessions.Sessions("admin-session", store))
+	r.POST("/login/:id", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set(middleware.SessionUserIDKey, c.Param("id"))
+		require.NoError(t, session.Save())
+	})
+	r.Use(func(c *gin.Context) {
+		userID, _ := sessions.Default(c).Get(middleware.SessionUserIDKey).(string)
+		if userID == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set(middleware.AdminUserKey, &models.AdminUser{ID: userID})
+		c.Next()
+	})
+	return r, handler
+}
+
+func loginSession(r *gin.Engine, userID string) string {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/login/"+userID, nil))
+	return w.Header().Get("Set-Cookie")
+}
+
+func sessionRequest(r *gin.Engine, method, path, cookie string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Cookie", cookie)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminSessionHandler_GetAllAndRevoke(t *testing.T) {
+	r, handler := setupAdminSessionTestRouter(t)
+	r.GET("/admin/me/sessions", handler.GetAll)
+	r.DELETE("/admin/me/sessions/:id", handler.Revoke)
+
+	laptop := loginSession(r, "user-1")
+	phone := loginSession(r, "user-1")
+	other := loginSession(r, "user-2")
+
+	w := sessionRequest(r, "GET", "/admin/me/sessions", laptop)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var listed []struct {
+		ID      string `json:"id"`
+		Current bool   `json:"current"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 2)
+
+	var phoneID string
+	for _, session := range listed {
+		if !session.Current {
+			phoneID = session.ID
+		}
+	}
+	require.NotEmpty(t, phoneID)
+
+	// Another admin's sessions cannot be revoked
+	w = sessionRequest(r, "DELETE", "/admin/me/sessions/"+phoneID, other)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = sessionRequest(r, "DELETE", "/admin/me/sessions/"+phoneID, laptop)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, sessionRequest(r, "GET", "/admin/me/sessions", phone).Code)
+	assert.Equal(t, http.StatusOK, sessionRequest(r, "GET", "/admin/me/sessions", laptop).Code)
+}
+
+func TestAdminSessionHandler_RevokeAll(t *testing.T) {
+	r, handler := setupAdminSessionTestRouter(t)
+	r.GET("/admin/me/sessions", handler.GetAll)
+	r.DELETE("/admin/me/sessions", handler.RevokeAll)
+
+	laptop := loginSession(r, "user-1")
+	phone := loginSession(r, "user-1")
+	other := loginSession(r, "user-2")
+
+	w := sessionRequest(r, "DELETE", "/admin/me/sessions", laptop)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "Max-Age=0")
+
+	assert.Equal(t, http.StatusUnauthorized, sessionRequest(r, "GET", "/admin/me/sessions", laptop).Code)
+	assert.Equal(t, http.StatusUnauthorized, sessionRequest(r, "GET", "/admin/me/sessions", phone).Code)
+	assert.Equal(t, http.StatusOK, sessionRequest(r, "GET", "/admin/me/sessions", other).Code)
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/oidc"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// pendingSSOTTL is how long the provider has to send the browser back
+const pendingSSOTTL = 10 * time.Minute
+
+// Error codes passed back to the frontend in the ssoError query parameter
+const (
+	ssoErrorFailed     = "failed"
+	ssoErrorExpired    = "expired"
+	ssoErrorNotAllowed = "not_allowed"
+	ssoErrorDisabled   = "disabled"
+)
+
+// SSOConfig enables signing in to the admin panel through an OpenID Connect
+// provider
+type SSOConfig struct {
+	Provider *oidc.Provider
+	Roles    auth.SSORoleMapping
+	// DisplayName names the provider on the login button, e.g. "Google"
+	DisplayName string
+	// FrontendURL is where the browser is sent once the login finishes
+	FrontendURL string
+}
+
+// EnableSSO lets admins sign in with single sign-on as well as a password
+func (h *AdminHandler) EnableSSO(config *SSOConfig) {
+	h.sso = config
+}
+
+// SSOStatus tells the login form whether to offer single sign-on
+func (h *AdminHandler) SSOStatus(c *gin.Context) {
+	if h.sso == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "name": h.sso.DisplayName})
+}
+
+// SSOLogin starts a single sign-on login by sending the browser to the
+// provider. The state, nonce and PKCE verifier are kept in the session for
+// SSOCallback to check.
+func (h *AdminHandler) SSOLogin(c *gin.Context) {
+	if h.sso == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	values := make([]string, 3)
+	for i := range values {
+		value, err := oidc.RandomString()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start single sign-on"})
+			return
+		}
+		values[i] = value
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	authURL, err := h.sso.Provider.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error starting single sign-on", "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Single sign-on provider is unavailable"})
+		return
+	}
+
+	session := sessions.Default(c)
+	session.Clear()
+	session.Set(middleware.SessionSSOStateKey, state)
+	session.Set(middleware.SessionSSONonceKey, nonce)
+	session.Set(middleware.SessionSSOVerifierKey, verifier)
+	session.Set(middleware.SessionSSOStartedAtKey, time.Now().Unix())
+	if err := session.Save(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save session"})
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// SSOCallback finishes a single sign-on login. The admin is matched by email;
+// someone with no account is given one if the role mapping grants them a
+// role. The browser is sent back to the admin panel, or to the home page
+// with an ssoError code if the login failed.
+func (h *AdminHandler) SSOCallback(c *gin.Context) {
+	if h.sso == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	session := sessions.Default(c)
+	expectedState, _ := session.Get(middleware.SessionSSOStateKey).(string)
+	nonce, _ := session.Get(middleware.SessionSSONonceKey).(string)
+	verifier, _ := session.Get(middleware.SessionSSOVerifierKey).(string)
+	startedAt, _ := session.Get(middleware.SessionSSOStartedAtKey).(int64)
+	session.Clear()
+
+	state := c.Query("state")
+	if expectedState == "" || subtle.ConstantTimeCompare([]byte(expectedState), []byte(state)) != 1 ||
+		time.Since(time.Unix(startedAt, 0)) > pendingSSOTTL {
+		h.ssoFailed(c, ssoErrorExpired)
+		return
+	}
+	if providerError := c.Query("error"); providerError != "" {
+		slog.WarnContext(c.Request.Context(), "Single sign-on provider returned an error", "providerError", providerError, "description", c.Query("error_description"))
+		h.ssoFailed(c, ssoErrorFailed)
+		return
+	}
+
+	claims, err := h.sso.Provider.Exchange(c.Request.Context(), c.Query("code"), verifier, nonce)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error completing single sign-on", "error", err)
+		h.recordAttempt(c, "", models.LoginReasonSSOFailed)
+		h.ssoFailed(c, ssoErrorFailed)
+		return
+	}
+
+	email := auth.NormalizeEmail(claims.Email)
+	role, allowed := h.sso.Roles.RoleFor(email, claims.Groups)
+	if email == "" || !claims.EmailVerified || !allowed {
+		h.recordAttempt(c, email, models.LoginReasonSSONotAllowed)
+		h.ssoFailed(c, ssoErrorNotAllowed)
+		return
+	}
+
+	user, ok := h.ssoUser(c, claims, email, role)
+	if !ok {
+		return
+	}
+
+	session.Set(middleware.SessionAuthMethodKey, middleware.AuthMethodSSO)
+	if _, ok := h.startSession(c, user); !ok {
+		return
+	}
+	h.recordAttempt(c, email, "")
+
+	c.Redirect(http.StatusFound, h.sso.FrontendURL+"/admin")
+}
+
+// ssoUser finds or creates the admin for a verified SSO identity and records
+// the sign-in. An account already linked to a different identity at the
+// provider is refused, so a recycled email address cannot take it over.
+func (h *AdminHandler) ssoUser(c *gin.Context, claims *oidc.Claims, email, role string) (*models.AdminUser, bool) {
+	ctx := c.Request.Context()
+	now := time.Now()
+
+	user, err := h.repo.GetAdminUserByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		displayName := claims.Name
+		if displayName == "" {
+			displayName = email
+		}
+		user = &models.AdminUser{
+			Email:       email,
+			DisplayName: displayName,
+			Role:        role,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			LastLoginAt: &now,
+			OIDCSubject: claims.Subject,
+			SSOManaged:  true,
+		}
+		if err := h.repo.CreateAdminUser(ctx, user); err != nil {
+			slog.ErrorContext(ctx, "Error creating admin from single sign-on", "email", email, "error", err)
+			h.ssoFailed(c, ssoErrorFailed)
+			return nil, false
+		}
+		slog.InfoContext(ctx, "Created admin user from single sign-on", "email", email, "role", role)
+		return user, true
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error loading admin for single sign-on", "email", email, "error", err)
+		h.ssoFailed(c, ssoErrorFailed)
+		return nil, false
+	}
+
+	if user.OIDCSubject != "" && user.OIDCSubject != claims.Subject {
+		h.recordAttempt(c, email, models.LoginReasonSSONotAllowed)
+		h.ssoFailed(c, ssoErrorNotAllowed)
+		return nil, false
+	}
+	if user.Disabled {
+		h.recordAttempt(c, email, models.LoginReasonAccountDisabled)
+		h.ssoFailed(c, ssoErrorDisabled)
+		return nil, false
+	}
+
+	user.OIDCSubject = claims.Subject
+	if user.SSOManaged {
+		user.Role = role
+	}
+	user.LastLoginAt = &now
+	user.UpdatedAt = now
+	if err := h.repo.UpdateAdminUser(ctx, user.ID, user); err != nil {
+		slog.ErrorContext(ctx, "Error updating admin after single sign-on", "email", email, "error", err)
+		h.ssoFailed(c, ssoErrorFailed)
+		return nil, false
+	}
+	return user, true
+}
+
+// ssoFailed forgets the pending login and sends the browser back to the
+// site with an error code for the login form to show
+func (h *AdminHandler) ssoFailed(c *gin.Context, code string) {
+	_ = sessions.Default(c).Save()
+	c.Redirect(http.StatusFound, h.sso.FrontendURL+"/?ssoError="+url.QueryEscape(code))
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/oidc"
+	"ai-india-workshop-backend/internal/repository"
+	"ai-india-workshop-backend/testutils"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testFrontendURL = "http://frontend.test"
+
+// setupSSOTestRouter serves the SSO routes backed by a mock provider, plus
+// /whoami to inspect the resulting session
+func setupSSOTestRouter(t *testing.T, mockRepo *repository.MockRepository) (*gin.Engine, *testutils.MockOIDCProvider) {
+	provider := testutils.NewMockOIDCProvider(t, "workshop")
+	roles, err := auth.ParseSSORoleMapping("domain:example.com=analyst,group:organisers=organiser")
+	require.NoError(t, err)
+
+	handler := newTestAdminHandler(mockRepo)
+	handler.EnableSSO(&SSOConfig{
+		Provider: oidc.NewProvider(oidc.Config{
+			IssuerURL:   provider.Issuer(),
+			ClientID:    "workshop",
+			RedirectURL: "http://api.test/api/admin/sso/callback",
+		}),
+		Roles:       roles,
+		DisplayName: "Mock",
+		FrontendURL: testFrontendURL,
+	})
+
+	r := setupAdminTestRouter()
+	r.GET("/api/admin/sso", handler.SSOStatus)
+	r.GET("/api/admin/sso/login", handler.SSOLogin)
+	r.GET("/api/admin/sso/callback", handler.SSOCallback)
+	r.GET("/whoami", func(c *gin.Context) {
+		session := sessions.Default(c)
+		c.JSON(http.StatusOK, gin.H{
+			"userID": session.Get(middleware.SessionUserIDKey),
+			"method": session.Get(middleware.SessionAuthMethodKey),
+		})
+	})
+	return r, provider
+}
+
+// ssoLogin runs the browser side of a login: start it, let the provider
+// approve it and follow the redirect back. It returns where the callback
+// sent the browser and the session cookie.
+func ssoLogin(t *testing.T, r *gin.Engine) (*url.URL, string) {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/sso/login", nil))
+	require.Equal(t, http.StatusFound, w.Code)
+	cookie := w.Header().Get("Set-Cookie")
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(w.Header().Get("Location"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", callback.RequestURI(), nil)
+	req.Header.Set("Cookie", cookie)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusFound, w.Code)
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	if newCookie := w.Header().Get("Set-Cookie"); newCookie != "" {
+		cookie = newCookie
+	}
+	return location, cookie
+}
+
+func TestAdminHandler_SSOCallback(t *testing.T) {
+	tests := []struct {
+		name          string
+		email         string
+		emailVerified bool
+		groups        []string
+		existing      *models.AdminUser
+		expectedError string
+		expectedRole  string
+	}{
+		{
+			name:          "new admin from mapped domain",
+			email:         "sam@example.com",
+			emailVerified: true,
+			expectedRole:  auth.RoleAnalyst,
+		},
+		{
+			name:          "new admin from mapped group",
+			email:         "guest@partner.test",
+			emailVerified: true,
+			groups:        []string{"organisers"},
+			expectedRole:  auth.RoleOrganiser,
+		},
+		{
+			name:          "SSO managed admin follows the mapping",
+			email:         "sam@example.com",
+			emailVerified: true,
+			groups:        []string{"organisers"},
+			existing:      &models.AdminUser{ID: "user-1", Email: "sam@example.com", Role: auth.RoleAnalyst, SSOManaged: true, OIDCSubject: "mock-subject"},
+			expectedRole:  auth.RoleOrganiser,
+		},
+		{
+			name:          "password admin keeps their role",
+			email:         "sam@example.com",
+			emailVerified: true,
+			existing:      &models.AdminUser{ID: "user-1", Email: "sam@example.com", Role: auth.RoleOwner},
+			expectedRole:  auth.RoleOwner,
+		},
+		{
+			name:          "unmapped domain",
+			email:         "someone@elsewhere.test",
+			emailVerified: true,
+			expectedError: "not_allowed",
+		},
+		{
+			name:          "unverified email",
+			email:         "sam@example.com",
+			expectedError: "not_allowed",
+		},
+		{
+			name:          "account linked to another identity",
+			email:         "sam@example.com",
+			emailVerified: true,
+			existing:      &models.AdminUser{ID: "user-1", Email: "sam@example.com", Role: auth.RoleOwner, OIDCSubject: "someone-else"},
+			expectedError: "not_allowed",
+		},
+		{
+			name:          "disabled account",
+			email:         "sam@example.com",
+			emailVerified: true,
+			existing:      &models.AdminUser{ID: "user-1", Email: "sam@example.com", Role: auth.RoleOwner, Disabled: true},
+			expectedError: "disabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			r, provider := setupSSOTestRouter(t, mockRepo)
+			provider.Email = tt.email
+			provider.EmailVerified = tt.emailVerified
+			provider.Groups = tt.groups
+
+			var saved *models.AdminUser
+			if tt.existing != nil {
+				mockRepo.On("GetAdminUserByEmail", mock.Anything, tt.email).Return(tt.existing, nil)
+				mockRepo.On("UpdateAdminUser", mock.Anything, "user-1", mock.Anything).Run(func(args mock.Arguments) {
+					saved = args.Get(2).(*models.AdminUser)
+				}).Return(nil).Maybe()
+			} else {
+				mockRepo.On("GetAdminUserByEmail", mock.Anything, tt.email).Return(nil, repository.ErrNotFound).Maybe()
+				mockRepo.On("CreateAdminUser", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					saved = args.Get(1).(*models.AdminUser)
+					saved.ID = "user-1"
+				}).Return(nil).Maybe()
+			}
+
+			location, cookie := ssoLogin(t, r)
+
+			if tt.expectedError != "" {
+				assert.Equal(t, "/", location.Path)
+				assert.Equal(t, tt.expectedError, location.Query().Get("ssoError"))
+				assert.Nil(t, saved)
+				return
+			}
+
+			assert.Equal(t, testFrontendURL+"/admin", location.String())
+			require.NotNil(t, saved)
+			assert.Equal(t, tt.expectedRole, saved.Role)
+			assert.Equal(t, "mock-subject", saved.OIDCSubject)
+			assert.NotNil(t, saved.LastLoginAt)
+			if tt.existing == nil {
+				assert.True(t, saved.SSOManaged)
+				assert.Empty(t, saved.PasswordHash)
+			}
+
+			req := httptest.NewRequest("GET", "/whoami", nil)
+			req.Header.Set("Cookie", cookie)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.JSONEq(t, `{"userID":"user-1","method":"sso"}`, w.Body.String())
+		})
+	}
+}
+
+func TestAdminHandler_SSOCallback_StateMismatch(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	r, _ := setupSSOTestRouter(t, mockRepo)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/sso/login", nil))
+	cookie := w.Header().Get("Set-Cookie")
+
+	// A callback carrying someone else's state is refused before the code is redeemed
+	req := httptest.NewRequest("GET", "/api/admin/sso/callback?code=stolen&state=forged", nil)
+	req.Header.Set("Cookie", cookie)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, testFrontendURL+"/?ssoError=expired", w.Header().Get("Location"))
+	mockRepo.AssertNotCalled(t, "GetAdminUserByEmail", mock.Anything, mock.Anything)
+}
+
+func TestAdminHandler_SSOStatus(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	r, _ := setupSSOTestRouter(t, mockRepo)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/sso", nil))
+	assert.JSONEq(t, `{"enabled":true,"name":"Mock"}`, w.Body.String())
+
+	r = setupAdminTestRouter()
+	r.GET("/api/admin/sso", newTestAdminHandler(mockRepo).SSOStatus)
+	r.GET("/api/admin/sso/login", newTestAdminHandler(mockRepo).SSOLogin)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/sso", nil))
+	assert.JSONEq(t, `{"enabled":false}`, w.Body.String())
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/sso/login", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -3,13 +3,19 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/middleware"
 	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/ratelimit"
 	"ai-india-workshop-backend/internal/repository"
+	"ai-india-workshop-backend/internal/totp"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
@@ -27,32 +33,79 @@ func setupAdminTestRouter() *gin.Engine {
 	return r
 }
 
+// newTestAdminHandler returns a handler with in-memory login limiters that
+// accepts any recorded login attempts
+func newTestAdminHandler(mockRepo *repository.MockRepository) *AdminHandler {
+	mockRepo.On("CreateLoginAttempt", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return NewAdminHandler(mockRepo,
+		ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.LoginIPPolicy),
+		ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.LoginAccountPolicy))
+}
+
 func TestAdminHandler_Login(t *testing.T) {
+	hash, err := auth.HashPassword("correct-password")
+	require.NoError(t, err)
+
 	tests := []struct {
 		name           string
+		email          string
 		password       string
-		adminPassword  string
+		user           *models.AdminUser
+		repoError      error
 		expectedStatus int
 		expectError    bool
 	}{
 		{
-			name:           "valid password",
+			name:           "valid credentials",
+			email:          "Admin@Example.com",
 			password:       "correct-password",
-			adminPassword:  "correct-password",
+			user:           &models.AdminUser{ID: "user-1", Email: "admin@example.com", PasswordHash: hash},
 			expectedStatus: http.StatusOK,
 			expectError:    false,
 		},
 		{
 			name:           "invalid password",
+			email:          "admin@example.com",
 			password:       "wrong-password",
-			adminPassword:  "correct-password",
+			user:           &models.AdminUser{ID: "user-1", Email: "admin@example.com", PasswordHash: hash},
+			expectedStatus: http.StatusUnauthorized,
+			expectError:    true,
+		},
+		{
+			name:           "unknown email",
+			email:          "nobody@example.com",
+			password:       "correct-password",
+			repoError:      repository.ErrNotFound,
 			expectedStatus: http.StatusUnauthorized,
 			expectError:    true,
 		},
+		{
+			name:           "disabled account",
+			email:          "admin@example.com",
+			password:       "correct-password",
+			user:           &models.AdminUser{ID: "user-1", Email: "admin@example.com", PasswordHash: hash, Disabled: true},
+			expectedStatus: http.StatusForbidden,
+			expectError:    true,
+		},
+		{
+			name:           "repository error",
+			email:          "admin@example.com",
+			password:       "correct-password",
+			repoError:      assert.AnError,
+			expectedStatus: http.StatusInternalServerError,
+			expectError:    true,
+		},
 		{
 			name:           "missing password",
+			email:          "admin@example.com",
 			password:       "",
-			adminPassword:  "correct-password",
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+		},
+		{
+			name:           "missing email",
+			email:          "",
+			password:       "correct-password",
 			expectedStatus: http.StatusBadRequest,
 			expectError:    true,
 		},
@@ -60,17 +113,27 @@ func TestAdminHandler_Login(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set admin password
-			os.Setenv("ADMIN_PASSWORD", tt.adminPassword)
-			defer os.Unsetenv("ADMIN_PASSWORD")
-
 			mockRepo := new(repository.MockRepository)
-			handler := NewAdminHandler(mockRepo)
+			handler := newTestAdminHandler(mockRepo)
+
+			if tt.user != nil {
+				mockRepo.On("GetAdminUserByEmail", mock.Anything, "admin@example.com").Return(tt.user, nil)
+			} else if tt.repoError != nil {
+				mockRepo.On("GetAdminUserByEmail", mock.Anything, strings.ToLower(tt.email)).Return(nil, tt.repoError)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				mockRepo.On("UpdateAdminUser", mock.Anything, "user-1", mock.MatchedBy(func(user *models.AdminUser) bool {
+					return user.LastLoginAt != nil
+				})).Return(nil)
+			}
 
 			r := setupAdminTestRouter()
 			r.POST("/admin/login", handler.Login)
 
 			reqBody := map[string]string{}
+			if tt.email != "" {
+				reqBody["email"] = tt.email
+			}
 			if tt.password != "" {
 				reqBody["password"] = tt.password
 			}
@@ -87,35 +150,234 @@ func TestAdminHandler_Login(t *testing.T) {
 				var response map[string]interface{}
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				require.NoError(t, err)
-				assert.True(t, response["success"].(bool))
+				assert.Equal(t, "Logged in successfully", response["message"])
+				assert.NotContains(t, response, "success")
+				assert.NotEmpty(t, response["csrfToken"])
+				assert.NotContains(t, w.Body.String(), "passwordHash")
+				assert.Contains(t, w.Header().Get("Set-Cookie"), "admin-session=")
 			}
+
+			mockRepo.AssertExpectations(t)
 		})
 	}
 }
 
-func TestAdminHandler_Login_MissingAdminPassword(t *testing.T) {
-	os.Unsetenv("ADMIN_PASSWORD")
+func TestAdminHandler_Login_Lockout(t *testing.T) {
+	hash, err := auth.HashPassword("correct-password")
+	require.NoError(t, err)
+	user := &models.AdminUser{ID: "user-1", Email: "admin@example.com", PasswordHash: hash}
+
+	mockRepo := new(repository.MockRepository)
+	var attempts []*models.LoginAttempt
+	mockRepo.On("CreateLoginAttempt", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		attempts = append(attempts, args.Get(1).(*models.LoginAttempt))
+	}).Return(nil)
+	mockRepo.On("GetAdminUserByEmail", mock.Anything, "admin@example.com").Return(user, nil)
+	mockRepo.On("GetAdminUserByEmail", mock.Anything, "nobody@example.com").Return(nil, repository.ErrNotFound)
+	handler := newTestAdminHandler(mockRepo)
+
+	r := setupAdminTestRouter()
+	r.POST("/admin/login", handler.Login)
+
+	login := func(email, password, ip string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+		req, _ := http.NewRequest("POST", "/admin/login", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < ratelimit.LoginAccountPolicy.FreeAttempts; i++ {
+		w := login("admin@example.com", "wrong-password", "10.0.0.1")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+
+	// The account is locked out even with the right password and from another IP
+	w := login("admin@example.com", "wrong-password", "10.0.0.1")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = login("admin@example.com", "correct-password", "10.0.0.2")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	// Other accounts are unaffected and unknown emails are reported the same way
+	w = login("nobody@example.com", "correct-password", "10.0.0.2")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid email or password")
+
+	require.Len(t, attempts, ratelimit.LoginAccountPolicy.FreeAttempts+3)
+	assert.Equal(t, models.LoginReasonInvalidPassword, attempts[0].Reason)
+	assert.Equal(t, "10.0.0.1", attempts[0].IP)
+	assert.False(t, attempts[0].Success)
+	assert.Equal(t, models.LoginReasonLockedOut, attempts[len(attempts)-2].Reason)
+	assert.Equal(t, models.LoginReasonUnknownEmail, attempts[len(attempts)-1].Reason)
+}
+
+func TestAdminHandler_Login_IPLockout(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetAdminUserByEmail", mock.Anything, mock.Anything).Return(nil, repository.ErrNotFound)
+	handler := newTestAdminHandler(mockRepo)
+
+	r := setupAdminTestRouter()
+	r.POST("/admin/login", handler.Login)
+
+	// Spreading guesses over many accounts still trips the per-IP limit
+	var w *httptest.ResponseRecorder
+	for i := 0; i <= ratelimit.LoginIPPolicy.FreeAttempts+1; i++ {
+		body := fmt.Sprintf(`{"email":"user%d@example.com","password":"guess"}`, i)
+		req, _ := http.NewRequest("POST", "/admin/login", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = "10.0.0.1:1234"
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestAdminHandler_LoginWithTwoFactor(t *testing.T) {
+	hash, err := auth.HashPassword("correct-password")
+	require.NoError(t, err)
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	code, err := totp.Code(secret, time.Now())
+	require.NoError(t, err)
+
+	user := &models.AdminUser{
+		ID:               "user-1",
+		Email:            "admin@example.com",
+		PasswordHash:     hash,
+		TwoFactorEnabled: true,
+		TOTPSecret:       secret,
+	}
 
 	mockRepo := new(repository.MockRepository)
-	handler := NewAdminHandler(mockRepo)
+	handler := newTestAdminHandler(mockRepo)
+	mockRepo.On("GetAdminUserByEmail", mock.Anything, "admin@example.com").Return(user, nil)
+	mockRepo.On("GetAdminUser", mock.Anything, "user-1").Return(user, nil)
+	mockRepo.On("UpdateAdminUser", mock.Anything, "user-1", mock.MatchedBy(func(u *models.AdminUser) bool {
+		return u.LastLoginAt != nil && u.LastTOTPStep > 0
+	})).Return(nil).Once()
 
 	r := setupAdminTestRouter()
 	r.POST("/admin/login", handler.Login)
+	r.POST("/admin/login/2fa", handler.VerifyTwoFactor)
+	r.GET("/whoami", func(c *gin.Context) {
+		session := sessions.Default(c)
+		c.JSON(http.StatusOK, gin.H{"userID": session.Get(middleware.SessionUserIDKey)})
+	})
+
+	post := func(path, body, cookie string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if cookie != "" {
+			req.Header.Set("Cookie", cookie)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// The password alone does not sign in
+	w := post("/admin/login", `{"email":"admin@example.com","password":"correct-password"}`, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"twoFactorRequired":true`)
+	cookie := w.Header().Get("Set-Cookie")
+
+	req, _ := http.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("Cookie", cookie)
+	probe := httptest.NewRecorder()
+	r.ServeHTTP(probe, req)
+	assert.JSONEq(t, `{"userID":null}`, probe.Body.String())
+
+	w = post("/admin/login/2fa", `{"code":"000000"}`, cookie)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid authentication code")
+	cookie = w.Header().Get("Set-Cookie")
+
+	w = post("/admin/login/2fa", `{"code":"`+code+`"}`, cookie)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"message":"Logged in successfully"`)
+	cookie = w.Header().Get("Set-Cookie")
+
+	req, _ = http.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("Cookie", cookie)
+	probe = httptest.NewRecorder()
+	r.ServeHTTP(probe, req)
+	assert.JSONEq(t, `{"userID":"user-1"}`, probe.Body.String())
+
+	// The pending login has been used up
+	w = post("/admin/login/2fa", `{"code":"`+code+`"}`, cookie)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAdminHandler_VerifyTwoFactor_AttemptLimit(t *testing.T) {
+	hash, err := auth.HashPassword("correct-password")
+	require.NoError(t, err)
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	user := &models.AdminUser{ID: "user-1", Email: "admin@example.com", PasswordHash: hash, TwoFactorEnabled: true, TOTPSecret: secret}
+	mockRepo := new(repository.MockRepository)
+	handler := newTestAdminHandler(mockRepo)
+	mockRepo.On("GetAdminUserByEmail", mock.Anything, "admin@example.com").Return(user, nil)
+	mockRepo.On("GetAdminUser", mock.Anything, "user-1").Return(user, nil)
 
-	reqBody := map[string]string{"password": "any-password"}
-	jsonBody, _ := json.Marshal(reqBody)
-	req, _ := http.NewRequest("POST", "/admin/login", bytes.NewBuffer(jsonBody))
+	r := setupAdminTestRouter()
+	r.POST("/admin/login", handler.Login)
+	r.POST("/admin/login/2fa", handler.VerifyTwoFactor)
+
+	req, _ := http.NewRequest("POST", "/admin/login", bytes.NewBufferString(`{"email":"admin@example.com","password":"correct-password"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	cookie := w.Header().Get("Set-Cookie")
+
+	for i := 0; i < maxSecondFactorAttempts; i++ {
+		req, _ := http.NewRequest("POST", "/admin/login/2fa", bytes.NewBufferString(`{"code":"000000"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Cookie", cookie)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Contains(t, w.Body.String(), "Invalid authentication code")
+		cookie = w.Header().Get("Set-Cookie")
+	}
+
+	// Even a correct code is refused once the attempts are used up
+	code, err := totp.Code(secret, time.Now())
+	require.NoError(t, err)
+	req, _ = http.NewRequest("POST", "/admin/login/2fa", bytes.NewBufferString(`{"code":"`+code+`"}`))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Cookie", cookie)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Login expired")
+}
 
+func TestAdminHandler_VerifyTwoFactor_NoPendingLogin(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := newTestAdminHandler(mockRepo)
+
+	r := setupAdminTestRouter()
+	r.POST("/admin/login/2fa", handler.VerifyTwoFactor)
+
+	req, _ := http.NewRequest("POST", "/admin/login/2fa", bytes.NewBufferString(`{"code":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockRepo.AssertNotCalled(t, "GetAdminUser", mock.Anything, mock.Anything)
 }
 
 func TestAdminHandler_Logout(t *testing.T) {
 	mockRepo := new(repository.MockRepository)
-	handler := NewAdminHandler(mockRepo)
+	handler := newTestAdminHandler(mockRepo)
 
 	r := setupAdminTestRouter()
 	r.POST("/admin/logout", handler.Logout)
@@ -159,9 +421,11 @@ func TestAdminHandler_GetStats(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := new(repository.MockRepository)
-			handler := NewAdminHandler(mockRepo)
+			handler := newTestAdminHandler(mockRepo)
 
 			mockRepo.On("GetDesignationBreakdown", mock.Anything).Return(tt.breakdown, tt.repoError)
+			mockRepo.On("GetRegistrationForm", mock.Anything).Return(&models.RegistrationForm{}, nil).Maybe()
+			mockRepo.On("GetDesignationTaxonomy", mock.Anything).Return(&models.DesignationTaxonomy{}, nil).Maybe()
 
 			r := setupAdminTestRouter()
 			r.GET("/admin/stats", handler.GetStats)
@@ -177,6 +441,7 @@ func TestAdminHandler_GetStats(t *testing.T) {
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				require.NoError(t, err)
 				assert.NotNil(t, response["designationBreakdown"])
+				assert.Equal(t, []interface{}{}, response["answerBreakdown"])
 			}
 
 			mockRepo.AssertExpectations(t)
@@ -184,3 +449,31 @@ func TestAdminHandler_GetStats(t *testing.T) {
 	}
 }
 
+func TestAdminHandler_GetStats_Answers(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := newTestAdminHandler(mockRepo)
+
+	mockRepo.On("GetDesignationBreakdown", mock.Anything).Return([]models.DesignationCount{}, nil)
+	mockRepo.On("GetDesignationTaxonomy", mock.Anything).Return(&models.DesignationTaxonomy{}, nil)
+	mockRepo.On("GetRegistrationForm", mock.Anything).Return(&models.RegistrationForm{Fields: []models.FormField{
+		{ID: "company", Label: "Company", Type: models.FieldTypeText},
+		{ID: "tshirt", Label: "T-shirt size", Type: models.FieldTypeSelect, Options: []string{"S", "M"}},
+	}}, nil)
+	mockRepo.On("GetAllAttendees", mock.Anything).Return([]*models.Attendee{
+		{ID: "1", Answers: map[string]interface{}{"company": "Acme", "tshirt": "M"}},
+		{ID: "2", Answers: map[string]interface{}{"tshirt": "M"}},
+	}, nil)
+
+	r := setupAdminTestRouter()
+	r.GET("/admin/stats", handler.GetStats)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/stats", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"designationBreakdown": [],
+		"answerBreakdown": [{"fieldId": "tshirt", "label": "T-shirt size", "counts": [{"answer": "S", "count": 0}, {"answer": "M", "count": 2}]}]
+	}`, w.Body.String())
+}
+
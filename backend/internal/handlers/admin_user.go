@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/problem"
+	"ai-india-workshop-backend/internal/repository"
+	"ai-india-workshop-backend/internal/sessionstore"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminUserHandler struct {
+	repo repository.RepositoryInterface
+}
+
+func NewAdminUserHandler(repo repository.RepositoryInterface) *AdminUserHandler {
+	return &AdminUserHandler{repo: repo}
+}
+
+func (h *AdminUserHandler) GetAll(c *gin.Context) {
+	users, err := h.repo.GetAllAdminUsers(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch admin users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// Create invites a new admin. When no password is supplied a temporary one is
+// generated and returned once so it can be handed to the new admin.
+func (h *AdminUserHandler) Create(c *gin.Context) {
+	var req struct {
+		Email       string `json:"email" binding:"required,email"`
+		DisplayName string `json:"displayName" binding:"required"`
+		Role        string `json:"role" binding:"required"`
+		Password    string `json:"password"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	if !auth.ValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role"})
+		return
+	}
+
+	password := req.Password
+	generated := password == ""
+	if generated {
+		var err error
+		if password, err = auth.GeneratePassword(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate password"})
+			return
+		}
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		if errors.Is(err, auth.ErrPasswordTooShort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	now := time.Now()
+	user := &models.AdminUser{
+		Email:        auth.NormalizeEmail(req.Email),
+		DisplayName:  req.DisplayName,
+		Role:         req.Role,
+		PasswordHash: hash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := h.repo.CreateAdminUser(c.Request.Context(), user); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "An admin with this email already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create admin user"})
+		return
+	}
+
+	response := gin.H{"user": user}
+	if generated {
+		response["temporaryPassword"] = password
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// Update changes an admin's display name or role, or disables/re-enables the account
+func (h *AdminUserHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		DisplayName *string `json:"displayName"`
+		Role        *string `json:"role"`
+		Disabled    *bool   `json:"disabled"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	if req.Role != nil && !auth.ValidRole(*req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role"})
+		return
+	}
+
+	// Stop admins from locking themselves out
+	if current := middleware.CurrentAdmin(c); current != nil && current.ID == id {
+		if req.Disabled != nil && *req.Disabled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot disable your own account"})
+			return
+		}
+		if req.Role != nil && *req.Role != current.Role {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot change your own role"})
+			return
+		}
+	}
+
+	user, ok := h.loadUser(c, id)
+	if !ok {
+		return
+	}
+
+	if req.DisplayName != nil {
+		user.DisplayName = *req.DisplayName
+	}
+	if req.Role != nil {
+		user.Role = *req.Role
+	}
+	if req.Disabled != nil {
+		user.Disabled = *req.Disabled
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := h.repo.UpdateAdminUser(c.Request.Context(), id, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// SetPassword lets an admin reset another admin's password
+func (h *AdminUserHandler) SetPassword(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	user, ok := h.loadUser(c, id)
+	if !ok {
+		return
+	}
+
+	h.savePassword(c, user, req.Password)
+}
+
+// Me returns the signed-in admin, the permissions granted by their role and
+// the session's CSRF token, so the admin panel can recover it after a reload
+func (h *AdminUserHandler) Me(c *gin.Context) {
+	user := middleware.CurrentAdmin(c)
+	csrfToken, err := middleware.CSRFToken(c)
+	if errors.Is(err, sessionstore.ErrSessionRevoked) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create CSRF token"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user": user, "permissions": auth.PermissionsFor(user.Role), "csrfToken": csrfToken})
+}
+
+// GetRoles returns the permission matrix so the admin panel can offer role choices
+func (h *AdminUserHandler) GetRoles(c *gin.Context) {
+	roles := make([]gin.H, 0)
+	for _, role := range auth.Roles() {
+		roles = append(roles, gin.H{"role": role, "permissions": auth.PermissionsFor(role)})
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// ChangeOwnPassword changes the signed-in admin's password after checking the current one
+func (h *AdminUserHandler) ChangeOwnPassword(c *gin.Context) {
+	var req struct {
+		CurrentPassword string `json:"currentPassword" binding:"required"`
+		NewPassword     string `json:"newPassword" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	user := middleware.CurrentAdmin(c)
+	if !auth.CheckPassword(user.PasswordHash, req.CurrentPassword) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	h.savePassword(c, user, req.NewPassword)
+}
+
+func (h *AdminUserHandler) loadUser(c *gin.Context, id string) (*models.AdminUser, bool) {
+	user, err := h.repo.GetAdminUser(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Admin user not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch admin user"})
+		return nil, false
+	}
+	return user, true
+}
+
+func (h *AdminUserHandler) savePassword(c *gin.Context, user *models.AdminUser, password string) {
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		if errors.Is(err, auth.ErrPasswordTooShort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	user.PasswordHash = hash
+	user.UpdatedAt = time.Now()
+	if err := h.repo.UpdateAdminUser(c.Request.Context(), user.ID, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAdminUserTestRouter returns a router where every request is made by current
+func setupAdminUserTestRouter(current *models.AdminUser) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(sessions.Sessions("admin-session", cookie.NewStore([]byte("test-secret-key"))))
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.AdminUserKey, current)
+		c.Next()
+	})
+	return r
+}
+
+func TestAdminUserHandler_GetAll(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewAdminUserHandler(mockRepo)
+
+	users := []*models.AdminUser{
+		{ID: "1", Email: "a@example.com", PasswordHash: "secret-hash"},
+		{ID: "2", Email: "b@example.com", Disabled: true},
+	}
+	mockRepo.On("GetAllAdminUsers", mock.Anything).Return(users, nil)
+
+	r := setupAdminUserTestRouter(users[0])
+	r.GET("/admin/users", handler.GetAll)
+
+	req, _ := http.NewRequest("GET", "/admin/users", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAdminUserHandler_Create(t *testing.T) {
+	tests := []struct {
+		name               string
+		requestBody        map[string]string
+		repoError          error
+		expectRepoCall     bool
+		expectedStatus     int
+		expectTempPassword bool
+	}{
+		{
+			name:               "invite with generated password",
+			requestBody:        map[string]string{"email": "New@Example.com", "displayName": "New Admin", "role": "organiser"},
+			expectRepoCall:     true,
+			expectedStatus:     http.StatusCreated,
+			expectTempPassword: true,
+		},
+		{
+			name:           "create with explicit password",
+			requestBody:    map[string]string{"email": "new@example.com", "displayName": "New Admin", "role": "content_editor", "password": "a-long-enough-password"},
+			expectRepoCall: true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "password too short",
+			requestBody:    map[string]string{"email": "new@example.com", "displayName": "New Admin", "role": "organiser", "password": "short"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid email",
+			requestBody:    map[string]string{"email": "not-an-email", "displayName": "New Admin", "role": "organiser"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown role",
+			requestBody:    map[string]string{"email": "new@example.com", "displayName": "New Admin", "role": "superuser"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "duplicate email",
+			requestBody:    map[string]string{"email": "new@example.com", "displayName": "New Admin", "role": "analyst"},
+			repoError:      repository.ErrAlreadyExists,
+			expectRepoCall: true,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewAdminUserHandler(mockRepo)
+
+			if tt.expectRepoCall {
+				mockRepo.On("CreateAdminUser", mock.Anything, mock.MatchedBy(func(user *models.AdminUser) bool {
+					return user.Email == "new@example.com" && user.PasswordHash != "" && auth.ValidRole(user.Role) && !user.CreatedAt.IsZero()
+				})).Return(tt.repoError)
+			}
+
+			r := setupAdminUserTestRouter(&models.AdminUser{ID: "owner"})
+			r.POST("/admin/users", handler.Create)
+
+			jsonBody, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest("POST", "/admin/users", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusCreated {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				require.NoError(t, err)
+				assert.NotContains(t, w.Body.String(), "passwordHash")
+				_, hasTempPassword := response["temporaryPassword"]
+				assert.Equal(t, tt.expectTempPassword, hasTempPassword)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminUserHandler_Update(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		requestBody    string
+		existing       *models.AdminUser
+		repoError      error
+		expectedStatus int
+	}{
+		{
+			name:           "disable another admin",
+			id:             "other",
+			requestBody:    `{"disabled": true}`,
+			existing:       &models.AdminUser{ID: "other", Email: "other@example.com", Role: auth.RoleAnalyst},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "change another admin's role",
+			id:             "other",
+			requestBody:    `{"role": "checkin_volunteer"}`,
+			existing:       &models.AdminUser{ID: "other", Email: "other@example.com", Role: auth.RoleAnalyst},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "cannot disable yourself",
+			id:             "owner",
+			requestBody:    `{"disabled": true}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "cannot change your own role",
+			id:             "owner",
+			requestBody:    `{"role": "analyst"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown role",
+			id:             "other",
+			requestBody:    `{"role": "superuser"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown admin",
+			id:             "missing",
+			requestBody:    `{"displayName": "Renamed"}`,
+			repoError:      repository.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewAdminUserHandler(mockRepo)
+
+			if tt.existing != nil {
+				mockRepo.On("GetAdminUser", mock.Anything, tt.id).Return(tt.existing, nil)
+				mockRepo.On("UpdateAdminUser", mock.Anything, tt.id, mock.MatchedBy(func(user *models.AdminUser) bool {
+					return user.Disabled || user.Role == auth.RoleCheckInVolunteer
+				})).Return(nil)
+			} else if tt.repoError != nil {
+				mockRepo.On("GetAdminUser", mock.Anything, tt.id).Return(nil, tt.repoError)
+			}
+
+			r := setupAdminUserTestRouter(&models.AdminUser{ID: "owner", Role: auth.RoleOwner})
+			r.PUT("/admin/users/:id", handler.Update)
+
+			req, _ := http.NewRequest("PUT", "/admin/users/"+tt.id, bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminUserHandler_SetPassword(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewAdminUserHandler(mockRepo)
+
+	existing := &models.AdminUser{ID: "other", Email: "other@example.com", PasswordHash: "old-hash"}
+	mockRepo.On("GetAdminUser", mock.Anything, "other").Return(existing, nil)
+	mockRepo.On("UpdateAdminUser", mock.Anything, "other", mock.MatchedBy(func(user *models.AdminUser) bool {
+		return auth.CheckPassword(user.PasswordHash, "brand-new-password")
+	})).Return(nil)
+
+	r := setupAdminUserTestRouter(&models.AdminUser{ID: "owner"})
+	r.PUT("/admin/users/:id/password", handler.SetPassword)
+
+	req, _ := http.NewRequest("PUT", "/admin/users/other/password", bytes.NewBufferString(`{"password": "brand-new-password"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAdminUserHandler_ChangeOwnPassword(t *testing.T) {
+	hash, err := auth.HashPassword("current-password")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		requestBody    map[string]string
+		expectUpdate   bool
+		expectedStatus int
+	}{
+		{
+			name:           "successful change",
+			requestBody:    map[string]string{"currentPassword": "current-password", "newPassword": "brand-new-password"},
+			expectUpdate:   true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wrong current password",
+			requestBody:    map[string]string{"currentPassword": "wrong-password", "newPassword": "brand-new-password"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "new password too short",
+			requestBody:    map[string]string{"currentPassword": "current-password", "newPassword": "short"},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewAdminUserHandler(mockRepo)
+
+			if tt.expectUpdate {
+				mockRepo.On("UpdateAdminUser", mock.Anything, "owner", mock.Anything).Return(nil)
+			}
+
+			r := setupAdminUserTestRouter(&models.AdminUser{ID: "owner", PasswordHash: hash})
+			r.PUT("/admin/me/password", handler.ChangeOwnPassword)
+
+			jsonBody, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest("PUT", "/admin/me/password", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminUserHandler_Me(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewAdminUserHandler(mockRepo)
+
+	r := setupAdminUserTestRouter(&models.AdminUser{ID: "1", Email: "volunteer@example.com", Role: auth.RoleCheckInVolunteer})
+	r.GET("/admin/me", handler.Me)
+
+	req, _ := http.NewRequest("GET", "/admin/me", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		User        models.AdminUser `json:"user"`
+		Permissions []string         `json:"permissions"`
+		CSRFToken   string           `json:"csrfToken"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, "volunteer@example.com", response.User.Email)
+	assert.NotEmpty(t, response.CSRFToken)
+	assert.ElementsMatch(t, []string{auth.PermAttendeesRead, auth.PermAttendeesCheckIn}, response.Permissions)
+}
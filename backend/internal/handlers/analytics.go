@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"ai-india-workshop-backend/internal/analytics"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Days covered by a series when no from date is given, and the most a
+// single request may cover, by granularity
+var (
+	defaultAnalyticsDays = map[string]int{analytics.GranularityHour: 2, analytics.GranularityDay: 30}
+	maxAnalyticsDays     = map[string]int{analytics.GranularityHour: 31, analytics.GranularityDay: 366}
+)
+
+// AnalyticsHandler serves registration time series bucketed in the
+// workshop's timezone
+type AnalyticsHandler struct {
+	repo     repository.RepositoryInterface
+	location *time.Location
+}
+
+func NewAnalyticsHandler(repo repository.RepositoryInterface, location *time.Location) *AnalyticsHandler {
+	return &AnalyticsHandler{repo: repo, location: location}
+}
+
+// Get returns registrations, cancellations, check-ins and the cumulative
+// curve per hour or day (?granularity=hour|day) between the from and to
+// dates, both inclusive. The series is built from the counters kept by the
+// repository; the first request for a workshop counts its existing attendees.
+func (h *AnalyticsHandler) Get(c *gin.Context) {
+	granularity := c.DefaultQuery("granularity", analytics.GranularityDay)
+	if !analytics.ValidGranularity(granularity) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "granularity must be hour or day"})
+		return
+	}
+	start, end, err := h.dateRange(c.Query("from"), c.Query("to"), granularity)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	days, err := h.repo.GetAnalyticsDays(ctx)
+	if errors.Is(err, repository.ErrNotFound) {
+		if err = h.repo.RebuildAnalytics(ctx); err == nil {
+			days, err = h.repo.GetAnalyticsDays(ctx)
+		}
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error loading analytics", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics.Series(days, granularity, start, end, h.location))
+}
+
+// dateRange turns the from and to dates into the start of the first day and
+// the end of the last one. The range ends today unless to is given.
+func (h *AnalyticsHandler) dateRange(from, to, granularity string) (time.Time, time.Time, error) {
+	now := time.Now().In(h.location)
+	last := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, h.location)
+	if to != "" {
+		var err error
+		if last, err = time.ParseInLocation(analytics.DateLayout, to, h.location); err != nil {
+			return time.Time{}, time.Time{}, errors.New("to must be a date like 2006-01-02")
+		}
+	}
+
+	first := last.AddDate(0, 0, 1-defaultAnalyticsDays[granularity])
+	if from != "" {
+		var err error
+		if first, err = time.ParseInLocation(analytics.DateLayout, from, h.location); err != nil {
+			return time.Time{}, time.Time{}, errors.New("from must be a date like 2006-01-02")
+		}
+	}
+
+	if last.Before(first) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	end := last.AddDate(0, 0, 1)
+	if limit := maxAnalyticsDays[granularity]; end.After(first.AddDate(0, 0, limit)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%s granularity covers at most %d days", granularity, limit)
+	}
+	return first, end, nil
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyticsHandler_Get(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	days := []*models.AnalyticsDay{
+		// 20:00 UTC on 1 March is 01:30 on 2 March in IST
+		{Date: "2026-03-01", Registrations: map[string]int{"40": 2, "80": 1}},
+		{Date: "2026-03-02", Registrations: map[string]int{"40": 1}, CheckIns: map[string]int{"41": 1}},
+	}
+
+	tests := []struct {
+		name            string
+		query           string
+		expectedStatus  int
+		expectedBuckets int
+		registrations   []int
+	}{
+		{
+			name:            "daily",
+			query:           "?granularity=day&from=2026-03-01&to=2026-03-03",
+			expectedStatus:  http.StatusOK,
+			expectedBuckets: 3,
+			registrations:   []int{2, 2, 0},
+		},
+		{
+			name:            "defaults to daily",
+			query:           "?from=2026-03-02&to=2026-03-02",
+			expectedStatus:  http.StatusOK,
+			expectedBuckets: 1,
+			registrations:   []int{2},
+		},
+		{
+			name:            "hourly",
+			query:           "?granularity=hour&from=2026-03-02&to=2026-03-02",
+			expectedStatus:  http.StatusOK,
+			expectedBuckets: 24,
+		},
+		{name: "unknown granularity", query: "?granularity=week", expectedStatus: http.StatusBadRequest},
+		{name: "invalid date", query: "?from=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "from after to", query: "?from=2026-03-05&to=2026-03-01", expectedStatus: http.StatusBadRequest},
+		{
+			name:           "hourly range too long",
+			query:          "?granularity=hour&from=2026-01-01&to=2026-03-01",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewAnalyticsHandler(mockRepo, ist)
+			if tt.expectedStatus == http.StatusOK {
+				mockRepo.On("GetAnalyticsDays", mock.Anything).Return(days, nil)
+			}
+
+			r := setupAdminTestRouter()
+			r.GET("/admin/analytics", handler.Get)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/analytics"+tt.query, nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			mockRepo.AssertExpectations(t)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response models.Analytics
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "IST", response.Timezone)
+			require.Len(t, response.Buckets, tt.expectedBuckets)
+			if tt.registrations != nil {
+				var registrations []int
+				for _, bucket := range response.Buckets {
+					registrations = append(registrations, bucket.Registrations)
+				}
+				assert.Equal(t, tt.registrations, registrations)
+			}
+			assert.Equal(t, models.AnalyticsTotals{
+				Registrations:  4,
+				CheckIns:       1,
+				Active:         4,
+				ConversionRate: 0.25,
+			}, response.Totals)
+		})
+	}
+}
+
+func TestAnalyticsHandler_Get_BuildsCountsOnFirstUse(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewAnalyticsHandler(mockRepo, time.UTC)
+	mockRepo.On("GetAnalyticsDays", mock.Anything).Return(nil, repository.ErrNotFound).Once()
+	mockRepo.On("RebuildAnalytics", mock.Anything).Return(nil)
+	mockRepo.On("GetAnalyticsDays", mock.Anything).Return([]*models.AnalyticsDay{
+		{Date: "2026-03-01", Registrations: map[string]int{"0": 1}},
+	}, nil).Once()
+
+	r := setupAdminTestRouter()
+	r.GET("/admin/analytics", handler.Get)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/analytics?from=2026-03-01&to=2026-03-01", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"registrations":1`)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAnalyticsHandler_Get_RebuildFails(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewAnalyticsHandler(mockRepo, time.UTC)
+	mockRepo.On("GetAnalyticsDays", mock.Anything).Return(nil, repository.ErrNotFound)
+	mockRepo.On("RebuildAnalytics", mock.Anything).Return(errors.New("too much contention"))
+
+	r := setupAdminTestRouter()
+	r.GET("/admin/analytics", handler.Get)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/analytics", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockRepo.AssertNumberOfCalls(t, "GetAnalyticsDays", 1)
+}
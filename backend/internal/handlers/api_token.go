@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/problem"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultAPITokenLifetime applies when a token is created without expiresInDays
+	defaultAPITokenLifetime = 90
+	// maxAPITokenLifetime is the longest a token can be valid for, in days
+	maxAPITokenLifetime = 365
+)
+
+// APITokenHandler manages API tokens. Admins create personal tokens for
+// themselves; admins who manage users can also create service tokens for
+// integrations and revoke anyone's tokens.
+type APITokenHandler struct {
+	repo repository.RepositoryInterface
+}
+
+func NewAPITokenHandler(repo repository.RepositoryInterface) *APITokenHandler {
+	return &APITokenHandler{repo: repo}
+}
+
+type createAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expiresInDays"`
+}
+
+// GetOwn lists the signed-in admin's personal tokens
+func (h *APITokenHandler) GetOwn(c *gin.Context) {
+	user := middleware.CurrentAdmin(c)
+	h.list(c, models.APITokenQuery{OwnerID: user.ID, Kind: models.APITokenKindPersonal})
+}
+
+// CreateOwn issues a personal token that acts as the signed-in admin. Its
+// scopes must be permissions the admin's role already has.
+func (h *APITokenHandler) CreateOwn(c *gin.Context) {
+	var req createAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	user := middleware.CurrentAdmin(c)
+	for _, scope := range req.Scopes {
+		if !auth.Can(user.Role, scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Your role does not have the permission " + scope})
+			return
+		}
+	}
+
+	h.create(c, req, &models.APIToken{Kind: models.APITokenKindPersonal, OwnerID: user.ID})
+}
+
+// RevokeOwn revokes one of the signed-in admin's personal tokens
+func (h *APITokenHandler) RevokeOwn(c *gin.Context) {
+	user := middleware.CurrentAdmin(c)
+	h.revoke(c, func(token *models.APIToken) bool {
+		return token.Kind == models.APITokenKindPersonal && token.OwnerID == user.ID
+	})
+}
+
+// GetAll lists every personal and service token
+func (h *APITokenHandler) GetAll(c *gin.Context) {
+	h.list(c, models.APITokenQuery{Kind: c.Query("kind")})
+}
+
+// CreateService issues a service token that is not tied to an admin account.
+// Service tokens cannot manage admin users.
+func (h *APITokenHandler) CreateService(c *gin.Context) {
+	var req createAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !auth.ValidPermission(scope) || scope == auth.PermUsersManage {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scope " + scope})
+			return
+		}
+	}
+
+	h.create(c, req, &models.APIToken{Kind: models.APITokenKindService})
+}
+
+// Revoke revokes any token
+func (h *APITokenHandler) Revoke(c *gin.Context) {
+	h.revoke(c, func(*models.APIToken) bool { return true })
+}
+
+func (h *APITokenHandler) list(c *gin.Context, query models.APITokenQuery) {
+	tokens, err := h.repo.GetAPITokens(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// create fills in and stores a token, returning its value once. The value
+// cannot be recovered afterwards.
+func (h *APITokenHandler) create(c *gin.Context, req createAPITokenRequest, token *models.APIToken) {
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultAPITokenLifetime
+	}
+	if days < 1 || days > maxAPITokenLifetime {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expiresInDays must be between 1 and 365"})
+		return
+	}
+
+	value, id, err := auth.GenerateAPIToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API token"})
+		return
+	}
+
+	now := time.Now()
+	token.ID = id
+	token.Name = req.Name
+	token.Prefix = auth.APITokenDisplayPrefix(value)
+	token.Scopes = req.Scopes
+	token.CreatedBy = middleware.CurrentAdmin(c).Email
+	token.CreatedAt = now
+	token.ExpiresAt = now.AddDate(0, 0, days)
+
+	if err := h.repo.CreateAPIToken(c.Request.Context(), token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"token": value, "apiToken": token})
+}
+
+// revoke revokes the :id token if allowed says the caller may, reporting
+// tokens they may not touch as missing
+func (h *APITokenHandler) revoke(c *gin.Context, allowed func(*models.APIToken) bool) {
+	id := c.Param("id")
+	token, err := h.repo.GetAPIToken(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API token not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API token"})
+		return
+	}
+	if !allowed(token) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API token not found"})
+		return
+	}
+	if token.RevokedAt != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "API token already revoked"})
+		return
+	}
+
+	if err := h.repo.RevokeAPIToken(c.Request.Context(), id, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked"})
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITokenHandler_CreateOwn(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "scopes within role",
+			body:           `{"name":"CRM sync","scopes":["attendees:read"],"expiresInDays":30}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "scope the role lacks",
+			body:           `{"name":"CRM sync","scopes":["users:manage"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "no scopes",
+			body:           `{"name":"CRM sync","scopes":[]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "lifetime too long",
+			body:           `{"name":"CRM sync","scopes":["attendees:read"],"expiresInDays":1000}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewAPITokenHandler(mockRepo)
+
+			var stored *models.APIToken
+			mockRepo.On("CreateAPIToken", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				stored = args.Get(1).(*models.APIToken)
+			}).Return(nil).Maybe()
+
+			user := &models.AdminUser{ID: "user-1", Email: "admin@example.com", Role: auth.RoleAnalyst}
+			r := setupAdminUserTestRouter(user)
+			r.POST("/admin/me/tokens", handler.CreateOwn)
+
+			req, _ := http.NewRequest("POST", "/admin/me/tokens", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Nil(t, stored)
+				return
+			}
+
+			var response struct {
+				Token    string          `json:"token"`
+				APIToken models.APIToken `json:"apiToken"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.NotNil(t, stored)
+
+			// Only the hash of the token is stored
+			assert.Equal(t, auth.APITokenID(response.Token), stored.ID)
+			assert.NotEqual(t, response.Token, stored.ID)
+			assert.True(t, strings.HasPrefix(response.Token, stored.Prefix))
+			assert.Equal(t, stored.ID, response.APIToken.ID)
+			assert.Equal(t, models.APITokenKindPersonal, stored.Kind)
+			assert.Equal(t, "user-1", stored.OwnerID)
+			assert.Equal(t, "admin@example.com", stored.CreatedBy)
+			assert.Equal(t, []string{auth.PermAttendeesRead}, stored.Scopes)
+			assert.WithinDuration(t, time.Now().AddDate(0, 0, 30), stored.ExpiresAt, time.Minute)
+		})
+	}
+}
+
+func TestAPITokenHandler_CreateService(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "valid scopes",
+			body:           `{"name":"Agenda sync","scopes":["sessions:write","speakers:write"]}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "unknown scope",
+			body:           `{"name":"Agenda sync","scopes":["everything"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "cannot manage users",
+			body:           `{"name":"Agenda sync","scopes":["users:manage"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewAPITokenHandler(mockRepo)
+			mockRepo.On("CreateAPIToken", mock.Anything, mock.MatchedBy(func(token *models.APIToken) bool {
+				return token.Kind == models.APITokenKindService && token.OwnerID == "" &&
+					token.ExpiresAt.After(time.Now().AddDate(0, 0, defaultAPITokenLifetime-1))
+			})).Return(nil).Maybe()
+
+			r := setupAdminUserTestRouter(&models.AdminUser{ID: "user-1", Email: "owner@example.com", Role: auth.RoleOwner})
+			r.POST("/admin/tokens", handler.CreateService)
+
+			req, _ := http.NewRequest("POST", "/admin/tokens", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPITokenHandler_RevokeOwn(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          *models.APIToken
+		expectedStatus int
+	}{
+		{
+			name:           "own token",
+			token:          &models.APIToken{ID: "tok-1", Kind: models.APITokenKindPersonal, OwnerID: "user-1"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "another admin's token",
+			token:          &models.APIToken{ID: "tok-1", Kind: models.APITokenKindPersonal, OwnerID: "user-2"},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "service token",
+			token:          &models.APIToken{ID: "tok-1", Kind: models.APITokenKindService},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewAPITokenHandler(mockRepo)
+			mockRepo.On("GetAPIToken", mock.Anything, "tok-1").Return(tt.token, nil)
+			if tt.expectedStatus == http.StatusOK {
+				mockRepo.On("RevokeAPIToken", mock.Anything, "tok-1", mock.Anything).Return(nil)
+			}
+
+			r := setupAdminUserTestRouter(&models.AdminUser{ID: "user-1", Role: auth.RoleAnalyst})
+			r.DELETE("/admin/me/tokens/:id", handler.RevokeOwn)
+
+			req, _ := http.NewRequest("DELETE", "/admin/me/tokens/tok-1", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPITokenHandler_GetOwn(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewAPITokenHandler(mockRepo)
+	tokens := []*models.APIToken{{ID: "tok-1", Name: "CRM sync", Kind: models.APITokenKindPersonal, OwnerID: "user-1"}}
+	mockRepo.On("GetAPITokens", mock.Anything, models.APITokenQuery{OwnerID: "user-1", Kind: models.APITokenKindPersonal}).Return(tokens, nil)
+
+	r := setupAdminUserTestRouter(&models.AdminUser{ID: "user-1"})
+	r.GET("/admin/me/tokens", handler.GetOwn)
+
+	req, _ := http.NewRequest("GET", "/admin/me/tokens", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "CRM sync")
+	mockRepo.AssertExpectations(t)
+}
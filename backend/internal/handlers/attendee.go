@@ -1,40 +1,145 @@
 package handlers
 
 import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"log/slog"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/botguard"
+	"ai-india-workshop-backend/internal/designation"
+	"ai-india-workshop-backend/internal/forms"
+	"ai-india-workshop-backend/internal/ids"
 	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/problem"
+	"ai-india-workshop-backend/internal/ratelimit"
 	"ai-india-workshop-backend/internal/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AttendeeHandler struct {
-	repo repository.RepositoryInterface
+	repo       repository.RepositoryInterface
+	protection *RegistrationProtection
 }
 
 func NewAttendeeHandler(repo repository.RepositoryInterface) *AttendeeHandler {
 	return &AttendeeHandler{repo: repo}
 }
 
+// RegistrationProtection throttles public registrations and screens out bots
+type RegistrationProtection struct {
+	// IPLimiter and EmailLimiter count registrations per client IP and per
+	// email address
+	IPLimiter    *ratelimit.Limiter
+	EmailLimiter *ratelimit.Limiter
+	// FormTokens rejects forms submitted sooner than a person could fill them in
+	FormTokens *botguard.FormTokens
+	// Verifier checks a proof-of-work or captcha challenge; nil means none
+	Verifier botguard.Verifier
+}
+
+// EnableProtection makes Register enforce rate limits, a honeypot field, a
+// minimum fill time and, if configured, a challenge
+func (h *AttendeeHandler) EnableProtection(protection *RegistrationProtection) {
+	h.protection = protection
+}
+
+// GetForm returns the workshop's designations and custom questions, and the
+// form token and challenge the registration form must send back with its
+// submission
+func (h *AttendeeHandler) GetForm(c *gin.Context) {
+	form, err := h.repo.GetRegistrationForm(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registration form"})
+		return
+	}
+	taxonomy, err := h.repo.GetDesignationTaxonomy(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registration form"})
+		return
+	}
+	designations := make([]string, len(taxonomy.Designations))
+	for i, d := range taxonomy.Designations {
+		designations[i] = d.Name
+	}
+	response := gin.H{"fields": form.Fields, "designations": designations}
+
+	if h.protection != nil {
+		formToken := h.protection.FormTokens.Issue()
+		response["formToken"] = formToken
+		if h.protection.Verifier != nil {
+			response["challenge"] = h.protection.Verifier.Challenge(formToken)
+		}
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *AttendeeHandler) Register(c *gin.Context) {
 	var req struct {
 		Name        string `json:"name" binding:"required"`
 		Email       string `json:"email" binding:"required,email"`
 		Designation string `json:"designation" binding:"required"`
+		// Answers to the custom questions, keyed by field ID
+		Answers map[string]interface{} `json:"answers"`
+
+		// Bot protection. Website is a honeypot that is hidden from people
+		// and must be left empty.
+		FormToken         string `json:"formToken"`
+		ChallengeResponse string `json:"challengeResponse"`
+		Website           string `json:"website"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.BindError(c, err)
+		return
+	}
+
+	// Answers are checked before the rate limits so that correcting a
+	// mistake does not use up an attempt
+	form, err := h.repo.GetRegistrationForm(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register attendee"})
+		return
+	}
+	answers, err := forms.ValidateAnswers(form.Fields, req.Answers)
+	if err != nil {
+		respondFormError(c, err)
 		return
 	}
 
 	attendee := &models.Attendee{
 		Name:        req.Name,
 		Email:       req.Email,
-		Designation: req.Designation,
+		Designation: h.normalizeDesignation(c.Request.Context(), req.Designation),
 		CreatedAt:   time.Now(),
+		Answers:     answers,
+	}
+
+	if h.protection != nil {
+		// Bots that fill in the honeypot are told they succeeded, with an ID
+		// like a saved attendee's, so they have no reason to try again
+		// differently
+		if req.Website != "" {
+			slog.InfoContext(c.Request.Context(), "Dropped registration: honeypot field filled in", "clientIp", c.ClientIP())
+			if attendee.ID, err = ids.New(); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register attendee"})
+				return
+			}
+			c.JSON(http.StatusCreated, attendee)
+			return
+		}
+		if !h.screenRegistration(c, req.Email, req.FormToken, req.ChallengeResponse) {
+			return
+		}
 	}
 
 	if err := h.repo.CreateAttendee(c.Request.Context(), attendee); err != nil {
@@ -45,6 +150,73 @@ func (h *AttendeeHandler) Register(c *gin.Context) {
 	c.JSON(http.StatusCreated, attendee)
 }
 
+// normalizeDesignation maps a designation onto the taxonomy. If the taxonomy
+// cannot be loaded the designation is only tidied up; Remap can fix it later.
+func (h *AttendeeHandler) normalizeDesignation(ctx context.Context, value string) string {
+	taxonomy, err := h.repo.GetDesignationTaxonomy(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error loading designation taxonomy", "error", err)
+		return designation.Clean(value)
+	}
+	return designation.NewMatcher(taxonomy.Designations).Normalize(value)
+}
+
+// screenRegistration applies the rate limits, minimum fill time and challenge,
+// writing the error response if the registration must be refused
+func (h *AttendeeHandler) screenRegistration(c *gin.Context, email, formToken, challengeResponse string) bool {
+	ctx := c.Request.Context()
+	p := h.protection
+
+	// Every attempt counts towards the limits, so retrying a failed
+	// challenge is throttled too. Limiter errors are logged and the
+	// registration allowed.
+	var wait time.Duration
+	for key, limiter := range map[string]*ratelimit.Limiter{
+		"ip:" + c.ClientIP():                  p.IPLimiter,
+		"email:" + auth.NormalizeEmail(email): p.EmailLimiter,
+	} {
+		remaining, err := limiter.Fail(ctx, key)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error updating registration limiter", "key", key, "error", err)
+			continue
+		}
+		wait = max(wait, remaining)
+	}
+	if wait > 0 {
+		seconds := int(math.Ceil(wait.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":      "Too many registrations, please try again later",
+			"retryAfter": seconds,
+		})
+		return false
+	}
+
+	if err := p.FormTokens.Check(formToken); err != nil {
+		if errors.Is(err, botguard.ErrTooFast) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The form was submitted too quickly, please try again"})
+			return false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The form has expired, please reload the page and try again"})
+		return false
+	}
+
+	if p.Verifier != nil {
+		err := p.Verifier.Verify(ctx, formToken, auth.NormalizeEmail(email), challengeResponse, c.ClientIP())
+		if errors.Is(err, botguard.ErrChallengeFailed) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Verification failed, please try again"})
+			return false
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Error verifying registration challenge", "error", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Verification is unavailable, please try again later"})
+			return false
+		}
+	}
+
+	return true
+}
+
 func (h *AttendeeHandler) GetAll(c *gin.Context) {
 	attendees, err := h.repo.GetAllAttendees(c.Request.Context())
 	if err != nil {
@@ -55,6 +227,82 @@ func (h *AttendeeHandler) GetAll(c *gin.Context) {
 	c.JSON(http.StatusOK, attendees)
 }
 
+// Export downloads the attendees as CSV, with a column for each custom
+// question. Answers to questions that have since been removed get columns
+// of their own after the current questions.
+func (h *AttendeeHandler) Export(c *gin.Context) {
+	ctx := c.Request.Context()
+	attendees, err := h.repo.GetAllAttendees(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attendees"})
+		return
+	}
+	form, err := h.repo.GetRegistrationForm(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registration form"})
+		return
+	}
+
+	header := []string{"ID", "Name", "Email", "Designation", "Registered At", "Checked In At"}
+	var answerKeys []string
+	current := map[string]bool{}
+	for _, field := range form.Fields {
+		header = append(header, field.Label)
+		answerKeys = append(answerKeys, field.ID)
+		current[field.ID] = true
+	}
+	var removed []string
+	for _, attendee := range attendees {
+		for id := range attendee.Answers {
+			if !current[id] {
+				current[id] = true
+				removed = append(removed, id)
+			}
+		}
+	}
+	sort.Strings(removed)
+	header = append(header, removed...)
+	answerKeys = append(answerKeys, removed...)
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="attendees.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(header)
+	for _, attendee := range attendees {
+		checkedInAt := ""
+		if attendee.CheckedInAt != nil {
+			checkedInAt = attendee.CheckedInAt.Format(time.RFC3339)
+		}
+		row := []string{
+			attendee.ID,
+			csvSafe(attendee.Name),
+			csvSafe(attendee.Email),
+			csvSafe(attendee.Designation),
+			attendee.CreatedAt.Format(time.RFC3339),
+			checkedInAt,
+		}
+		for _, id := range answerKeys {
+			row = append(row, csvSafe(forms.FormatAnswer(attendee.Answers[id])))
+		}
+		_ = w.Write(row)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error writing attendee export", "error", err)
+	}
+}
+
+// csvSafe stops a value entered by an attendee being run as a formula when
+// the export is opened in a spreadsheet
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
 func (h *AttendeeHandler) GetCount(c *gin.Context) {
 	count, err := h.repo.GetAttendeeCount(c.Request.Context())
 	if err != nil {
@@ -68,6 +316,10 @@ func (h *AttendeeHandler) GetCount(c *gin.Context) {
 func (h *AttendeeHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	if err := h.repo.DeleteAttendee(c.Request.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attendee not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attendee"})
 		return
 	}
@@ -75,3 +327,20 @@ func (h *AttendeeHandler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Attendee deleted successfully"})
 }
 
+func (h *AttendeeHandler) CheckIn(c *gin.Context) {
+	id := c.Param("id")
+	attendee, err := h.repo.CheckInAttendee(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attendee not found"})
+		case errors.Is(err, repository.ErrAlreadyCheckedIn):
+			c.JSON(http.StatusConflict, gin.H{"error": "Attendee already checked in"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in attendee"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, attendee)
+}
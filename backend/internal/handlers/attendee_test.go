@@ -8,7 +8,9 @@ import (
 	"testing"
 	"time"
 
+	"ai-india-workshop-backend/internal/botguard"
 	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/ratelimit"
 	"ai-india-workshop-backend/internal/repository"
 
 	"github.com/gin-gonic/gin"
@@ -99,6 +101,8 @@ func TestAttendeeHandler_Register(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := new(repository.MockRepository)
 			handler := NewAttendeeHandler(mockRepo)
+			mockRepo.On("GetRegistrationForm", mock.Anything).Return(&models.RegistrationForm{}, nil).Maybe()
+			mockRepo.On("GetDesignationTaxonomy", mock.Anything).Return(&models.DesignationTaxonomy{}, nil).Maybe()
 
 			if !tt.expectError || tt.repoError != nil {
 				mockRepo.On("CreateAttendee", mock.Anything, mock.MatchedBy(func(attendee *models.Attendee) bool {
@@ -137,6 +141,330 @@ func TestAttendeeHandler_Register(t *testing.T) {
 	}
 }
 
+func TestAttendeeHandler_Register_Answers(t *testing.T) {
+	form := &models.RegistrationForm{Fields: []models.FormField{
+		{ID: "company", Label: "Company", Type: models.FieldTypeText, Required: true},
+		{ID: "tshirt", Label: "T-shirt size", Type: models.FieldTypeSelect, Options: []string{"S", "M", "L"}},
+		{ID: "consent", Label: "I agree to the code of conduct", Type: models.FieldTypeCheckbox, Required: true},
+	}}
+
+	tests := []struct {
+		name            string
+		answers         map[string]interface{}
+		expectedStatus  int
+		expectedField   string
+		expectedAnswers map[string]interface{}
+	}{
+		{
+			name:            "valid answers",
+			answers:         map[string]interface{}{"company": " Acme ", "tshirt": "M", "consent": true},
+			expectedStatus:  http.StatusCreated,
+			expectedAnswers: map[string]interface{}{"company": "Acme", "tshirt": "M", "consent": true},
+		},
+		{
+			name:           "required answer missing",
+			answers:        map[string]interface{}{"consent": true},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "company",
+		},
+		{
+			name:           "consent not given",
+			answers:        map[string]interface{}{"company": "Acme", "consent": false},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "consent",
+		},
+		{
+			name:           "unknown option",
+			answers:        map[string]interface{}{"company": "Acme", "tshirt": "XXL", "consent": true},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "tshirt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewAttendeeHandler(mockRepo)
+			mockRepo.On("GetRegistrationForm", mock.Anything).Return(form, nil)
+			mockRepo.On("GetDesignationTaxonomy", mock.Anything).Return(&models.DesignationTaxonomy{}, nil).Maybe()
+			var saved *models.Attendee
+			mockRepo.On("CreateAttendee", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				saved = args.Get(1).(*models.Attendee)
+			}).Return(nil).Maybe()
+
+			r := setupAttendeeTestRouter()
+			r.POST("/attendees", handler.Register)
+
+			jsonBody, _ := json.Marshal(map[string]interface{}{
+				"name":        "John Doe",
+				"email":       "john@example.com",
+				"designation": "Engineer",
+				"answers":     tt.answers,
+			})
+			req := httptest.NewRequest("POST", "/attendees", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedField != "" {
+				var response map[string]string
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedField, response["field"])
+				assert.Nil(t, saved)
+				return
+			}
+			require.NotNil(t, saved)
+			assert.Equal(t, tt.expectedAnswers, saved.Answers)
+		})
+	}
+}
+
+func TestAttendeeHandler_Register_NormalizesDesignation(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewAttendeeHandler(mockRepo)
+	mockRepo.On("GetRegistrationForm", mock.Anything).Return(&models.RegistrationForm{}, nil)
+	mockRepo.On("GetDesignationTaxonomy", mock.Anything).Return(&models.DesignationTaxonomy{Designations: []models.Designation{
+		{Name: "Software Engineer", Aliases: []string{"SDE"}},
+	}}, nil)
+	mockRepo.On("CreateAttendee", mock.Anything, mock.MatchedBy(func(attendee *models.Attendee) bool {
+		return attendee.Designation == "Software Engineer"
+	})).Return(nil)
+
+	r := setupAttendeeTestRouter()
+	r.POST("/attendees", handler.Register)
+
+	jsonBody, _ := json.Marshal(map[string]string{"name": "John Doe", "email": "john@example.com", "designation": " sde "})
+	req := httptest.NewRequest("POST", "/attendees", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAttendeeHandler_Export(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewAttendeeHandler(mockRepo)
+
+	createdAt := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	checkedInAt := createdAt.Add(24 * time.Hour)
+	mockRepo.On("GetAllAttendees", mock.Anything).Return([]*models.Attendee{
+		{
+			ID: "1", Name: "John Doe", Email: "john@example.com", Designation: "Engineer", CreatedAt: createdAt,
+			CheckedInAt: &checkedInAt,
+			Answers:     map[string]interface{}{"company": "Acme, Inc.", "diet": []interface{}{"Vegan", "Gluten free"}},
+		},
+		{
+			ID: "2", Name: "=HYPERLINK(\"http://evil\")", Email: "jane@example.com", Designation: "Student", CreatedAt: createdAt,
+			Answers: map[string]interface{}{"referral": "Friend"},
+		},
+	}, nil)
+	mockRepo.On("GetRegistrationForm", mock.Anything).Return(&models.RegistrationForm{Fields: []models.FormField{
+		{ID: "company", Label: "Company", Type: models.FieldTypeText},
+		{ID: "diet", Label: "Dietary needs", Type: models.FieldTypeMultiSelect, Options: []string{"Vegan", "Gluten free"}},
+	}}, nil)
+
+	r := setupAttendeeTestRouter()
+	r.GET("/attendees/export", handler.Export)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/attendees/export", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	// The referral question has been removed from the form but its answers
+	// are still exported
+	assert.Equal(t, "ID,Name,Email,Designation,Registered At,Checked In At,Company,Dietary needs,referral\n"+
+		"1,John Doe,john@example.com,Engineer,2026-03-01T09:30:00Z,2026-03-02T09:30:00Z,\"Acme, Inc.\",Vegan; Gluten free,\n"+
+		"2,\"'=HYPERLINK(\"\"http://evil\"\")\",jane@example.com,Student,2026-03-01T09:30:00Z,,,,Friend\n",
+		w.Body.String())
+}
+
+// newProtectedAttendeeHandler enables registration protection with in-memory
+// limiters. A minFillTime of zero accepts a form as soon as it is served.
+func newProtectedAttendeeHandler(mockRepo *repository.MockRepository, minFillTime time.Duration, verifier botguard.Verifier) *AttendeeHandler {
+	mockRepo.On("GetRegistrationForm", mock.Anything).Return(&models.RegistrationForm{}, nil).Maybe()
+	mockRepo.On("GetDesignationTaxonomy", mock.Anything).Return(&models.DesignationTaxonomy{}, nil).Maybe()
+	handler := NewAttendeeHandler(mockRepo)
+	handler.EnableProtection(&RegistrationProtection{
+		IPLimiter:    ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Policy{FreeAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}),
+		EmailLimiter: ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Policy{FreeAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}),
+		FormTokens:   botguard.NewFormTokens([]byte("test-secret"), minFillTime, time.Hour),
+		Verifier:     verifier,
+	})
+	return handler
+}
+
+// fetchRegistrationForm returns the form token and challenge served to the
+// registration form
+func fetchRegistrationForm(t *testing.T, r *gin.Engine) (string, map[string]interface{}) {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/attendees/form", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var form struct {
+		FormToken string                 `json:"formToken"`
+		Challenge map[string]interface{} `json:"challenge"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &form))
+	return form.FormToken, form.Challenge
+}
+
+func TestAttendeeHandler_Register_Protected(t *testing.T) {
+	tests := []struct {
+		name        string
+		minFillTime time.Duration
+		verifier    botguard.Verifier
+		// solve returns the challenge response for a form token, if any
+		solve          func(formToken, email string) string
+		formToken      string
+		website        string
+		expectedStatus int
+		expectCreated  bool
+	}{
+		{
+			name:           "valid registration",
+			expectedStatus: http.StatusCreated,
+			expectCreated:  true,
+		},
+		{
+			name:           "honeypot filled in",
+			website:        "http://spam.example",
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "submitted too quickly",
+			minFillTime:    time.Hour,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "forged form token",
+			formToken:      "1700000000000.forged",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:     "proof of work solved",
+			verifier: botguard.ProofOfWork{Difficulty: 8},
+			solve: func(formToken, email string) string {
+				return botguard.SolveProofOfWork(formToken, email, 8)
+			},
+			expectedStatus: http.StatusCreated,
+			expectCreated:  true,
+		},
+		{
+			name:     "proof of work solved for another email",
+			verifier: botguard.ProofOfWork{Difficulty: 8},
+			solve: func(formToken, _ string) string {
+				return botguard.SolveProofOfWork(formToken, "someone@example.com", 8)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "fake challenge missing",
+			verifier:       botguard.FakeVerifier{},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:     "fake challenge passed",
+			verifier: botguard.FakeVerifier{},
+			solve: func(string, string) string {
+				return botguard.FakeVerifierPass
+			},
+			expectedStatus: http.StatusCreated,
+			expectCreated:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := newProtectedAttendeeHandler(mockRepo, tt.minFillTime, tt.verifier)
+			if tt.expectCreated {
+				mockRepo.On("CreateAttendee", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			r := setupAttendeeTestRouter()
+			r.GET("/attendees/form", handler.GetForm)
+			r.POST("/attendees", handler.Register)
+
+			formToken, challenge := fetchRegistrationForm(t, r)
+			if tt.verifier != nil {
+				assert.NotNil(t, challenge)
+			}
+			if tt.formToken != "" {
+				formToken = tt.formToken
+			}
+			body := map[string]string{
+				"name":        "John Doe",
+				"email":       "john@example.com",
+				"designation": "Engineer",
+				"formToken":   formToken,
+				"website":     tt.website,
+			}
+			if tt.solve != nil {
+				body["challengeResponse"] = tt.solve(formToken, body["email"])
+			}
+
+			jsonBody, _ := json.Marshal(body)
+			req := httptest.NewRequest("POST", "/attendees", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectCreated {
+				mockRepo.AssertExpectations(t)
+			} else {
+				mockRepo.AssertNotCalled(t, "CreateAttendee", mock.Anything, mock.Anything)
+			}
+			if tt.website != "" {
+				// The dropped registration looks like a saved one
+				var attendee models.Attendee
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &attendee))
+				assert.Regexp(t, `^[A-Za-z0-9]{20}$`, attendee.ID)
+			}
+		})
+	}
+}
+
+func TestAttendeeHandler_Register_RateLimited(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := newProtectedAttendeeHandler(mockRepo, 0, nil)
+	mockRepo.On("CreateAttendee", mock.Anything, mock.Anything).Return(nil)
+
+	r := setupAttendeeTestRouter()
+	r.GET("/attendees/form", handler.GetForm)
+	r.POST("/attendees", handler.Register)
+
+	register := func(email string) *httptest.ResponseRecorder {
+		formToken, _ := fetchRegistrationForm(t, r)
+		jsonBody, _ := json.Marshal(map[string]string{
+			"name":        "John Doe",
+			"email":       email,
+			"designation": "Engineer",
+			"formToken":   formToken,
+		})
+		req := httptest.NewRequest("POST", "/attendees", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// One registration per email address is allowed, however it is spelled
+	assert.Equal(t, http.StatusCreated, register("john@example.com").Code)
+	w := register("John@Example.com")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Three registrations per IP address are allowed
+	assert.Equal(t, http.StatusCreated, register("jane@example.com").Code)
+	assert.Equal(t, http.StatusTooManyRequests, register("ravi@example.com").Code)
+	mockRepo.AssertNumberOfCalls(t, "CreateAttendee", 2)
+}
+
 func TestAttendeeHandler_GetAll(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -263,6 +591,12 @@ func TestAttendeeHandler_Delete(t *testing.T) {
 			repoError:      nil,
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:           "already deleted or missing",
+			id:             "123",
+			repoError:      repository.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
 		{
 			name:           "repository error",
 			id:             "123",
@@ -300,3 +634,71 @@ func TestAttendeeHandler_Delete(t *testing.T) {
 }
 
 
+
+func TestAttendeeHandler_CheckIn(t *testing.T) {
+	checkedInAt := time.Now()
+	tests := []struct {
+		name           string
+		id             string
+		attendee       *models.Attendee
+		repoError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful check-in",
+			id:             "123",
+			attendee:       &models.Attendee{ID: "123", Name: "John Doe", CheckedInAt: &checkedInAt},
+			repoError:      nil,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "already checked in",
+			id:             "123",
+			repoError:      repository.ErrAlreadyCheckedIn,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "unknown attendee",
+			id:             "123",
+			repoError:      repository.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "repository error",
+			id:             "123",
+			repoError:      assert.AnError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewAttendeeHandler(mockRepo)
+
+			if tt.attendee != nil {
+				mockRepo.On("CheckInAttendee", mock.Anything, tt.id).Return(tt.attendee, nil)
+			} else {
+				mockRepo.On("CheckInAttendee", mock.Anything, tt.id).Return(nil, tt.repoError)
+			}
+
+			r := setupAttendeeTestRouter()
+			r.POST("/attendees/:id/checkin", handler.CheckIn)
+
+			req, _ := http.NewRequest("POST", "/attendees/"+tt.id+"/checkin", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var attendee models.Attendee
+				err := json.Unmarshal(w.Body.Bytes(), &attendee)
+				require.NoError(t, err)
+				assert.NotNil(t, attendee.CheckedInAt)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
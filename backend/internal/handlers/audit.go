@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type AuditHandler struct {
+	repo repository.RepositoryInterface
+}
+
+func NewAuditHandler(repo repository.RepositoryInterface) *AuditHandler {
+	return &AuditHandler{repo: repo}
+}
+
+// GetAll lists audit entries, newest first. Supports the from/to (RFC 3339),
+// actor, resourceType, resourceId and limit query parameters.
+func (h *AuditHandler) GetAll(c *gin.Context) {
+	query := models.AuditQuery{
+		Actor:        c.Query("actor"),
+		ResourceType: c.Query("resourceType"),
+		ResourceID:   c.Query("resourceId"),
+		Limit:        defaultAuditLimit,
+	}
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		if query.From, err = time.Parse(time.RFC3339, from); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from timestamp, expected RFC 3339"})
+			return
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if query.To, err = time.Parse(time.RFC3339, to); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to timestamp, expected RFC 3339"})
+			return
+		}
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+	if limit := c.Query("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 || query.Limit > maxAuditLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxAuditLimit)})
+			return
+		}
+	}
+
+	entries, err := h.repo.GetAuditEntries(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetLoginAttempts lists admin sign-in attempts, newest first. Supports the
+// email, ip, failed (true to hide successful sign-ins) and limit query
+// parameters.
+func (h *AuditHandler) GetLoginAttempts(c *gin.Context) {
+	query := models.LoginAttemptQuery{
+		Email:      auth.NormalizeEmail(c.Query("email")),
+		IP:         c.Query("ip"),
+		FailedOnly: c.Query("failed") == "true",
+		Limit:      defaultAuditLimit,
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 || query.Limit > maxAuditLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxAuditLimit)})
+			return
+		}
+	}
+
+	attempts, err := h.repo.GetLoginAttempts(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch login attempts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, attempts)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuditTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return gin.New()
+}
+
+func TestAuditHandler_GetAll(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		queryString    string
+		expectedQuery  *models.AuditQuery
+		entries        []*models.AuditEntry
+		repoError      error
+		expectedStatus int
+	}{
+		{
+			name:           "defaults",
+			queryString:    "",
+			expectedQuery:  &models.AuditQuery{Limit: defaultAuditLimit},
+			entries:        []*models.AuditEntry{{ID: "1", Actor: "admin", Action: "DELETE /api/attendees/:id"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "all filters",
+			queryString: "?from=2024-03-01T00:00:00Z&to=2024-03-02T00:00:00Z&actor=admin&resourceType=speakers&resourceId=42&limit=10",
+			expectedQuery: &models.AuditQuery{
+				From: from, To: to, Actor: "admin", ResourceType: "speakers", ResourceID: "42", Limit: 10,
+			},
+			entries:        []*models.AuditEntry{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid from",
+			queryString:    "?from=yesterday",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "to before from",
+			queryString:    "?from=2024-03-02T00:00:00Z&to=2024-03-01T00:00:00Z",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "limit out of range",
+			queryString:    "?limit=5000",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "repository error",
+			queryString:    "",
+			expectedQuery:  &models.AuditQuery{Limit: defaultAuditLimit},
+			repoError:      assert.AnError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewAuditHandler(mockRepo)
+
+			if tt.expectedQuery != nil {
+				mockRepo.On("GetAuditEntries", mock.Anything, *tt.expectedQuery).Return(tt.entries, tt.repoError)
+			}
+
+			r := setupAuditTestRouter()
+			r.GET("/admin/audit", handler.GetAll)
+
+			req, _ := http.NewRequest("GET", "/admin/audit"+tt.queryString, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var entries []*models.AuditEntry
+				err := json.Unmarshal(w.Body.Bytes(), &entries)
+				require.NoError(t, err)
+				assert.Len(t, entries, len(tt.entries))
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuditHandler_GetLoginAttempts(t *testing.T) {
+	tests := []struct {
+		name           string
+		queryString    string
+		expectedQuery  *models.LoginAttemptQuery
+		repoError      error
+		expectedStatus int
+	}{
+		{
+			name:           "defaults",
+			queryString:    "",
+			expectedQuery:  &models.LoginAttemptQuery{Limit: defaultAuditLimit},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "all filters",
+			queryString:    "?email=Admin@Example.com&ip=10.0.0.1&failed=true&limit=5",
+			expectedQuery:  &models.LoginAttemptQuery{Email: "admin@example.com", IP: "10.0.0.1", FailedOnly: true, Limit: 5},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "limit out of range",
+			queryString:    "?limit=0",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "repository error",
+			queryString:    "",
+			expectedQuery:  &models.LoginAttemptQuery{Limit: defaultAuditLimit},
+			repoError:      assert.AnError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewAuditHandler(mockRepo)
+
+			if tt.expectedQuery != nil {
+				attempts := []*models.LoginAttempt{{ID: "1", Email: "admin@example.com", Reason: models.LoginReasonInvalidPassword}}
+				if tt.repoError != nil {
+					attempts = nil
+				}
+				mockRepo.On("GetLoginAttempts", mock.Anything, *tt.expectedQuery).Return(attempts, tt.repoError)
+			}
+
+			r := setupAuditTestRouter()
+			r.GET("/admin/login-attempts", handler.GetLoginAttempts)
+
+			req, _ := http.NewRequest("GET", "/admin/login-attempts"+tt.queryString, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
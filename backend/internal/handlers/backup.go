@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ai-india-workshop-backend/internal/backup"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRestoreSize caps the size of an uploaded backup archive
+const maxRestoreSize = 64 << 20
+
+// BackupHandler lets owners download a backup of the workshop and restore
+// one
+type BackupHandler struct {
+	repo     repository.RepositoryInterface
+	workshop string
+}
+
+// NewBackupHandler serves backups of workshop, the ID of the workshop repo
+// holds
+func NewBackupHandler(repo repository.RepositoryInterface, workshop string) *BackupHandler {
+	return &BackupHandler{repo: repo, workshop: workshop}
+}
+
+// Download sends a backup archive of every attendee, speaker and session,
+// including those in the trash
+func (h *BackupHandler) Download(c *gin.Context) {
+	data, err := h.repo.GetWorkshopData(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workshop data"})
+		return
+	}
+
+	now := time.Now().UTC()
+	c.Header("Content-Type", backup.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.ndjson"`, h.workshop, now.Format("20060102T150405Z")))
+	c.Status(http.StatusOK)
+	if _, err := backup.Write(c.Writer, h.workshop, data, now); err != nil {
+		// The archive is already on its way; without its footer it will be
+		// rejected on restore
+		slog.ErrorContext(c.Request.Context(), "Error writing backup", "error", err)
+	}
+}
+
+// Restore writes an uploaded archive into the workshop. The workshop must be
+// empty unless replace=true, which permanently removes what it holds first.
+func (h *BackupHandler) Restore(c *gin.Context) {
+	replace := false
+	if raw := c.Query("replace"); raw != "" {
+		var err error
+		if replace, err = strconv.ParseBool(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "replace must be true or false", "field": "replace"})
+			return
+		}
+	}
+
+	if c.Request.ContentLength > maxRestoreSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Backup archive is too large"})
+		return
+	}
+
+	archive, err := backup.Read(http.MaxBytesReader(c.Writer, c.Request.Body, maxRestoreSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Backup archive is too large"})
+		case errors.Is(err, backup.ErrInvalidArchive):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read backup archive"})
+		}
+		return
+	}
+
+	result, err := backup.Restore(c.Request.Context(), h.repo, archive, backup.RestoreOptions{Workshop: h.workshop, Replace: replace})
+	if err != nil {
+		if errors.Is(err, backup.ErrWorkshopNotEmpty) {
+			c.JSON(http.StatusConflict, gin.H{"error": "The workshop already has attendees, speakers or sessions; restore with replace=true to remove them"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore backup"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
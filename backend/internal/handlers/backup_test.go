@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/backup"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var backupOwner = &models.AdminUser{ID: "owner-1", Email: "owner@example.com", Role: auth.RoleOwner}
+
+func backupData() *models.WorkshopData {
+	return &models.WorkshopData{
+		Speakers:  []*models.Speaker{{ID: "sp1", Name: "Asha Rao"}},
+		Sessions:  []*models.Session{{ID: "s1", Title: "Keynote", Speakers: []string{"sp1"}}},
+		Attendees: []*models.Attendee{{ID: "a1", Name: "Meera", Email: "meera@example.com", CreatedAt: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)}},
+	}
+}
+
+func TestBackupHandler_Download(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewBackupHandler(mockRepo, "ws-2026")
+	mockRepo.On("GetWorkshopData", mock.Anything).Return(backupData(), nil)
+
+	r := setupAdminUserTestRouter(backupOwner)
+	r.GET("/admin/backup", handler.Download)
+
+	req, _ := http.NewRequest("GET", "/admin/backup", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, backup.ContentType, w.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename="ws-2026-\d{8}T\d{6}Z\.ndjson"$`, w.Header().Get("Content-Disposition"))
+
+	archive, err := backup.Read(w.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ws-2026", archive.Header.Workshop)
+	assert.Equal(t, backup.Counts{Speakers: 1, Sessions: 1, Attendees: 1}, archive.Footer.Counts)
+}
+
+func TestBackupHandler_DownloadFails(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewBackupHandler(mockRepo, "ws-2026")
+	mockRepo.On("GetWorkshopData", mock.Anything).Return(nil, errors.New("unavailable"))
+
+	r := setupAdminUserTestRouter(backupOwner)
+	r.GET("/admin/backup", handler.Download)
+
+	req, _ := http.NewRequest("GET", "/admin/backup", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+}
+
+func TestBackupHandler_Restore(t *testing.T) {
+	var archive bytes.Buffer
+	_, err := backup.Write(&archive, "ws-2025", backupData(), time.Now())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		existing       *models.WorkshopData
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "into an empty workshop",
+			path:           "/admin/restore",
+			body:           archive.String(),
+			existing:       &models.WorkshopData{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "into a workshop with data",
+			path:           "/admin/restore",
+			body:           archive.String(),
+			existing:       backupData(),
+			expectedStatus: http.StatusConflict,
+			expectedError:  "replace=true",
+		},
+		{
+			name:           "replacing a workshop's data",
+			path:           "/admin/restore?replace=true",
+			body:           archive.String(),
+			existing:       backupData(),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "a truncated archive",
+			path:           "/admin/restore",
+			body:           archive.String()[:archive.Len()/2],
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid backup archive",
+		},
+		{
+			name:           "an invalid replace",
+			path:           "/admin/restore?replace=please",
+			body:           archive.String(),
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "replace must be true or false",
+		},
+		{
+			name:           "an archive that is too large",
+			path:           "/admin/restore",
+			body:           strings.Repeat("x", maxRestoreSize+1),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewBackupHandler(mockRepo, "ws-2026")
+			if tt.existing != nil {
+				mockRepo.On("GetWorkshopData", mock.Anything).Return(tt.existing, nil)
+			}
+			mockRepo.On("ReplaceWorkshopData", mock.Anything, mock.AnythingOfType("*models.WorkshopData")).Return(nil)
+			mockRepo.On("RebuildAnalytics", mock.Anything).Return(nil)
+
+			r := setupAdminUserTestRouter(backupOwner)
+			r.POST("/admin/restore", handler.Restore)
+
+			req, _ := http.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", backup.ContentType)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				mockRepo.AssertNotCalled(t, "ReplaceWorkshopData", mock.Anything, mock.Anything)
+				return
+			}
+
+			var result backup.RestoreResult
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(t, "ws-2026", result.Workshop)
+			assert.Equal(t, "ws-2025", result.Source)
+			assert.True(t, result.Remapped)
+			assert.Equal(t, backup.Counts{Speakers: 1, Sessions: 1, Attendees: 1}, result.Restored)
+			mockRepo.AssertCalled(t, "RebuildAnalytics", mock.Anything)
+		})
+	}
+}
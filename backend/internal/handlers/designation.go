@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"ai-india-workshop-backend/internal/designation"
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/problem"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DesignationHandler manages the designation taxonomy
+type DesignationHandler struct {
+	repo repository.RepositoryInterface
+}
+
+func NewDesignationHandler(repo repository.RepositoryInterface) *DesignationHandler {
+	return &DesignationHandler{repo: repo}
+}
+
+func (h *DesignationHandler) Get(c *gin.Context) {
+	taxonomy, err := h.repo.GetDesignationTaxonomy(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch designations"})
+		return
+	}
+	c.JSON(http.StatusOK, taxonomy)
+}
+
+// Update replaces the taxonomy. Existing attendees keep their designation
+// until Remap is run.
+func (h *DesignationHandler) Update(c *gin.Context) {
+	var req struct {
+		Designations []models.Designation `json:"designations"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+	if req.Designations == nil {
+		req.Designations = []models.Designation{}
+	}
+	for i := range req.Designations {
+		if req.Designations[i].Aliases == nil {
+			req.Designations[i].Aliases = []string{}
+		}
+	}
+
+	if err := designation.Validate(req.Designations); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	taxonomy := &models.DesignationTaxonomy{Designations: req.Designations, UpdatedAt: time.Now()}
+	if admin := middleware.CurrentAdmin(c); admin != nil {
+		taxonomy.UpdatedBy = admin.Email
+	}
+	if err := h.repo.SaveDesignationTaxonomy(c.Request.Context(), taxonomy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save designations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, taxonomy)
+}
+
+// Remap rewrites existing attendees' designations to the canonical ones and
+// tidies the spacing of the rest. Attendees in the trash are remapped too, so
+// they come back with the canonical designation if restored. With
+// ?dryRun=true it only reports what would change.
+func (h *DesignationHandler) Remap(c *gin.Context) {
+	ctx := c.Request.Context()
+	dryRun := c.Query("dryRun") == "true"
+
+	taxonomy, err := h.repo.GetDesignationTaxonomy(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch designations"})
+		return
+	}
+	attendees, err := h.repo.GetAllAttendees(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attendees"})
+		return
+	}
+	trash, err := h.repo.GetTrash(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attendees"})
+		return
+	}
+	attendees = append(attendees, trash.Attendees...)
+
+	matcher := designation.NewMatcher(taxonomy.Designations)
+	updates := map[string]string{}
+	counts := map[models.DesignationChange]int{}
+	for _, attendee := range attendees {
+		normalized := matcher.Normalize(attendee.Designation)
+		if normalized == attendee.Designation {
+			continue
+		}
+		updates[attendee.ID] = normalized
+		counts[models.DesignationChange{From: attendee.Designation, To: normalized}]++
+	}
+
+	changes := make([]models.DesignationChange, 0, len(counts))
+	for change, count := range counts {
+		change.Count = count
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Count != changes[j].Count {
+			return changes[i].Count > changes[j].Count
+		}
+		return changes[i].From < changes[j].From
+	})
+
+	updated := len(updates)
+	if !dryRun && len(updates) > 0 {
+		if updated, err = h.repo.SetAttendeeDesignations(ctx, updates); err != nil {
+			slog.ErrorContext(ctx, "Error remapping designations", "updated", updated, "total", len(updates), "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remap designations", "updated": updated})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dryRun": dryRun, "updated": updated, "changes": changes})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDesignationHandler_Update(t *testing.T) {
+	tests := []struct {
+		name           string
+		designations   []models.Designation
+		expectedStatus int
+		expectSave     bool
+	}{
+		{
+			name: "valid taxonomy",
+			designations: []models.Designation{
+				{Name: "Software Engineer", Aliases: []string{"SDE", "SWE"}},
+				{Name: "Student"},
+			},
+			expectedStatus: http.StatusOK,
+			expectSave:     true,
+		},
+		{
+			name: "alias claimed twice",
+			designations: []models.Designation{
+				{Name: "Software Engineer", Aliases: []string{"Engineer"}},
+				{Name: "Data Engineer", Aliases: []string{"Engineer"}},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewDesignationHandler(mockRepo)
+			if tt.expectSave {
+				mockRepo.On("SaveDesignationTaxonomy", mock.Anything, mock.MatchedBy(func(taxonomy *models.DesignationTaxonomy) bool {
+					return len(taxonomy.Designations) == len(tt.designations) && taxonomy.UpdatedBy == "owner@example.com"
+				})).Return(nil)
+			}
+
+			r := setupAdminUserTestRouter(&models.AdminUser{ID: "user-1", Email: "owner@example.com"})
+			r.PUT("/admin/designations", handler.Update)
+
+			jsonBody, _ := json.Marshal(map[string]interface{}{"designations": tt.designations})
+			req := httptest.NewRequest("PUT", "/admin/designations", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				// Designations without aliases come back with an empty list
+				assert.Contains(t, w.Body.String(), `{"name":"Student","aliases":[]}`)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDesignationHandler_Remap(t *testing.T) {
+	taxonomy := &models.DesignationTaxonomy{Designations: []models.Designation{
+		{Name: "Software Engineer", Aliases: []string{"SDE"}},
+	}}
+	attendees := []*models.Attendee{
+		{ID: "1", Designation: "SDE"},
+		{ID: "2", Designation: "sde"},
+		{ID: "3", Designation: "software engineer "},
+		{ID: "4", Designation: "Software Engineer"},
+		{ID: "5", Designation: " Data  Scientist"},
+		{ID: "6", Designation: "Chef"},
+	}
+	deletedAt := time.Now()
+	trash := &models.Trash{Attendees: []*models.Attendee{{ID: "7", Designation: "SDE", DeletedAt: &deletedAt}}}
+	expectedUpdates := map[string]string{
+		"1": "Software Engineer",
+		"2": "Software Engineer",
+		"3": "Software Engineer",
+		"5": "Data Scientist",
+		"7": "Software Engineer",
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		t.Run(map[bool]string{true: "dry run", false: "apply"}[dryRun], func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewDesignationHandler(mockRepo)
+			mockRepo.On("GetDesignationTaxonomy", mock.Anything).Return(taxonomy, nil)
+			mockRepo.On("GetAllAttendees", mock.Anything).Return(attendees, nil)
+			mockRepo.On("GetTrash", mock.Anything).Return(trash, nil)
+			if !dryRun {
+				mockRepo.On("SetAttendeeDesignations", mock.Anything, expectedUpdates).Return(len(expectedUpdates), nil)
+			}
+
+			r := setupAdminUserTestRouter(&models.AdminUser{ID: "user-1", Email: "owner@example.com"})
+			r.POST("/admin/designations/remap", handler.Remap)
+
+			url := "/admin/designations/remap"
+			if dryRun {
+				url += "?dryRun=true"
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("POST", url, nil))
+
+			require.Equal(t, http.StatusOK, w.Code)
+			var response struct {
+				DryRun  bool                       `json:"dryRun"`
+				Updated int                        `json:"updated"`
+				Changes []models.DesignationChange `json:"changes"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, dryRun, response.DryRun)
+			assert.Equal(t, 5, response.Updated)
+			assert.Equal(t, []models.DesignationChange{
+				{From: "SDE", To: "Software Engineer", Count: 2},
+				{From: " Data  Scientist", To: "Data Scientist", Count: 1},
+				{From: "sde", To: "Software Engineer", Count: 1},
+				{From: "software engineer ", To: "Software Engineer", Count: 1},
+			}, response.Changes)
+
+			mockRepo.AssertExpectations(t)
+			if dryRun {
+				mockRepo.AssertNotCalled(t, "SetAttendeeDesignations", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
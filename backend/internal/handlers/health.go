@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultReadinessTimeout bounds how long a readiness check waits for the
+// repository
+const DefaultReadinessTimeout = 2 * time.Second
+
+// HealthHandler answers liveness and readiness probes
+type HealthHandler struct {
+	repo     repository.RepositoryInterface
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewHealthHandler(repo repository.RepositoryInterface, timeout time.Duration) *HealthHandler {
+	return &HealthHandler{repo: repo, timeout: timeout}
+}
+
+// Drain makes readiness checks fail from now on, so no new traffic is sent
+// while the server shuts down
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// Live reports that the process is up and serving requests
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready reports whether requests can be served, which needs the repository
+// to answer within the timeout
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": "Shutting down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+	if err := h.repo.Ping(ctx); err != nil {
+		slog.WarnContext(ctx, "Readiness check failed", "error", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": "Repository unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
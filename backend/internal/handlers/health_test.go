@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHealthHandler_Live(t *testing.T) {
+	handler := NewHealthHandler(new(repository.MockRepository), DefaultReadinessTimeout)
+	r := setupAdminTestRouter()
+	r.GET("/healthz", handler.Live)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMock      func(*repository.MockRepository)
+		drain          bool
+		expectedStatus int
+	}{
+		{
+			name: "repository reachable",
+			setupMock: func(m *repository.MockRepository) {
+				m.On("Ping", mock.Anything).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "repository error",
+			setupMock: func(m *repository.MockRepository) {
+				m.On("Ping", mock.Anything).Return(errors.New("unavailable"))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name: "repository too slow",
+			setupMock: func(m *repository.MockRepository) {
+				m.On("Ping", mock.Anything).Run(func(args mock.Arguments) {
+					<-args.Get(0).(context.Context).Done()
+				}).Return(context.DeadlineExceeded)
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "draining",
+			setupMock:      func(m *repository.MockRepository) {},
+			drain:          true,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			tt.setupMock(mockRepo)
+			handler := NewHealthHandler(mockRepo, 10*time.Millisecond)
+			if tt.drain {
+				handler.Drain()
+			}
+
+			r := setupAdminTestRouter()
+			r.GET("/readyz", handler.Ready)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultJobLimit applies when jobs are listed without a limit
+	defaultJobLimit = 50
+	// maxJobLimit caps the number of jobs returned at once
+	maxJobLimit = 200
+)
+
+// JobHandler lets admins inspect the background jobs and retry those that
+// failed
+type JobHandler struct {
+	repo repository.RepositoryInterface
+}
+
+func NewJobHandler(repo repository.RepositoryInterface) *JobHandler {
+	return &JobHandler{repo: repo}
+}
+
+// GetAll lists jobs, newest first, optionally only those of a type or with
+// a status
+func (h *JobHandler) GetAll(c *gin.Context) {
+	query := models.JobQuery{Type: c.Query("type"), Status: c.Query("status"), Limit: defaultJobLimit}
+	switch query.Status {
+	case "", models.JobPending, models.JobSucceeded, models.JobFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, succeeded or failed", "field": "status"})
+		return
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxJobLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200", "field": "limit"})
+			return
+		}
+		query.Limit = limit
+	}
+
+	jobs, err := h.repo.GetJobs(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// Get returns a job, including its payload and last error
+func (h *JobHandler) Get(c *gin.Context) {
+	job, err := h.repo.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// Retry takes a failed job out of the dead-letter queue and runs it again
+// straight away, with a fresh set of attempts
+func (h *JobHandler) Retry(c *gin.Context) {
+	job, err := h.repo.RetryJob(c.Request.Context(), c.Param("id"), time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errors.Is(err, repository.ErrJobNotFailed):
+			c.JSON(http.StatusConflict, gin.H{"error": "Only failed jobs can be retried"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry job"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
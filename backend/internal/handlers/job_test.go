@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var jobOrganiser = &models.AdminUser{ID: "organiser-1", Email: "organiser@example.com", Role: auth.RoleOrganiser}
+
+func TestJobHandler_GetAll(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewJobHandler(mockRepo)
+
+	mockRepo.On("GetJobs", mock.Anything, models.JobQuery{Status: models.JobFailed, Type: models.JobPublishEvent, Limit: 10}).
+		Return([]*models.Job{{ID: "j1", Type: models.JobPublishEvent, Status: models.JobFailed}}, nil)
+	mockRepo.On("GetJobs", mock.Anything, models.JobQuery{Limit: defaultJobLimit}).Return([]*models.Job{}, nil)
+
+	r := setupAdminUserTestRouter(jobOrganiser)
+	r.GET("/admin/jobs", handler.GetAll)
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{path: "/admin/jobs?status=failed&type=event.publish&limit=10", expectedStatus: http.StatusOK},
+		{path: "/admin/jobs", expectedStatus: http.StatusOK},
+		{path: "/admin/jobs?status=stuck", expectedStatus: http.StatusBadRequest},
+		{path: "/admin/jobs?limit=0", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestJobHandler_Get(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewJobHandler(mockRepo)
+
+	mockRepo.On("GetJob", mock.Anything, "j1").Return(&models.Job{ID: "j1", Type: models.JobPublishEvent, Payload: "{}"}, nil)
+	mockRepo.On("GetJob", mock.Anything, "missing").Return(nil, repository.ErrNotFound)
+
+	r := setupAdminUserTestRouter(jobOrganiser)
+	r.GET("/admin/jobs/:id", handler.Get)
+
+	req, _ := http.NewRequest("GET", "/admin/jobs/j1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/admin/jobs/missing", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestJobHandler_Retry(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "failed job", expectedStatus: http.StatusOK},
+		{name: "job that has not failed", err: repository.ErrJobNotFailed, expectedStatus: http.StatusConflict},
+		{name: "unknown job", err: repository.ErrNotFound, expectedStatus: http.StatusNotFound},
+		{name: "store error", err: assert.AnError, expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewJobHandler(mockRepo)
+
+			var retried *models.Job
+			if tt.err == nil {
+				retried = &models.Job{ID: "j1", Type: models.JobPublishEvent, Status: models.JobPending}
+			}
+			mockRepo.On("RetryJob", mock.Anything, "j1", mock.Anything).Return(retried, tt.err)
+
+			r := setupAdminUserTestRouter(jobOrganiser)
+			r.POST("/admin/jobs/:id/retry", handler.Retry)
+
+			req, _ := http.NewRequest("POST", "/admin/jobs/j1/retry", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response models.Job
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, models.JobPending, response.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"ai-india-workshop-backend/internal/forms"
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/problem"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegistrationFormHandler manages the workshop's custom registration
+// questions
+type RegistrationFormHandler struct {
+	repo repository.RepositoryInterface
+}
+
+func NewRegistrationFormHandler(repo repository.RepositoryInterface) *RegistrationFormHandler {
+	return &RegistrationFormHandler{repo: repo}
+}
+
+func (h *RegistrationFormHandler) Get(c *gin.Context) {
+	form, err := h.repo.GetRegistrationForm(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registration form"})
+		return
+	}
+	c.JSON(http.StatusOK, form)
+}
+
+// Update replaces the form's questions. Answers already given to questions
+// that are removed stay on the attendees and in exports.
+func (h *RegistrationFormHandler) Update(c *gin.Context) {
+	var req struct {
+		Fields []models.FormField `json:"fields"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+	if req.Fields == nil {
+		req.Fields = []models.FormField{}
+	}
+
+	if err := forms.ValidateFields(req.Fields); err != nil {
+		respondFormError(c, err)
+		return
+	}
+
+	form := &models.RegistrationForm{Fields: req.Fields, UpdatedAt: time.Now()}
+	if admin := middleware.CurrentAdmin(c); admin != nil {
+		form.UpdatedBy = admin.Email
+	}
+	if err := h.repo.SaveRegistrationForm(c.Request.Context(), form); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save registration form"})
+		return
+	}
+
+	c.JSON(http.StatusOK, form)
+}
+
+// respondFormError reports an invalid form or answer, naming the field when
+// there is one
+func respondFormError(c *gin.Context, err error) {
+	var validationErr *forms.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message, "field": validationErr.Field})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRegistrationFormHandler_Update(t *testing.T) {
+	tests := []struct {
+		name           string
+		fields         []models.FormField
+		repoError      error
+		expectedStatus int
+		expectSave     bool
+	}{
+		{
+			name: "valid form",
+			fields: []models.FormField{
+				{ID: "company", Label: " Company ", Type: models.FieldTypeText, Required: true},
+				{ID: "tshirt", Label: "T-shirt size", Type: models.FieldTypeSelect, Options: []string{"S", "M", "L"}},
+			},
+			expectedStatus: http.StatusOK,
+			expectSave:     true,
+		},
+		{
+			name:           "no questions",
+			fields:         nil,
+			expectedStatus: http.StatusOK,
+			expectSave:     true,
+		},
+		{
+			name:           "invalid pattern",
+			fields:         []models.FormField{{ID: "company", Label: "Company", Type: models.FieldTypeText, Pattern: "(["}},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "repository error",
+			fields:         []models.FormField{{ID: "company", Label: "Company", Type: models.FieldTypeText}},
+			repoError:      assert.AnError,
+			expectedStatus: http.StatusInternalServerError,
+			expectSave:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewRegistrationFormHandler(mockRepo)
+			if tt.expectSave {
+				mockRepo.On("SaveRegistrationForm", mock.Anything, mock.MatchedBy(func(form *models.RegistrationForm) bool {
+					return len(form.Fields) == len(tt.fields) && form.Fields != nil && form.UpdatedBy == "owner@example.com"
+				})).Return(tt.repoError)
+			}
+
+			r := setupAdminUserTestRouter(&models.AdminUser{ID: "user-1", Email: "owner@example.com"})
+			r.PUT("/admin/registration-form", handler.Update)
+
+			jsonBody, _ := json.Marshal(map[string]interface{}{"fields": tt.fields})
+			req := httptest.NewRequest("PUT", "/admin/registration-form", bytes.NewBuffer(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var form models.RegistrationForm
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &form))
+				if len(tt.fields) > 0 {
+					assert.Equal(t, "Company", form.Fields[0].Label)
+				}
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/problem"
 	"ai-india-workshop-backend/internal/repository"
 
 	"github.com/gin-gonic/gin"
@@ -62,7 +64,7 @@ func (h *SessionHandler) GetAll(c *gin.Context) {
 func (h *SessionHandler) Create(c *gin.Context) {
 	var session models.Session
 	if err := c.ShouldBindJSON(&session); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.BindError(c, err)
 		return
 	}
 
@@ -78,11 +80,15 @@ func (h *SessionHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 	var session models.Session
 	if err := c.ShouldBindJSON(&session); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.BindError(c, err)
 		return
 	}
 
 	if err := h.repo.UpdateSession(c.Request.Context(), id, &session); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update session"})
 		return
 	}
@@ -94,10 +100,13 @@ func (h *SessionHandler) Update(c *gin.Context) {
 func (h *SessionHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	if err := h.repo.DeleteSession(c.Request.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete session"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Session deleted successfully"})
 }
-
@@ -312,6 +312,12 @@ func TestSessionHandler_Delete(t *testing.T) {
 			repoError:      nil,
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:           "already deleted or missing",
+			id:             "123",
+			repoError:      repository.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
 		{
 			name:           "repository error",
 			id:             "123",
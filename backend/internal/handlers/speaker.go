@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/problem"
 	"ai-india-workshop-backend/internal/repository"
 
 	"github.com/gin-gonic/gin"
@@ -33,7 +35,7 @@ func (h *SpeakerHandler) GetAll(c *gin.Context) {
 func (h *SpeakerHandler) Create(c *gin.Context) {
 	var speaker models.Speaker
 	if err := c.ShouldBindJSON(&speaker); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.BindError(c, err)
 		return
 	}
 
@@ -49,11 +51,15 @@ func (h *SpeakerHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 	var speaker models.Speaker
 	if err := c.ShouldBindJSON(&speaker); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.BindError(c, err)
 		return
 	}
 
 	if err := h.repo.UpdateSpeaker(c.Request.Context(), id, &speaker); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update speaker"})
 		return
 	}
@@ -65,10 +71,13 @@ func (h *SpeakerHandler) Update(c *gin.Context) {
 func (h *SpeakerHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	if err := h.repo.DeleteSpeaker(c.Request.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete speaker"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Speaker deleted successfully"})
 }
-
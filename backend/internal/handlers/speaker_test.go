@@ -274,6 +274,12 @@ func TestSpeakerHandler_Delete(t *testing.T) {
 			repoError:      nil,
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:           "already deleted or missing",
+			id:             "123",
+			repoError:      repository.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
 		{
 			name:           "repository error",
 			id:             "123",
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	repo repository.RepositoryInterface
+}
+
+func NewTrashHandler(repo repository.RepositoryInterface) *TrashHandler {
+	return &TrashHandler{repo: repo}
+}
+
+func (h *TrashHandler) GetAll(c *gin.Context) {
+	trash, err := h.repo.GetTrash(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		return
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
+
+func (h *TrashHandler) Restore(c *gin.Context) {
+	resourceType := c.Param("type")
+	id := c.Param("id")
+	if err := h.repo.RestoreFromTrash(c.Request.Context(), resourceType, id); err != nil {
+		h.respondError(c, err, "Failed to restore item")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item restored successfully"})
+}
+
+func (h *TrashHandler) Purge(c *gin.Context) {
+	resourceType := c.Param("type")
+	id := c.Param("id")
+	if err := h.repo.PurgeFromTrash(c.Request.Context(), resourceType, id); err != nil {
+		h.respondError(c, err, "Failed to purge item")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item permanently deleted"})
+}
+
+func (h *TrashHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrUnknownResourceType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown resource type"})
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found in trash"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
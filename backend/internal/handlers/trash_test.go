@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTrashTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return gin.New()
+}
+
+func TestTrashHandler_GetAll(t *testing.T) {
+	deletedAt := time.Now()
+	tests := []struct {
+		name           string
+		trash          *models.Trash
+		repoError      error
+		expectedStatus int
+	}{
+		{
+			name: "successful retrieval",
+			trash: &models.Trash{
+				Attendees: []*models.Attendee{{ID: "1", Name: "John Doe", DeletedAt: &deletedAt}},
+				Speakers:  []*models.Speaker{},
+				Sessions:  []*models.Session{{ID: "2", Title: "Keynote", DeletedAt: &deletedAt}},
+			},
+			repoError:      nil,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "repository error",
+			trash:          nil,
+			repoError:      assert.AnError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewTrashHandler(mockRepo)
+
+			mockRepo.On("GetTrash", mock.Anything).Return(tt.trash, tt.repoError)
+
+			r := setupTrashTestRouter()
+			r.GET("/admin/trash", handler.GetAll)
+
+			req, _ := http.NewRequest("GET", "/admin/trash", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var trash models.Trash
+				err := json.Unmarshal(w.Body.Bytes(), &trash)
+				require.NoError(t, err)
+				assert.Len(t, trash.Attendees, 1)
+				assert.Len(t, trash.Sessions, 1)
+				assert.NotNil(t, trash.Attendees[0].DeletedAt)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTrashHandler_Restore(t *testing.T) {
+	tests := []struct {
+		name           string
+		resourceType   string
+		id             string
+		repoError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful restore",
+			resourceType:   models.ResourceSpeakers,
+			id:             "123",
+			repoError:      nil,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not in trash",
+			resourceType:   models.ResourceSpeakers,
+			id:             "123",
+			repoError:      repository.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unknown resource type",
+			resourceType:   "workshops",
+			id:             "123",
+			repoError:      repository.ErrUnknownResourceType,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "repository error",
+			resourceType:   models.ResourceAttendees,
+			id:             "123",
+			repoError:      assert.AnError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewTrashHandler(mockRepo)
+
+			mockRepo.On("RestoreFromTrash", mock.Anything, tt.resourceType, tt.id).Return(tt.repoError)
+
+			r := setupTrashTestRouter()
+			r.POST("/admin/trash/:type/:id/restore", handler.Restore)
+
+			req, _ := http.NewRequest("POST", "/admin/trash/"+tt.resourceType+"/"+tt.id+"/restore", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]string
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				require.NoError(t, err)
+				assert.Equal(t, "Item restored successfully", response["message"])
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTrashHandler_Purge(t *testing.T) {
+	tests := []struct {
+		name           string
+		resourceType   string
+		id             string
+		repoError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful purge",
+			resourceType:   models.ResourceAttendees,
+			id:             "123",
+			repoError:      nil,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not in trash",
+			resourceType:   models.ResourceSessions,
+			id:             "123",
+			repoError:      repository.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "repository error",
+			resourceType:   models.ResourceSessions,
+			id:             "123",
+			repoError:      assert.AnError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewTrashHandler(mockRepo)
+
+			mockRepo.On("PurgeFromTrash", mock.Anything, tt.resourceType, tt.id).Return(tt.repoError)
+
+			r := setupTrashTestRouter()
+			r.DELETE("/admin/trash/:type/:id", handler.Purge)
+
+			req, _ := http.NewRequest("DELETE", "/admin/trash/"+tt.resourceType+"/"+tt.id, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
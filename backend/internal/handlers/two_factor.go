@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/problem"
+	"ai-india-workshop-backend/internal/repository"
+	"ai-india-workshop-backend/internal/totp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler manages TOTP enrolment for the signed-in admin
+type TwoFactorHandler struct {
+	repo   repository.RepositoryInterface
+	policy auth.TwoFactorPolicy
+	issuer string
+}
+
+func NewTwoFactorHandler(repo repository.RepositoryInterface, policy auth.TwoFactorPolicy, issuer string) *TwoFactorHandler {
+	return &TwoFactorHandler{repo: repo, policy: policy, issuer: issuer}
+}
+
+// Status reports whether 2FA is enabled and whether the admin's role requires it
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	user := middleware.CurrentAdmin(c)
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                user.TwoFactorEnabled,
+		"required":               h.policy.Required(user.Role),
+		"recoveryCodesRemaining": len(user.RecoveryCodeHashes),
+	})
+}
+
+// Setup issues a new secret and the provisioning URI to show as a QR code.
+// 2FA is not enabled until the secret is confirmed with Enable.
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	user := middleware.CurrentAdmin(c)
+	if user.TwoFactorEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	user.PendingTOTPSecret = secret
+	if !h.save(c, user) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":          secret,
+		"provisioningUri": totp.ProvisioningURI(h.issuer, user.Email, secret),
+	})
+}
+
+// Enable confirms the pending secret with a code from the authenticator app
+// and returns the recovery codes, which are only shown once
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	user := middleware.CurrentAdmin(c)
+	if user.TwoFactorEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if user.PendingTOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start two-factor setup first"})
+		return
+	}
+
+	step, ok := totp.Validate(user.PendingTOTPSecret, req.Code, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	codes, hashes, err := auth.GenerateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	user.TwoFactorEnabled = true
+	user.TOTPSecret = user.PendingTOTPSecret
+	user.PendingTOTPSecret = ""
+	user.LastTOTPStep = step
+	user.RecoveryCodeHashes = hashes
+	if !h.save(c, user) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled", "recoveryCodes": codes})
+}
+
+// Disable turns 2FA off after checking the password and a current code.
+// Admins whose role requires 2FA cannot disable it.
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req struct {
+		Password string `json:"password" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	user := middleware.CurrentAdmin(c)
+	if !user.TwoFactorEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if h.policy.Required(user.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for your role"})
+		return
+	}
+	if !auth.CheckPassword(user.PasswordHash, req.Password) || !auth.VerifySecondFactor(user, req.Code, time.Now()) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password or authentication code"})
+		return
+	}
+
+	clearTwoFactor(user)
+	if !h.save(c, user) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a current code
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+
+	user := middleware.CurrentAdmin(c)
+	if !user.TwoFactorEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if !auth.VerifySecondFactor(user, req.Code, time.Now()) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	codes, hashes, err := auth.GenerateRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	user.RecoveryCodeHashes = hashes
+	if !h.save(c, user) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+// Reset removes another admin's 2FA enrolment, e.g. after they lose their
+// device and recovery codes. They will have to enrol again if their role
+// requires it.
+func (h *TwoFactorHandler) Reset(c *gin.Context) {
+	user, err := h.repo.GetAdminUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Admin user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch admin user"})
+		return
+	}
+
+	clearTwoFactor(user)
+	if !h.save(c, user) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset"})
+}
+
+func (h *TwoFactorHandler) save(c *gin.Context, user *models.AdminUser) bool {
+	user.UpdatedAt = time.Now()
+	if err := h.repo.UpdateAdminUser(c.Request.Context(), user.ID, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin user"})
+		return false
+	}
+	return true
+}
+
+func clearTwoFactor(user *models.AdminUser) {
+	user.TwoFactorEnabled = false
+	user.TOTPSecret = ""
+	user.PendingTOTPSecret = ""
+	user.RecoveryCodeHashes = nil
+	user.LastTOTPStep = 0
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+	"ai-india-workshop-backend/internal/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoFactorHandler_SetupAndEnable(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewTwoFactorHandler(mockRepo, auth.TwoFactorPolicy{}, "Workshop")
+	mockRepo.On("UpdateAdminUser", mock.Anything, "user-1", mock.Anything).Return(nil)
+
+	user := &models.AdminUser{ID: "user-1", Email: "admin@example.com", Role: auth.RoleOrganiser}
+	r := setupAdminUserTestRouter(user)
+	r.POST("/admin/me/2fa/setup", handler.Setup)
+	r.POST("/admin/me/2fa/enable", handler.Enable)
+
+	req, _ := http.NewRequest("POST", "/admin/me/2fa/setup", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var setup map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &setup))
+	assert.Equal(t, user.PendingTOTPSecret, setup["secret"])
+	assert.Contains(t, setup["provisioningUri"], "otpauth://totp/Workshop:admin@example.com?")
+	assert.False(t, user.TwoFactorEnabled)
+
+	// A wrong code leaves 2FA disabled
+	req, _ = http.NewRequest("POST", "/admin/me/2fa/enable", bytes.NewBufferString(`{"code":"000000"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, user.TwoFactorEnabled)
+
+	code, err := totp.Code(setup["secret"], time.Now())
+	require.NoError(t, err)
+	req, _ = http.NewRequest("POST", "/admin/me/2fa/enable", bytes.NewBufferString(`{"code":"`+code+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var enabled struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enabled))
+	assert.Len(t, enabled.RecoveryCodes, auth.RecoveryCodeCount)
+	assert.True(t, user.TwoFactorEnabled)
+	assert.Equal(t, setup["secret"], user.TOTPSecret)
+	assert.Empty(t, user.PendingTOTPSecret)
+	assert.Len(t, user.RecoveryCodeHashes, auth.RecoveryCodeCount)
+
+	// Setup cannot be restarted while enabled
+	req, _ = http.NewRequest("POST", "/admin/me/2fa/setup", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestTwoFactorHandler_Disable(t *testing.T) {
+	hash, err := auth.HashPassword("correct-password")
+	require.NoError(t, err)
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	requireOwners, err := auth.ParseTwoFactorPolicy(auth.RoleOwner)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		role           string
+		password       string
+		code           string
+		expectedStatus int
+	}{
+		{
+			name:           "valid password and recovery code",
+			role:           auth.RoleOrganiser,
+			password:       "correct-password",
+			code:           "aaaaa-bbbbb",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wrong password",
+			role:           auth.RoleOrganiser,
+			password:       "wrong-password",
+			code:           "aaaaa-bbbbb",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong code",
+			role:           auth.RoleOrganiser,
+			password:       "correct-password",
+			code:           "000000",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "role requires 2FA",
+			role:           auth.RoleOwner,
+			password:       "correct-password",
+			code:           "aaaaa-bbbbb",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewTwoFactorHandler(mockRepo, requireOwners, "Workshop")
+			if tt.expectedStatus == http.StatusOK {
+				mockRepo.On("UpdateAdminUser", mock.Anything, "user-1", mock.Anything).Return(nil)
+			}
+
+			user := &models.AdminUser{
+				ID:                 "user-1",
+				Role:               tt.role,
+				PasswordHash:       hash,
+				TwoFactorEnabled:   true,
+				TOTPSecret:         secret,
+				RecoveryCodeHashes: []string{auth.HashRecoveryCode("aaaaa-bbbbb")},
+			}
+			r := setupAdminUserTestRouter(user)
+			r.POST("/admin/me/2fa/disable", handler.Disable)
+
+			body, _ := json.Marshal(map[string]string{"password": tt.password, "code": tt.code})
+			req, _ := http.NewRequest("POST", "/admin/me/2fa/disable", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedStatus != http.StatusOK, user.TwoFactorEnabled)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTwoFactorHandler_Reset(t *testing.T) {
+	tests := []struct {
+		name           string
+		repoError      error
+		expectedStatus int
+	}{
+		{name: "resets enrolment", expectedStatus: http.StatusOK},
+		{name: "not found", repoError: repository.ErrNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewTwoFactorHandler(mockRepo, auth.TwoFactorPolicy{}, "Workshop")
+
+			if tt.repoError != nil {
+				mockRepo.On("GetAdminUser", mock.Anything, "user-2").Return(nil, tt.repoError)
+			} else {
+				mockRepo.On("GetAdminUser", mock.Anything, "user-2").Return(&models.AdminUser{
+					ID: "user-2", TwoFactorEnabled: true, TOTPSecret: "SECRET", RecoveryCodeHashes: []string{"hash"},
+				}, nil)
+				mockRepo.On("UpdateAdminUser", mock.Anything, "user-2", mock.MatchedBy(func(user *models.AdminUser) bool {
+					return !user.TwoFactorEnabled && user.TOTPSecret == "" && len(user.RecoveryCodeHashes) == 0
+				})).Return(nil)
+			}
+
+			r := setupAdminUserTestRouter(&models.AdminUser{ID: "user-1", Role: auth.RoleOwner})
+			r.DELETE("/admin/users/:id/2fa", handler.Reset)
+
+			req, _ := http.NewRequest("DELETE", "/admin/users/user-2/2fa", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
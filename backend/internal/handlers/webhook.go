@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ai-india-workshop-backend/internal/middleware"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/problem"
+	"ai-india-workshop-backend/internal/repository"
+	"ai-india-workshop-backend/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultDeliveryLimit applies when the delivery log is fetched without a limit
+	defaultDeliveryLimit = 50
+	// maxDeliveryLimit caps the number of deliveries returned at once
+	maxDeliveryLimit = 200
+)
+
+// WebhookHandler manages the webhooks that are told about registrations,
+// cancellations, check-ins and agenda changes, and their delivery log
+type WebhookHandler struct {
+	repo repository.RepositoryInterface
+}
+
+func NewWebhookHandler(repo repository.RepositoryInterface) *WebhookHandler {
+	return &WebhookHandler{repo: repo}
+}
+
+type webhookRequest struct {
+	URL         string   `json:"url" binding:"required,url"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	Description string   `json:"description" binding:"max=200"`
+	// Active defaults to true
+	Active *bool `json:"active"`
+	// Secret is generated when left out of a new webhook
+	Secret string `json:"secret"`
+	// RotateSecret replaces an existing webhook's secret with a generated one
+	RotateSecret bool `json:"rotateSecret"`
+}
+
+// GetAll lists every webhook
+func (h *WebhookHandler) GetAll(c *gin.Context) {
+	webhooks, err := h.repo.GetAllWebhooks(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// Create adds a webhook and returns its secret, which is not shown again
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+	if !validWebhookRequest(c, req) {
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = webhook.NewSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook secret"})
+			return
+		}
+	}
+
+	now := time.Now()
+	hook := &models.Webhook{
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      req.Events,
+		Secret:      secret,
+		Active:      req.Active == nil || *req.Active,
+		CreatedBy:   middleware.CurrentAdmin(c).Email,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := h.repo.CreateWebhook(c.Request.Context(), hook); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"webhook": hook, "secret": secret})
+}
+
+// Update replaces a webhook's settings. The secret is kept unless a new one
+// is given or rotateSecret is set, in which case it is returned once.
+func (h *WebhookHandler) Update(c *gin.Context) {
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.BindError(c, err)
+		return
+	}
+	if !validWebhookRequest(c, req) {
+		return
+	}
+
+	hook, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	secret := req.Secret
+	if req.RotateSecret {
+		var err error
+		if secret, err = webhook.NewSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook secret"})
+			return
+		}
+	}
+
+	hook.URL = req.URL
+	hook.Description = req.Description
+	hook.Events = req.Events
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+	if secret != "" {
+		hook.Secret = secret
+	}
+	hook.UpdatedAt = time.Now()
+	if err := h.repo.UpdateWebhook(c.Request.Context(), hook.ID, hook); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	response := gin.H{"webhook": hook}
+	if secret != "" {
+		response["secret"] = secret
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Delete removes a webhook. Deliveries still queued for it fail.
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	if err := h.repo.DeleteWebhook(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// GetDeliveries returns a webhook's delivery log, newest first, optionally
+// only those with a status
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	hook, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	query := models.WebhookDeliveryQuery{WebhookID: hook.ID, Status: c.Query("status"), Limit: defaultDeliveryLimit}
+	switch query.Status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, succeeded or failed", "field": "status"})
+		return
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxDeliveryLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200", "field": "limit"})
+			return
+		}
+		query.Limit = limit
+	}
+
+	deliveries, err := h.repo.GetWebhookDeliveries(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// Replay queues a delivery to be sent again. The payload, including the
+// event ID receivers use to spot repeats, is the same as the original's.
+func (h *WebhookHandler) Replay(c *gin.Context) {
+	hook, ok := h.load(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.repo.GetWebhookDelivery(c.Request.Context(), c.Param("deliveryId"))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook delivery"})
+		return
+	}
+	if err != nil || delivery.WebhookID != hook.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook delivery not found"})
+		return
+	}
+
+	replay, err := webhook.Replay(c.Request.Context(), h.repo, delivery, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay webhook delivery"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, replay)
+}
+
+// load fetches the :id webhook, responding 404 if there is none
+func (h *WebhookHandler) load(c *gin.Context) (*models.Webhook, bool) {
+	hook, err := h.repo.GetWebhook(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook"})
+		return nil, false
+	}
+	return hook, true
+}
+
+// validWebhookRequest checks what the binding tags cannot, responding 400
+// if the request is invalid
+func validWebhookRequest(c *gin.Context, req webhookRequest) bool {
+	if !webhook.ValidURL(req.URL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url must use https, or http to localhost", "field": "url"})
+		return false
+	}
+	for _, event := range req.Events {
+		if !webhook.ValidEvent(event) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown event " + event, "field": "events"})
+			return false
+		}
+	}
+	if req.Secret != "" && len(req.Secret) < webhook.MinSecretLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "secret must be at least 16 characters", "field": "secret"})
+		return false
+	}
+	return true
+}
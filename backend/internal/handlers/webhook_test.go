@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var webhookOwner = &models.AdminUser{ID: "owner-1", Email: "owner@example.com", Role: auth.RoleOwner}
+
+func TestWebhookHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedSecret string
+	}{
+		{
+			name:           "generated secret",
+			body:           `{"url":"https://crm.example.com/hooks","events":["attendee.registered","attendee.cancelled"]}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "chosen secret",
+			body:           `{"url":"https://crm.example.com/hooks","events":["agenda.changed"],"secret":"a-long-enough-secret"}`,
+			expectedStatus: http.StatusCreated,
+			expectedSecret: "a-long-enough-secret",
+		},
+		{
+			name:           "local receiver over http",
+			body:           `{"url":"http://localhost:9000/hooks","events":["attendee.checked_in"]}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "plain http",
+			body:           `{"url":"http://crm.example.com/hooks","events":["attendee.registered"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown event",
+			body:           `{"url":"https://crm.example.com/hooks","events":["attendee.deleted"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "no events",
+			body:           `{"url":"https://crm.example.com/hooks","events":[]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "short secret",
+			body:           `{"url":"https://crm.example.com/hooks","events":["attendee.registered"],"secret":"short"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewWebhookHandler(mockRepo)
+
+			var stored *models.Webhook
+			mockRepo.On("CreateWebhook", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				stored = args.Get(1).(*models.Webhook)
+				stored.ID = "wh1"
+			}).Return(nil).Maybe()
+
+			r := setupAdminUserTestRouter(webhookOwner)
+			r.POST("/admin/webhooks", handler.Create)
+
+			req, _ := http.NewRequest("POST", "/admin/webhooks", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusCreated {
+				assert.Nil(t, stored)
+				return
+			}
+
+			var response struct {
+				Webhook map[string]any `json:"webhook"`
+				Secret  string         `json:"secret"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.NotNil(t, stored)
+			assert.Equal(t, stored.Secret, response.Secret)
+			if tt.expectedSecret != "" {
+				assert.Equal(t, tt.expectedSecret, response.Secret)
+			} else {
+				assert.True(t, strings.HasPrefix(response.Secret, "whsec_"))
+			}
+			assert.True(t, stored.Active)
+			assert.Equal(t, "owner@example.com", stored.CreatedBy)
+			assert.Equal(t, "wh1", response.Webhook["id"])
+			assert.NotContains(t, response.Webhook, "secret")
+		})
+	}
+}
+
+func TestWebhookHandler_Update(t *testing.T) {
+	existing := func() *models.Webhook {
+		return &models.Webhook{ID: "wh1", URL: "https://crm.example.com/hooks", Events: []string{"attendee.registered"}, Secret: "whsec_original", Active: true}
+	}
+
+	tests := []struct {
+		name         string
+		body         string
+		expectSecret bool
+		check        func(t *testing.T, hook *models.Webhook)
+	}{
+		{
+			name: "keeps secret",
+			body: `{"url":"https://crm.example.com/v2/hooks","events":["attendee.registered"],"active":false}`,
+			check: func(t *testing.T, hook *models.Webhook) {
+				assert.Equal(t, "https://crm.example.com/v2/hooks", hook.URL)
+				assert.Equal(t, "whsec_original", hook.Secret)
+				assert.False(t, hook.Active)
+			},
+		},
+		{
+			name:         "rotates secret",
+			body:         `{"url":"https://crm.example.com/hooks","events":["attendee.registered"],"rotateSecret":true}`,
+			expectSecret: true,
+			check: func(t *testing.T, hook *models.Webhook) {
+				assert.NotEqual(t, "whsec_original", hook.Secret)
+				assert.True(t, hook.Active)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewWebhookHandler(mockRepo)
+
+			var stored *models.Webhook
+			mockRepo.On("GetWebhook", mock.Anything, "wh1").Return(existing(), nil)
+			mockRepo.On("UpdateWebhook", mock.Anything, "wh1", mock.Anything).Run(func(args mock.Arguments) {
+				stored = args.Get(2).(*models.Webhook)
+			}).Return(nil)
+
+			r := setupAdminUserTestRouter(webhookOwner)
+			r.PUT("/admin/webhooks/:id", handler.Update)
+
+			req, _ := http.NewRequest("PUT", "/admin/webhooks/wh1", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			require.NotNil(t, stored)
+			tt.check(t, stored)
+
+			var response map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectSecret {
+				assert.Equal(t, stored.Secret, response["secret"])
+			} else {
+				assert.NotContains(t, response, "secret")
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_GetDeliveries(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	handler := NewWebhookHandler(mockRepo)
+
+	mockRepo.On("GetWebhook", mock.Anything, "wh1").Return(&models.Webhook{ID: "wh1"}, nil)
+	mockRepo.On("GetWebhook", mock.Anything, "missing").Return(nil, repository.ErrNotFound)
+	mockRepo.On("GetWebhookDeliveries", mock.Anything, models.WebhookDeliveryQuery{WebhookID: "wh1", Status: "failed", Limit: 10}).
+		Return([]*models.WebhookDelivery{{ID: "d1", WebhookID: "wh1", Status: "failed"}}, nil)
+
+	r := setupAdminUserTestRouter(webhookOwner)
+	r.GET("/admin/webhooks/:id/deliveries", handler.GetDeliveries)
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+	}{
+		{path: "/admin/webhooks/wh1/deliveries?status=failed&limit=10", expectedStatus: http.StatusOK},
+		{path: "/admin/webhooks/wh1/deliveries?status=lost", expectedStatus: http.StatusBadRequest},
+		{path: "/admin/webhooks/wh1/deliveries?limit=1000", expectedStatus: http.StatusBadRequest},
+		{path: "/admin/webhooks/missing/deliveries", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestWebhookHandler_Replay(t *testing.T) {
+	original := &models.WebhookDelivery{
+		ID:        "d1",
+		WebhookID: "wh1",
+		EventID:   "evt_1",
+		Event:     models.WebhookAttendeeRegistered,
+		Payload:   `{"id":"evt_1"}`,
+		Status:    models.WebhookDeliveryFailed,
+		Attempts:  10,
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "replays", path: "/admin/webhooks/wh1/deliveries/d1/replay", expectedStatus: http.StatusAccepted},
+		{name: "another webhook's delivery", path: "/admin/webhooks/wh2/deliveries/d1/replay", expectedStatus: http.StatusNotFound},
+		{name: "unknown delivery", path: "/admin/webhooks/wh1/deliveries/d9/replay", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			handler := NewWebhookHandler(mockRepo)
+
+			mockRepo.On("GetWebhook", mock.Anything, "wh1").Return(&models.Webhook{ID: "wh1"}, nil).Maybe()
+			mockRepo.On("GetWebhook", mock.Anything, "wh2").Return(&models.Webhook{ID: "wh2"}, nil).Maybe()
+			mockRepo.On("GetWebhookDelivery", mock.Anything, "d1").Return(original, nil).Maybe()
+			mockRepo.On("GetWebhookDelivery", mock.Anything, "d9").Return(nil, repository.ErrNotFound).Maybe()
+
+			var queued []*models.WebhookDelivery
+			mockRepo.On("CreateWebhookDeliveries", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				queued = args.Get(1).([]*models.WebhookDelivery)
+			}).Return(nil).Maybe()
+
+			r := setupAdminUserTestRouter(webhookOwner)
+			r.POST("/admin/webhooks/:id/deliveries/:deliveryId/replay", handler.Replay)
+
+			req, _ := http.NewRequest("POST", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusAccepted {
+				assert.Nil(t, queued)
+				return
+			}
+
+			require.Len(t, queued, 1)
+			assert.Equal(t, "evt_1", queued[0].EventID)
+			assert.Equal(t, original.Payload, queued[0].Payload)
+			assert.Equal(t, "d1", queued[0].ReplayOf)
+			assert.Equal(t, models.WebhookDeliveryPending, queued[0].Status)
+			assert.Equal(t, 0, queued[0].Attempts)
+			assert.NotNil(t, queued[0].NextAttemptAt)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+// Package ids generates random record IDs in the same alphabet and length
+// as Firestore's automatic document IDs
+package ids
+
+import "crypto/rand"
+
+const (
+	alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	length   = 20
+
+	// unbiased is the largest multiple of len(alphabet) a byte can hold.
+	// Bytes from it up are discarded so that every character is equally
+	// likely.
+	unbiased = 256 - 256%len(alphabet)
+)
+
+// New returns a random 20-character alphanumeric ID
+func New() (string, error) {
+	id := make([]byte, 0, length)
+	buf := make([]byte, length)
+	for len(id) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) < unbiased && len(id) < length {
+				id = append(id, alphabet[int(b)%len(alphabet)])
+			}
+		}
+	}
+	return string(id), nil
+}
@@ -0,0 +1,29 @@
+package ids
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	seen := map[string]bool{}
+	counts := map[rune]int{}
+	for i := 0; i < 1000; i++ {
+		id, err := New()
+		require.NoError(t, err)
+		assert.Regexp(t, `^[A-Za-z0-9]{20}$`, id)
+		assert.False(t, seen[id], "%s generated twice", id)
+		seen[id] = true
+		for _, c := range id {
+			counts[c]++
+		}
+	}
+
+	// 20,000 characters over 62 is about 323 each; a character that is
+	// never or rarely drawn means part of the alphabet is unreachable
+	for _, c := range alphabet {
+		assert.Greater(t, counts[c], 200, "%q drawn %d times", c, counts[c])
+	}
+}
@@ -0,0 +1,292 @@
+// Package jobs runs the background work queued in the jobs collection,
+// including the outbox jobs written alongside attendee and agenda changes.
+// Every server instance can run a Runner: a job is leased before it runs,
+// so only one instance runs it at a time. Failed jobs are retried with
+// exponential backoff and end up in a dead-letter queue, as failed jobs,
+// once they run out of attempts.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+)
+
+const (
+	// DefaultWorkers is how many jobs an instance runs at once
+	DefaultWorkers = 4
+	// DefaultPollInterval is how often the queue is checked for due jobs
+	DefaultPollInterval = 5 * time.Second
+	// DefaultLease is how long a job is held by the instance running it.
+	// A job still unfinished when its lease runs out is run again.
+	DefaultLease = time.Minute
+	// DefaultMaxAttempts is how many times a job is tried before it fails;
+	// with the backoff below the last try is about twenty minutes after
+	// the first
+	DefaultMaxAttempts = 8
+)
+
+const (
+	// batchSize is the most jobs claimed per poll
+	batchSize = 50
+	// firstRetryDelay doubles after each failed attempt up to maxRetryDelay
+	firstRetryDelay = 10 * time.Second
+	maxRetryDelay   = time.Hour
+	// succeededRetention is how long succeeded jobs are kept for inspection
+	succeededRetention = 7 * 24 * time.Hour
+)
+
+// Handler does the work of one type of job. An error retries the job
+// later, unless it is Permanent.
+type Handler func(ctx context.Context, job *models.Job) error
+
+// Config tunes the Runner. Zero values get the defaults above.
+type Config struct {
+	Workers      int
+	PollInterval time.Duration
+	Lease        time.Duration
+	MaxAttempts  int
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error that retrying cannot fix, such as a payload that
+// cannot be decoded. The job fails straight away.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err, or an error it wraps, was marked with
+// Permanent
+func IsPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+// Runner claims due jobs and runs them on a pool of workers
+type Runner struct {
+	repo        repository.RepositoryInterface
+	handlers    map[string]Handler
+	workers     int
+	interval    time.Duration
+	lease       time.Duration
+	maxAttempts int
+	now         func() time.Time
+
+	// instance and claims make every lease ID unique, so a job whose lease
+	// ran out cannot be recorded by the worker that lost it
+	instance string
+	claims   atomic.Uint64
+}
+
+func NewRunner(repo repository.RepositoryInterface, cfg Config) *Runner {
+	r := &Runner{
+		repo:        repo,
+		handlers:    map[string]Handler{},
+		workers:     cfg.Workers,
+		interval:    cfg.PollInterval,
+		lease:       cfg.Lease,
+		maxAttempts: cfg.MaxAttempts,
+		now:         time.Now,
+		instance:    instanceID(),
+	}
+	if r.workers <= 0 {
+		r.workers = DefaultWorkers
+	}
+	if r.interval <= 0 {
+		r.interval = DefaultPollInterval
+	}
+	if r.lease <= 0 {
+		r.lease = DefaultLease
+	}
+	if r.maxAttempts <= 0 {
+		r.maxAttempts = DefaultMaxAttempts
+	}
+	return r
+}
+
+// Handle registers the handler for a type of job. Jobs of a type without a
+// handler fail.
+func (r *Runner) Handle(jobType string, handler Handler) {
+	r.handlers[jobType] = handler
+}
+
+// Run runs due jobs straight away and then once per interval until the
+// context is cancelled
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RunDue(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Error running jobs", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs the due jobs on the worker pool and returns how many were
+// run once they have all finished. A job whose outcome could not be
+// recorded is run again once its lease runs out.
+func (r *Runner) RunDue(ctx context.Context) (int, error) {
+	due, err := r.repo.GetDueJobs(ctx, r.now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		ran  int
+		errs []error
+	)
+	slots := make(chan struct{}, r.workers)
+	for _, job := range due {
+		select {
+		case <-ctx.Done():
+		case slots <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			started, err := r.run(ctx, id)
+			mu.Lock()
+			defer mu.Unlock()
+			if started {
+				ran++
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("job %s: %w", id, err))
+			}
+		}(job.ID)
+	}
+	wg.Wait()
+	return ran, errors.Join(errs...)
+}
+
+// run claims a job, runs its handler and records the outcome, reporting
+// whether the handler was started
+func (r *Runner) run(ctx context.Context, id string) (bool, error) {
+	leaseID := fmt.Sprintf("%s/%d", r.instance, r.claims.Add(1))
+	now := r.now()
+	job, err := r.repo.ClaimJob(ctx, id, leaseID, now, now.Add(r.lease))
+	if errors.Is(err, repository.ErrNotFound) {
+		// Another instance got there first, or the job was retried or
+		// finished since it was listed
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	runErr := r.call(ctx, job)
+	if runErr != nil && ctx.Err() != nil {
+		// Interrupted by shutdown: another instance picks the job up once
+		// the lease runs out, without counting this as an attempt
+		return true, nil
+	}
+
+	r.record(job, runErr)
+	if job.Status == models.JobFailed {
+		slog.WarnContext(ctx, "Job failed", "jobId", job.ID, "type", job.Type, "attempts", job.Attempts, "error", job.LastError)
+	}
+	err = r.repo.UpdateJob(context.WithoutCancel(ctx), job, leaseID)
+	if errors.Is(err, repository.ErrLeaseLost) {
+		slog.WarnContext(ctx, "Job outlived its lease", "jobId", job.ID, "type", job.Type, "lease", r.lease.String())
+		return true, nil
+	}
+	return true, err
+}
+
+// call runs the job's handler, which has three quarters of the lease to
+// finish. A panic is an error like any other.
+func (r *Runner) call(ctx context.Context, job *models.Job) (err error) {
+	handler, ok := r.handlers[job.Type]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler for job type %s", job.Type))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.lease*3/4)
+	defer cancel()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// record notes the outcome of an attempt and schedules the next one
+func (r *Runner) record(job *models.Job, err error) {
+	now := r.now()
+	job.Attempts++
+	job.UpdatedAt = now
+	job.LeaseID = ""
+
+	switch {
+	case err == nil:
+		expires := now.Add(succeededRetention)
+		job.ExpiresAt = &expires
+		r.finish(job, models.JobSucceeded, "")
+	case IsPermanent(err) || job.Attempts >= r.maxAttempts:
+		r.finish(job, models.JobFailed, err.Error())
+	default:
+		next := now.Add(retryDelay(job.Attempts))
+		job.RunAt = &next
+		job.LastError = err.Error()
+	}
+}
+
+// finish takes a job off the queue
+func (r *Runner) finish(job *models.Job, status, message string) {
+	now := r.now()
+	job.Status = status
+	job.LastError = message
+	job.RunAt = nil
+	job.CompletedAt = &now
+}
+
+// retryDelay is how long to wait after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// instanceID names this process in lease IDs: the hostname, which is the
+// instance on Cloud Run, and a random suffix in case it is not unique
+func instanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "worker"
+	}
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return host + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,257 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQueue keeps jobs the way the store would, including the leases
+type fakeQueue struct {
+	*repository.MockRepository
+	mu   sync.Mutex
+	jobs map[string]*models.Job
+}
+
+func queue(jobs ...*models.Job) *fakeQueue {
+	q := &fakeQueue{MockRepository: new(repository.MockRepository), jobs: map[string]*models.Job{}}
+	for _, job := range jobs {
+		q.jobs[job.ID] = job
+	}
+	return q
+}
+
+func (q *fakeQueue) GetDueJobs(_ context.Context, now time.Time, _ int) ([]*models.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var due []*models.Job
+	for _, job := range q.jobs {
+		if job.RunAt != nil && !job.RunAt.After(now) {
+			copied := *job
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (q *fakeQueue) ClaimJob(_ context.Context, id, leaseID string, now, until time.Time) (*models.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job := q.jobs[id]
+	if job == nil || job.Status != models.JobPending || job.RunAt == nil || job.RunAt.After(now) {
+		return nil, repository.ErrNotFound
+	}
+	job.RunAt = &until
+	job.LeaseID = leaseID
+	claimed := *job
+	return &claimed, nil
+}
+
+func (q *fakeQueue) UpdateJob(_ context.Context, job *models.Job, leaseID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.jobs[job.ID].LeaseID != leaseID {
+		return repository.ErrLeaseLost
+	}
+	stored := *job
+	q.jobs[job.ID] = &stored
+	return nil
+}
+
+func (q *fakeQueue) job(id string) *models.Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.jobs[id]
+}
+
+func pendingJob(id, jobType string, now time.Time) *models.Job {
+	return &models.Job{ID: id, Type: jobType, Payload: "{}", Status: models.JobPending, RunAt: &now, CreatedAt: now, UpdatedAt: now}
+}
+
+// testRunner returns a runner whose clock is read from now
+func testRunner(q *fakeQueue, cfg Config, now *time.Time) *Runner {
+	runner := NewRunner(q, cfg)
+	runner.now = func() time.Time { return *now }
+	return runner
+}
+
+func TestRunner_RunsJobs(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	q := queue(pendingJob("j1", "test", now), pendingJob("j2", "test", now), pendingJob("j3", "test", now.Add(time.Minute)))
+	runner := testRunner(q, Config{Workers: 2}, &now)
+
+	var mu sync.Mutex
+	var handled []string
+	runner.Handle("test", func(ctx context.Context, job *models.Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, job.ID)
+		return nil
+	})
+
+	ran, err := runner.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, ran)
+	assert.ElementsMatch(t, []string{"j1", "j2"}, handled)
+
+	job := q.job("j1")
+	assert.Equal(t, models.JobSucceeded, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Nil(t, job.RunAt)
+	assert.Empty(t, job.LeaseID)
+	require.NotNil(t, job.CompletedAt)
+	require.NotNil(t, job.ExpiresAt)
+	assert.Equal(t, now.Add(succeededRetention), *job.ExpiresAt)
+
+	// The job that is not yet due was left alone
+	assert.Equal(t, models.JobPending, q.job("j3").Status)
+}
+
+func TestRunner_RetriesThenFails(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	q := queue(pendingJob("j1", "test", now))
+	runner := testRunner(q, Config{MaxAttempts: 3}, &now)
+	runner.Handle("test", func(context.Context, *models.Job) error { return errors.New("receiver is down") })
+
+	_, err := runner.RunDue(context.Background())
+	require.NoError(t, err)
+	job := q.job("j1")
+	assert.Equal(t, models.JobPending, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, "receiver is down", job.LastError)
+	assert.Equal(t, now.Add(10*time.Second), *job.RunAt)
+
+	// Not due again until the backoff has passed
+	ran, err := runner.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, ran)
+
+	now = now.Add(10 * time.Second)
+	_, err = runner.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, q.job("j1").Attempts)
+	assert.Equal(t, now.Add(20*time.Second), *q.job("j1").RunAt)
+
+	now = now.Add(20 * time.Second)
+	_, err = runner.RunDue(context.Background())
+	require.NoError(t, err)
+	job = q.job("j1")
+	assert.Equal(t, models.JobFailed, job.Status)
+	assert.Equal(t, 3, job.Attempts)
+	assert.Nil(t, job.RunAt)
+	assert.Nil(t, job.ExpiresAt)
+	assert.Equal(t, "receiver is down", job.LastError)
+}
+
+func TestRunner_PermanentErrorsFailStraightAway(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	q := queue(pendingJob("j1", "test", now), pendingJob("j2", "unknown", now), pendingJob("j3", "panics", now))
+	runner := testRunner(q, Config{}, &now)
+	runner.Handle("test", func(context.Context, *models.Job) error { return Permanent(errors.New("bad payload")) })
+	runner.Handle("panics", func(context.Context, *models.Job) error { panic("oops") })
+
+	_, err := runner.RunDue(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, models.JobFailed, q.job("j1").Status)
+	assert.Equal(t, "bad payload", q.job("j1").LastError)
+	assert.Equal(t, models.JobFailed, q.job("j2").Status)
+	assert.Equal(t, "no handler for job type unknown", q.job("j2").LastError)
+
+	// A panic is retried like any other error
+	assert.Equal(t, models.JobPending, q.job("j3").Status)
+	assert.Equal(t, "panic: oops", q.job("j3").LastError)
+}
+
+func TestRunner_SkipsJobsClaimedElsewhere(t *testing.T) {
+	now := time.Now()
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetDueJobs", mock.Anything, mock.Anything, mock.Anything).Return([]*models.Job{pendingJob("j1", "test", now)}, nil)
+	mockRepo.On("ClaimJob", mock.Anything, "j1", mock.Anything, mock.Anything, mock.Anything).Return(nil, repository.ErrNotFound)
+
+	runner := NewRunner(mockRepo, Config{})
+	runner.Handle("test", func(context.Context, *models.Job) error {
+		t.Error("handler ran for a job claimed elsewhere")
+		return nil
+	})
+
+	ran, err := runner.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, ran)
+	mockRepo.AssertNotCalled(t, "UpdateJob", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRunner_LostLeaseIsNotRecorded(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	q := queue(pendingJob("j1", "test", now))
+	runner := testRunner(q, Config{}, &now)
+	runner.Handle("test", func(_ context.Context, job *models.Job) error {
+		// Another instance claims the job after this one's lease ran out
+		q.mu.Lock()
+		q.jobs[job.ID].LeaseID = "other-instance/1"
+		q.mu.Unlock()
+		return nil
+	})
+
+	ran, err := runner.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, ran)
+	assert.Equal(t, models.JobPending, q.job("j1").Status)
+	assert.Equal(t, "other-instance/1", q.job("j1").LeaseID)
+}
+
+func TestRunner_LeaseIDsAreUnique(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	mockRepo := new(repository.MockRepository)
+	var leases []string
+	mockRepo.On("ClaimJob", mock.Anything, "j1", mock.Anything, now, now.Add(DefaultLease)).Run(func(args mock.Arguments) {
+		leases = append(leases, args.String(2))
+	}).Return(nil, repository.ErrNotFound)
+
+	runner := NewRunner(mockRepo, Config{})
+	runner.now = func() time.Time { return now }
+	for range 2 {
+		_, err := runner.run(context.Background(), "j1")
+		require.NoError(t, err)
+	}
+
+	require.Len(t, leases, 2)
+	assert.NotEqual(t, leases[0], leases[1])
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 10*time.Second, retryDelay(1))
+	assert.Equal(t, 20*time.Second, retryDelay(2))
+	assert.Equal(t, 80*time.Second, retryDelay(4))
+	assert.Equal(t, time.Hour, retryDelay(20))
+}
+
+func TestRunner_RunStopsOnCancel(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetDueJobs", mock.Anything, mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	runner := NewRunner(mockRepo, Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		runner.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runner did not stop after context cancellation")
+	}
+}
@@ -0,0 +1,151 @@
+// Package logging sets up structured JSON logs. Records carry the ID of the
+// request they were logged for and of the current trace, and email addresses
+// and credentials are redacted before anything is written.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Redacted replaces the value of attributes that hold credentials
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are substrings of attribute keys whose values are never logged
+var sensitiveKeys = []string{"password", "secret", "token"}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@([A-Za-z0-9\-]+\.)+[A-Za-z]{2,}`)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose log records carry the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored by WithRequestID, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// ParseLevel reads a level such as "debug", "info", "warn" or "error". An
+// empty string is info.
+func ParseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	if value == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", value)
+	}
+	return level, nil
+}
+
+// New returns a logger writing JSON records at or above level to w. Field
+// names follow Cloud Logging's, so severities and messages are recognised
+// without further configuration.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: replaceAttr,
+	})
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+// MaskEmail keeps the first character and the domain of an email address,
+// enough to tell addresses apart when debugging
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return Redacted
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// RedactString masks every email address in s
+func RedactString(s string) string {
+	return emailPattern.ReplaceAllStringFunc(s, MaskEmail)
+}
+
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 {
+		switch a.Key {
+		case slog.LevelKey:
+			level, _ := a.Value.Any().(slog.Level)
+			return slog.String("severity", severity(level))
+		case slog.MessageKey:
+			return slog.String("message", RedactString(a.Value.String()))
+		case slog.TimeKey:
+			return a
+		}
+	}
+
+	key := strings.ToLower(a.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(a.Key, Redacted)
+		}
+	}
+	if strings.Contains(key, "email") && a.Value.Kind() == slog.KindString {
+		return slog.String(a.Key, MaskEmail(a.Value.String()))
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, RedactString(a.Value.String()))
+	case slog.KindAny:
+		// Errors and other values are logged as text, so they are redacted as text
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, RedactString(err.Error()))
+		}
+		if s, ok := a.Value.Any().(fmt.Stringer); ok {
+			return slog.String(a.Key, RedactString(s.String()))
+		}
+	}
+	return a
+}
+
+// severity names a level the way Cloud Logging does
+func severity(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARNING"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
+
+// contextHandler adds the request ID and the current trace and span IDs from
+// the context to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("requestId", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("traceId", span.TraceID().String()), slog.String("spanId", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func logRecord(t *testing.T, level slog.Level, log func(*slog.Logger)) map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	log(New(&buf, level))
+	if buf.Len() == 0 {
+		return nil
+	}
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
+
+func TestNew_CloudLoggingFields(t *testing.T) {
+	record := logRecord(t, slog.LevelInfo, func(logger *slog.Logger) {
+		logger.Warn("Disk almost full", "percent", 91)
+	})
+
+	assert.Equal(t, "WARNING", record["severity"])
+	assert.Equal(t, "Disk almost full", record["message"])
+	assert.Equal(t, 91.0, record["percent"])
+	assert.NotContains(t, record, "level")
+	assert.NotContains(t, record, "msg")
+}
+
+func TestNew_Level(t *testing.T) {
+	assert.Nil(t, logRecord(t, slog.LevelWarn, func(logger *slog.Logger) {
+		logger.Info("Not interesting")
+	}))
+	assert.NotNil(t, logRecord(t, slog.LevelDebug, func(logger *slog.Logger) {
+		logger.Debug("Interesting")
+	}))
+}
+
+func TestNew_RequestID(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-123")
+	record := logRecord(t, slog.LevelInfo, func(logger *slog.Logger) {
+		logger.With("component", "test").InfoContext(ctx, "Handled")
+	})
+
+	assert.Equal(t, "req-123", record["requestId"])
+	assert.Equal(t, "test", record["component"])
+	assert.NotContains(t, record, "traceId")
+}
+
+func TestNew_TraceContext(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	record := logRecord(t, slog.LevelInfo, func(logger *slog.Logger) {
+		logger.InfoContext(ctx, "Handled")
+	})
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["traceId"])
+	assert.Equal(t, "00f067aa0ba902b7", record["spanId"])
+}
+
+func TestNew_Redaction(t *testing.T) {
+	record := logRecord(t, slog.LevelInfo, func(logger *slog.Logger) {
+		logger.Info("Registered jane.doe@example.com",
+			"email", "john@example.org",
+			"adminEmail", "not-an-address",
+			"password", "hunter2",
+			"clientSecret", "s3cret",
+			"error", errors.New(`admin "ops@example.com" already exists`),
+			slog.Group("attendee", "contact", "a@b.co"),
+			"count", 3,
+		)
+	})
+
+	assert.Equal(t, "Registered j***@example.com", record["message"])
+	assert.Equal(t, "j***@example.org", record["email"])
+	assert.Equal(t, Redacted, record["adminEmail"])
+	assert.Equal(t, Redacted, record["password"])
+	assert.Equal(t, Redacted, record["clientSecret"])
+	assert.Equal(t, `admin "o***@example.com" already exists`, record["error"])
+	assert.Equal(t, map[string]interface{}{"contact": "a***@b.co"}, record["attendee"])
+	assert.Equal(t, 3.0, record["count"])
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    slog.Level
+		expectError bool
+	}{
+		{value: "", expected: slog.LevelInfo},
+		{value: "debug", expected: slog.LevelDebug},
+		{value: "WARN", expected: slog.LevelWarn},
+		{value: "error", expected: slog.LevelError},
+		{value: "verbose", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			level, err := ParseLevel(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, level)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"ai-india-workshop-backend/internal/analytics"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// attendeeLoadTimeout bounds how long a scrape waits for Firestore
+const attendeeLoadTimeout = 5 * time.Second
+
+// attendeeCollector reports attendee totals from the analytics counters.
+// The totals are cached for ttl so frequent scrapes do not each read
+// Firestore, and the last totals are reported if a refresh fails.
+type attendeeCollector struct {
+	repo repository.RepositoryInterface
+	ttl  time.Duration
+	desc *prometheus.Desc
+
+	mu        sync.Mutex
+	totals    *models.AnalyticsTotals
+	fetchedAt time.Time
+}
+
+// RegisterAttendees adds the workshop_attendees gauge, refreshed from the
+// repository at most once per ttl
+func (m *Metrics) RegisterAttendees(repo repository.RepositoryInterface, ttl time.Duration) {
+	m.registry.MustRegister(&attendeeCollector{
+		repo: repo,
+		ttl:  ttl,
+		desc: prometheus.NewDesc(
+			"workshop_attendees",
+			"Attendees by state: registered and not cancelled, cancelled, or checked in.",
+			[]string{"state"}, nil,
+		),
+	})
+}
+
+func (c *attendeeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *attendeeCollector) Collect(ch chan<- prometheus.Metric) {
+	totals := c.load()
+	if totals == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(totals.Active), "registered")
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(totals.Cancellations), "cancelled")
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(totals.CheckIns), "checked_in")
+}
+
+func (c *attendeeCollector) load() *models.AnalyticsTotals {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.totals != nil && time.Since(c.fetchedAt) < c.ttl {
+		return c.totals
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), attendeeLoadTimeout)
+	defer cancel()
+	days, err := c.repo.GetAnalyticsDays(ctx)
+	if errors.Is(err, repository.ErrNotFound) {
+		if err = c.repo.RebuildAnalytics(ctx); err == nil {
+			days, err = c.repo.GetAnalyticsDays(ctx)
+		}
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error loading attendee totals for metrics", "error", err)
+		return c.totals
+	}
+
+	totals := analytics.Totals(days)
+	c.totals, c.fetchedAt = &totals, time.Now()
+	return c.totals
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+// otherOperation labels reads made outside an instrumented repository
+// method, such as by the login limiter and session stores
+const otherOperation = "other"
+
+type operationKey struct{}
+
+// withOperation tags the context with the repository method running, so the
+// Firestore reads it makes are attributed to it
+func withOperation(ctx context.Context, method string) context.Context {
+	return context.WithValue(ctx, operationKey{}, method)
+}
+
+func operation(ctx context.Context) string {
+	if method, ok := ctx.Value(operationKey{}).(string); ok {
+		return method
+	}
+	return otherOperation
+}
+
+// FirestoreOptions are passed to the Firestore client so it counts the
+// documents each response carries
+func (m *Metrics) FirestoreOptions() []option.ClientOption {
+	return []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(m.countUnaryReads)),
+		option.WithGRPCDialOption(grpc.WithChainStreamInterceptor(m.countStreamReads)),
+	}
+}
+
+func (m *Metrics) countUnaryReads(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err == nil {
+		m.countReads(ctx, documentsIn(reply))
+	}
+	return err
+}
+
+func (m *Metrics) countStreamReads(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &readCountingStream{ClientStream: stream, count: func(n int) { m.countReads(ctx, n) }}, nil
+}
+
+func (m *Metrics) countReads(ctx context.Context, n int) {
+	if n > 0 {
+		m.firestoreReads.WithLabelValues(operation(ctx)).Add(float64(n))
+	}
+}
+
+type readCountingStream struct {
+	grpc.ClientStream
+	count func(int)
+}
+
+func (s *readCountingStream) RecvMsg(msg any) error {
+	err := s.ClientStream.RecvMsg(msg)
+	if err == nil {
+		s.count(documentsIn(msg))
+	}
+	return err
+}
+
+// documentsIn returns how many documents a Firestore response carries. An
+// aggregation such as a count is billed per thousand index entries it
+// scans, which the response does not say, so it is counted as one read.
+func documentsIn(msg any) int {
+	switch response := msg.(type) {
+	case *firestorepb.Document:
+		return 1
+	case *firestorepb.ListDocumentsResponse:
+		return len(response.GetDocuments())
+	case *firestorepb.BatchGetDocumentsResponse:
+		if response.GetFound() != nil {
+			return 1
+		}
+	case *firestorepb.RunQueryResponse:
+		if response.GetDocument() != nil {
+			return 1
+		}
+	case *firestorepb.RunAggregationQueryResponse:
+		if response.GetResult() != nil {
+			return 1
+		}
+	}
+	return 0
+}
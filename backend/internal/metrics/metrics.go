@@ -0,0 +1,90 @@
+// Package metrics exposes Prometheus metrics for HTTP requests, repository
+// operations, Firestore reads and attendee totals
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute labels requests that matched no route, so scanners probing
+// random paths cannot create a series per path
+const unmatchedRoute = "unmatched"
+
+// Metrics holds the collectors and the registry they are served from
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+	repoDuration        *prometheus.HistogramVec
+	repoErrors          *prometheus.CounterVec
+	firestoreReads      *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		repoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "repository_operation_duration_seconds",
+			Help:    "Repository operation latency by method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		repoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "repository_operation_errors_total",
+			Help: "Repository operations that failed, by method. Not found and similar expected outcomes are not counted.",
+		}, []string{"method"}),
+		firestoreReads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "firestore_document_reads_total",
+			Help: "Firestore documents read, by the repository method that read them.",
+		}, []string{"method"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.repoDuration,
+		m.repoErrors,
+		m.firestoreReads,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records every request. Routes are labelled with their pattern,
+// e.g. /api/attendees/:id, rather than the path requested.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		m.httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.httpRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
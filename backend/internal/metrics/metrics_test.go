@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestMiddleware_LabelsByRoute(t *testing.T) {
+	m := New()
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/api/speakers/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/api/speakers/1", "/api/speakers/2", "/wp-login.php"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/api/speakers/:id", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", unmatchedRoute, "404")))
+	// One series per route, not per path
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpRequests))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpRequestDuration))
+}
+
+func TestInstrumentRepository(t *testing.T) {
+	m := New()
+	mockRepo := new(repository.MockRepository)
+	repo := m.InstrumentRepository(mockRepo)
+
+	mockRepo.On("GetAttendee", mock.Anything, "missing").Return(nil, repository.ErrNotFound)
+	mockRepo.On("GetAttendee", mock.Anything, "broken").Return(nil, errors.New("unavailable"))
+	mockRepo.On("GetAttendee", mock.Anything, "1").Run(func(args mock.Arguments) {
+		// Reads made while the method runs are attributed to it
+		m.countReads(args.Get(0).(context.Context), 1)
+	}).Return(&models.Attendee{ID: "1"}, nil)
+
+	for _, id := range []string{"missing", "broken", "1"} {
+		_, _ = repo.GetAttendee(context.Background(), id)
+	}
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.repoErrors.WithLabelValues("GetAttendee")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.firestoreReads.WithLabelValues("GetAttendee")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.repoDuration))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDocumentsIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      any
+		expected int
+	}{
+		{name: "query result", msg: &firestorepb.RunQueryResponse{Document: &firestorepb.Document{}}, expected: 1},
+		{name: "query progress", msg: &firestorepb.RunQueryResponse{}, expected: 0},
+		{
+			name:     "found document",
+			msg:      &firestorepb.BatchGetDocumentsResponse{Result: &firestorepb.BatchGetDocumentsResponse_Found{Found: &firestorepb.Document{}}},
+			expected: 1,
+		},
+		{
+			name:     "missing document",
+			msg:      &firestorepb.BatchGetDocumentsResponse{Result: &firestorepb.BatchGetDocumentsResponse_Missing{Missing: "doc"}},
+			expected: 0,
+		},
+		{
+			name:     "listed documents",
+			msg:      &firestorepb.ListDocumentsResponse{Documents: []*firestorepb.Document{{}, {}}},
+			expected: 2,
+		},
+		{name: "write", msg: &firestorepb.CommitResponse{}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, documentsIn(tt.msg))
+		})
+	}
+}
+
+func TestAttendeeCollector(t *testing.T) {
+	m := New()
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetAnalyticsDays", mock.Anything).Return([]*models.AnalyticsDay{
+		{Date: "2026-03-01", Registrations: map[string]int{"40": 5}, Cancellations: map[string]int{"41": 1}, CheckIns: map[string]int{"42": 2}},
+	}, nil).Once()
+	m.RegisterAttendees(mockRepo, time.Minute)
+
+	expected := `
+# HELP workshop_attendees Attendees by state: registered and not cancelled, cancelled, or checked in.
+# TYPE workshop_attendees gauge
+workshop_attendees{state="cancelled"} 1
+workshop_attendees{state="checked_in"} 2
+workshop_attendees{state="registered"} 4
+`
+	require.NoError(t, testutil.GatherAndCompare(m.registry, strings.NewReader(expected), "workshop_attendees"))
+	// The second scrape is served from the cache
+	require.NoError(t, testutil.GatherAndCompare(m.registry, strings.NewReader(expected), "workshop_attendees"))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAttendeeCollector_KeepsTotalsWhenRefreshFails(t *testing.T) {
+	m := New()
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetAnalyticsDays", mock.Anything).Return([]*models.AnalyticsDay{
+		{Date: "2026-03-01", Registrations: map[string]int{"40": 3}},
+	}, nil).Once()
+	mockRepo.On("GetAnalyticsDays", mock.Anything).Return(nil, errors.New("unavailable"))
+	m.RegisterAttendees(mockRepo, 0)
+
+	for range 2 {
+		count, err := testutil.GatherAndCount(m.registry, "workshop_attendees")
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	}
+}
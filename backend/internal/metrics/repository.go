@@ -0,0 +1,421 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+)
+
+// instrumentedRepository records the latency and errors of every repository
+// method, and tags the context so Firestore reads are attributed to it
+type instrumentedRepository struct {
+	next    repository.RepositoryInterface
+	metrics *Metrics
+}
+
+// InstrumentRepository wraps repo so its operations are measured
+func (m *Metrics) InstrumentRepository(repo repository.RepositoryInterface) repository.RepositoryInterface {
+	return &instrumentedRepository{next: repo, metrics: m}
+}
+
+func (r *instrumentedRepository) observe(ctx context.Context, method string) (context.Context, func(error)) {
+	start := time.Now()
+	return withOperation(ctx, method), func(err error) {
+		r.metrics.repoDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+		if err != nil && !repository.IsExpectedError(err) {
+			r.metrics.repoErrors.WithLabelValues(method).Inc()
+		}
+	}
+}
+
+func (r *instrumentedRepository) Ping(ctx context.Context) (err error) {
+	ctx, done := r.observe(ctx, "Ping")
+	defer func() { done(err) }()
+	return r.next.Ping(ctx)
+}
+
+func (r *instrumentedRepository) CreateAttendee(ctx context.Context, attendee *models.Attendee) (err error) {
+	ctx, done := r.observe(ctx, "CreateAttendee")
+	defer func() { done(err) }()
+	return r.next.CreateAttendee(ctx, attendee)
+}
+
+func (r *instrumentedRepository) GetAllAttendees(ctx context.Context) (_ []*models.Attendee, err error) {
+	ctx, done := r.observe(ctx, "GetAllAttendees")
+	defer func() { done(err) }()
+	return r.next.GetAllAttendees(ctx)
+}
+
+func (r *instrumentedRepository) GetAttendee(ctx context.Context, id string) (_ *models.Attendee, err error) {
+	ctx, done := r.observe(ctx, "GetAttendee")
+	defer func() { done(err) }()
+	return r.next.GetAttendee(ctx, id)
+}
+
+func (r *instrumentedRepository) GetAttendeeCount(ctx context.Context) (_ int, err error) {
+	ctx, done := r.observe(ctx, "GetAttendeeCount")
+	defer func() { done(err) }()
+	return r.next.GetAttendeeCount(ctx)
+}
+
+func (r *instrumentedRepository) DeleteAttendee(ctx context.Context, id string) (err error) {
+	ctx, done := r.observe(ctx, "DeleteAttendee")
+	defer func() { done(err) }()
+	return r.next.DeleteAttendee(ctx, id)
+}
+
+func (r *instrumentedRepository) CheckInAttendee(ctx context.Context, id string) (_ *models.Attendee, err error) {
+	ctx, done := r.observe(ctx, "CheckInAttendee")
+	defer func() { done(err) }()
+	return r.next.CheckInAttendee(ctx, id)
+}
+
+func (r *instrumentedRepository) SetAttendeeDesignations(ctx context.Context, designations map[string]string) (_ int, err error) {
+	ctx, done := r.observe(ctx, "SetAttendeeDesignations")
+	defer func() { done(err) }()
+	return r.next.SetAttendeeDesignations(ctx, designations)
+}
+
+func (r *instrumentedRepository) CreateSpeaker(ctx context.Context, speaker *models.Speaker) (err error) {
+	ctx, done := r.observe(ctx, "CreateSpeaker")
+	defer func() { done(err) }()
+	return r.next.CreateSpeaker(ctx, speaker)
+}
+
+func (r *instrumentedRepository) GetAllSpeakers(ctx context.Context) (_ []*models.Speaker, err error) {
+	ctx, done := r.observe(ctx, "GetAllSpeakers")
+	defer func() { done(err) }()
+	return r.next.GetAllSpeakers(ctx)
+}
+
+func (r *instrumentedRepository) GetSpeaker(ctx context.Context, id string) (_ *models.Speaker, err error) {
+	ctx, done := r.observe(ctx, "GetSpeaker")
+	defer func() { done(err) }()
+	return r.next.GetSpeaker(ctx, id)
+}
+
+func (r *instrumentedRepository) UpdateSpeaker(ctx context.Context, id string, speaker *models.Speaker) (err error) {
+	ctx, done := r.observe(ctx, "UpdateSpeaker")
+	defer func() { done(err) }()
+	return r.next.UpdateSpeaker(ctx, id, speaker)
+}
+
+func (r *instrumentedRepository) DeleteSpeaker(ctx context.Context, id string) (err error) {
+	ctx, done := r.observe(ctx, "DeleteSpeaker")
+	defer func() { done(err) }()
+	return r.next.DeleteSpeaker(ctx, id)
+}
+
+func (r *instrumentedRepository) CreateSession(ctx context.Context, session *models.Session) (err error) {
+	ctx, done := r.observe(ctx, "CreateSession")
+	defer func() { done(err) }()
+	return r.next.CreateSession(ctx, session)
+}
+
+func (r *instrumentedRepository) GetAllSessions(ctx context.Context) (_ []*models.Session, err error) {
+	ctx, done := r.observe(ctx, "GetAllSessions")
+	defer func() { done(err) }()
+	return r.next.GetAllSessions(ctx)
+}
+
+func (r *instrumentedRepository) GetSession(ctx context.Context, id string) (_ *models.Session, err error) {
+	ctx, done := r.observe(ctx, "GetSession")
+	defer func() { done(err) }()
+	return r.next.GetSession(ctx, id)
+}
+
+func (r *instrumentedRepository) UpdateSession(ctx context.Context, id string, session *models.Session) (err error) {
+	ctx, done := r.observe(ctx, "UpdateSession")
+	defer func() { done(err) }()
+	return r.next.UpdateSession(ctx, id, session)
+}
+
+func (r *instrumentedRepository) DeleteSession(ctx context.Context, id string) (err error) {
+	ctx, done := r.observe(ctx, "DeleteSession")
+	defer func() { done(err) }()
+	return r.next.DeleteSession(ctx, id)
+}
+
+func (r *instrumentedRepository) GetDesignationBreakdown(ctx context.Context) (_ []models.DesignationCount, err error) {
+	ctx, done := r.observe(ctx, "GetDesignationBreakdown")
+	defer func() { done(err) }()
+	return r.next.GetDesignationBreakdown(ctx)
+}
+
+func (r *instrumentedRepository) GetRegistrationForm(ctx context.Context) (_ *models.RegistrationForm, err error) {
+	ctx, done := r.observe(ctx, "GetRegistrationForm")
+	defer func() { done(err) }()
+	return r.next.GetRegistrationForm(ctx)
+}
+
+func (r *instrumentedRepository) SaveRegistrationForm(ctx context.Context, form *models.RegistrationForm) (err error) {
+	ctx, done := r.observe(ctx, "SaveRegistrationForm")
+	defer func() { done(err) }()
+	return r.next.SaveRegistrationForm(ctx, form)
+}
+
+func (r *instrumentedRepository) GetDesignationTaxonomy(ctx context.Context) (_ *models.DesignationTaxonomy, err error) {
+	ctx, done := r.observe(ctx, "GetDesignationTaxonomy")
+	defer func() { done(err) }()
+	return r.next.GetDesignationTaxonomy(ctx)
+}
+
+func (r *instrumentedRepository) SaveDesignationTaxonomy(ctx context.Context, taxonomy *models.DesignationTaxonomy) (err error) {
+	ctx, done := r.observe(ctx, "SaveDesignationTaxonomy")
+	defer func() { done(err) }()
+	return r.next.SaveDesignationTaxonomy(ctx, taxonomy)
+}
+
+func (r *instrumentedRepository) GetAnalyticsDays(ctx context.Context) (_ []*models.AnalyticsDay, err error) {
+	ctx, done := r.observe(ctx, "GetAnalyticsDays")
+	defer func() { done(err) }()
+	return r.next.GetAnalyticsDays(ctx)
+}
+
+func (r *instrumentedRepository) RebuildAnalytics(ctx context.Context) (err error) {
+	ctx, done := r.observe(ctx, "RebuildAnalytics")
+	defer func() { done(err) }()
+	return r.next.RebuildAnalytics(ctx)
+}
+
+func (r *instrumentedRepository) GetTrash(ctx context.Context) (_ *models.Trash, err error) {
+	ctx, done := r.observe(ctx, "GetTrash")
+	defer func() { done(err) }()
+	return r.next.GetTrash(ctx)
+}
+
+func (r *instrumentedRepository) RestoreFromTrash(ctx context.Context, resourceType, id string) (err error) {
+	ctx, done := r.observe(ctx, "RestoreFromTrash")
+	defer func() { done(err) }()
+	return r.next.RestoreFromTrash(ctx, resourceType, id)
+}
+
+func (r *instrumentedRepository) PurgeFromTrash(ctx context.Context, resourceType, id string) (err error) {
+	ctx, done := r.observe(ctx, "PurgeFromTrash")
+	defer func() { done(err) }()
+	return r.next.PurgeFromTrash(ctx, resourceType, id)
+}
+
+func (r *instrumentedRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (_ int, err error) {
+	ctx, done := r.observe(ctx, "PurgeDeletedBefore")
+	defer func() { done(err) }()
+	return r.next.PurgeDeletedBefore(ctx, cutoff)
+}
+
+func (r *instrumentedRepository) GetWorkshopData(ctx context.Context) (_ *models.WorkshopData, err error) {
+	ctx, done := r.observe(ctx, "GetWorkshopData")
+	defer func() { done(err) }()
+	return r.next.GetWorkshopData(ctx)
+}
+
+func (r *instrumentedRepository) ReplaceWorkshopData(ctx context.Context, data *models.WorkshopData) (err error) {
+	ctx, done := r.observe(ctx, "ReplaceWorkshopData")
+	defer func() { done(err) }()
+	return r.next.ReplaceWorkshopData(ctx, data)
+}
+
+func (r *instrumentedRepository) ImportAttendees(ctx context.Context, attendees []*models.Attendee) (err error) {
+	ctx, done := r.observe(ctx, "ImportAttendees")
+	defer func() { done(err) }()
+	return r.next.ImportAttendees(ctx, attendees)
+}
+
+func (r *instrumentedRepository) PurgeAttendees(ctx context.Context, ids []string) (err error) {
+	ctx, done := r.observe(ctx, "PurgeAttendees")
+	defer func() { done(err) }()
+	return r.next.PurgeAttendees(ctx, ids)
+}
+
+func (r *instrumentedRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) (err error) {
+	ctx, done := r.observe(ctx, "CreateAuditEntry")
+	defer func() { done(err) }()
+	return r.next.CreateAuditEntry(ctx, entry)
+}
+
+func (r *instrumentedRepository) GetAuditEntries(ctx context.Context, query models.AuditQuery) (_ []*models.AuditEntry, err error) {
+	ctx, done := r.observe(ctx, "GetAuditEntries")
+	defer func() { done(err) }()
+	return r.next.GetAuditEntries(ctx, query)
+}
+
+func (r *instrumentedRepository) CreateAdminUser(ctx context.Context, user *models.AdminUser) (err error) {
+	ctx, done := r.observe(ctx, "CreateAdminUser")
+	defer func() { done(err) }()
+	return r.next.CreateAdminUser(ctx, user)
+}
+
+func (r *instrumentedRepository) GetAllAdminUsers(ctx context.Context) (_ []*models.AdminUser, err error) {
+	ctx, done := r.observe(ctx, "GetAllAdminUsers")
+	defer func() { done(err) }()
+	return r.next.GetAllAdminUsers(ctx)
+}
+
+func (r *instrumentedRepository) GetAdminUser(ctx context.Context, id string) (_ *models.AdminUser, err error) {
+	ctx, done := r.observe(ctx, "GetAdminUser")
+	defer func() { done(err) }()
+	return r.next.GetAdminUser(ctx, id)
+}
+
+func (r *instrumentedRepository) GetAdminUserByEmail(ctx context.Context, email string) (_ *models.AdminUser, err error) {
+	ctx, done := r.observe(ctx, "GetAdminUserByEmail")
+	defer func() { done(err) }()
+	return r.next.GetAdminUserByEmail(ctx, email)
+}
+
+func (r *instrumentedRepository) UpdateAdminUser(ctx context.Context, id string, user *models.AdminUser) (err error) {
+	ctx, done := r.observe(ctx, "UpdateAdminUser")
+	defer func() { done(err) }()
+	return r.next.UpdateAdminUser(ctx, id, user)
+}
+
+func (r *instrumentedRepository) CreateLoginAttempt(ctx context.Context, attempt *models.LoginAttempt) (err error) {
+	ctx, done := r.observe(ctx, "CreateLoginAttempt")
+	defer func() { done(err) }()
+	return r.next.CreateLoginAttempt(ctx, attempt)
+}
+
+func (r *instrumentedRepository) GetLoginAttempts(ctx context.Context, query models.LoginAttemptQuery) (_ []*models.LoginAttempt, err error) {
+	ctx, done := r.observe(ctx, "GetLoginAttempts")
+	defer func() { done(err) }()
+	return r.next.GetLoginAttempts(ctx, query)
+}
+
+func (r *instrumentedRepository) CreateAPIToken(ctx context.Context, token *models.APIToken) (err error) {
+	ctx, done := r.observe(ctx, "CreateAPIToken")
+	defer func() { done(err) }()
+	return r.next.CreateAPIToken(ctx, token)
+}
+
+func (r *instrumentedRepository) GetAPIToken(ctx context.Context, id string) (_ *models.APIToken, err error) {
+	ctx, done := r.observe(ctx, "GetAPIToken")
+	defer func() { done(err) }()
+	return r.next.GetAPIToken(ctx, id)
+}
+
+func (r *instrumentedRepository) GetAPITokens(ctx context.Context, query models.APITokenQuery) (_ []*models.APIToken, err error) {
+	ctx, done := r.observe(ctx, "GetAPITokens")
+	defer func() { done(err) }()
+	return r.next.GetAPITokens(ctx, query)
+}
+
+func (r *instrumentedRepository) RevokeAPIToken(ctx context.Context, id string, revokedAt time.Time) (err error) {
+	ctx, done := r.observe(ctx, "RevokeAPIToken")
+	defer func() { done(err) }()
+	return r.next.RevokeAPIToken(ctx, id, revokedAt)
+}
+
+func (r *instrumentedRepository) TouchAPIToken(ctx context.Context, id string, usedAt time.Time, ip string) (err error) {
+	ctx, done := r.observe(ctx, "TouchAPIToken")
+	defer func() { done(err) }()
+	return r.next.TouchAPIToken(ctx, id, usedAt, ip)
+}
+
+func (r *instrumentedRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) (err error) {
+	ctx, done := r.observe(ctx, "CreateWebhook")
+	defer func() { done(err) }()
+	return r.next.CreateWebhook(ctx, webhook)
+}
+
+func (r *instrumentedRepository) GetAllWebhooks(ctx context.Context) (_ []*models.Webhook, err error) {
+	ctx, done := r.observe(ctx, "GetAllWebhooks")
+	defer func() { done(err) }()
+	return r.next.GetAllWebhooks(ctx)
+}
+
+func (r *instrumentedRepository) GetWebhook(ctx context.Context, id string) (_ *models.Webhook, err error) {
+	ctx, done := r.observe(ctx, "GetWebhook")
+	defer func() { done(err) }()
+	return r.next.GetWebhook(ctx, id)
+}
+
+func (r *instrumentedRepository) UpdateWebhook(ctx context.Context, id string, webhook *models.Webhook) (err error) {
+	ctx, done := r.observe(ctx, "UpdateWebhook")
+	defer func() { done(err) }()
+	return r.next.UpdateWebhook(ctx, id, webhook)
+}
+
+func (r *instrumentedRepository) DeleteWebhook(ctx context.Context, id string) (err error) {
+	ctx, done := r.observe(ctx, "DeleteWebhook")
+	defer func() { done(err) }()
+	return r.next.DeleteWebhook(ctx, id)
+}
+
+func (r *instrumentedRepository) CreateWebhookDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) (err error) {
+	ctx, done := r.observe(ctx, "CreateWebhookDeliveries")
+	defer func() { done(err) }()
+	return r.next.CreateWebhookDeliveries(ctx, deliveries)
+}
+
+func (r *instrumentedRepository) GetWebhookDelivery(ctx context.Context, id string) (_ *models.WebhookDelivery, err error) {
+	ctx, done := r.observe(ctx, "GetWebhookDelivery")
+	defer func() { done(err) }()
+	return r.next.GetWebhookDelivery(ctx, id)
+}
+
+func (r *instrumentedRepository) GetWebhookDeliveries(ctx context.Context, query models.WebhookDeliveryQuery) (_ []*models.WebhookDelivery, err error) {
+	ctx, done := r.observe(ctx, "GetWebhookDeliveries")
+	defer func() { done(err) }()
+	return r.next.GetWebhookDeliveries(ctx, query)
+}
+
+func (r *instrumentedRepository) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) (_ []*models.WebhookDelivery, err error) {
+	ctx, done := r.observe(ctx, "GetDueWebhookDeliveries")
+	defer func() { done(err) }()
+	return r.next.GetDueWebhookDeliveries(ctx, now, limit)
+}
+
+func (r *instrumentedRepository) ClaimWebhookDelivery(ctx context.Context, id string, now, until time.Time) (_ *models.WebhookDelivery, err error) {
+	ctx, done := r.observe(ctx, "ClaimWebhookDelivery")
+	defer func() { done(err) }()
+	return r.next.ClaimWebhookDelivery(ctx, id, now, until)
+}
+
+func (r *instrumentedRepository) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) (err error) {
+	ctx, done := r.observe(ctx, "UpdateWebhookDelivery")
+	defer func() { done(err) }()
+	return r.next.UpdateWebhookDelivery(ctx, delivery)
+}
+
+func (r *instrumentedRepository) CreateJob(ctx context.Context, job *models.Job) (err error) {
+	ctx, done := r.observe(ctx, "CreateJob")
+	defer func() { done(err) }()
+	return r.next.CreateJob(ctx, job)
+}
+
+func (r *instrumentedRepository) GetJob(ctx context.Context, id string) (_ *models.Job, err error) {
+	ctx, done := r.observe(ctx, "GetJob")
+	defer func() { done(err) }()
+	return r.next.GetJob(ctx, id)
+}
+
+func (r *instrumentedRepository) GetJobs(ctx context.Context, query models.JobQuery) (_ []*models.Job, err error) {
+	ctx, done := r.observe(ctx, "GetJobs")
+	defer func() { done(err) }()
+	return r.next.GetJobs(ctx, query)
+}
+
+func (r *instrumentedRepository) GetDueJobs(ctx context.Context, now time.Time, limit int) (_ []*models.Job, err error) {
+	ctx, done := r.observe(ctx, "GetDueJobs")
+	defer func() { done(err) }()
+	return r.next.GetDueJobs(ctx, now, limit)
+}
+
+func (r *instrumentedRepository) ClaimJob(ctx context.Context, id, leaseID string, now, until time.Time) (_ *models.Job, err error) {
+	ctx, done := r.observe(ctx, "ClaimJob")
+	defer func() { done(err) }()
+	return r.next.ClaimJob(ctx, id, leaseID, now, until)
+}
+
+func (r *instrumentedRepository) UpdateJob(ctx context.Context, job *models.Job, leaseID string) (err error) {
+	ctx, done := r.observe(ctx, "UpdateJob")
+	defer func() { done(err) }()
+	return r.next.UpdateJob(ctx, job, leaseID)
+}
+
+func (r *instrumentedRepository) RetryJob(ctx context.Context, id string, now time.Time) (_ *models.Job, err error) {
+	ctx, done := r.observe(ctx, "RetryJob")
+	defer func() { done(err) }()
+	return r.next.RetryJob(ctx, id, now)
+}
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAuditPayload caps how much of a response is kept for the audit entry;
+// a larger response is audited without its payload
+const maxAuditPayload = 64 << 10
+
+// Audit records every request it wraps in the audit log, including who made
+// it, the state of the affected resource before the change and the
+// response payload after it. Reads are audited without their payload, so
+// their responses, such as exports, are not copied.
+func Audit(repo repository.RepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entry := &models.AuditEntry{
+			Actor:        currentActor(c),
+			Action:       c.Request.Method + " " + c.FullPath(),
+			ResourceType: auditResourceType(c),
+			ResourceID:   c.Param("id"),
+			IP:           c.ClientIP(),
+			UserAgent:    c.Request.UserAgent(),
+		}
+
+		if entry.ResourceID != "" && c.Request.Method != http.MethodGet {
+			entry.Before = snapshotResource(c.Request.Context(), repo, entry.ResourceType, entry.ResourceID)
+		}
+
+		var recorder *responseRecorder
+		if c.Request.Method != http.MethodGet {
+			recorder = &responseRecorder{ResponseWriter: c.Writer}
+			c.Writer = recorder
+		}
+
+		c.Next()
+
+		if entry.Actor == "" {
+			entry.Actor = currentActor(c)
+		}
+		entry.Status = c.Writer.Status()
+		entry.Timestamp = time.Now()
+		if recorder != nil && !recorder.overflowed && entry.Status < http.StatusBadRequest {
+			entry.After = decodePayload(recorder.body.Bytes())
+		}
+
+		// The response has already been written, so a failure here must not
+		// affect the client; a cancelled request still gets audited
+		ctx := context.WithoutCancel(c.Request.Context())
+		if err := repo.CreateAuditEntry(ctx, entry); err != nil {
+			slog.ErrorContext(ctx, "Error writing audit entry", "action", entry.Action, "error", err)
+		}
+	}
+}
+
+// responseRecorder keeps a copy of the response body for the audit entry,
+// giving up once it is longer than maxAuditPayload
+type responseRecorder struct {
+	gin.ResponseWriter
+	body       bytes.Buffer
+	overflowed bool
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	if w.keep(len(data)) {
+		w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	if w.keep(len(s)) {
+		w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// keep reports whether n more bytes fit in the copy, dropping the copy if
+// they do not
+func (w *responseRecorder) keep(n int) bool {
+	if w.overflowed {
+		return false
+	}
+	if w.body.Len()+n > maxAuditPayload {
+		w.overflowed = true
+		w.body = bytes.Buffer{}
+		return false
+	}
+	return true
+}
+
+// auditResourceType derives the resource from the route, e.g. "speakers"
+// for /api/v1/speakers/:id or the :type parameter for trash routes
+func auditResourceType(c *gin.Context) string {
+	if resourceType := c.Param("type"); resourceType != "" {
+		return resourceType
+	}
+
+	segments := strings.Split(strings.Trim(c.FullPath(), "/"), "/")
+	for _, segment := range segments {
+		if segment == "api" || segment == "v1" || segment == "admin" || strings.HasPrefix(segment, ":") {
+			continue
+		}
+		return segment
+	}
+	return ""
+}
+
+// snapshotResource loads the current state of a resource before it is changed
+func snapshotResource(ctx context.Context, repo repository.RepositoryInterface, resourceType, id string) map[string]interface{} {
+	var resource interface{}
+	var err error
+	switch resourceType {
+	case models.ResourceAttendees:
+		resource, err = repo.GetAttendee(ctx, id)
+	case models.ResourceSpeakers:
+		resource, err = repo.GetSpeaker(ctx, id)
+	case models.ResourceSessions:
+		resource, err = repo.GetSession(ctx, id)
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil
+	}
+	return decodePayload(data)
+}
+
+// redactedFields are response fields that carry credentials and must not be
+// copied into the audit log
+var redactedFields = []string{"temporaryPassword", "secret", "provisioningUri", "recoveryCodes", "token", "csrfToken"}
+
+// decodePayload converts a JSON object into a map; anything else is dropped
+func decodePayload(data []byte) map[string]interface{} {
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil
+	}
+	for _, field := range redactedFields {
+		if _, ok := payload[field]; ok {
+			payload[field] = "[redacted]"
+		}
+	}
+	return payload
+}
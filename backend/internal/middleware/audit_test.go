@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit_RecordsUpdateWithBeforeAndAfter(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetSpeaker", mock.Anything, "spk-1").Return(&models.Speaker{ID: "spk-1", Name: "Old Name"}, nil)
+
+	var recorded *models.AuditEntry
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(*models.AuditEntry)
+	}).Return(nil)
+
+	r := setupAuthTestRouter()
+	r.PUT("/api/speakers/:id", func(c *gin.Context) {
+		c.Set(ActorKey, "admin")
+		c.Next()
+	}, Audit(mockRepo), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": "spk-1", "name": "New Name"})
+	})
+
+	req, _ := http.NewRequest("PUT", "/api/speakers/spk-1", bytes.NewBufferString(`{"name":"New Name"}`))
+	req.Header.Set("User-Agent", "audit-test")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, recorded)
+	assert.Equal(t, "admin", recorded.Actor)
+	assert.Equal(t, "PUT /api/speakers/:id", recorded.Action)
+	assert.Equal(t, models.ResourceSpeakers, recorded.ResourceType)
+	assert.Equal(t, "spk-1", recorded.ResourceID)
+	assert.Equal(t, "Old Name", recorded.Before["name"])
+	assert.Equal(t, "New Name", recorded.After["name"])
+	assert.Equal(t, http.StatusOK, recorded.Status)
+	assert.Equal(t, "audit-test", recorded.UserAgent)
+	assert.False(t, recorded.Timestamp.IsZero())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAudit_ReadsAndFailuresHaveNoPayload(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+
+	var recorded []*models.AuditEntry
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recorded = append(recorded, args.Get(1).(*models.AuditEntry))
+	}).Return(nil)
+
+	r := setupAuthTestRouter()
+	r.GET("/api/attendees", Audit(mockRepo), func(c *gin.Context) {
+		c.JSON(http.StatusOK, []gin.H{{"email": "john@example.com"}})
+	})
+	r.POST("/api/admin/login", Audit(mockRepo), func(c *gin.Context) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/api/attendees", nil),
+		httptest.NewRequest("POST", "/api/admin/login", nil),
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	require.Len(t, recorded, 2)
+	assert.Equal(t, models.ResourceAttendees, recorded[0].ResourceType)
+	assert.Nil(t, recorded[0].After)
+	assert.Equal(t, "POST /api/admin/login", recorded[1].Action)
+	assert.Equal(t, http.StatusUnauthorized, recorded[1].Status)
+	assert.Empty(t, recorded[1].Actor)
+	assert.Nil(t, recorded[1].After)
+}
+
+func TestAudit_StoreFailureDoesNotAffectResponse(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetAttendee", mock.Anything, "att-1").Return(nil, repository.ErrNotFound)
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.Anything).Return(assert.AnError)
+
+	r := setupAuthTestRouter()
+	r.DELETE("/api/attendees/:id", Audit(mockRepo), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "Attendee deleted successfully"})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/attendees/att-1", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Attendee deleted successfully")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAudit_RedactsCredentials(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+
+	var recorded *models.AuditEntry
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(*models.AuditEntry)
+	}).Return(nil)
+
+	r := setupAuthTestRouter()
+	r.POST("/api/admin/me/2fa/enable", Audit(mockRepo), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "enabled", "recoveryCodes": []string{"abcde-fghjk"}})
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/admin/me/2fa/enable", nil))
+
+	require.NotNil(t, recorded)
+	assert.Equal(t, "enabled", recorded.After["message"])
+	assert.Equal(t, "[redacted]", recorded.After["recoveryCodes"])
+}
+
+func TestAudit_LargeResponseHasNoPayload(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+
+	var recorded *models.AuditEntry
+	mockRepo.On("CreateAuditEntry", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(*models.AuditEntry)
+	}).Return(nil)
+
+	large := strings.Repeat("x", maxAuditPayload)
+	r := setupAuthTestRouter()
+	r.POST("/api/admin/restore", Audit(mockRepo), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": large})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/restore", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), large)
+	require.NotNil(t, recorded)
+	assert.Nil(t, recorded.After)
+}
+
+func TestAuditResourceType(t *testing.T) {
+	tests := []struct {
+		route    string
+		path     string
+		expected string
+	}{
+		{route: "/api/attendees", path: "/api/attendees", expected: "attendees"},
+		{route: "/api/sessions/:id", path: "/api/sessions/1", expected: "sessions"},
+		{route: "/api/v1/sessions/:id", path: "/api/v1/sessions/1", expected: "sessions"},
+		{route: "/api/admin/stats", path: "/api/admin/stats", expected: "stats"},
+		{route: "/api/admin/trash/:type/:id/restore", path: "/api/admin/trash/speakers/1/restore", expected: "speakers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			var resourceType string
+			r := setupAuthTestRouter()
+			r.Any(tt.route, func(c *gin.Context) {
+				resourceType = auditResourceType(c)
+			})
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.path, nil))
+			assert.Equal(t, tt.expected, resourceType)
+		})
+	}
+}
@@ -1,23 +1,267 @@
 package middleware
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 )
 
-func RequireAdmin() gin.HandlerFunc {
+const (
+	// ActorKey is the gin context key holding the email of the authenticated admin
+	ActorKey = "actor"
+	// AdminUserKey is the gin context key holding the authenticated *models.AdminUser
+	AdminUserKey = "adminUser"
+	// APITokenKey is the gin context key holding the *models.APIToken used
+	// to authenticate, if the request did not use a session
+	APITokenKey = "apiToken"
+
+	// Session keys written by AdminHandler.Login
+	SessionUserIDKey = "adminUserID"
+	SessionEmailKey  = "adminEmail"
+
+	// Session keys for a login that has passed the password check and is
+	// waiting for a second factor
+	SessionPendingUserIDKey   = "pendingAdminUserID"
+	SessionPendingAtKey       = "pendingAdminAt"
+	SessionPendingAttemptsKey = "pendingAdminAttempts"
+
+	// SessionAuthMethodKey records how the session signed in. Sessions
+	// started through single sign-on hold AuthMethodSSO.
+	SessionAuthMethodKey = "authMethod"
+	AuthMethodSSO        = "sso"
+
+	// Session keys for a single sign-on login waiting for the provider to
+	// redirect back
+	SessionSSOStateKey     = "ssoState"
+	SessionSSONonceKey     = "ssoNonce"
+	SessionSSOVerifierKey  = "ssoVerifier"
+	SessionSSOStartedAtKey = "ssoStartedAt"
+)
+
+// apiTokenTouchInterval limits how often a token's last-used time is written
+const apiTokenTouchInterval = time.Minute
+
+// RequireAdmin loads the admin user referenced by the session and rejects the
+// request if there is none or the account has been disabled. Requests with
+// an Authorization: Bearer header are authenticated by API token instead.
+func RequireAdmin(repo repository.RepositoryInterface) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if bearer, ok := bearerToken(c); ok {
+			authenticateAPIToken(c, repo, bearer)
+			return
+		}
+
 		session := sessions.Default(c)
-		isAdmin := session.Get("isAdmin")
-		if isAdmin != true {
+		userID, _ := session.Get(SessionUserIDKey).(string)
+		if userID == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			c.Abort()
 			return
 		}
+
+		user, err := repo.GetAdminUser(c.Request.Context(), userID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load admin user"})
+			c.Abort()
+			return
+		}
+		if err != nil || user.Disabled {
+			session.Clear()
+			_ = session.Save()
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		c.Set(AdminUserKey, user)
+		c.Set(ActorKey, user.Email)
 		c.Next()
 	}
 }
 
+// authenticateAPIToken resolves a bearer token to the admin it acts as. A
+// personal token acts as its owner, who must still be active; a service
+// token acts as a placeholder admin with no role of its own.
+func authenticateAPIToken(c *gin.Context, repo repository.RepositoryInterface, bearer string) {
+	unauthorized := func() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API token"})
+		c.Abort()
+	}
+	if !auth.IsAPIToken(bearer) {
+		unauthorized()
+		return
+	}
 
+	ctx := c.Request.Context()
+	token, err := repo.GetAPIToken(ctx, auth.APITokenID(bearer))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load API token"})
+		c.Abort()
+		return
+	}
+	now := time.Now()
+	if err != nil || !token.Active(now) {
+		unauthorized()
+		return
+	}
+
+	var user *models.AdminUser
+	switch token.Kind {
+	case models.APITokenKindPersonal:
+		user, err = repo.GetAdminUser(ctx, token.OwnerID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load admin user"})
+			c.Abort()
+			return
+		}
+		if err != nil || user.Disabled {
+			unauthorized()
+			return
+		}
+	case models.APITokenKindService:
+		user = &models.AdminUser{ID: "token:" + token.ID, Email: "service:" + token.Name, DisplayName: token.Name}
+	default:
+		unauthorized()
+		return
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval {
+		if err := repo.TouchAPIToken(context.WithoutCancel(ctx), token.ID, now, c.ClientIP()); err != nil {
+			slog.ErrorContext(ctx, "Error recording use of API token", "prefix", token.Prefix, "error", err)
+		}
+	}
+
+	c.Set(AdminUserKey, user)
+	c.Set(ActorKey, user.Email)
+	c.Set(APITokenKey, token)
+	c.Next()
+}
+
+// bearerToken returns the credential from an Authorization: Bearer header
+func bearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return "", false
+	}
+	return strings.TrimSpace(header[len("Bearer "):]), true
+}
+
+// RequireBearerToken only lets through requests presenting the given token
+// in an Authorization: Bearer header, for endpoints such as /metrics that
+// are read by machines rather than admins
+func RequireBearerToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bearer, ok := bearerToken(c)
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession rejects requests authenticated with an API token, for
+// routes such as token management that only a signed-in admin may use. It
+// must run after RequireAdmin.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if CurrentAPIToken(c) != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint cannot be used with an API token"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission rejects admins whose role does not grant the permission.
+// Requests made with an API token also need the permission in the token's
+// scopes; service tokens have only their scopes. It must run after
+// RequireAdmin.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasPermission(c, permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireTwoFactor rejects admins whose role the policy says must use 2FA
+// until they have enrolled. Sessions started through single sign-on are let
+// through, as the identity provider enforces its own second factor. It must
+// run after RequireAdmin and should not wrap the routes used to enrol.
+func RequireTwoFactor(policy auth.TwoFactorPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := CurrentAdmin(c)
+		if user != nil && policy.Required(user.Role) && !user.TwoFactorEnabled && !signedInWithSSO(c) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":                  "Two-factor authentication must be enabled for your role",
+				"twoFactorSetupRequired": true,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func hasPermission(c *gin.Context, permission string) bool {
+	user := CurrentAdmin(c)
+	if user == nil {
+		return false
+	}
+	token := CurrentAPIToken(c)
+	if token == nil {
+		return auth.Can(user.Role, permission)
+	}
+	if !token.HasScope(permission) {
+		return false
+	}
+	return token.Kind == models.APITokenKindService || auth.Can(user.Role, permission)
+}
+
+// CurrentAPIToken returns the API token the request was authenticated with, or nil
+func CurrentAPIToken(c *gin.Context) *models.APIToken {
+	value, _ := c.Get(APITokenKey)
+	token, _ := value.(*models.APIToken)
+	return token
+}
+
+func signedInWithSSO(c *gin.Context) bool {
+	if CurrentAPIToken(c) != nil {
+		return false
+	}
+	method, _ := sessions.Default(c).Get(SessionAuthMethodKey).(string)
+	return method == AuthMethodSSO
+}
+
+// CurrentAdmin returns the admin user loaded by RequireAdmin, or nil
+func CurrentAdmin(c *gin.Context) *models.AdminUser {
+	user, _ := c.Get(AdminUserKey)
+	admin, _ := user.(*models.AdminUser)
+	return admin
+}
+
+// currentActor resolves who is making the request, falling back to the
+// session for public auth routes that do not run RequireAdmin
+func currentActor(c *gin.Context) string {
+	if actor := c.GetString(ActorKey); actor != "" {
+		return actor
+	}
+	email, _ := sessions.Default(c).Get(SessionEmailKey).(string)
+	return email
+}
@@ -5,11 +5,17 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/auth"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func setupAuthTestRouter() *gin.Engine {
@@ -48,9 +54,10 @@ func TestRequireAdmin(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := setupAuthTestRouter()
+			mockRepo := new(repository.MockRepository)
 
 			// Setup a test handler that requires admin
-			r.GET("/protected", RequireAdmin(), func(c *gin.Context) {
+			r.GET("/protected", RequireAdmin(mockRepo), func(c *gin.Context) {
 				c.JSON(http.StatusOK, gin.H{"message": "success"})
 			})
 
@@ -75,11 +82,32 @@ func TestRequireAdmin(t *testing.T) {
 	}
 }
 
+// loginAs establishes a session for the given admin user ID and returns the cookie
+func loginAs(r *gin.Engine, userID string) string {
+	r.POST("/test-login", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set(SessionUserIDKey, userID)
+		session.Set(SessionEmailKey, "admin@example.com")
+		session.Save()
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+
+	loginReq, _ := http.NewRequest("POST", "/test-login", nil)
+	loginW := httptest.NewRecorder()
+	r.ServeHTTP(loginW, loginReq)
+	return loginW.Header().Get("Set-Cookie")
+}
+
 func TestRequireAdmin_WithSession(t *testing.T) {
 	r := setupAuthTestRouter()
+	mockRepo := new(repository.MockRepository)
+	admin := &models.AdminUser{ID: "user-1", Email: "admin@example.com"}
+	mockRepo.On("GetAdminUser", mock.Anything, "user-1").Return(admin, nil)
 
 	// Setup a test handler that requires admin
-	r.GET("/protected", RequireAdmin(), func(c *gin.Context) {
+	r.GET("/protected", RequireAdmin(mockRepo), func(c *gin.Context) {
+		assert.Equal(t, admin, CurrentAdmin(c))
+		assert.Equal(t, "admin@example.com", c.GetString(ActorKey))
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
 
@@ -90,21 +118,8 @@ func TestRequireAdmin_WithSession(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w1.Code)
 
 	// We need to simulate a login to set the session
-	// For testing, we'll use a helper route that sets the session
-	r.POST("/test-login", func(c *gin.Context) {
-		session := sessions.Default(c)
-		session.Set("isAdmin", true)
-		session.Save()
-		c.JSON(http.StatusOK, gin.H{"success": true})
-	})
-	
-	loginReq2, _ := http.NewRequest("POST", "/test-login", nil)
-	loginW2 := httptest.NewRecorder()
-	r.ServeHTTP(loginW2, loginReq2)
-	
-	// Extract cookies from login response
-	cookies := loginW2.Header().Get("Set-Cookie")
-	
+	cookies := loginAs(r, "user-1")
+
 	// Now make protected request with session cookie
 	req2, _ := http.NewRequest("GET", "/protected", nil)
 	if cookies != "" {
@@ -112,17 +127,68 @@ func TestRequireAdmin_WithSession(t *testing.T) {
 	}
 	w2 := httptest.NewRecorder()
 	r.ServeHTTP(w2, req2)
-	
+
 	// Session cookie should work, so request should succeed
 	assert.Equal(t, http.StatusOK, w2.Code)
 	assert.Contains(t, w2.Body.String(), "success")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRequireAdmin_RejectsDisabledOrDeletedUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		user           *models.AdminUser
+		repoError      error
+		expectedStatus int
+	}{
+		{
+			name:           "disabled user",
+			user:           &models.AdminUser{ID: "user-1", Email: "admin@example.com", Disabled: true},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "deleted user",
+			repoError:      repository.ErrNotFound,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "repository error",
+			repoError:      assert.AnError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupAuthTestRouter()
+			mockRepo := new(repository.MockRepository)
+			if tt.user != nil {
+				mockRepo.On("GetAdminUser", mock.Anything, "user-1").Return(tt.user, nil)
+			} else {
+				mockRepo.On("GetAdminUser", mock.Anything, "user-1").Return(nil, tt.repoError)
+			}
+
+			r.GET("/protected", RequireAdmin(mockRepo), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			cookies := loginAs(r, "user-1")
+			req, _ := http.NewRequest("GET", "/protected", nil)
+			req.Header.Set("Cookie", cookies)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.NotContains(t, w.Body.String(), "success")
+		})
+	}
 }
 
 func TestRequireAdmin_Integration(t *testing.T) {
 	r := setupAuthTestRouter()
 
 	// Protected route
-	r.GET("/admin/stats", RequireAdmin(), func(c *gin.Context) {
+	r.GET("/admin/stats", RequireAdmin(new(repository.MockRepository)), func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"stats": "data"})
 	})
 
@@ -141,3 +207,354 @@ func TestRequireAdmin_Integration(t *testing.T) {
 	}
 }
 
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name           string
+		user           *models.AdminUser
+		expectedStatus int
+	}{
+		{
+			name:           "role grants permission",
+			user:           &models.AdminUser{ID: "1", Role: auth.RoleCheckInVolunteer},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "role lacks permission",
+			user:           &models.AdminUser{ID: "1", Role: auth.RoleAnalyst},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "no role",
+			user:           &models.AdminUser{ID: "1"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "RequireAdmin did not run",
+			user:           nil,
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupAuthTestRouter()
+			r.POST("/attendees/:id/checkin", func(c *gin.Context) {
+				if tt.user != nil {
+					c.Set(AdminUserKey, tt.user)
+				}
+				c.Next()
+			}, RequirePermission(auth.PermAttendeesCheckIn), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			req, _ := http.NewRequest("POST", "/attendees/1/checkin", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestRequireTwoFactor(t *testing.T) {
+	policy, err := auth.ParseTwoFactorPolicy(auth.RoleOwner)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		user           *models.AdminUser
+		expectedStatus int
+	}{
+		{
+			name:           "required role enrolled",
+			user:           &models.AdminUser{ID: "1", Role: auth.RoleOwner, TwoFactorEnabled: true},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "required role not enrolled",
+			user:           &models.AdminUser{ID: "1", Role: auth.RoleOwner},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "optional role not enrolled",
+			user:           &models.AdminUser{ID: "1", Role: auth.RoleAnalyst},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupAuthTestRouter()
+			r.GET("/stats", func(c *gin.Context) {
+				c.Set(AdminUserKey, tt.user)
+				c.Next()
+			}, RequireTwoFactor(policy), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			req, _ := http.NewRequest("GET", "/stats", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusForbidden {
+				var response map[string]interface{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, true, response["twoFactorSetupRequired"])
+			}
+		})
+	}
+}
+
+func TestRequireAdmin_APIToken(t *testing.T) {
+	const bearer = "aiw_test-token-value"
+	tokenID := auth.APITokenID(bearer)
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	owner := &models.AdminUser{ID: "user-1", Email: "admin@example.com", Role: auth.RoleAnalyst}
+
+	tests := []struct {
+		name           string
+		header         string
+		token          *models.APIToken
+		owner          *models.AdminUser
+		expectedStatus int
+		expectedActor  string
+	}{
+		{
+			name:           "personal token",
+			header:         "Bearer " + bearer,
+			token:          &models.APIToken{ID: tokenID, Kind: models.APITokenKindPersonal, OwnerID: "user-1", ExpiresAt: future},
+			owner:          owner,
+			expectedStatus: http.StatusOK,
+			expectedActor:  "admin@example.com",
+		},
+		{
+			name:           "service token",
+			header:         "bearer " + bearer,
+			token:          &models.APIToken{ID: tokenID, Name: "crm", Kind: models.APITokenKindService, ExpiresAt: future},
+			expectedStatus: http.StatusOK,
+			expectedActor:  "service:crm",
+		},
+		{
+			name:           "expired token",
+			header:         "Bearer " + bearer,
+			token:          &models.APIToken{ID: tokenID, Kind: models.APITokenKindService, ExpiresAt: past},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "revoked token",
+			header:         "Bearer " + bearer,
+			token:          &models.APIToken{ID: tokenID, Kind: models.APITokenKindService, ExpiresAt: future, RevokedAt: &past},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "disabled owner",
+			header:         "Bearer " + bearer,
+			token:          &models.APIToken{ID: tokenID, Kind: models.APITokenKindPersonal, OwnerID: "user-1", ExpiresAt: future},
+			owner:          &models.AdminUser{ID: "user-1", Disabled: true},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unknown token",
+			header:         "Bearer " + bearer,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "not an API token",
+			header:         "Bearer something-else",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupAuthTestRouter()
+			mockRepo := new(repository.MockRepository)
+			if tt.token != nil {
+				mockRepo.On("GetAPIToken", mock.Anything, tokenID).Return(tt.token, nil)
+			} else {
+				mockRepo.On("GetAPIToken", mock.Anything, tokenID).Return(nil, repository.ErrNotFound).Maybe()
+			}
+			if tt.owner != nil {
+				mockRepo.On("GetAdminUser", mock.Anything, "user-1").Return(tt.owner, nil)
+			}
+			if tt.expectedStatus == http.StatusOK {
+				mockRepo.On("TouchAPIToken", mock.Anything, tokenID, mock.Anything, mock.Anything).Return(nil)
+			}
+
+			r.GET("/protected", RequireAdmin(mockRepo), func(c *gin.Context) {
+				assert.Equal(t, tt.token, CurrentAPIToken(c))
+				assert.Equal(t, tt.expectedActor, c.GetString(ActorKey))
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			req, _ := http.NewRequest("GET", "/protected", nil)
+			req.Header.Set("Authorization", tt.header)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Empty(t, w.Header().Get("Set-Cookie"))
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequireAdmin_APITokenRecentlyUsed(t *testing.T) {
+	const bearer = "aiw_test-token-value"
+	recent := time.Now().Add(-10 * time.Second)
+	token := &models.APIToken{ID: auth.APITokenID(bearer), Kind: models.APITokenKindService, ExpiresAt: time.Now().Add(time.Hour), LastUsedAt: &recent}
+
+	r := setupAuthTestRouter()
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetAPIToken", mock.Anything, token.ID).Return(token, nil)
+	r.GET("/protected", RequireAdmin(mockRepo), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+bearer)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// The last-used time is not rewritten on every request
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRepo.AssertNotCalled(t, "TouchAPIToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRequirePermission_APIToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		user           *models.AdminUser
+		token          *models.APIToken
+		expectedStatus int
+	}{
+		{
+			name:           "personal token with scope",
+			user:           &models.AdminUser{ID: "1", Role: auth.RoleCheckInVolunteer},
+			token:          &models.APIToken{Kind: models.APITokenKindPersonal, Scopes: []string{auth.PermAttendeesCheckIn}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "personal token without scope",
+			user:           &models.AdminUser{ID: "1", Role: auth.RoleCheckInVolunteer},
+			token:          &models.APIToken{Kind: models.APITokenKindPersonal, Scopes: []string{auth.PermAttendeesRead}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "personal token whose owner lost the permission",
+			user:           &models.AdminUser{ID: "1", Role: auth.RoleAnalyst},
+			token:          &models.APIToken{Kind: models.APITokenKindPersonal, Scopes: []string{auth.PermAttendeesCheckIn}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "service token with scope",
+			user:           &models.AdminUser{ID: "token:1"},
+			token:          &models.APIToken{Kind: models.APITokenKindService, Scopes: []string{auth.PermAttendeesCheckIn}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "service token without scope",
+			user:           &models.AdminUser{ID: "token:1"},
+			token:          &models.APIToken{Kind: models.APITokenKindService},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupAuthTestRouter()
+			r.POST("/attendees/:id/checkin", func(c *gin.Context) {
+				c.Set(AdminUserKey, tt.user)
+				c.Set(APITokenKey, tt.token)
+				c.Next()
+			}, RequirePermission(auth.PermAttendeesCheckIn), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			req, _ := http.NewRequest("POST", "/attendees/1/checkin", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	r := setupAuthTestRouter()
+	r.POST("/admin/me/tokens", func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			c.Set(APITokenKey, &models.APIToken{})
+		}
+		c.Next()
+	}, RequireSession(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	req, _ := http.NewRequest("POST", "/admin/me/tokens", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("POST", "/admin/me/tokens", nil)
+	req.Header.Set("Authorization", "Bearer aiw_x")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRequireTwoFactor_SSOSession(t *testing.T) {
+	policy, err := auth.ParseTwoFactorPolicy(auth.RoleOwner)
+	assert.NoError(t, err)
+
+	r := setupAuthTestRouter()
+	r.GET("/stats", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set(SessionAuthMethodKey, c.Query("method"))
+		c.Set(AdminUserKey, &models.AdminUser{ID: "1", Role: auth.RoleOwner})
+		c.Next()
+	}, RequireTwoFactor(policy), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	// The identity provider is trusted to have checked a second factor
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/stats?method=sso", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/stats?method=password", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRequireBearerToken(t *testing.T) {
+	r := setupAuthTestRouter()
+	r.GET("/metrics", RequireBearerToken("scrape-secret"), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	tests := []struct {
+		name           string
+		header         string
+		expectedStatus int
+	}{
+		{name: "valid token", header: "Bearer scrape-secret", expectedStatus: http.StatusOK},
+		{name: "wrong token", header: "Bearer scrape-guess", expectedStatus: http.StatusUnauthorized},
+		{name: "no header", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/metrics", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-india-workshop-backend/internal/models"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireCSRFToken(t *testing.T) {
+	r := setupAuthTestRouter()
+	r.POST("/login", func(c *gin.Context) {
+		session := sessions.Default(c)
+		token, err := IssueCSRFToken(session)
+		require.NoError(t, err)
+		require.NoError(t, session.Save())
+		c.String(http.StatusOK, token)
+	})
+	protected := r.Group("/api", RequireCSRFToken([]string{"https://admin.example.com"}))
+	protected.GET("/speakers", func(c *gin.Context) { c.Status(http.StatusOK) })
+	protected.POST("/speakers", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	login := httptest.NewRecorder()
+	r.ServeHTTP(login, httptest.NewRequest("POST", "/login", nil))
+	cookie := login.Header().Get("Set-Cookie")
+	token := login.Body.String()
+
+	tests := []struct {
+		name           string
+		method         string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{
+			name:           "safe method needs no token",
+			method:         "GET",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "valid token",
+			method:         "POST",
+			headers:        map[string]string{CSRFHeader: token},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "valid token from allowed origin",
+			method:         "POST",
+			headers:        map[string]string{CSRFHeader: token, "Origin": "https://admin.example.com"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "valid token from same origin",
+			method:         "POST",
+			headers:        map[string]string{CSRFHeader: token, "Origin": "http://example.com"},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "missing token",
+			method:         "POST",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "wrong token",
+			method:         "POST",
+			headers:        map[string]string{CSRFHeader: "forged"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "foreign origin",
+			method:         "POST",
+			headers:        map[string]string{CSRFHeader: token, "Origin": "https://evil.example.net"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "cross-site fetch without origin",
+			method:         "POST",
+			headers:        map[string]string{CSRFHeader: token, "Sec-Fetch-Site": "cross-site"},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/speakers", nil)
+			req.Header.Set("Cookie", cookie)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestRequireCSRFToken_NoSessionToken(t *testing.T) {
+	r := setupAuthTestRouter()
+	r.POST("/api/speakers", RequireCSRFToken(nil), func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	// An empty header must not match a session without a token
+	req := httptest.NewRequest("POST", "/api/speakers", nil)
+	req.Header.Set(CSRFHeader, "")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCheckOrigin(t *testing.T) {
+	r := setupAuthTestRouter()
+	r.POST("/api/admin/login", CheckOrigin([]string{"https://admin.example.com"}), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{name: "no browser headers", expectedStatus: http.StatusOK},
+		{name: "allowed origin", headers: map[string]string{"Origin": "https://admin.example.com"}, expectedStatus: http.StatusOK},
+		{name: "same-site fetch", headers: map[string]string{"Sec-Fetch-Site": "same-site"}, expectedStatus: http.StatusOK},
+		{name: "foreign origin", headers: map[string]string{"Origin": "https://evil.example.net"}, expectedStatus: http.StatusForbidden},
+		{name: "null origin", headers: map[string]string{"Origin": "null"}, expectedStatus: http.StatusForbidden},
+		{name: "cross-site fetch", headers: map[string]string{"Sec-Fetch-Site": "cross-site"}, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/admin/login", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestRequireCSRFToken_APIToken(t *testing.T) {
+	r := setupAuthTestRouter()
+	r.POST("/api/speakers", func(c *gin.Context) {
+		c.Set(APITokenKey, &models.APIToken{})
+		c.Next()
+	}, RequireCSRFToken([]string{"https://admin.example.com"}), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	// Scripts using a bearer token have no session and send no CSRF token
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/speakers", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecatedKey is set on requests to a deprecated route, whose responses
+// keep the shape they had before it was deprecated
+const DeprecatedKey = "deprecated"
+
+// Deprecated marks the responses of routes under prefix as deprecated since
+// the given time (RFC 9745), with a Link to the same route under successor
+func Deprecated(prefix, successor string, since time.Time) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(since.Unix(), 10)
+	return func(c *gin.Context) {
+		c.Set(DeprecatedKey, true)
+		c.Header("Deprecation", deprecation)
+		if rest, ok := strings.CutPrefix(c.Request.URL.Path, prefix); ok {
+			c.Header("Link", "<"+successor+rest+`>; rel="successor-version"`)
+		}
+		c.Next()
+	}
+}
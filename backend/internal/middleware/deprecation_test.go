@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	since := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	r := gin.New()
+	r.Use(Deprecated("/api", "/api/v1", since))
+	r.GET("/api/speakers/:id", func(c *gin.Context) {
+		assert.True(t, c.GetBool(DeprecatedKey))
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/speakers/s1", nil))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "@1790812800", w.Header().Get("Deprecation"))
+	assert.Equal(t, `</api/v1/speakers/s1>; rel="successor-version"`, w.Header().Get("Link"))
+}
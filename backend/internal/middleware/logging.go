@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"ai-india-workshop-backend/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern limits the request IDs accepted from clients, so a crafted
+// header cannot inject text into the logs
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+// RequestID tags each request with an ID that is returned in X-Request-ID
+// and added to everything logged for it. A valid incoming X-Request-ID is
+// kept; otherwise the trace ID from a Cloud Trace or W3C traceparent header
+// is used so the logs line up with the trace, and failing that one is generated.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := requestID(c.Request.Header)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+func requestID(header http.Header) string {
+	if id := header.Get(RequestIDHeader); requestIDPattern.MatchString(id) {
+		return id
+	}
+	// X-Cloud-Trace-Context: TRACE_ID/SPAN_ID;o=OPTIONS
+	if value := header.Get("X-Cloud-Trace-Context"); value != "" {
+		traceID, _, _ := strings.Cut(value, "/")
+		if requestIDPattern.MatchString(traceID) {
+			return traceID
+		}
+	}
+	// traceparent: VERSION-TRACE_ID-PARENT_ID-FLAGS
+	if parts := strings.Split(header.Get("traceparent"), "-"); len(parts) == 4 && requestIDPattern.MatchString(parts[1]) {
+		return parts[1]
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// RequestLogger logs every request once it has been handled. Server errors
+// are logged as errors and everything else at info.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("clientIp", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+		slog.LogAttrs(c.Request.Context(), level, "Request handled", attrs...)
+	}
+}
+
+// Recover turns a panic in a handler into a 500 response, logging it with
+// the stack trace
+func Recover() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// The server aborts the response itself for this one
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+			slog.ErrorContext(c.Request.Context(), "Panic handling request",
+				"panic", fmt.Sprint(recovered),
+				"stack", string(debug.Stack()),
+			)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}()
+		c.Next()
+	}
+}
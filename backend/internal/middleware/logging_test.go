@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-india-workshop-backend/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs sends the default logger's output to the returned buffer for
+// the rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected string
+	}{
+		{name: "incoming request ID", headers: map[string]string{"X-Request-ID": "abc-123"}, expected: "abc-123"},
+		{
+			name:     "cloud trace",
+			headers:  map[string]string{"X-Cloud-Trace-Context": "105445aa7843bc8bf206b12000100000/1;o=1"},
+			expected: "105445aa7843bc8bf206b12000100000",
+		},
+		{
+			name:     "traceparent",
+			headers:  map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			expected: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name: "request ID preferred over trace",
+			headers: map[string]string{
+				"X-Request-ID":          "abc-123",
+				"X-Cloud-Trace-Context": "105445aa7843bc8bf206b12000100000/1;o=1",
+			},
+			expected: "abc-123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			r := gin.New()
+			r.GET("/test", RequestID(), func(c *gin.Context) {
+				seen = logging.RequestID(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, seen)
+			assert.Equal(t, tt.expected, w.Header().Get(RequestIDHeader))
+		})
+	}
+}
+
+func TestRequestID_GeneratedWhenMissingOrInvalid(t *testing.T) {
+	r := gin.New()
+	r.GET("/test", RequestID(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, header := range []string{"", "bad id\nINFO forged entry"} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set(RequestIDHeader, header)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Regexp(t, `^[0-9a-f]{32}$`, w.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	logs := captureLogs(t)
+
+	r := gin.New()
+	r.Use(RequestID(), RequestLogger())
+	r.GET("/api/speakers/:id", func(c *gin.Context) { c.Status(http.StatusServiceUnavailable) })
+
+	req := httptest.NewRequest("GET", "/api/speakers/spk-1", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+	assert.Equal(t, "ERROR", record["severity"])
+	assert.Equal(t, "req-1", record["requestId"])
+	assert.Equal(t, "/api/speakers/:id", record["route"])
+	assert.Equal(t, "/api/speakers/spk-1", record["path"])
+	assert.Equal(t, 503.0, record["status"])
+}
+
+func TestRecover(t *testing.T) {
+	logs := captureLogs(t)
+
+	r := gin.New()
+	r.Use(RequestID(), Recover())
+	r.GET("/panic", func(c *gin.Context) { panic("boom for someone@example.com") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+	assert.Equal(t, "Panic handling request", record["message"])
+	assert.Equal(t, "boom for s***@example.com", record["panic"])
+	assert.Contains(t, record["stack"], "runtime/debug.Stack")
+}
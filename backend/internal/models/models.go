@@ -1,30 +1,41 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Attendee struct {
-	ID          string    `json:"id" firestore:"id"`
-	Name        string    `json:"name" firestore:"name"`
-	Email       string    `json:"email" firestore:"email"`
-	Designation string    `json:"designation" firestore:"designation"`
-	CreatedAt   time.Time `json:"createdAt" firestore:"createdAt"`
+	ID          string     `json:"id" firestore:"id"`
+	Name        string     `json:"name" firestore:"name"`
+	Email       string     `json:"email" firestore:"email"`
+	Designation string     `json:"designation" firestore:"designation"`
+	CreatedAt   time.Time  `json:"createdAt" firestore:"createdAt"`
+	CheckedInAt *time.Time `json:"checkedInAt,omitempty" firestore:"checkedInAt,omitempty"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty" firestore:"deletedAt,omitempty"`
+
+	// Answers to the workshop's custom registration questions, keyed by
+	// FormField.ID
+	Answers map[string]interface{} `json:"answers,omitempty" firestore:"answers,omitempty"`
 }
 
 type Speaker struct {
-	ID       string `json:"id" firestore:"id"`
-	Name     string `json:"name" firestore:"name"`
-	Bio      string `json:"bio" firestore:"bio"`
-	Avatar   string `json:"avatar,omitempty" firestore:"avatar,omitempty"`
-	LinkedIn string `json:"linkedin,omitempty" firestore:"linkedin,omitempty"`
-	Twitter  string `json:"twitter,omitempty" firestore:"twitter,omitempty"`
+	ID        string     `json:"id" firestore:"id"`
+	Name      string     `json:"name" firestore:"name"`
+	Bio       string     `json:"bio" firestore:"bio"`
+	Avatar    string     `json:"avatar,omitempty" firestore:"avatar,omitempty"`
+	LinkedIn  string     `json:"linkedin,omitempty" firestore:"linkedin,omitempty"`
+	Twitter   string     `json:"twitter,omitempty" firestore:"twitter,omitempty"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty" firestore:"deletedAt,omitempty"`
 }
 
 type Session struct {
-	ID          string   `json:"id" firestore:"id"`
-	Title       string   `json:"title" firestore:"title"`
-	Description string   `json:"description" firestore:"description"`
-	Time        string   `json:"time" firestore:"time"`
-	Speakers    []string `json:"speakers" firestore:"speakers"`
+	ID          string     `json:"id" firestore:"id"`
+	Title       string     `json:"title" firestore:"title"`
+	Description string     `json:"description" firestore:"description"`
+	Time        string     `json:"time" firestore:"time"`
+	Speakers    []string   `json:"speakers" firestore:"speakers"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty" firestore:"deletedAt,omitempty"`
 }
 
 type SessionWithSpeakers struct {
@@ -41,4 +52,429 @@ type DesignationCount struct {
 	Count       int    `json:"count"`
 }
 
+// DesignationTaxonomy is the workshop's list of canonical designations.
+// Registrations matching a name or alias, ignoring case and spacing, are
+// stored under the name.
+type DesignationTaxonomy struct {
+	Designations []Designation `json:"designations" firestore:"designations"`
+	UpdatedAt    time.Time     `json:"updatedAt" firestore:"updatedAt"`
+	UpdatedBy    string        `json:"updatedBy,omitempty" firestore:"updatedBy,omitempty"`
+}
+
+type Designation struct {
+	Name    string   `json:"name" firestore:"name"`
+	Aliases []string `json:"aliases" firestore:"aliases"`
+}
+
+// DesignationChange is one rewrite made by remapping existing attendees
+type DesignationChange struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+// AnswerBreakdown counts the answers given to a choice question
+type AnswerBreakdown struct {
+	FieldID string        `json:"fieldId"`
+	Label   string        `json:"label"`
+	Counts  []AnswerCount `json:"counts"`
+}
+
+type AnswerCount struct {
+	Answer string `json:"answer"`
+	Count  int    `json:"count"`
+}
+
+// AnalyticsDay counts attendee events during one UTC day. Each map is keyed
+// by the quarter hour of the day the events happened in, "0" to "95", which
+// is fine enough to regroup them into hours or days in any timezone.
+type AnalyticsDay struct {
+	Date          string         `json:"date" firestore:"date"`
+	Registrations map[string]int `json:"registrations,omitempty" firestore:"registrations,omitempty"`
+	Cancellations map[string]int `json:"cancellations,omitempty" firestore:"cancellations,omitempty"`
+	CheckIns      map[string]int `json:"checkIns,omitempty" firestore:"checkIns,omitempty"`
+}
+
+// Attendee events counted in AnalyticsDay. The values double as the
+// Firestore field names.
+const (
+	AnalyticsRegistrations = "registrations"
+	AnalyticsCancellations = "cancellations"
+	AnalyticsCheckIns      = "checkIns"
+)
+
+// Analytics is a time series of attendee events bucketed by hour or day
+type Analytics struct {
+	Granularity string            `json:"granularity"`
+	Timezone    string            `json:"timezone"`
+	Buckets     []AnalyticsBucket `json:"buckets"`
+	Totals      AnalyticsTotals   `json:"totals"`
+}
+
+type AnalyticsBucket struct {
+	Start         time.Time `json:"start"`
+	Registrations int       `json:"registrations"`
+	Cancellations int       `json:"cancellations"`
+	CheckIns      int       `json:"checkIns"`
+	// Cumulative is the number of registrations not cancelled by the end of
+	// the bucket, counting everything before the first bucket too
+	Cumulative int `json:"cumulative"`
+}
+
+// AnalyticsTotals covers the whole history, not just the buckets returned
+type AnalyticsTotals struct {
+	Registrations int `json:"registrations"`
+	Cancellations int `json:"cancellations"`
+	CheckIns      int `json:"checkIns"`
+	// Active is registrations minus cancellations
+	Active int `json:"active"`
+	// ConversionRate is the share of active registrations that checked in
+	ConversionRate float64 `json:"conversionRate"`
+}
+
+// RegistrationForm holds the custom questions a workshop asks on top of
+// name, email and designation
+type RegistrationForm struct {
+	Fields    []FormField `json:"fields" firestore:"fields"`
+	UpdatedAt time.Time   `json:"updatedAt" firestore:"updatedAt"`
+	UpdatedBy string      `json:"updatedBy,omitempty" firestore:"updatedBy,omitempty"`
+}
+
+// FormField is one custom registration question. ID is the key its answer
+// is stored under and must not change once people have answered.
+type FormField struct {
+	ID       string   `json:"id" firestore:"id"`
+	Label    string   `json:"label" firestore:"label"`
+	Type     string   `json:"type" firestore:"type"`
+	Required bool     `json:"required" firestore:"required"`
+	Options  []string `json:"options,omitempty" firestore:"options,omitempty"`
+	// Pattern is a regular expression text answers must match in full
+	Pattern  string `json:"pattern,omitempty" firestore:"pattern,omitempty"`
+	HelpText string `json:"helpText,omitempty" firestore:"helpText,omitempty"`
+}
+
+// Form field types. Checkbox answers are booleans, so a required checkbox
+// must be ticked (e.g. for consent); multiselect answers are lists of options.
+const (
+	FieldTypeText        = "text"
+	FieldTypeTextarea    = "textarea"
+	FieldTypeNumber      = "number"
+	FieldTypeSelect      = "select"
+	FieldTypeMultiSelect = "multiselect"
+	FieldTypeCheckbox    = "checkbox"
+)
+
+// Resource types that can be soft deleted. The values double as the
+// Firestore collection names.
+const (
+	ResourceAttendees = "attendees"
+	ResourceSpeakers  = "speakers"
+	ResourceSessions  = "sessions"
+)
+
+// Trash holds every soft-deleted record that has not been purged yet
+type Trash struct {
+	Attendees []*Attendee `json:"attendees"`
+	Speakers  []*Speaker  `json:"speakers"`
+	Sessions  []*Session  `json:"sessions"`
+}
+
+// WorkshopData is every attendee, speaker and session of a workshop,
+// including those in the trash, as held in a backup
+type WorkshopData struct {
+	Speakers  []*Speaker
+	Sessions  []*Session
+	Attendees []*Attendee
+}
+
+// AuditEntry is an append-only record of an admin action
+type AuditEntry struct {
+	ID           string                 `json:"id" firestore:"id"`
+	Timestamp    time.Time              `json:"timestamp" firestore:"timestamp"`
+	Actor        string                 `json:"actor" firestore:"actor"`
+	Action       string                 `json:"action" firestore:"action"`
+	ResourceType string                 `json:"resourceType,omitempty" firestore:"resourceType,omitempty"`
+	ResourceID   string                 `json:"resourceId,omitempty" firestore:"resourceId,omitempty"`
+	Before       map[string]interface{} `json:"before,omitempty" firestore:"before,omitempty"`
+	After        map[string]interface{} `json:"after,omitempty" firestore:"after,omitempty"`
+	Status       int                    `json:"status" firestore:"status"`
+	IP           string                 `json:"ip" firestore:"ip"`
+	UserAgent    string                 `json:"userAgent" firestore:"userAgent"`
+}
+
+// AuditQuery filters audit log lookups. Zero values are ignored.
+type AuditQuery struct {
+	From         time.Time
+	To           time.Time
+	Actor        string
+	ResourceType string
+	ResourceID   string
+	Limit        int
+}
+
+// LoginAttempt records an admin sign-in attempt. Reason says why a failed
+// attempt was rejected.
+type LoginAttempt struct {
+	ID        string    `json:"id" firestore:"id"`
+	Timestamp time.Time `json:"timestamp" firestore:"timestamp"`
+	Email     string    `json:"email" firestore:"email"`
+	IP        string    `json:"ip" firestore:"ip"`
+	UserAgent string    `json:"userAgent" firestore:"userAgent"`
+	Success   bool      `json:"success" firestore:"success"`
+	Reason    string    `json:"reason,omitempty" firestore:"reason,omitempty"`
+}
+
+// Reasons recorded on failed login attempts
+const (
+	LoginReasonUnknownEmail     = "unknown_email"
+	LoginReasonInvalidPassword  = "invalid_password"
+	LoginReasonAccountDisabled  = "account_disabled"
+	LoginReasonInvalidTwoFactor = "invalid_2fa_code"
+	LoginReasonLockedOut        = "locked_out"
+	LoginReasonSSOFailed        = "sso_failed"
+	LoginReasonSSONotAllowed    = "sso_not_allowed"
+)
 
+// LoginAttemptQuery filters login attempt lookups. Zero values are ignored.
+type LoginAttemptQuery struct {
+	Email      string
+	IP         string
+	FailedOnly bool
+	Limit      int
+}
+
+// AdminUser is an individual account that can sign in to the admin panel
+type AdminUser struct {
+	ID           string     `json:"id" firestore:"id"`
+	Email        string     `json:"email" firestore:"email"`
+	DisplayName  string     `json:"displayName" firestore:"displayName"`
+	Role         string     `json:"role" firestore:"role"`
+	PasswordHash string     `json:"-" firestore:"passwordHash"`
+	Disabled     bool       `json:"disabled" firestore:"disabled"`
+	CreatedAt    time.Time  `json:"createdAt" firestore:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt" firestore:"updatedAt"`
+	LastLoginAt  *time.Time `json:"lastLoginAt,omitempty" firestore:"lastLoginAt,omitempty"`
+
+	// Two-factor authentication. PendingTOTPSecret holds a secret that has
+	// been issued but not yet confirmed with a code; LastTOTPStep stops a
+	// code being used twice.
+	TwoFactorEnabled   bool     `json:"twoFactorEnabled" firestore:"twoFactorEnabled"`
+	TOTPSecret         string   `json:"-" firestore:"totpSecret,omitempty"`
+	PendingTOTPSecret  string   `json:"-" firestore:"pendingTotpSecret,omitempty"`
+	RecoveryCodeHashes []string `json:"-" firestore:"recoveryCodeHashes,omitempty"`
+	LastTOTPStep       int64    `json:"-" firestore:"lastTotpStep,omitempty"`
+
+	// Single sign-on. OIDCSubject links the account to the provider's user.
+	// SSOManaged accounts were created by signing in with SSO and have
+	// their role updated from the role mapping at every sign-in.
+	OIDCSubject string `json:"-" firestore:"oidcSubject,omitempty"`
+	SSOManaged  bool   `json:"ssoManaged" firestore:"ssoManaged"`
+}
+
+// APIToken lets a script call the admin API with an Authorization: Bearer
+// header. Only a hash of the token is stored, and it doubles as the ID.
+// Personal tokens act as the admin who created them; service tokens belong
+// to no admin and are limited to their scopes.
+type APIToken struct {
+	ID         string     `json:"id" firestore:"id"`
+	Name       string     `json:"name" firestore:"name"`
+	Kind       string     `json:"kind" firestore:"kind"`
+	Prefix     string     `json:"prefix" firestore:"prefix"`
+	OwnerID    string     `json:"ownerId,omitempty" firestore:"ownerId,omitempty"`
+	Scopes     []string   `json:"scopes" firestore:"scopes"`
+	CreatedBy  string     `json:"createdBy" firestore:"createdBy"`
+	CreatedAt  time.Time  `json:"createdAt" firestore:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt" firestore:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" firestore:"lastUsedAt,omitempty"`
+	LastUsedIP string     `json:"lastUsedIp,omitempty" firestore:"lastUsedIp,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" firestore:"revokedAt,omitempty"`
+}
+
+// API token kinds
+const (
+	APITokenKindPersonal = "personal"
+	APITokenKindService  = "service"
+)
+
+// Active reports whether the token can still be used
+func (t *APIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// HasScope reports whether the token was granted a permission
+func (t *APIToken) HasScope(permission string) bool {
+	for _, scope := range t.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// APITokenQuery filters API token lookups. Zero values are ignored.
+type APITokenQuery struct {
+	OwnerID string
+	Kind    string
+}
+
+// Webhook events. Agenda changes cover speakers and sessions being created,
+// updated, deleted or restored.
+const (
+	WebhookAttendeeRegistered = "attendee.registered"
+	WebhookAttendeeCancelled  = "attendee.cancelled"
+	WebhookAttendeeCheckedIn  = "attendee.checked_in"
+	WebhookAgendaChanged      = "agenda.changed"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to
+var WebhookEvents = []string{WebhookAttendeeRegistered, WebhookAttendeeCancelled, WebhookAttendeeCheckedIn, WebhookAgendaChanged}
+
+// Webhook is an admin-managed subscription that has events POSTed to a URL.
+// Each payload is signed with the secret, which is only shown when the
+// webhook is created or its secret replaced.
+type Webhook struct {
+	ID          string    `json:"id" firestore:"id"`
+	URL         string    `json:"url" firestore:"url"`
+	Description string    `json:"description,omitempty" firestore:"description,omitempty"`
+	Events      []string  `json:"events" firestore:"events"`
+	Secret      string    `json:"-" firestore:"secret"`
+	Active      bool      `json:"active" firestore:"active"`
+	CreatedBy   string    `json:"createdBy" firestore:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt" firestore:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt" firestore:"updatedAt"`
+}
+
+// Subscribed reports whether the webhook is active and wants the event
+func (w *Webhook) Subscribed(event string) bool {
+	if !w.Active {
+		return false
+	}
+	for _, subscribed := range w.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for one webhook. Payload is the exact
+// body that is sent. A pending delivery is due once NextAttemptAt has
+// passed; finished deliveries have none.
+type WebhookDelivery struct {
+	ID             string     `json:"id" firestore:"id"`
+	WebhookID      string     `json:"webhookId" firestore:"webhookId"`
+	EventID        string     `json:"eventId" firestore:"eventId"`
+	Event          string     `json:"event" firestore:"event"`
+	Payload        string     `json:"payload" firestore:"payload"`
+	Status         string     `json:"status" firestore:"status"`
+	Attempts       int        `json:"attempts" firestore:"attempts"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt,omitempty" firestore:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt,omitempty" firestore:"lastAttemptAt,omitempty"`
+	ResponseStatus int        `json:"responseStatus,omitempty" firestore:"responseStatus,omitempty"`
+	Error          string     `json:"error,omitempty" firestore:"error,omitempty"`
+	// ReplayOf is the delivery this one was replayed from
+	ReplayOf    string     `json:"replayOf,omitempty" firestore:"replayOf,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt" firestore:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" firestore:"completedAt,omitempty"`
+}
+
+// Webhook delivery statuses. Failed deliveries ran out of attempts.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookDeliveryQuery filters delivery log lookups. Zero values are ignored.
+type WebhookDeliveryQuery struct {
+	WebhookID string
+	Status    string
+	Limit     int
+}
+
+// Event is a change to the attendees or the agenda. It is written to the
+// outbox, as a JobPublishEvent job, in the same transaction as the change,
+// and published to the webhooks subscribed to Type.
+type Event struct {
+	// Type is one of WebhookEvents
+	Type string `json:"type"`
+	// Action says how the resource changed: created, updated, deleted or
+	// restored
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   string    `json:"resourceId"`
+	Attendee     *Attendee `json:"attendee,omitempty"`
+	Speaker      *Speaker  `json:"speaker,omitempty"`
+	Session      *Session  `json:"session,omitempty"`
+	OccurredAt   time.Time `json:"occurredAt"`
+}
+
+// Event actions
+const (
+	ActionCreated  = "created"
+	ActionUpdated  = "updated"
+	ActionDeleted  = "deleted"
+	ActionRestored = "restored"
+)
+
+// Job types
+const (
+	// JobPublishEvent queues an Event's webhook deliveries
+	JobPublishEvent = "event.publish"
+)
+
+// Job is a unit of background work, run by the job runner. Jobs written in
+// the same transaction as a change form an outbox: the work is done if and
+// only if the change was stored, however long the work takes or however
+// often it fails. A pending job is due once RunAt has passed; a worker
+// leases it by moving RunAt to when its lease ends.
+type Job struct {
+	ID       string `json:"id" firestore:"id"`
+	Type     string `json:"type" firestore:"type"`
+	Payload  string `json:"payload" firestore:"payload"`
+	Status   string `json:"status" firestore:"status"`
+	Attempts int    `json:"attempts" firestore:"attempts"`
+	// RunAt is when the job is next due; finished jobs have none
+	RunAt *time.Time `json:"runAt,omitempty" firestore:"runAt,omitempty"`
+	// LeaseID names the worker holding the job while it runs
+	LeaseID     string     `json:"leaseId,omitempty" firestore:"leaseId,omitempty"`
+	LastError   string     `json:"lastError,omitempty" firestore:"lastError,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt" firestore:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt" firestore:"updatedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" firestore:"completedAt,omitempty"`
+	// ExpiresAt is set on succeeded jobs so a TTL policy can remove them;
+	// failed jobs are kept until they are retried
+	ExpiresAt *time.Time `json:"-" firestore:"expiresAt,omitempty"`
+}
+
+// Job statuses. Failed jobs ran out of attempts or failed in a way that
+// retrying cannot fix, and wait in the dead-letter queue for an admin.
+const (
+	JobPending   = "pending"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// NewJob returns a pending job of the given type, due at now, with payload
+// encoded as JSON
+func NewJob(jobType string, payload any, now time.Time) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Job{
+		Type:      jobType,
+		Payload:   string(data),
+		Status:    JobPending,
+		RunAt:     &now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// JobQuery filters job lookups. Zero values are ignored.
+type JobQuery struct {
+	Type   string
+	Status string
+	Limit  int
+}
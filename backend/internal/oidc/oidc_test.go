@@ -0,0 +1,111 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"ai-india-workshop-backend/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authorize follows the provider's authorization endpoint and returns the
+// code and state it redirects back with
+func authorize(t *testing.T, authURL string) (string, string) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/callback", location.Path)
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func newTestProvider(mock *testutils.MockOIDCProvider) *Provider {
+	return NewProvider(Config{
+		IssuerURL:   mock.Issuer() + "/",
+		ClientID:    mock.ClientID,
+		RedirectURL: "http://localhost:8080/callback",
+	})
+}
+
+func TestProvider_LoginFlow(t *testing.T) {
+	mock := testutils.NewMockOIDCProvider(t, "workshop")
+	mock.Groups = []string{"organisers"}
+	provider := newTestProvider(mock)
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "the-state", "the-nonce", "the-verifier")
+	require.NoError(t, err)
+	code, state := authorize(t, authURL)
+	assert.Equal(t, "the-state", state)
+
+	claims, err := provider.Exchange(ctx, code, "the-verifier", "the-nonce")
+	require.NoError(t, err)
+	assert.Equal(t, "mock-subject", claims.Subject)
+	assert.Equal(t, "admin@example.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+	assert.Equal(t, "Mock Admin", claims.Name)
+	assert.Equal(t, []string{"organisers"}, claims.Groups)
+
+	// Codes can only be redeemed once
+	_, err = provider.Exchange(ctx, code, "the-verifier", "the-nonce")
+	assert.Error(t, err)
+}
+
+func TestProvider_RejectsWrongVerifier(t *testing.T) {
+	mock := testutils.NewMockOIDCProvider(t, "workshop")
+	provider := newTestProvider(mock)
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", "the-verifier")
+	require.NoError(t, err)
+	code, _ := authorize(t, authURL)
+
+	_, err = provider.Exchange(ctx, code, "another-verifier", "nonce")
+	assert.Error(t, err)
+}
+
+func TestProvider_RejectsWrongNonce(t *testing.T) {
+	mock := testutils.NewMockOIDCProvider(t, "workshop")
+	provider := newTestProvider(mock)
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	require.NoError(t, err)
+	code, _ := authorize(t, authURL)
+
+	_, err = provider.Exchange(ctx, code, "verifier", "other-nonce")
+	assert.ErrorIs(t, err, ErrNonceMismatch)
+}
+
+func TestProvider_RejectsTokenFromAnotherProvider(t *testing.T) {
+	mock := testutils.NewMockOIDCProvider(t, "workshop")
+	impostor := testutils.NewMockOIDCProvider(t, "workshop")
+	provider := newTestProvider(mock)
+	ctx := context.Background()
+
+	// Redeem a code at a provider whose keys we do not trust
+	discovery, err := provider.discover(ctx)
+	require.NoError(t, err)
+	discovery.TokenEndpoint = impostor.Issuer() + "/token"
+	authURL, err := newTestProvider(impostor).AuthCodeURL(ctx, "state", "nonce", "verifier")
+	require.NoError(t, err)
+	code, _ := authorize(t, authURL)
+
+	_, err = provider.Exchange(ctx, code, "verifier", "nonce")
+	assert.ErrorIs(t, err, ErrInvalidIDToken)
+}
+
+func TestS256Challenge(t *testing.T) {
+	// RFC 7636 appendix B
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", S256Challenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
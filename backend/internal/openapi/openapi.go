@@ -0,0 +1,359 @@
+// Package openapi serves the API's OpenAPI document and checks requests and
+// responses against the schemas in it
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// documentURL names the document while its schemas are compiled
+const documentURL = "openapi.json"
+
+//go:embed openapi.yaml
+var document []byte
+
+// ErrUnknownOperation is returned for requests the document does not describe
+var ErrUnknownOperation = errors.New("operation is not documented")
+
+var methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// Spec is the parsed document with every request and response schema
+// compiled
+type Spec struct {
+	json       []byte
+	operations []*Operation
+}
+
+// Operation is one method on one path of the document
+type Operation struct {
+	Method string
+	// Path is the path template, with parameters such as {id}, relative to
+	// each of Servers
+	Path string
+	// Servers are the base paths the operation is served under, e.g. /api/v1
+	Servers []string
+
+	segments     []string
+	parameters   []*parameter
+	body         *jsonschema.Schema
+	bodyRequired bool
+	// responses maps each status code (or "default") to its schema for
+	// each media type; a nil schema accepts any content
+	responses map[string]map[string]*jsonschema.Schema
+}
+
+type parameter struct {
+	name     string
+	in       string
+	required bool
+	// kind is the schema's type, which says how to read the string value
+	kind   string
+	schema *jsonschema.Schema
+}
+
+// Load parses the embedded document
+func Load() (*Spec, error) {
+	return parse(document)
+}
+
+func parse(data []byte) (*Spec, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI document: %w", err)
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("encoding OpenAPI document: %w", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(encoded))
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	if err := compiler.AddResource(documentURL, doc); err != nil {
+		return nil, err
+	}
+
+	p := &parser{doc: doc, compiler: compiler}
+	spec := &Spec{json: encoded}
+	root, _ := doc.(map[string]any)
+	servers := serverURLs(root)
+	if servers == nil {
+		servers = []string{"/"}
+	}
+	paths, _ := p.object("/paths")
+	templates := make([]string, 0, len(paths))
+	for path := range paths {
+		templates = append(templates, path)
+	}
+	sort.Strings(templates)
+
+	for _, path := range templates {
+		itemPtr := "/paths/" + escape(path)
+		item, _ := p.object(itemPtr)
+		for _, method := range methods {
+			if _, ok := item[strings.ToLower(method)]; !ok {
+				continue
+			}
+			op, err := p.operation(method, path, itemPtr)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			// Servers on the path override the document's
+			op.Servers = servers
+			if itemServers := serverURLs(item); itemServers != nil {
+				op.Servers = itemServers
+			}
+			spec.operations = append(spec.operations, op)
+		}
+	}
+	return spec, nil
+}
+
+// JSON returns the document as JSON
+func (s *Spec) JSON() []byte {
+	return s.json
+}
+
+// Handler serves the document
+func (s *Spec) Handler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", s.json)
+}
+
+// Operations lists every documented operation
+func (s *Spec) Operations() []*Operation {
+	return s.operations
+}
+
+// Find returns the operation a request is for. The longest server base the
+// path starts with wins, so /api/v1/attendees is not read as /v1/attendees
+// under /api; then paths with literal segments win over parameters, so
+// /attendees/count is not taken for an ID.
+func (s *Spec) Find(method, path string) (*Operation, error) {
+	op, _, err := s.find(method, path)
+	return op, err
+}
+
+// find also returns the path relative to the operation's server
+func (s *Spec) find(method, path string) (*Operation, string, error) {
+	var best *Operation
+	var bestPath string
+	bestBase, bestLiterals := -1, -1
+	for _, op := range s.operations {
+		if op.Method != method {
+			continue
+		}
+		for _, server := range op.Servers {
+			relative, ok := relativePath(path, server)
+			if !ok {
+				continue
+			}
+			literals, ok := op.match(split(relative))
+			if !ok {
+				continue
+			}
+			if len(server) > bestBase || len(server) == bestBase && literals > bestLiterals {
+				best, bestPath, bestBase, bestLiterals = op, relative, len(server), literals
+			}
+		}
+	}
+	if best == nil {
+		return nil, "", fmt.Errorf("%w: %s %s", ErrUnknownOperation, method, path)
+	}
+	return best, bestPath, nil
+}
+
+// relativePath strips a server's base path from a request path
+func relativePath(path, server string) (string, bool) {
+	base := strings.TrimSuffix(server, "/")
+	if base == "" {
+		return path, true
+	}
+	if path == base {
+		return "/", true
+	}
+	rest, ok := strings.CutPrefix(path, base+"/")
+	return "/" + rest, ok
+}
+
+// serverURLs lists the URLs of an object's servers, or nil if it has none
+func serverURLs(object map[string]any) []string {
+	list, _ := object["servers"].([]any)
+	var urls []string
+	for _, server := range list {
+		if m, ok := server.(map[string]any); ok {
+			if url, ok := m["url"].(string); ok {
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
+
+// match reports whether the path segments fit the template and how many of
+// them were literal
+func (op *Operation) match(segments []string) (int, bool) {
+	if len(segments) != len(op.segments) {
+		return 0, false
+	}
+	literals := 0
+	for i, segment := range op.segments {
+		if isParameter(segment) {
+			continue
+		}
+		if segment != segments[i] {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, true
+}
+
+// pathParameters maps the template's parameter names to their values
+func (op *Operation) pathParameters(path string) map[string]string {
+	values := map[string]string{}
+	for i, segment := range split(path) {
+		if i < len(op.segments) && isParameter(op.segments[i]) {
+			values[strings.Trim(op.segments[i], "{}")] = segment
+		}
+	}
+	return values
+}
+
+func split(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isParameter(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// escape encodes a JSON pointer token
+func escape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// parser walks the document, following $refs, and compiles the schemas it
+// finds by their JSON pointer
+type parser struct {
+	doc      any
+	compiler *jsonschema.Compiler
+}
+
+// object returns the object at a JSON pointer, and the pointer it was found
+// at once any $ref has been followed
+func (p *parser) object(ptr string) (map[string]any, string) {
+	for range 10 {
+		node := p.doc
+		for _, token := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+			token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+			switch n := node.(type) {
+			case map[string]any:
+				node = n[token]
+			case []any:
+				i, err := strconv.Atoi(token)
+				if err != nil || i < 0 || i >= len(n) {
+					return nil, ptr
+				}
+				node = n[i]
+			default:
+				return nil, ptr
+			}
+		}
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, ptr
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return m, ptr
+		}
+		ptr = strings.TrimPrefix(ref, "#")
+	}
+	return nil, ptr
+}
+
+func (p *parser) schema(ptr string) (*jsonschema.Schema, error) {
+	return p.compiler.Compile(documentURL + "#" + ptr)
+}
+
+func (p *parser) operation(method, path, itemPtr string) (*Operation, error) {
+	opPtr := itemPtr + "/" + strings.ToLower(method)
+	op := &Operation{
+		Method:    method,
+		Path:      path,
+		segments:  split(path),
+		responses: map[string]map[string]*jsonschema.Schema{},
+	}
+
+	// Parameters on the operation override those on the path
+	seen := map[string]bool{}
+	for _, ownerPtr := range []string{opPtr, itemPtr} {
+		owner, _ := p.object(ownerPtr)
+		list, _ := owner["parameters"].([]any)
+		for i := range list {
+			param, ptr := p.object(fmt.Sprintf("%s/parameters/%d", ownerPtr, i))
+			name, _ := param["name"].(string)
+			in, _ := param["in"].(string)
+			if seen[in+":"+name] {
+				continue
+			}
+			seen[in+":"+name] = true
+
+			required, _ := param["required"].(bool)
+			parsed := &parameter{name: name, in: in, required: required}
+			if schema, _ := param["schema"].(map[string]any); schema != nil {
+				parsed.kind, _ = schema["type"].(string)
+				var err error
+				if parsed.schema, err = p.schema(ptr + "/schema"); err != nil {
+					return nil, err
+				}
+			}
+			op.parameters = append(op.parameters, parsed)
+		}
+	}
+
+	if body, ptr := p.object(opPtr + "/requestBody"); body != nil {
+		op.bodyRequired, _ = body["required"].(bool)
+		if content, _ := body["content"].(map[string]any); content["application/json"] != nil {
+			var err error
+			if op.body, err = p.schema(ptr + "/content/application~1json/schema"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	responses, _ := p.object(opPtr + "/responses")
+	for status := range responses {
+		response, ptr := p.object(opPtr + "/responses/" + escape(status))
+		content, _ := response["content"].(map[string]any)
+		media := map[string]*jsonschema.Schema{}
+		for mediaType, value := range content {
+			media[mediaType] = nil
+			if m, _ := value.(map[string]any); m["schema"] != nil {
+				schema, err := p.schema(ptr + "/content/" + escape(mediaType) + "/schema")
+				if err != nil {
+					return nil, err
+				}
+				media[mediaType] = schema
+			}
+		}
+		op.responses[status] = media
+	}
+	return op, nil
+}
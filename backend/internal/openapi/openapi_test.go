@@ -0,0 +1,358 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadSpec(t *testing.T) *Spec {
+	t.Helper()
+	spec, err := Load()
+	require.NoError(t, err)
+	return spec
+}
+
+func TestLoad(t *testing.T) {
+	spec := loadSpec(t)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(spec.JSON(), &doc))
+	assert.Equal(t, "3.1.0", doc["openapi"])
+
+	ids := map[string]bool{}
+	for _, op := range spec.Operations() {
+		item := doc["paths"].(map[string]any)[op.Path].(map[string]any)
+		id, _ := item[strings.ToLower(op.Method)].(map[string]any)["operationId"].(string)
+		assert.NotEmpty(t, id, "%s %s has no operationId", op.Method, op.Path)
+		assert.False(t, ids[id], "operationId %s is used twice", id)
+		ids[id] = true
+		assert.NotEmpty(t, op.responses, "%s %s has no responses", op.Method, op.Path)
+	}
+}
+
+func TestFind(t *testing.T) {
+	spec := loadSpec(t)
+
+	tests := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{"GET", "/api/v1/attendees/count", "/attendees/count"},
+		{"DELETE", "/api/v1/attendees/abc", "/attendees/{id}"},
+		{"POST", "/api/v1/attendees/abc/checkin", "/attendees/{id}/checkin"},
+		{"DELETE", "/api/v1/admin/trash/speakers/abc", "/admin/trash/{type}/{id}"},
+		{"GET", "/api/v1/admin/me", "/admin/me"},
+		{"GET", "/api/admin/me", "/admin/me"},
+		{"GET", "/healthz", "/healthz"},
+	}
+	for _, tt := range tests {
+		op, err := spec.Find(tt.method, tt.path)
+		require.NoError(t, err, "%s %s", tt.method, tt.path)
+		assert.Equal(t, tt.expected, op.Path)
+	}
+
+	_, err := spec.Find("GET", "/api/v1/attendees/abc")
+	assert.ErrorIs(t, err, ErrUnknownOperation)
+	_, err = spec.Find("GET", "/api/v1/unknown")
+	assert.ErrorIs(t, err, ErrUnknownOperation)
+	// Probes are only served from the root
+	_, err = spec.Find("GET", "/api/v1/healthz")
+	assert.ErrorIs(t, err, ErrUnknownOperation)
+}
+
+func TestValidateRequest(t *testing.T) {
+	spec := loadSpec(t)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		contentType   string
+		body          string
+		expectedField string
+		expectedError string
+	}{
+		{
+			name:   "valid registration",
+			method: "POST",
+			path:   "/api/v1/attendees",
+			body:   `{"name":"Test User","email":"test@example.com","designation":"Engineer","answers":{"track":"ml","topics":["llm"]}}`,
+		},
+		{
+			name:          "missing property",
+			method:        "POST",
+			path:          "/api/v1/attendees",
+			body:          `{"name":"Test User","designation":"Engineer"}`,
+			expectedField: "email",
+			expectedError: "email is required",
+		},
+		{
+			name:          "invalid email",
+			method:        "POST",
+			path:          "/api/v1/attendees",
+			body:          `{"name":"Test User","email":"not-an-email","designation":"Engineer"}`,
+			expectedField: "email",
+			expectedError: "email: 'not-an-email' is not valid email: missing @",
+		},
+		{
+			name:          "nested property",
+			method:        "PUT",
+			path:          "/api/v1/admin/registration-form",
+			body:          `{"fields":[{"id":"track","label":"Track","type":"dropdown"}]}`,
+			expectedField: "fields.0.type",
+		},
+		{
+			name:          "missing body",
+			method:        "POST",
+			path:          "/api/v1/admin/login",
+			expectedError: "Request body is required",
+		},
+		{
+			name:          "not JSON",
+			method:        "POST",
+			path:          "/api/v1/admin/login",
+			body:          `{"email":`,
+			expectedError: "Request body is not valid JSON",
+		},
+		{
+			name:          "wrong content type",
+			method:        "POST",
+			path:          "/api/v1/admin/login",
+			contentType:   "text/plain",
+			body:          `{"email":"admin@example.com","password":"secret"}`,
+			expectedError: "Content-Type must be application/json",
+		},
+		{
+			name:   "no body expected",
+			method: "POST",
+			path:   "/api/v1/admin/designations/remap?dryRun=true",
+			body:   "null",
+		},
+		{
+			name:   "valid query",
+			method: "GET",
+			path:   "/api/v1/admin/audit?limit=50&from=2026-01-01T00:00:00Z",
+		},
+		{
+			name:          "query not a number",
+			method:        "GET",
+			path:          "/api/v1/admin/audit?limit=many",
+			expectedField: "limit",
+			expectedError: "limit must be an integer",
+		},
+		{
+			name:          "query out of range",
+			method:        "GET",
+			path:          "/api/v1/admin/login-attempts?limit=5000",
+			expectedField: "limit",
+		},
+		{
+			name:          "query not in enum",
+			method:        "GET",
+			path:          "/api/v1/admin/analytics?granularity=week",
+			expectedField: "granularity",
+		},
+		{
+			name:          "path parameter",
+			method:        "POST",
+			path:          "/api/v1/admin/trash/widgets/abc/restore",
+			expectedField: "type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType == "" {
+				tt.contentType = "application/json"
+			}
+			req.Header.Set("Content-Type", tt.contentType)
+
+			err := spec.ValidateRequest(req)
+			if tt.expectedField == "" && tt.expectedError == "" {
+				require.NoError(t, err)
+				// The body can still be read by the handler
+				body, _ := io.ReadAll(req.Body)
+				assert.Equal(t, tt.body, string(body))
+				return
+			}
+
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.expectedField, validationErr.Field)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, validationErr.Message)
+			}
+		})
+	}
+}
+
+func TestValidateRequest_UnknownOperation(t *testing.T) {
+	spec := loadSpec(t)
+	err := spec.ValidateRequest(httptest.NewRequest("PATCH", "/api/v1/speakers/1", nil))
+	assert.ErrorIs(t, err, ErrUnknownOperation)
+}
+
+func TestValidateResponse(t *testing.T) {
+	spec := loadSpec(t)
+	jsonHeader := http.Header{"Content-Type": {"application/json; charset=utf-8"}}
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		status        int
+		header        http.Header
+		body          string
+		expectedError string
+	}{
+		{
+			name:   "valid",
+			method: "GET",
+			path:   "/api/v1/attendees/count",
+			status: 200,
+			header: jsonHeader,
+			body:   `{"count":3}`,
+		},
+		{
+			name:          "wrong type",
+			method:        "GET",
+			path:          "/api/v1/attendees/count",
+			status:        200,
+			header:        jsonHeader,
+			body:          `{"count":"3"}`,
+			expectedError: "count: got string, want integer",
+		},
+		{
+			name:          "undocumented property",
+			method:        "GET",
+			path:          "/api/v1/attendees/count",
+			status:        200,
+			header:        jsonHeader,
+			body:          `{"count":3,"total":3}`,
+			expectedError: "additional properties 'total' not allowed",
+		},
+		{
+			name:   "problem",
+			method: "DELETE",
+			path:   "/api/v1/speakers/1",
+			status: 404,
+			header: http.Header{"Content-Type": {"application/problem+json"}},
+			body:   `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found","detail":"Speaker not found"}`,
+		},
+		{
+			name:          "legacy error",
+			method:        "DELETE",
+			path:          "/api/v1/speakers/1",
+			status:        404,
+			header:        jsonHeader,
+			body:          `{"error":"Speaker not found"}`,
+			expectedError: `undocumented content type "application/json"`,
+		},
+		{
+			name:          "undocumented status",
+			method:        "GET",
+			path:          "/api/v1/attendees/count",
+			status:        404,
+			header:        jsonHeader,
+			body:          `{"error":"Not found"}`,
+			expectedError: "status 404 is not documented",
+		},
+		{
+			name:          "undocumented content type",
+			method:        "GET",
+			path:          "/api/v1/attendees/count",
+			status:        200,
+			header:        http.Header{"Content-Type": {"text/plain"}},
+			body:          "3",
+			expectedError: `undocumented content type "text/plain"`,
+		},
+		{
+			name:   "CSV",
+			method: "GET",
+			path:   "/api/v1/attendees/export",
+			status: 200,
+			header: http.Header{"Content-Type": {"text/csv; charset=utf-8"}},
+			body:   "Name,Email\n",
+		},
+		{
+			name:   "redirect",
+			method: "GET",
+			path:   "/api/v1/admin/sso/login",
+			status: 302,
+			header: http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+			body:   `<a href="https://idp.example.com">Found</a>.`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := spec.ValidateResponse(tt.method, tt.path, tt.status, tt.header, []byte(tt.body))
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spec := loadSpec(t)
+
+	r := gin.New()
+	r.Use(spec.Middleware())
+	r.POST("/api/admin/login", func(c *gin.Context) {
+		var req struct {
+			Email string `json:"email"`
+		}
+		require.NoError(t, c.ShouldBindJSON(&req))
+		c.JSON(http.StatusOK, gin.H{"email": req.Email})
+	})
+	r.GET("/undocumented", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/admin/login", `{"email":"admin@example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"password is required","field":"password"}`, w.Body.String())
+
+	w = send("POST", "/api/admin/login", `{"email":"admin@example.com","password":"secret"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"email":"admin@example.com"}`, w.Body.String())
+
+	w = send("GET", "/undocumented", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spec := loadSpec(t)
+
+	r := gin.New()
+	r.GET("/api/v1/openapi.json", spec.Handler)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, string(spec.JSON()), w.Body.String())
+}
@@ -0,0 +1,217 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+var printer = message.NewPrinter(language.English)
+
+// ValidationError says why a request or response does not match the
+// document. Field names the body property or parameter at fault, if any.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func invalid(field, format string, args ...any) *ValidationError {
+	return &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// ValidateRequest checks a request's parameters and JSON body. The body is
+// read and replaced, so handlers can still bind it.
+func (s *Spec) ValidateRequest(r *http.Request) error {
+	op, relative, err := s.find(r.Method, r.URL.Path)
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+	path := op.pathParameters(relative)
+	for _, param := range op.parameters {
+		var value string
+		var ok bool
+		switch param.in {
+		case "query":
+			ok = query.Has(param.name)
+			value = query.Get(param.name)
+		case "path":
+			value, ok = path[param.name]
+		default:
+			continue
+		}
+		if !ok {
+			if param.required {
+				return invalid(param.name, "%s is required", param.name)
+			}
+			continue
+		}
+		if err := param.validate(value); err != nil {
+			return err
+		}
+	}
+
+	if op.body == nil {
+		return nil
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return invalid("", "Failed to read request body")
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	if len(bytes.TrimSpace(data)) == 0 {
+		if op.bodyRequired {
+			return invalid("", "Request body is required")
+		}
+		return nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		return invalid("", "Content-Type must be application/json")
+	}
+	body, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return invalid("", "Request body is not valid JSON")
+	}
+	if err := op.body.Validate(body); err != nil {
+		return schemaError(err)
+	}
+	return nil
+}
+
+// validate reads a parameter's value as its schema's type and checks it
+func (param *parameter) validate(value string) error {
+	var v any = value
+	switch param.kind {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return invalid(param.name, "%s must be an integer", param.name)
+		}
+		v = n
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid(param.name, "%s must be true or false", param.name)
+		}
+		v = b
+	}
+	if param.schema == nil {
+		return nil
+	}
+	if err := param.schema.Validate(v); err != nil {
+		e := schemaError(err)
+		e.Field = param.name
+		e.Message = param.name + ": " + strings.TrimPrefix(e.Message, ": ")
+		return e
+	}
+	return nil
+}
+
+// ValidateResponse checks that the status code is documented for the
+// operation and that the body matches its schema
+func (s *Spec) ValidateResponse(method, path string, status int, header http.Header, body []byte) error {
+	op, err := s.Find(method, path)
+	if err != nil {
+		return err
+	}
+	content, ok := op.responses[strconv.Itoa(status)]
+	if !ok {
+		if content, ok = op.responses["default"]; !ok {
+			return fmt.Errorf("%s %s: status %d is not documented", method, op.Path, status)
+		}
+	}
+	if len(content) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	schema, ok := content[mediaType]
+	if !ok {
+		return fmt.Errorf("%s %s: %d response has undocumented content type %q", method, op.Path, status, mediaType)
+	}
+	if schema == nil || mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	v, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s %s: %d response is not valid JSON: %w", method, op.Path, status, err)
+	}
+	if err := schema.Validate(v); err != nil {
+		return fmt.Errorf("%s %s: %d response does not match the schema: %w", method, op.Path, status, schemaError(err))
+	}
+	return nil
+}
+
+// schemaError turns a schema validation error into a message listing each
+// problem with where it is, and the field of the first one
+func schemaError(err error) *ValidationError {
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return invalid("", "%v", err)
+	}
+
+	var problems []*ValidationError
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		field := strings.Join(e.InstanceLocation, ".")
+		if required, ok := e.ErrorKind.(*kind.Required); ok {
+			for _, missing := range required.Missing {
+				name := strings.TrimPrefix(field+"."+missing, ".")
+				problems = append(problems, invalid(name, "%s is required", name))
+			}
+			return
+		}
+		problems = append(problems, invalid(field, "%s: %s", field, e.ErrorKind.LocalizedString(printer)))
+	}
+	walk(validationErr)
+
+	if len(problems) == 0 {
+		return invalid("", "%v", err)
+	}
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		messages[i] = problem.Message
+	}
+	return &ValidationError{Field: problems[0].Field, Message: strings.Join(messages, "; ")}
+}
+
+// Middleware rejects requests that do not match the document with a 400
+// naming the field at fault. Requests for undocumented routes are passed on.
+func (s *Spec) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := s.ValidateRequest(c.Request)
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			response := gin.H{"error": validationErr.Message}
+			if validationErr.Field != "" {
+				response["field"] = validationErr.Field
+			}
+			c.JSON(http.StatusBadRequest, response)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
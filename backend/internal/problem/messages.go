@@ -0,0 +1,208 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// Validation messages. The English text is the message key; translations
+// are added to the catalog for each supported language.
+const (
+	msgBodyRequired = "Request body is required"
+	msgBodyInvalid  = "Request body is not valid JSON"
+	msgRequired     = "%s is required"
+	msgEmail        = "%s must be a valid email address"
+	msgURL          = "%s must be a valid URL"
+	msgMinLength    = "%s must be at least %s characters"
+	msgMaxLength    = "%s must be at most %s characters"
+	msgMinItems     = "%s must have at least %s items"
+	msgMaxItems     = "%s must have at most %s items"
+	msgMin          = "%s must be at least %s"
+	msgMax          = "%s must be at most %s"
+	msgOneOf        = "%s must be one of: %s"
+	msgString       = "%s must be a string"
+	msgNumber       = "%s must be a number"
+	msgBoolean      = "%s must be true or false"
+	msgArray        = "%s must be a list"
+	msgObject       = "%s must be an object"
+	msgInvalid      = "%s is invalid"
+)
+
+var translations = map[language.Tag]map[string]string{
+	language.Hindi: {
+		msgBodyRequired: "अनुरोध में डेटा आवश्यक है",
+		msgBodyInvalid:  "अनुरोध का डेटा मान्य JSON नहीं है",
+		msgRequired:     "%s आवश्यक है",
+		msgEmail:        "%s एक मान्य ईमेल पता होना चाहिए",
+		msgURL:          "%s एक मान्य URL होना चाहिए",
+		msgMinLength:    "%s में कम से कम %s अक्षर होने चाहिए",
+		msgMaxLength:    "%s में अधिकतम %s अक्षर हो सकते हैं",
+		msgMinItems:     "%s में कम से कम %s आइटम होने चाहिए",
+		msgMaxItems:     "%s में अधिकतम %s आइटम हो सकते हैं",
+		msgMin:          "%s कम से कम %s होना चाहिए",
+		msgMax:          "%s अधिकतम %s हो सकता है",
+		msgOneOf:        "%s इनमें से एक होना चाहिए: %s",
+		msgString:       "%s एक स्ट्रिंग होना चाहिए",
+		msgNumber:       "%s एक संख्या होना चाहिए",
+		msgBoolean:      "%s true या false होना चाहिए",
+		msgArray:        "%s एक सूची होना चाहिए",
+		msgObject:       "%s एक ऑब्जेक्ट होना चाहिए",
+		msgInvalid:      "%s अमान्य है",
+	},
+}
+
+// Languages lists the languages validation messages are available in, the
+// first being the default
+var Languages = []language.Tag{language.English, language.Hindi}
+
+var (
+	messages = catalog.NewBuilder(catalog.Fallback(language.English))
+	matcher  = language.NewMatcher(Languages)
+)
+
+func init() {
+	for tag, texts := range translations {
+		for key, text := range texts {
+			if err := messages.SetString(tag, key, text); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	// Name invalid fields by their JSON name rather than the Go one
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonName)
+	}
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// Printer formats messages in the best language for the request's
+// Accept-Language header
+func Printer(c *gin.Context) *message.Printer {
+	_, index := language.MatchStrings(matcher, c.GetHeader("Accept-Language"))
+	return message.NewPrinter(Languages[index], message.Catalog(messages))
+}
+
+// BindError responds 400 to a request whose body could not be bound. The
+// error names the first invalid field; the problem details list them all.
+func BindError(c *gin.Context, err error) {
+	errs := FieldErrors(Printer(c), err)
+	first := errs[0]
+	if first.Field == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": first.Message})
+		return
+	}
+	c.Set(fieldErrorsKey, errs)
+	c.JSON(http.StatusBadRequest, gin.H{"error": first.Message, "field": first.Field})
+}
+
+// FieldErrors describes a binding error as one or more invalid fields. An
+// error with the body as a whole has an empty Field.
+func FieldErrors(p *message.Printer, err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		errs := make([]FieldError, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			errs[i] = validationError(p, fieldErr)
+		}
+		return errs
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		key := msgInvalid
+		switch typeErr.Type.Kind() {
+		case reflect.String:
+			key = msgString
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			key = msgNumber
+		case reflect.Bool:
+			key = msgBoolean
+		case reflect.Slice, reflect.Array:
+			key = msgArray
+		case reflect.Map, reflect.Struct:
+			key = msgObject
+		}
+		return []FieldError{{Field: typeErr.Field, Code: "type", Message: p.Sprintf(key, typeErr.Field)}}
+	}
+
+	if errors.Is(err, io.EOF) {
+		return []FieldError{{Code: "required", Message: p.Sprintf(msgBodyRequired)}}
+	}
+	return []FieldError{{Code: "invalid_json", Message: p.Sprintf(msgBodyInvalid)}}
+}
+
+// validationError describes a failed binding tag, e.g. required or email
+func validationError(p *message.Printer, err validator.FieldError) FieldError {
+	field := fieldPath(err)
+	param := err.Param()
+	countable := err.Kind() == reflect.Slice || err.Kind() == reflect.Map || err.Kind() == reflect.Array
+
+	var text string
+	switch err.Tag() {
+	case "required":
+		text = p.Sprintf(msgRequired, field)
+	case "email":
+		text = p.Sprintf(msgEmail, field)
+	case "url", "http_url":
+		text = p.Sprintf(msgURL, field)
+	case "min", "gte":
+		switch {
+		case err.Kind() == reflect.String:
+			text = p.Sprintf(msgMinLength, field, param)
+		case countable:
+			text = p.Sprintf(msgMinItems, field, param)
+		default:
+			text = p.Sprintf(msgMin, field, param)
+		}
+	case "max", "lte":
+		switch {
+		case err.Kind() == reflect.String:
+			text = p.Sprintf(msgMaxLength, field, param)
+		case countable:
+			text = p.Sprintf(msgMaxItems, field, param)
+		default:
+			text = p.Sprintf(msgMax, field, param)
+		}
+	case "oneof":
+		text = p.Sprintf(msgOneOf, field, strings.Join(strings.Fields(param), ", "))
+	default:
+		text = p.Sprintf(msgInvalid, field)
+	}
+	return FieldError{Field: field, Code: err.Tag(), Message: text}
+}
+
+// fieldPath turns a validator namespace such as loginRequest.fields[0].label
+// into fields.0.label. Unless the struct is anonymous, the namespace starts
+// with its type name, which the Go namespace starts with too.
+func fieldPath(err validator.FieldError) string {
+	path := err.Namespace()
+	first, rest, found := strings.Cut(path, ".")
+	if structFirst, _, _ := strings.Cut(err.StructNamespace(), "."); found && first == structFirst {
+		path = rest
+	}
+	return strings.NewReplacer("[", ".", "]", "").Replace(path)
+}
@@ -0,0 +1,189 @@
+// Package problem writes API errors as RFC 7807 problem details and turns
+// request binding errors into messages in the client's language
+package problem
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"ai-india-workshop-backend/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of problem details
+const ContentType = "application/problem+json"
+
+// CodeValidationFailed is the code of problems listing invalid fields
+const CodeValidationFailed = "validation_failed"
+
+// fieldErrorsKey holds the invalid fields found by BindError, which the
+// legacy error body only has room for one of
+const fieldErrorsKey = "problem.fieldErrors"
+
+// Details is an RFC 7807 problem. Code is a stable, machine-readable name
+// for the problem; Detail is the human-readable message. Extensions holds
+// any other members, such as retryAfter.
+type Details struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Code       string
+	RequestID  string
+	Errors     []FieldError
+	Extensions map[string]any
+}
+
+// FieldError is one invalid property or parameter. Field is its path in the
+// request body, e.g. fields.0.label, or the parameter's name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// New describes a problem with the request being handled. The code is
+// derived from the status, e.g. not_found for 404.
+func New(c *gin.Context, status int, detail string) *Details {
+	return &Details{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      statusCode(status),
+		RequestID: logging.RequestID(c.Request.Context()),
+	}
+}
+
+// MarshalJSON writes the extension members alongside the standard ones
+func (d *Details) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(d.Extensions)+8)
+	for name, value := range d.Extensions {
+		members[name] = value
+	}
+	members["type"] = d.Type
+	members["title"] = d.Title
+	members["status"] = d.Status
+	members["code"] = d.Code
+	if d.Detail != "" {
+		members["detail"] = d.Detail
+	}
+	if d.Instance != "" {
+		members["instance"] = d.Instance
+	}
+	if d.RequestID != "" {
+		members["requestId"] = d.RequestID
+	}
+	if len(d.Errors) > 0 {
+		members["errors"] = d.Errors
+	}
+	return json.Marshal(members)
+}
+
+// Write responds with the problem and aborts the request
+func Write(c *gin.Context, d *Details) {
+	body, err := json.Marshal(d)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Data(d.Status, ContentType, body)
+	c.Abort()
+}
+
+// statusCode names a status in snake case, e.g. too_many_requests
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+// Envelope rewrites the JSON error bodies the handlers write, {"error":
+// message, "field": name, ...}, as problem details. Any other members are
+// kept as extensions. Error bodies of another shape are passed on as they
+// are.
+func Envelope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := &envelopeWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		c.Next()
+
+		if !w.buffering {
+			return
+		}
+		body := w.body.Bytes()
+		if d := fromLegacy(c, w.Status(), body); d != nil {
+			body, _ = json.Marshal(d)
+			w.Header().Set("Content-Type", ContentType)
+		}
+		w.Header().Del("Content-Length")
+		w.ResponseWriter.Write(body)
+	}
+}
+
+// fromLegacy builds the problem for a handler's error body, or returns nil
+// if the body is not one
+func fromLegacy(c *gin.Context, status int, body []byte) *Details {
+	var members map[string]any
+	if err := json.Unmarshal(body, &members); err != nil {
+		return nil
+	}
+	message, ok := members["error"].(string)
+	if !ok {
+		return nil
+	}
+	field, _ := members["field"].(string)
+	delete(members, "error")
+	delete(members, "field")
+
+	d := New(c, status, message)
+	if errs, ok := c.Get(fieldErrorsKey); ok {
+		d.Errors = errs.([]FieldError)
+	} else if field != "" {
+		d.Errors = []FieldError{{Field: field, Code: "invalid", Message: message}}
+	}
+	if len(d.Errors) > 0 {
+		d.Code = CodeValidationFailed
+	}
+	if len(members) > 0 {
+		d.Extensions = members
+	}
+	return d
+}
+
+// envelopeWriter holds back JSON error bodies so that Envelope can rewrite
+// them; everything else is written straight through
+type envelopeWriter struct {
+	gin.ResponseWriter
+	checked   bool
+	buffering bool
+	body      bytes.Buffer
+}
+
+func (w *envelopeWriter) intercept() bool {
+	if !w.checked {
+		w.checked = true
+		mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		w.buffering = w.Status() >= http.StatusBadRequest && mediaType == "application/json"
+	}
+	return w.buffering
+}
+
+func (w *envelopeWriter) Write(data []byte) (int, error) {
+	if w.intercept() {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *envelopeWriter) WriteString(s string) (int, error) {
+	if w.intercept() {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,223 @@
+package problem
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ai-india-workshop-backend/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), "req-1"))
+		c.Next()
+	}, Envelope())
+	r.GET("/not-found", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Speaker not found"})
+	})
+	r.GET("/field", func(c *gin.Context) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown question", "field": "track"})
+	})
+	r.GET("/locked", func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many attempts", "retryAfter": 30})
+	})
+	r.GET("/ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"count": 3})
+	})
+	r.GET("/other", func(c *gin.Context) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable"})
+	})
+
+	tests := []struct {
+		path         string
+		status       int
+		contentType  string
+		expectedBody string
+	}{
+		{
+			path:        "/not-found",
+			status:      http.StatusNotFound,
+			contentType: ContentType,
+			expectedBody: `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found",
+				"detail":"Speaker not found","instance":"/not-found","requestId":"req-1"}`,
+		},
+		{
+			path:        "/field",
+			status:      http.StatusBadRequest,
+			contentType: ContentType,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"validation_failed",
+				"detail":"Unknown question","instance":"/field","requestId":"req-1",
+				"errors":[{"field":"track","code":"invalid","message":"Unknown question"}]}`,
+		},
+		{
+			path:        "/locked",
+			status:      http.StatusTooManyRequests,
+			contentType: ContentType,
+			expectedBody: `{"type":"about:blank","title":"Too Many Requests","status":429,"code":"too_many_requests",
+				"detail":"Too many attempts","instance":"/locked","requestId":"req-1","retryAfter":30}`,
+		},
+		{
+			path:         "/ok",
+			status:       http.StatusOK,
+			contentType:  "application/json; charset=utf-8",
+			expectedBody: `{"count":3}`,
+		},
+		{
+			path:         "/other",
+			status:       http.StatusServiceUnavailable,
+			contentType:  "application/json; charset=utf-8",
+			expectedBody: `{"status":"unavailable"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestBindError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type loginRequest struct {
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required,min=12"`
+	}
+	type formRequest struct {
+		Fields []struct {
+			Label string `json:"label" binding:"required"`
+		} `json:"fields" binding:"required,min=1,dive"`
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		acceptLanguage string
+		bind           func(c *gin.Context) error
+		expectedBody   string
+		expectedErrors []FieldError
+	}{
+		{
+			name:         "invalid fields",
+			body:         `{"email":"not-an-email","password":"short"}`,
+			bind:         func(c *gin.Context) error { return c.ShouldBindJSON(&loginRequest{}) },
+			expectedBody: `{"error":"email must be a valid email address","field":"email"}`,
+			expectedErrors: []FieldError{
+				{Field: "email", Code: "email", Message: "email must be a valid email address"},
+				{Field: "password", Code: "min", Message: "password must be at least 12 characters"},
+			},
+		},
+		{
+			name:           "Hindi",
+			body:           `{"password":"a long enough password"}`,
+			acceptLanguage: "hi-IN,hi;q=0.9,en;q=0.8",
+			bind:           func(c *gin.Context) error { return c.ShouldBindJSON(&loginRequest{}) },
+			expectedBody:   `{"error":"email आवश्यक है","field":"email"}`,
+		},
+		{
+			name:           "unsupported language",
+			body:           `{"password":"a long enough password"}`,
+			acceptLanguage: "fr",
+			bind:           func(c *gin.Context) error { return c.ShouldBindJSON(&loginRequest{}) },
+			expectedBody:   `{"error":"email is required","field":"email"}`,
+		},
+		{
+			name: "nested field",
+			body: `{"fields":[{"label":"Track"},{"label":""}]}`,
+			bind: func(c *gin.Context) error {
+				var req formRequest
+				return c.ShouldBindJSON(&req)
+			},
+			expectedBody: `{"error":"fields.1.label is required","field":"fields.1.label"}`,
+		},
+		{
+			name:         "wrong type",
+			body:         `{"email":42}`,
+			bind:         func(c *gin.Context) error { return c.ShouldBindJSON(&loginRequest{}) },
+			expectedBody: `{"error":"email must be a string","field":"email"}`,
+		},
+		{
+			name:         "not JSON",
+			body:         `{"email":`,
+			bind:         func(c *gin.Context) error { return c.ShouldBindJSON(&loginRequest{}) },
+			expectedBody: `{"error":"Request body is not valid JSON"}`,
+		},
+		{
+			name:         "no body",
+			bind:         func(c *gin.Context) error { return c.ShouldBindJSON(&loginRequest{}) },
+			expectedBody: `{"error":"Request body is required"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			if tt.acceptLanguage != "" {
+				c.Request.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			BindError(c, tt.bind(c))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			if tt.expectedErrors != nil {
+				errs, _ := c.Get(fieldErrorsKey)
+				assert.Equal(t, tt.expectedErrors, errs)
+			}
+		})
+	}
+}
+
+func TestEnvelope_BindError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Envelope())
+	r.POST("/login", func(c *gin.Context) {
+		var req struct {
+			Email    string `json:"email" binding:"required,email"`
+			Password string `json:"password" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BindError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"email":"nope"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"code": "validation_failed",
+		"detail": "email must be a valid email address",
+		"instance": "/login",
+		"errors": [
+			{"field": "email", "code": "email", "message": "email must be a valid email address"},
+			{"field": "password", "code": "required", "message": "password is required"}
+		]
+	}`, w.Body.String())
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many updates pass between removing expired entries
+const sweepEvery = 1000
+
+// MemoryStore keeps entries in process memory. Each instance counts
+// separately, so it is only suitable for a single server or for tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+	updates int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries[key], nil
+}
+
+func (s *MemoryStore) Update(ctx context.Context, key string, fn func(*Entry)) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updates++
+	if s.updates%sweepEvery == 0 {
+		now := time.Now()
+		for k, entry := range s.entries {
+			if entry.ExpiresAt.Before(now) {
+				delete(s.entries, k)
+			}
+		}
+	}
+
+	entry := s.entries[key]
+	fn(&entry)
+	s.entries[key] = entry
+	return entry, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package ratelimit tracks failed attempts per key (an IP address, an
+// account) and locks the key out for exponentially longer periods once it
+// has used up its free attempts.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Entry is the state stored for one key
+type Entry struct {
+	Failures    int       `firestore:"failures"`
+	LastFailure time.Time `firestore:"lastFailure"`
+	LockedUntil time.Time `firestore:"lockedUntil"`
+	// ExpiresAt is when the entry can be forgotten; shared stores can use it
+	// for automatic cleanup (e.g. a Firestore TTL policy)
+	ExpiresAt time.Time `firestore:"expiresAt"`
+}
+
+// Store persists entries. Update must apply fn atomically so that
+// concurrent failures on several instances are all counted.
+type Store interface {
+	Get(ctx context.Context, key string) (Entry, error)
+	Update(ctx context.Context, key string, fn func(*Entry)) (Entry, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Policy controls how quickly a key is locked out
+type Policy struct {
+	// FreeAttempts is how many failures are allowed before lockouts start
+	FreeAttempts int
+	// BaseDelay is the first lockout; each further failure doubles it
+	BaseDelay time.Duration
+	// MaxDelay caps the lockout
+	MaxDelay time.Duration
+	// Window is how long failures are remembered after the last one
+	Window time.Duration
+}
+
+var (
+	// LoginAccountPolicy applies to failed logins for one email address
+	LoginAccountPolicy = Policy{FreeAttempts: 5, BaseDelay: 30 * time.Second, MaxDelay: 15 * time.Minute, Window: time.Hour}
+	// LoginIPPolicy applies to failed logins from one IP address, which may
+	// be shared by several admins behind the same NAT
+	LoginIPPolicy = Policy{FreeAttempts: 20, BaseDelay: 30 * time.Second, MaxDelay: time.Hour, Window: time.Hour}
+	// RegistrationIPPolicy applies to public registrations from one IP
+	// address. Every registration is counted, not only failed ones.
+	RegistrationIPPolicy = Policy{FreeAttempts: 10, BaseDelay: 10 * time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+	// RegistrationEmailPolicy applies to registrations for one email address
+	RegistrationEmailPolicy = Policy{FreeAttempts: 3, BaseDelay: 10 * time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+)
+
+// Limiter applies a policy to the entries in a store
+type Limiter struct {
+	store  Store
+	policy Policy
+	now    func() time.Time
+}
+
+func NewLimiter(store Store, policy Policy) *Limiter {
+	return &Limiter{store: store, policy: policy, now: time.Now}
+}
+
+// Check returns how long the key must wait before trying again, or zero
+func (l *Limiter) Check(ctx context.Context, key string) (time.Duration, error) {
+	entry, err := l.store.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return l.remaining(entry), nil
+}
+
+// Fail records a failed attempt and returns the lockout it caused, if any
+func (l *Limiter) Fail(ctx context.Context, key string) (time.Duration, error) {
+	now := l.now()
+	entry, err := l.store.Update(ctx, key, func(entry *Entry) {
+		if now.Sub(entry.LastFailure) > l.policy.Window {
+			*entry = Entry{}
+		}
+		entry.Failures++
+		entry.LastFailure = now
+		if over := entry.Failures - l.policy.FreeAttempts; over > 0 {
+			entry.LockedUntil = now.Add(l.delay(over))
+		}
+		entry.ExpiresAt = now.Add(l.policy.Window)
+		if entry.LockedUntil.After(entry.LastFailure) {
+			entry.ExpiresAt = entry.LockedUntil.Add(l.policy.Window)
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return l.remaining(entry), nil
+}
+
+// Reset forgets the key's failures, e.g. after a successful login
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	return l.store.Delete(ctx, key)
+}
+
+// delay is the lockout after the nth failure beyond the free attempts
+func (l *Limiter) delay(n int) time.Duration {
+	delay := l.policy.BaseDelay
+	for i := 1; i < n && delay < l.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > l.policy.MaxDelay {
+		delay = l.policy.MaxDelay
+	}
+	return delay
+}
+
+func (l *Limiter) remaining(entry Entry) time.Duration {
+	if wait := entry.LockedUntil.Sub(l.now()); wait > 0 {
+		return wait
+	}
+	return 0
+}
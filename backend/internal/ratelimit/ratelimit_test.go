@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(policy Policy) (*Limiter, *time.Time) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(NewMemoryStore(), policy)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestLimiter_ExponentialBackoff(t *testing.T) {
+	ctx := context.Background()
+	limiter, _ := newTestLimiter(Policy{FreeAttempts: 2, BaseDelay: time.Second, MaxDelay: 5 * time.Second, Window: time.Hour})
+
+	expected := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		wait, err := limiter.Fail(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, want, wait, "failure %d", i+1)
+	}
+
+	wait, err := limiter.Check(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, wait)
+
+	// Other keys are unaffected
+	wait, err = limiter.Check(ctx, "other")
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+}
+
+func TestLimiter_LockoutExpires(t *testing.T) {
+	ctx := context.Background()
+	limiter, now := newTestLimiter(Policy{FreeAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour})
+
+	_, err := limiter.Fail(ctx, "key")
+	require.NoError(t, err)
+	wait, err := limiter.Fail(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, wait)
+
+	*now = now.Add(30 * time.Second)
+	wait, err = limiter.Check(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, wait)
+
+	*now = now.Add(31 * time.Second)
+	wait, err = limiter.Check(ctx, "key")
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+
+	// The failures are still remembered, so the next one locks for longer
+	wait, err = limiter.Fail(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, wait)
+}
+
+func TestLimiter_WindowAndReset(t *testing.T) {
+	ctx := context.Background()
+	limiter, now := newTestLimiter(Policy{FreeAttempts: 1, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour})
+
+	_, err := limiter.Fail(ctx, "key")
+	require.NoError(t, err)
+
+	// Failures older than the window are forgotten
+	*now = now.Add(2 * time.Hour)
+	wait, err := limiter.Fail(ctx, "key")
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+
+	wait, err = limiter.Fail(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, wait)
+
+	require.NoError(t, limiter.Reset(ctx, "key"))
+	wait, err = limiter.Check(ctx, "key")
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+}
@@ -2,61 +2,71 @@ package repository
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
-	"log"
-	"os"
+	"fmt"
+	"log/slog"
+	"time"
 
+	"ai-india-workshop-backend/internal/analytics"
 	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/ratelimit"
+	"ai-india-workshop-backend/internal/sessionstore"
 
 	"cloud.google.com/go/firestore"
 	"firebase.google.com/go/v4"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Repository struct {
-	client         *firestore.Client
+	client        *firestore.Client
 	subcollection string
 }
 
-func NewRepository(ctx context.Context) (*Repository, error) {
-	subcollection := os.Getenv("FIRESTORE_SUBCOLLECTION_ID")
-	if subcollection == "" {
-		return nil, errors.New("FIRESTORE_SUBCOLLECTION_ID environment variable is required")
+// Config locates the workshop's data in Firestore
+type Config struct {
+	// SubcollectionID is the workshops document holding the collections
+	SubcollectionID string
+	// CredentialsFile is a service account key. Without it Application
+	// Default Credentials are used.
+	CredentialsFile string
+	// ProjectID is only needed with Application Default Credentials when it
+	// cannot be detected
+	ProjectID string
+}
+
+// NewRepository connects to Firestore. opts are passed on to the Firestore
+// client, e.g. to instrument its calls.
+func NewRepository(ctx context.Context, cfg Config, opts ...option.ClientOption) (*Repository, error) {
+	if cfg.SubcollectionID == "" {
+		return nil, errors.New("a Firestore subcollection ID is required")
 	}
 
 	var app *firebase.App
 	var err error
 
 	// Check if service account path is provided (for local development)
-	serviceAccountPath := os.Getenv("FIREBASE_SERVICE_ACCOUNT_PATH")
-	if serviceAccountPath != "" {
+	if cfg.CredentialsFile != "" {
 		// Use service account file for local development
-		opt := option.WithCredentialsFile(serviceAccountPath)
-		app, err = firebase.NewApp(ctx, nil, opt)
+		opt := option.WithCredentialsFile(cfg.CredentialsFile)
+		app, err = firebase.NewApp(ctx, nil, append(opts, opt)...)
 		if err != nil {
 			return nil, err
 		}
 	} else {
 		// Use Application Default Credentials (ADC) for Cloud Run
 		// Cloud Run automatically provides credentials via the attached service account
-		// Project ID is required when using ADC
-		projectID := os.Getenv("GCP_PROJECT_ID")
-		if projectID == "" {
-			// Try alternative environment variable names
-			projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
-			if projectID == "" {
-				projectID = os.Getenv("GCLOUD_PROJECT")
-			}
-		}
-		
-		var config *firebase.Config
-		if projectID != "" {
-			config = &firebase.Config{
-				ProjectID: projectID,
+		var firebaseConfig *firebase.Config
+		if cfg.ProjectID != "" {
+			firebaseConfig = &firebase.Config{
+				ProjectID: cfg.ProjectID,
 			}
 		}
-		
-		app, err = firebase.NewApp(ctx, config)
+
+		app, err = firebase.NewApp(ctx, firebaseConfig, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -68,8 +78,8 @@ func NewRepository(ctx context.Context) (*Repository, error) {
 	}
 
 	return &Repository{
-		client:         client,
-		subcollection: subcollection,
+		client:        client,
+		subcollection: cfg.SubcollectionID,
 	}, nil
 }
 
@@ -77,11 +87,177 @@ func (r *Repository) getSubcollectionPath(collectionName string) *firestore.Coll
 	return r.client.Collection("workshops").Doc(r.subcollection).Collection(collectionName)
 }
 
+// isDeleted reports whether a document has been moved to the trash
+func isDeleted(doc *firestore.DocumentSnapshot) bool {
+	deletedAt, err := doc.DataAt("deletedAt")
+	return err == nil && deletedAt != nil
+}
+
+// translateError maps Firestore "not found" errors to ErrNotFound
+func translateError(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// softDelete marks a live document as deleted so it disappears from queries
+// but can still be restored from the trash. The event published includes
+// the record as it was.
+func (r *Repository) softDelete(ctx context.Context, collectionName, id string) error {
+	docRef := r.getSubcollectionPath(collectionName).Doc(id)
+
+	now := time.Now()
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return err
+		}
+		if isDeleted(doc) {
+			return ErrNotFound
+		}
+		event, err := resourceEvent(doc, models.ActionDeleted, now)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Update(docRef, []firestore.Update{{Path: "deletedAt", Value: now}}); err != nil {
+			return err
+		}
+		if collectionName == models.ResourceAttendees {
+			if err := r.countEvent(tx, models.AnalyticsCancellations, now, 1); err != nil {
+				return err
+			}
+		}
+		return r.publish(tx, event)
+	})
+	return translateError(err)
+}
+
+// resourceEvent describes a change to the attendee, speaker or session in
+// doc. Removing an attendee cancels their registration; restoring one
+// registers them again.
+func resourceEvent(doc *firestore.DocumentSnapshot, action string, now time.Time) (models.Event, error) {
+	event := models.Event{Action: action, ResourceType: doc.Ref.Parent.ID, ResourceID: doc.Ref.ID, OccurredAt: now}
+	switch event.ResourceType {
+	case models.ResourceAttendees:
+		event.Type = models.WebhookAttendeeRegistered
+		if action == models.ActionDeleted {
+			event.Type = models.WebhookAttendeeCancelled
+		}
+		event.Attendee = &models.Attendee{}
+		if err := doc.DataTo(event.Attendee); err != nil {
+			return event, err
+		}
+		event.Attendee.ID = doc.Ref.ID
+		event.Attendee.DeletedAt = nil
+	case models.ResourceSpeakers:
+		event.Type = models.WebhookAgendaChanged
+		event.Speaker = &models.Speaker{}
+		if err := doc.DataTo(event.Speaker); err != nil {
+			return event, err
+		}
+		event.Speaker.ID = doc.Ref.ID
+		event.Speaker.DeletedAt = nil
+	case models.ResourceSessions:
+		event.Type = models.WebhookAgendaChanged
+		event.Session = &models.Session{}
+		if err := doc.DataTo(event.Session); err != nil {
+			return event, err
+		}
+		event.Session.ID = doc.Ref.ID
+		event.Session.DeletedAt = nil
+	default:
+		return event, ErrUnknownResourceType
+	}
+	return event, nil
+}
+
+// publish writes an event to the outbox in the transaction making the
+// change, so that it is published if and only if the change is stored
+func (r *Repository) publish(tx *firestore.Transaction, event models.Event) error {
+	job, err := models.NewJob(models.JobPublishEvent, event, event.OccurredAt)
+	if err != nil {
+		return err
+	}
+	docRef := r.getSubcollectionPath("jobs").NewDoc()
+	job.ID = docRef.ID
+	return tx.Create(docRef, job)
+}
+
+// getLiveDoc reads a document in a transaction and ensures it is not in the
+// trash, so that a trashed record cannot be changed
+func getLiveDoc(tx *firestore.Transaction, docRef *firestore.DocumentRef) (*firestore.DocumentSnapshot, error) {
+	doc, err := tx.Get(docRef)
+	if err != nil {
+		return nil, err
+	}
+	if isDeleted(doc) {
+		return nil, ErrNotFound
+	}
+	return doc, nil
+}
+
+// getTrashedDoc reads a document in a transaction and ensures it is
+// currently in the trash
+func (r *Repository) getTrashedDoc(tx *firestore.Transaction, resourceType, id string) (*firestore.DocumentSnapshot, error) {
+	switch resourceType {
+	case models.ResourceAttendees, models.ResourceSpeakers, models.ResourceSessions:
+	default:
+		return nil, ErrUnknownResourceType
+	}
+
+	doc, err := tx.Get(r.getSubcollectionPath(resourceType).Doc(id))
+	if err != nil {
+		return nil, err
+	}
+	if !isDeleted(doc) {
+		return nil, ErrNotFound
+	}
+	return doc, nil
+}
+
+// Ping reads the registration form document, which need not exist, to check
+// that Firestore can be reached
+func (r *Repository) Ping(ctx context.Context) error {
+	_, err := r.getSubcollectionPath("settings").Doc("registrationForm").Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+// Close releases the Firestore client once the server has stopped using it
+func (r *Repository) Close() error {
+	return r.client.Close()
+}
+
 // Attendee operations
 func (r *Repository) CreateAttendee(ctx context.Context, attendee *models.Attendee) error {
-	attendeesRef := r.getSubcollectionPath("attendees")
-	_, _, err := attendeesRef.Add(ctx, attendee)
-	return err
+	docRef := r.getSubcollectionPath("attendees").NewDoc()
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Create(docRef, attendee); err != nil {
+			return err
+		}
+		if err := r.countEvent(tx, models.AnalyticsRegistrations, attendee.CreatedAt, 1); err != nil {
+			return err
+		}
+		registered := *attendee
+		registered.ID = docRef.ID
+		return r.publish(tx, models.Event{
+			Type:         models.WebhookAttendeeRegistered,
+			Action:       models.ActionCreated,
+			ResourceType: models.ResourceAttendees,
+			ResourceID:   docRef.ID,
+			Attendee:     &registered,
+			OccurredAt:   attendee.CreatedAt,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	attendee.ID = docRef.ID
+	return nil
 }
 
 func (r *Repository) GetAllAttendees(ctx context.Context) ([]*models.Attendee, error) {
@@ -95,7 +271,10 @@ func (r *Repository) GetAllAttendees(ctx context.Context) ([]*models.Attendee, e
 	for _, doc := range docs {
 		var attendee models.Attendee
 		if err := doc.DataTo(&attendee); err != nil {
-			log.Printf("Error parsing attendee: %v", err)
+			slog.ErrorContext(ctx, "Error parsing attendee", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		if attendee.DeletedAt != nil {
 			continue
 		}
 		attendee.ID = doc.Ref.ID
@@ -105,26 +284,152 @@ func (r *Repository) GetAllAttendees(ctx context.Context) ([]*models.Attendee, e
 	return attendees, nil
 }
 
+func (r *Repository) GetAttendee(ctx context.Context, id string) (*models.Attendee, error) {
+	attendeesRef := r.getSubcollectionPath("attendees")
+	doc, err := attendeesRef.Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var attendee models.Attendee
+	if err := doc.DataTo(&attendee); err != nil {
+		return nil, err
+	}
+	if attendee.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	attendee.ID = doc.Ref.ID
+	return &attendee, nil
+}
+
 func (r *Repository) GetAttendeeCount(ctx context.Context) (int, error) {
 	attendeesRef := r.getSubcollectionPath("attendees")
 	docs, err := attendeesRef.Documents(ctx).GetAll()
 	if err != nil {
 		return 0, err
 	}
-	return len(docs), nil
+
+	count := 0
+	for _, doc := range docs {
+		if !isDeleted(doc) {
+			count++
+		}
+	}
+	return count, nil
 }
 
 func (r *Repository) DeleteAttendee(ctx context.Context, id string) error {
+	return r.softDelete(ctx, models.ResourceAttendees, id)
+}
+
+// SetAttendeeDesignations updates the attendees in bulk. The updates are not
+// atomic: on an error the count returned is of those updated, whose changes
+// are kept.
+func (r *Repository) SetAttendeeDesignations(ctx context.Context, designations map[string]string) (int, error) {
 	attendeesRef := r.getSubcollectionPath("attendees")
-	_, err := attendeesRef.Doc(id).Delete(ctx)
-	return err
+	bw := r.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(designations))
+	for id, designation := range designations {
+		job, err := bw.Update(attendeesRef.Doc(id), []firestore.Update{{Path: "designation", Value: designation}})
+		if err != nil {
+			bw.End()
+			return 0, err
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+
+	// Attendees purged since the remap was planned are skipped
+	updated := 0
+	var errs []error
+	for _, job := range jobs {
+		if _, err := job.Results(); err == nil {
+			updated++
+		} else if status.Code(err) != codes.NotFound {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return updated, fmt.Errorf("%d of %d updates failed, first: %w", len(errs), len(jobs), errs[0])
+	}
+	return updated, nil
+}
+
+// CheckInAttendee records the attendee's arrival. The read and write run in a
+// transaction so two volunteers scanning the same person only check them in once.
+func (r *Repository) CheckInAttendee(ctx context.Context, id string) (*models.Attendee, error) {
+	docRef := r.getSubcollectionPath("attendees").Doc(id)
+	var attendee models.Attendee
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return translateError(err)
+		}
+		if err := doc.DataTo(&attendee); err != nil {
+			return err
+		}
+		if attendee.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if attendee.CheckedInAt != nil {
+			return ErrAlreadyCheckedIn
+		}
+
+		now := time.Now()
+		attendee.ID = id
+		attendee.CheckedInAt = &now
+		if err := tx.Update(docRef, []firestore.Update{{Path: "checkedInAt", Value: now}}); err != nil {
+			return err
+		}
+		if err := r.countEvent(tx, models.AnalyticsCheckIns, now, 1); err != nil {
+			return err
+		}
+		checkedIn := attendee
+		return r.publish(tx, models.Event{
+			Type:         models.WebhookAttendeeCheckedIn,
+			Action:       models.ActionUpdated,
+			ResourceType: models.ResourceAttendees,
+			ResourceID:   id,
+			Attendee:     &checkedIn,
+			OccurredAt:   now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &attendee, nil
 }
 
 // Speaker operations
 func (r *Repository) CreateSpeaker(ctx context.Context, speaker *models.Speaker) error {
-	speakersRef := r.getSubcollectionPath("speakers")
-	_, _, err := speakersRef.Add(ctx, speaker)
-	return err
+	docRef := r.getSubcollectionPath("speakers").NewDoc()
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Create(docRef, speaker); err != nil {
+			return err
+		}
+		return r.publish(tx, speakerEvent(models.ActionCreated, docRef.ID, speaker))
+	})
+	if err != nil {
+		return err
+	}
+	speaker.ID = docRef.ID
+	return nil
+}
+
+// speakerEvent describes a change to the agenda's speakers
+func speakerEvent(action, id string, speaker *models.Speaker) models.Event {
+	changed := *speaker
+	changed.ID = id
+	return models.Event{
+		Type:         models.WebhookAgendaChanged,
+		Action:       action,
+		ResourceType: models.ResourceSpeakers,
+		ResourceID:   id,
+		Speaker:      &changed,
+		OccurredAt:   time.Now(),
+	}
 }
 
 func (r *Repository) GetAllSpeakers(ctx context.Context) ([]*models.Speaker, error) {
@@ -138,7 +443,10 @@ func (r *Repository) GetAllSpeakers(ctx context.Context) ([]*models.Speaker, err
 	for _, doc := range docs {
 		var speaker models.Speaker
 		if err := doc.DataTo(&speaker); err != nil {
-			log.Printf("Error parsing speaker: %v", err)
+			slog.ErrorContext(ctx, "Error parsing speaker", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		if speaker.DeletedAt != nil {
 			continue
 		}
 		speaker.ID = doc.Ref.ID
@@ -152,13 +460,16 @@ func (r *Repository) GetSpeaker(ctx context.Context, id string) (*models.Speaker
 	speakersRef := r.getSubcollectionPath("speakers")
 	doc, err := speakersRef.Doc(id).Get(ctx)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	var speaker models.Speaker
 	if err := doc.DataTo(&speaker); err != nil {
 		return nil, err
 	}
+	if speaker.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
 	speaker.ID = doc.Ref.ID
 	return &speaker, nil
 }
@@ -178,24 +489,50 @@ func (r *Repository) UpdateSpeaker(ctx context.Context, id string, speaker *mode
 	if speaker.Twitter != "" {
 		updates = append(updates, firestore.Update{Path: "twitter", Value: speaker.Twitter})
 	}
-	_, err := speakersRef.Doc(id).Update(ctx, updates)
-	if err != nil {
-		return err
-	}
-	return nil
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := getLiveDoc(tx, speakersRef.Doc(id)); err != nil {
+			return err
+		}
+		if err := tx.Update(speakersRef.Doc(id), updates); err != nil {
+			return err
+		}
+		return r.publish(tx, speakerEvent(models.ActionUpdated, id, speaker))
+	})
+	return translateError(err)
 }
 
 func (r *Repository) DeleteSpeaker(ctx context.Context, id string) error {
-	speakersRef := r.getSubcollectionPath("speakers")
-	_, err := speakersRef.Doc(id).Delete(ctx)
-	return err
+	return r.softDelete(ctx, models.ResourceSpeakers, id)
 }
 
 // Session operations
 func (r *Repository) CreateSession(ctx context.Context, session *models.Session) error {
-	sessionsRef := r.getSubcollectionPath("sessions")
-	_, _, err := sessionsRef.Add(ctx, session)
-	return err
+	docRef := r.getSubcollectionPath("sessions").NewDoc()
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Create(docRef, session); err != nil {
+			return err
+		}
+		return r.publish(tx, sessionEvent(models.ActionCreated, docRef.ID, session))
+	})
+	if err != nil {
+		return err
+	}
+	session.ID = docRef.ID
+	return nil
+}
+
+// sessionEvent describes a change to the agenda's sessions
+func sessionEvent(action, id string, session *models.Session) models.Event {
+	changed := *session
+	changed.ID = id
+	return models.Event{
+		Type:         models.WebhookAgendaChanged,
+		Action:       action,
+		ResourceType: models.ResourceSessions,
+		ResourceID:   id,
+		Session:      &changed,
+		OccurredAt:   time.Now(),
+	}
 }
 
 func (r *Repository) GetAllSessions(ctx context.Context) ([]*models.Session, error) {
@@ -209,7 +546,10 @@ func (r *Repository) GetAllSessions(ctx context.Context) ([]*models.Session, err
 	for _, doc := range docs {
 		var session models.Session
 		if err := doc.DataTo(&session); err != nil {
-			log.Printf("Error parsing session: %v", err)
+			slog.ErrorContext(ctx, "Error parsing session", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		if session.DeletedAt != nil {
 			continue
 		}
 		session.ID = doc.Ref.ID
@@ -223,27 +563,43 @@ func (r *Repository) GetSession(ctx context.Context, id string) (*models.Session
 	sessionsRef := r.getSubcollectionPath("sessions")
 	doc, err := sessionsRef.Doc(id).Get(ctx)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	var session models.Session
 	if err := doc.DataTo(&session); err != nil {
 		return nil, err
 	}
+	if session.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
 	session.ID = doc.Ref.ID
 	return &session, nil
 }
 
 func (r *Repository) UpdateSession(ctx context.Context, id string, session *models.Session) error {
 	sessionsRef := r.getSubcollectionPath("sessions")
-	_, err := sessionsRef.Doc(id).Set(ctx, session)
-	return err
+	// A session in the trash cannot be edited until it is restored
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := getLiveDoc(tx, sessionsRef.Doc(id)); err != nil {
+			return err
+		}
+		err := tx.Update(sessionsRef.Doc(id), []firestore.Update{
+			{Path: "title", Value: session.Title},
+			{Path: "description", Value: session.Description},
+			{Path: "time", Value: session.Time},
+			{Path: "speakers", Value: session.Speakers},
+		})
+		if err != nil {
+			return err
+		}
+		return r.publish(tx, sessionEvent(models.ActionUpdated, id, session))
+	})
+	return translateError(err)
 }
 
 func (r *Repository) DeleteSession(ctx context.Context, id string) error {
-	sessionsRef := r.getSubcollectionPath("sessions")
-	_, err := sessionsRef.Doc(id).Delete(ctx)
-	return err
+	return r.softDelete(ctx, models.ResourceSessions, id)
 }
 
 // Stats operations
@@ -258,7 +614,10 @@ func (r *Repository) GetDesignationBreakdown(ctx context.Context) ([]models.Desi
 	for _, doc := range docs {
 		var attendee models.Attendee
 		if err := doc.DataTo(&attendee); err != nil {
-			log.Printf("Error parsing attendee for stats: %v", err)
+			slog.ErrorContext(ctx, "Error parsing attendee for stats", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		if attendee.DeletedAt != nil {
 			continue
 		}
 		designationMap[attendee.Designation]++
@@ -275,3 +634,1183 @@ func (r *Repository) GetDesignationBreakdown(ctx context.Context) ([]models.Desi
 	return breakdown, nil
 }
 
+// Registration form operations. The form is a single document in the
+// workshop's settings collection.
+func (r *Repository) GetRegistrationForm(ctx context.Context) (*models.RegistrationForm, error) {
+	doc, err := r.getSubcollectionPath("settings").Doc("registrationForm").Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return &models.RegistrationForm{Fields: []models.FormField{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var form models.RegistrationForm
+	if err := doc.DataTo(&form); err != nil {
+		return nil, err
+	}
+	if form.Fields == nil {
+		form.Fields = []models.FormField{}
+	}
+	return &form, nil
+}
+
+func (r *Repository) SaveRegistrationForm(ctx context.Context, form *models.RegistrationForm) error {
+	_, err := r.getSubcollectionPath("settings").Doc("registrationForm").Set(ctx, form)
+	return err
+}
+
+func (r *Repository) GetDesignationTaxonomy(ctx context.Context) (*models.DesignationTaxonomy, error) {
+	doc, err := r.getSubcollectionPath("settings").Doc("designations").Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return &models.DesignationTaxonomy{Designations: []models.Designation{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var taxonomy models.DesignationTaxonomy
+	if err := doc.DataTo(&taxonomy); err != nil {
+		return nil, err
+	}
+	if taxonomy.Designations == nil {
+		taxonomy.Designations = []models.Designation{}
+	}
+	return &taxonomy, nil
+}
+
+func (r *Repository) SaveDesignationTaxonomy(ctx context.Context, taxonomy *models.DesignationTaxonomy) error {
+	_, err := r.getSubcollectionPath("settings").Doc("designations").Set(ctx, taxonomy)
+	return err
+}
+
+// Analytics operations
+
+// countEvent adds delta to the analytics slot an event at t falls in. It runs
+// in the transaction that records the event so the counts cannot drift.
+func (r *Repository) countEvent(tx *firestore.Transaction, event string, t time.Time, delta int) error {
+	date, slot := analytics.Slot(t)
+	return tx.Set(r.getSubcollectionPath("analyticsDays").Doc(date), map[string]interface{}{
+		"date": date,
+		event:  map[string]interface{}{slot: firestore.Increment(delta)},
+	}, firestore.MergeAll)
+}
+
+func (r *Repository) GetAnalyticsDays(ctx context.Context) ([]*models.AnalyticsDay, error) {
+	_, err := r.getSubcollectionPath("settings").Doc("analytics").Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	docs, err := r.getSubcollectionPath("analyticsDays").Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	days := make([]*models.AnalyticsDay, 0, len(docs))
+	for _, doc := range docs {
+		var day models.AnalyticsDay
+		if err := doc.DataTo(&day); err != nil {
+			slog.ErrorContext(ctx, "Error parsing analytics day", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		days = append(days, &day)
+	}
+	return days, nil
+}
+
+// RebuildAnalytics recounts every attendee, including those in the trash.
+// It reads the attendees in the same transaction as it writes the counts,
+// so registrations made meanwhile are not lost. A transaction can write at
+// most 500 documents, which allows for well over a year of registrations.
+func (r *Repository) RebuildAnalytics(ctx context.Context) error {
+	attendeesRef := r.getSubcollectionPath("attendees")
+	daysRef := r.getSubcollectionPath("analyticsDays")
+
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		attendeeDocs, err := tx.Documents(attendeesRef).GetAll()
+		if err != nil {
+			return err
+		}
+		existing, err := tx.Documents(daysRef).GetAll()
+		if err != nil {
+			return err
+		}
+
+		attendees := make([]*models.Attendee, 0, len(attendeeDocs))
+		for _, doc := range attendeeDocs {
+			var attendee models.Attendee
+			if err := doc.DataTo(&attendee); err != nil {
+				slog.ErrorContext(ctx, "Error parsing attendee for analytics", "id", doc.Ref.ID, "error", err)
+				continue
+			}
+			attendees = append(attendees, &attendee)
+		}
+
+		counted := map[string]bool{}
+		for _, day := range analytics.Build(attendees) {
+			counted[day.Date] = true
+			if err := tx.Set(daysRef.Doc(day.Date), day); err != nil {
+				return err
+			}
+		}
+		for _, doc := range existing {
+			if !counted[doc.Ref.ID] {
+				if err := tx.Delete(doc.Ref); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Set(r.getSubcollectionPath("settings").Doc("analytics"), map[string]interface{}{"rebuiltAt": time.Now()})
+	})
+}
+
+// Trash operations
+func (r *Repository) GetTrash(ctx context.Context) (*models.Trash, error) {
+	trash := &models.Trash{
+		Attendees: make([]*models.Attendee, 0),
+		Speakers:  make([]*models.Speaker, 0),
+		Sessions:  make([]*models.Session, 0),
+	}
+
+	attendeeDocs, err := r.getSubcollectionPath(models.ResourceAttendees).Where("deletedAt", "!=", nil).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range attendeeDocs {
+		var attendee models.Attendee
+		if err := doc.DataTo(&attendee); err != nil {
+			slog.ErrorContext(ctx, "Error parsing deleted attendee", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		attendee.ID = doc.Ref.ID
+		trash.Attendees = append(trash.Attendees, &attendee)
+	}
+
+	speakerDocs, err := r.getSubcollectionPath(models.ResourceSpeakers).Where("deletedAt", "!=", nil).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range speakerDocs {
+		var speaker models.Speaker
+		if err := doc.DataTo(&speaker); err != nil {
+			slog.ErrorContext(ctx, "Error parsing deleted speaker", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		speaker.ID = doc.Ref.ID
+		trash.Speakers = append(trash.Speakers, &speaker)
+	}
+
+	sessionDocs, err := r.getSubcollectionPath(models.ResourceSessions).Where("deletedAt", "!=", nil).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range sessionDocs {
+		var session models.Session
+		if err := doc.DataTo(&session); err != nil {
+			slog.ErrorContext(ctx, "Error parsing deleted session", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		session.ID = doc.Ref.ID
+		trash.Sessions = append(trash.Sessions, &session)
+	}
+
+	return trash, nil
+}
+
+// RestoreFromTrash checks and restores in one transaction, so two restores
+// of the same record only take back one cancellation and publish one event
+func (r *Repository) RestoreFromTrash(ctx context.Context, resourceType, id string) error {
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := r.getTrashedDoc(tx, resourceType, id)
+		if err != nil {
+			return err
+		}
+
+		// Restoring an attendee takes back their cancellation
+		var cancelledAt *time.Time
+		if resourceType == models.ResourceAttendees {
+			var attendee models.Attendee
+			if err := doc.DataTo(&attendee); err != nil {
+				return err
+			}
+			cancelledAt = attendee.DeletedAt
+		}
+		event, err := resourceEvent(doc, models.ActionRestored, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Update(doc.Ref, []firestore.Update{{Path: "deletedAt", Value: firestore.Delete}}); err != nil {
+			return err
+		}
+		if cancelledAt != nil {
+			if err := r.countEvent(tx, models.AnalyticsCancellations, *cancelledAt, -1); err != nil {
+				return err
+			}
+		}
+		return r.publish(tx, event)
+	})
+	return translateError(err)
+}
+
+// PurgeFromTrash checks and deletes in one transaction, so a record restored
+// in the meantime is left alone
+func (r *Repository) PurgeFromTrash(ctx context.Context, resourceType, id string) error {
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := r.getTrashedDoc(tx, resourceType, id)
+		if err != nil {
+			return err
+		}
+		return tx.Delete(doc.Ref)
+	})
+	return translateError(err)
+}
+
+// PurgeDeletedBefore permanently removes every record that was soft deleted
+// before the cutoff and returns how many documents were removed. A record
+// changed since it was found, such as one restored in the meantime, is
+// skipped and left for a later sweep.
+func (r *Repository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	purged := 0
+	for _, collectionName := range []string{models.ResourceAttendees, models.ResourceSpeakers, models.ResourceSessions} {
+		docs, err := r.getSubcollectionPath(collectionName).Where("deletedAt", "<", cutoff).Documents(ctx).GetAll()
+		if err != nil {
+			return purged, err
+		}
+		for _, doc := range docs {
+			if _, err := doc.Ref.Delete(ctx, firestore.LastUpdateTime(doc.UpdateTime)); err != nil {
+				if code := status.Code(err); code == codes.FailedPrecondition || code == codes.NotFound {
+					continue
+				}
+				return purged, err
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// Backup operations
+func (r *Repository) GetWorkshopData(ctx context.Context) (*models.WorkshopData, error) {
+	data := &models.WorkshopData{
+		Speakers:  make([]*models.Speaker, 0),
+		Sessions:  make([]*models.Session, 0),
+		Attendees: make([]*models.Attendee, 0),
+	}
+
+	speakerDocs, err := r.getSubcollectionPath(models.ResourceSpeakers).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range speakerDocs {
+		var speaker models.Speaker
+		if err := doc.DataTo(&speaker); err != nil {
+			return nil, fmt.Errorf("parsing speaker %s: %w", doc.Ref.ID, err)
+		}
+		speaker.ID = doc.Ref.ID
+		data.Speakers = append(data.Speakers, &speaker)
+	}
+
+	sessionDocs, err := r.getSubcollectionPath(models.ResourceSessions).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range sessionDocs {
+		var session models.Session
+		if err := doc.DataTo(&session); err != nil {
+			return nil, fmt.Errorf("parsing session %s: %w", doc.Ref.ID, err)
+		}
+		session.ID = doc.Ref.ID
+		data.Sessions = append(data.Sessions, &session)
+	}
+
+	attendeeDocs, err := r.getSubcollectionPath(models.ResourceAttendees).OrderBy("createdAt", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range attendeeDocs {
+		var attendee models.Attendee
+		if err := doc.DataTo(&attendee); err != nil {
+			return nil, fmt.Errorf("parsing attendee %s: %w", doc.Ref.ID, err)
+		}
+		attendee.ID = doc.Ref.ID
+		data.Attendees = append(data.Attendees, &attendee)
+	}
+
+	return data, nil
+}
+
+// ReplaceWorkshopData writes with a BulkWriter, as a workshop can have far
+// more records than a transaction can hold. Each document is written once:
+// the records in data are set and the other existing ones deleted. If it
+// fails part way, calling it again with the same data finishes the job.
+func (r *Repository) ReplaceWorkshopData(ctx context.Context, data *models.WorkshopData) error {
+	writes := map[string]map[string]interface{}{
+		models.ResourceSpeakers:  {},
+		models.ResourceSessions:  {},
+		models.ResourceAttendees: {},
+	}
+	for _, speaker := range data.Speakers {
+		writes[models.ResourceSpeakers][speaker.ID] = speaker
+	}
+	for _, session := range data.Sessions {
+		writes[models.ResourceSessions][session.ID] = session
+	}
+	for _, attendee := range data.Attendees {
+		writes[models.ResourceAttendees][attendee.ID] = attendee
+	}
+	for collectionName, records := range writes {
+		if _, ok := records[""]; ok {
+			return fmt.Errorf("a record in %s has no ID", collectionName)
+		}
+	}
+
+	bw := r.client.BulkWriter(ctx)
+	var jobs []*firestore.BulkWriterJob
+	for collectionName, records := range writes {
+		collection := r.getSubcollectionPath(collectionName)
+		existing, err := collection.DocumentRefs(ctx).GetAll()
+		if err != nil {
+			bw.End()
+			return err
+		}
+		for _, ref := range existing {
+			if _, keep := records[ref.ID]; keep {
+				continue
+			}
+			job, err := bw.Delete(ref)
+			if err != nil {
+				bw.End()
+				return err
+			}
+			jobs = append(jobs, job)
+		}
+		for id, record := range records {
+			job, err := bw.Set(collection.Doc(id), record)
+			if err != nil {
+				bw.End()
+				return err
+			}
+			jobs = append(jobs, job)
+		}
+	}
+	bw.End()
+	return bulkWriteErrors(jobs)
+}
+
+// ImportAttendees writes the attendees in bulk. Those written before an
+// error have their ID set, so the caller can tell which were imported.
+func (r *Repository) ImportAttendees(ctx context.Context, attendees []*models.Attendee) error {
+	collection := r.getSubcollectionPath("attendees")
+	bw := r.client.BulkWriter(ctx)
+	refs := make([]*firestore.DocumentRef, 0, len(attendees))
+	jobs := make([]*firestore.BulkWriterJob, 0, len(attendees))
+	for _, attendee := range attendees {
+		docRef := collection.NewDoc()
+		imported := *attendee
+		imported.ID = docRef.ID
+		job, err := bw.Create(docRef, &imported)
+		if err != nil {
+			bw.End()
+			return err
+		}
+		refs = append(refs, docRef)
+		jobs = append(jobs, job)
+	}
+	bw.End()
+
+	for i, job := range jobs {
+		if _, err := job.Results(); err == nil {
+			attendees[i].ID = refs[i].ID
+		}
+	}
+	return bulkWriteErrors(jobs)
+}
+
+func (r *Repository) PurgeAttendees(ctx context.Context, ids []string) error {
+	collection := r.getSubcollectionPath("attendees")
+	bw := r.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(ids))
+	for _, id := range ids {
+		job, err := bw.Delete(collection.Doc(id))
+		if err != nil {
+			bw.End()
+			return err
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+	return bulkWriteErrors(jobs)
+}
+
+// bulkWriteErrors waits for the jobs of an ended BulkWriter and reports
+// how many failed
+func bulkWriteErrors(jobs []*firestore.BulkWriterJob) error {
+	var errs []error
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d writes failed, first: %w", len(errs), len(jobs), errs[0])
+	}
+	return nil
+}
+
+// Audit log operations
+func (r *Repository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	auditRef := r.getSubcollectionPath("auditLog")
+	docRef, _, err := auditRef.Add(ctx, entry)
+	if err != nil {
+		return err
+	}
+	entry.ID = docRef.ID
+	return nil
+}
+
+// GetAuditEntries returns matching entries, newest first. Combining the
+// equality filters with the timestamp range needs a composite index on
+// the auditLog collection.
+func (r *Repository) GetAuditEntries(ctx context.Context, query models.AuditQuery) ([]*models.AuditEntry, error) {
+	q := r.getSubcollectionPath("auditLog").Query
+	if query.Actor != "" {
+		q = q.Where("actor", "==", query.Actor)
+	}
+	if query.ResourceType != "" {
+		q = q.Where("resourceType", "==", query.ResourceType)
+	}
+	if query.ResourceID != "" {
+		q = q.Where("resourceId", "==", query.ResourceID)
+	}
+	if !query.From.IsZero() {
+		q = q.Where("timestamp", ">=", query.From)
+	}
+	if !query.To.IsZero() {
+		q = q.Where("timestamp", "<=", query.To)
+	}
+	q = q.OrderBy("timestamp", firestore.Desc)
+	if query.Limit > 0 {
+		q = q.Limit(query.Limit)
+	}
+
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*models.AuditEntry, 0, len(docs))
+	for _, doc := range docs {
+		var entry models.AuditEntry
+		if err := doc.DataTo(&entry); err != nil {
+			slog.ErrorContext(ctx, "Error parsing audit entry", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		entry.ID = doc.Ref.ID
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
+
+// Admin user operations
+func (r *Repository) CreateAdminUser(ctx context.Context, user *models.AdminUser) error {
+	usersRef := r.getSubcollectionPath("adminUsers")
+	docRef := usersRef.NewDoc()
+
+	// Check and create in one transaction so two invites cannot claim the same email
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		existing, err := tx.Documents(usersRef.Where("email", "==", user.Email).Limit(1)).GetAll()
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return ErrAlreadyExists
+		}
+		return tx.Create(docRef, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	user.ID = docRef.ID
+	return nil
+}
+
+func (r *Repository) GetAllAdminUsers(ctx context.Context) ([]*models.AdminUser, error) {
+	usersRef := r.getSubcollectionPath("adminUsers")
+	docs, err := usersRef.OrderBy("email", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return []*models.AdminUser{}, err
+	}
+
+	users := make([]*models.AdminUser, 0)
+	for _, doc := range docs {
+		var user models.AdminUser
+		if err := doc.DataTo(&user); err != nil {
+			slog.ErrorContext(ctx, "Error parsing admin user", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		user.ID = doc.Ref.ID
+		users = append(users, &user)
+	}
+
+	return users, nil
+}
+
+func (r *Repository) GetAdminUser(ctx context.Context, id string) (*models.AdminUser, error) {
+	usersRef := r.getSubcollectionPath("adminUsers")
+	doc, err := usersRef.Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var user models.AdminUser
+	if err := doc.DataTo(&user); err != nil {
+		return nil, err
+	}
+	user.ID = doc.Ref.ID
+	return &user, nil
+}
+
+func (r *Repository) GetAdminUserByEmail(ctx context.Context, email string) (*models.AdminUser, error) {
+	usersRef := r.getSubcollectionPath("adminUsers")
+	docs, err := usersRef.Where("email", "==", email).Limit(1).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var user models.AdminUser
+	if err := docs[0].DataTo(&user); err != nil {
+		return nil, err
+	}
+	user.ID = docs[0].Ref.ID
+	return &user, nil
+}
+
+func (r *Repository) UpdateAdminUser(ctx context.Context, id string, user *models.AdminUser) error {
+	usersRef := r.getSubcollectionPath("adminUsers")
+	updates := []firestore.Update{
+		{Path: "displayName", Value: user.DisplayName},
+		{Path: "role", Value: user.Role},
+		{Path: "passwordHash", Value: user.PasswordHash},
+		{Path: "disabled", Value: user.Disabled},
+		{Path: "updatedAt", Value: user.UpdatedAt},
+		{Path: "twoFactorEnabled", Value: user.TwoFactorEnabled},
+		{Path: "totpSecret", Value: user.TOTPSecret},
+		{Path: "pendingTotpSecret", Value: user.PendingTOTPSecret},
+		{Path: "recoveryCodeHashes", Value: user.RecoveryCodeHashes},
+		{Path: "lastTotpStep", Value: user.LastTOTPStep},
+		{Path: "oidcSubject", Value: user.OIDCSubject},
+		{Path: "ssoManaged", Value: user.SSOManaged},
+	}
+	if user.LastLoginAt != nil {
+		updates = append(updates, firestore.Update{Path: "lastLoginAt", Value: *user.LastLoginAt})
+	}
+	_, err := usersRef.Doc(id).Update(ctx, updates)
+	return translateError(err)
+}
+
+// Login attempt operations
+func (r *Repository) CreateLoginAttempt(ctx context.Context, attempt *models.LoginAttempt) error {
+	attemptsRef := r.getSubcollectionPath("loginAttempts")
+	docRef, _, err := attemptsRef.Add(ctx, attempt)
+	if err != nil {
+		return err
+	}
+	attempt.ID = docRef.ID
+	return nil
+}
+
+// GetLoginAttempts returns matching attempts, newest first. Filtering by
+// email, IP or outcome needs a composite index with timestamp on the
+// loginAttempts collection.
+func (r *Repository) GetLoginAttempts(ctx context.Context, query models.LoginAttemptQuery) ([]*models.LoginAttempt, error) {
+	q := r.getSubcollectionPath("loginAttempts").Query
+	if query.Email != "" {
+		q = q.Where("email", "==", query.Email)
+	}
+	if query.IP != "" {
+		q = q.Where("ip", "==", query.IP)
+	}
+	if query.FailedOnly {
+		q = q.Where("success", "==", false)
+	}
+	q = q.OrderBy("timestamp", firestore.Desc)
+	if query.Limit > 0 {
+		q = q.Limit(query.Limit)
+	}
+
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := make([]*models.LoginAttempt, 0, len(docs))
+	for _, doc := range docs {
+		var attempt models.LoginAttempt
+		if err := doc.DataTo(&attempt); err != nil {
+			slog.ErrorContext(ctx, "Error parsing login attempt", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		attempt.ID = doc.Ref.ID
+		attempts = append(attempts, &attempt)
+	}
+
+	return attempts, nil
+}
+
+// API token operations
+func (r *Repository) CreateAPIToken(ctx context.Context, token *models.APIToken) error {
+	tokensRef := r.getSubcollectionPath("apiTokens")
+	_, err := tokensRef.Doc(token.ID).Create(ctx, token)
+	if status.Code(err) == codes.AlreadyExists {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *Repository) GetAPIToken(ctx context.Context, id string) (*models.APIToken, error) {
+	tokensRef := r.getSubcollectionPath("apiTokens")
+	doc, err := tokensRef.Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var token models.APIToken
+	if err := doc.DataTo(&token); err != nil {
+		return nil, err
+	}
+	token.ID = doc.Ref.ID
+	return &token, nil
+}
+
+// GetAPITokens returns matching tokens, newest first. Filtering by owner or
+// kind needs a composite index with createdAt on the apiTokens collection.
+func (r *Repository) GetAPITokens(ctx context.Context, query models.APITokenQuery) ([]*models.APIToken, error) {
+	q := r.getSubcollectionPath("apiTokens").Query
+	if query.OwnerID != "" {
+		q = q.Where("ownerId", "==", query.OwnerID)
+	}
+	if query.Kind != "" {
+		q = q.Where("kind", "==", query.Kind)
+	}
+	q = q.OrderBy("createdAt", firestore.Desc)
+
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*models.APIToken, 0, len(docs))
+	for _, doc := range docs {
+		var token models.APIToken
+		if err := doc.DataTo(&token); err != nil {
+			slog.ErrorContext(ctx, "Error parsing API token", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		token.ID = doc.Ref.ID
+		tokens = append(tokens, &token)
+	}
+
+	return tokens, nil
+}
+
+func (r *Repository) RevokeAPIToken(ctx context.Context, id string, revokedAt time.Time) error {
+	tokensRef := r.getSubcollectionPath("apiTokens")
+	_, err := tokensRef.Doc(id).Update(ctx, []firestore.Update{
+		{Path: "revokedAt", Value: revokedAt},
+	})
+	return translateError(err)
+}
+
+func (r *Repository) TouchAPIToken(ctx context.Context, id string, usedAt time.Time, ip string) error {
+	tokensRef := r.getSubcollectionPath("apiTokens")
+	_, err := tokensRef.Doc(id).Update(ctx, []firestore.Update{
+		{Path: "lastUsedAt", Value: usedAt},
+		{Path: "lastUsedIp", Value: ip},
+	})
+	return translateError(err)
+}
+
+// Webhook operations
+func (r *Repository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	webhooksRef := r.getSubcollectionPath("webhooks")
+	docRef, _, err := webhooksRef.Add(ctx, webhook)
+	if err != nil {
+		return err
+	}
+	webhook.ID = docRef.ID
+	return nil
+}
+
+func (r *Repository) GetAllWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	docs, err := r.getSubcollectionPath("webhooks").OrderBy("createdAt", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]*models.Webhook, 0, len(docs))
+	for _, doc := range docs {
+		var webhook models.Webhook
+		if err := doc.DataTo(&webhook); err != nil {
+			slog.ErrorContext(ctx, "Error parsing webhook", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		webhook.ID = doc.Ref.ID
+		webhooks = append(webhooks, &webhook)
+	}
+
+	return webhooks, nil
+}
+
+func (r *Repository) GetWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	doc, err := r.getSubcollectionPath("webhooks").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var webhook models.Webhook
+	if err := doc.DataTo(&webhook); err != nil {
+		return nil, err
+	}
+	webhook.ID = doc.Ref.ID
+	return &webhook, nil
+}
+
+func (r *Repository) UpdateWebhook(ctx context.Context, id string, webhook *models.Webhook) error {
+	docRef := r.getSubcollectionPath("webhooks").Doc(id)
+	if _, err := docRef.Get(ctx); err != nil {
+		return translateError(err)
+	}
+
+	webhook.ID = id
+	_, err := docRef.Set(ctx, webhook)
+	return err
+}
+
+// DeleteWebhook removes the webhook. Its queued deliveries fail when the
+// dispatcher finds it gone; its delivery log is kept.
+func (r *Repository) DeleteWebhook(ctx context.Context, id string) error {
+	docRef := r.getSubcollectionPath("webhooks").Doc(id)
+	if _, err := docRef.Get(ctx); err != nil {
+		return translateError(err)
+	}
+
+	_, err := docRef.Delete(ctx)
+	return err
+}
+
+// Webhook delivery operations
+
+// CreateWebhookDeliveries queues the deliveries of one event in a single
+// transaction, so either every subscribed webhook gets the event or none do
+func (r *Repository) CreateWebhookDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	deliveriesRef := r.getSubcollectionPath("webhookDeliveries")
+	docRefs := make([]*firestore.DocumentRef, len(deliveries))
+	for i := range deliveries {
+		docRefs[i] = deliveriesRef.NewDoc()
+	}
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		for i, delivery := range deliveries {
+			if err := tx.Create(docRefs[i], delivery); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i, delivery := range deliveries {
+		delivery.ID = docRefs[i].ID
+	}
+	return nil
+}
+
+func (r *Repository) GetWebhookDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	doc, err := r.getSubcollectionPath("webhookDeliveries").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var delivery models.WebhookDelivery
+	if err := doc.DataTo(&delivery); err != nil {
+		return nil, err
+	}
+	delivery.ID = doc.Ref.ID
+	return &delivery, nil
+}
+
+// GetWebhookDeliveries returns matching deliveries, newest first. Filtering
+// by webhook or status needs a composite index with createdAt on the
+// webhookDeliveries collection.
+func (r *Repository) GetWebhookDeliveries(ctx context.Context, query models.WebhookDeliveryQuery) ([]*models.WebhookDelivery, error) {
+	q := r.getSubcollectionPath("webhookDeliveries").Query
+	if query.WebhookID != "" {
+		q = q.Where("webhookId", "==", query.WebhookID)
+	}
+	if query.Status != "" {
+		q = q.Where("status", "==", query.Status)
+	}
+	q = q.OrderBy("createdAt", firestore.Desc)
+	if query.Limit > 0 {
+		q = q.Limit(query.Limit)
+	}
+
+	return r.webhookDeliveries(ctx, q)
+}
+
+// GetDueWebhookDeliveries returns pending deliveries whose next attempt is
+// due, oldest first. Finished deliveries have no nextAttemptAt, so they are
+// left out by the range filter alone.
+func (r *Repository) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	q := r.getSubcollectionPath("webhookDeliveries").
+		Where("nextAttemptAt", "<=", now).
+		OrderBy("nextAttemptAt", firestore.Asc).
+		Limit(limit)
+	return r.webhookDeliveries(ctx, q)
+}
+
+func (r *Repository) webhookDeliveries(ctx context.Context, q firestore.Query) ([]*models.WebhookDelivery, error) {
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*models.WebhookDelivery, 0, len(docs))
+	for _, doc := range docs {
+		var delivery models.WebhookDelivery
+		if err := doc.DataTo(&delivery); err != nil {
+			slog.ErrorContext(ctx, "Error parsing webhook delivery", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		delivery.ID = doc.Ref.ID
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, nil
+}
+
+// ClaimWebhookDelivery moves a due delivery's next attempt to until in a
+// transaction, so another instance polling at the same time skips it. If
+// the claimant stops before recording the attempt, the delivery becomes
+// due again at until.
+func (r *Repository) ClaimWebhookDelivery(ctx context.Context, id string, now, until time.Time) (*models.WebhookDelivery, error) {
+	docRef := r.getSubcollectionPath("webhookDeliveries").Doc(id)
+	var delivery models.WebhookDelivery
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return translateError(err)
+		}
+		if err := doc.DataTo(&delivery); err != nil {
+			return err
+		}
+		if delivery.Status != models.WebhookDeliveryPending || delivery.NextAttemptAt == nil || delivery.NextAttemptAt.After(now) {
+			return ErrNotFound
+		}
+
+		delivery.NextAttemptAt = &until
+		return tx.Update(docRef, []firestore.Update{{Path: "nextAttemptAt", Value: until}})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.ID = id
+	return &delivery, nil
+}
+
+func (r *Repository) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	_, err := r.getSubcollectionPath("webhookDeliveries").Doc(delivery.ID).Set(ctx, delivery)
+	return err
+}
+
+// Job operations
+func (r *Repository) CreateJob(ctx context.Context, job *models.Job) error {
+	docRef := r.getSubcollectionPath("jobs").NewDoc()
+	job.ID = docRef.ID
+	if _, err := docRef.Create(ctx, job); err != nil {
+		job.ID = ""
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	doc, err := r.getSubcollectionPath("jobs").Doc(id).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var job models.Job
+	if err := doc.DataTo(&job); err != nil {
+		return nil, err
+	}
+	job.ID = doc.Ref.ID
+	return &job, nil
+}
+
+// GetJobs returns matching jobs, newest first. Filtering by type or status
+// needs a composite index with createdAt on the jobs collection.
+func (r *Repository) GetJobs(ctx context.Context, query models.JobQuery) ([]*models.Job, error) {
+	q := r.getSubcollectionPath("jobs").Query
+	if query.Type != "" {
+		q = q.Where("type", "==", query.Type)
+	}
+	if query.Status != "" {
+		q = q.Where("status", "==", query.Status)
+	}
+	q = q.OrderBy("createdAt", firestore.Desc)
+	if query.Limit > 0 {
+		q = q.Limit(query.Limit)
+	}
+
+	return r.jobs(ctx, q)
+}
+
+// GetDueJobs returns pending jobs that are due, oldest first. Finished jobs
+// have no runAt, so they are left out by the range filter alone.
+func (r *Repository) GetDueJobs(ctx context.Context, now time.Time, limit int) ([]*models.Job, error) {
+	q := r.getSubcollectionPath("jobs").
+		Where("runAt", "<=", now).
+		OrderBy("runAt", firestore.Asc).
+		Limit(limit)
+	return r.jobs(ctx, q)
+}
+
+func (r *Repository) jobs(ctx context.Context, q firestore.Query) ([]*models.Job, error) {
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*models.Job, 0, len(docs))
+	for _, doc := range docs {
+		var job models.Job
+		if err := doc.DataTo(&job); err != nil {
+			slog.ErrorContext(ctx, "Error parsing job", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		job.ID = doc.Ref.ID
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, nil
+}
+
+// ClaimJob leases a due job in a transaction, so another worker polling at
+// the same time skips it. If the worker stops before recording the attempt,
+// the job becomes due again when the lease ends.
+func (r *Repository) ClaimJob(ctx context.Context, id, leaseID string, now, until time.Time) (*models.Job, error) {
+	docRef := r.getSubcollectionPath("jobs").Doc(id)
+	var job models.Job
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return translateError(err)
+		}
+		if err := doc.DataTo(&job); err != nil {
+			return err
+		}
+		if job.Status != models.JobPending || job.RunAt == nil || job.RunAt.After(now) {
+			return ErrNotFound
+		}
+
+		job.RunAt = &until
+		job.LeaseID = leaseID
+		return tx.Update(docRef, []firestore.Update{
+			{Path: "runAt", Value: until},
+			{Path: "leaseId", Value: leaseID},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	job.ID = id
+	return &job, nil
+}
+
+// UpdateJob only writes the job if leaseID still holds it, so a worker that
+// overran its lease cannot overwrite the outcome of the worker that took
+// the job over
+func (r *Repository) UpdateJob(ctx context.Context, job *models.Job, leaseID string) error {
+	docRef := r.getSubcollectionPath("jobs").Doc(job.ID)
+	return r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return translateError(err)
+		}
+		current, err := doc.DataAt("leaseId")
+		if err != nil || current != leaseID {
+			return ErrLeaseLost
+		}
+		return tx.Set(docRef, job)
+	})
+}
+
+func (r *Repository) RetryJob(ctx context.Context, id string, now time.Time) (*models.Job, error) {
+	docRef := r.getSubcollectionPath("jobs").Doc(id)
+	var job models.Job
+
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return translateError(err)
+		}
+		if err := doc.DataTo(&job); err != nil {
+			return err
+		}
+		if job.Status != models.JobFailed {
+			return ErrJobNotFailed
+		}
+
+		job.Status = models.JobPending
+		job.Attempts = 0
+		job.RunAt = &now
+		job.LeaseID = ""
+		job.CompletedAt = nil
+		job.UpdatedAt = now
+		return tx.Set(docRef, &job)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	job.ID = id
+	return &job, nil
+}
+
+// LimiterStore keeps rate limiter entries in Firestore so that every server
+// instance shares the same counts. A TTL policy on the expiresAt field of
+// the collection cleans up old entries.
+type LimiterStore struct {
+	client     *firestore.Client
+	collection *firestore.CollectionRef
+}
+
+// NewLimiterStore returns a ratelimit.Store backed by the named collection
+func (r *Repository) NewLimiterStore(collection string) *LimiterStore {
+	return &LimiterStore{client: r.client, collection: r.getSubcollectionPath(collection)}
+}
+
+func (s *LimiterStore) Get(ctx context.Context, key string) (ratelimit.Entry, error) {
+	var entry ratelimit.Entry
+	doc, err := s.doc(key).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return entry, nil
+		}
+		return entry, err
+	}
+	err = doc.DataTo(&entry)
+	return entry, err
+}
+
+func (s *LimiterStore) Update(ctx context.Context, key string, fn func(*ratelimit.Entry)) (ratelimit.Entry, error) {
+	var entry ratelimit.Entry
+	ref := s.doc(key)
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		entry = ratelimit.Entry{}
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			if err := doc.DataTo(&entry); err != nil {
+				return err
+			}
+		}
+		fn(&entry)
+		return tx.Set(ref, entry)
+	})
+	return entry, err
+}
+
+func (s *LimiterStore) Delete(ctx context.Context, key string) error {
+	_, err := s.doc(key).Delete(ctx)
+	return err
+}
+
+// doc hashes the key because emails and IPv6 addresses are not safe document IDs
+func (s *LimiterStore) doc(key string) *firestore.DocumentRef {
+	sum := sha256.Sum256([]byte(key))
+	return s.collection.Doc(hex.EncodeToString(sum[:]))
+}
+
+// SessionBackend keeps admin sessions in Firestore so that they survive
+// restarts and are shared between instances. A TTL policy on the expiresAt
+// field of the collection cleans up expired sessions.
+type SessionBackend struct {
+	collection *firestore.CollectionRef
+}
+
+// NewSessionBackend returns a sessionstore.Backend backed by the named collection
+func (r *Repository) NewSessionBackend(collection string) *SessionBackend {
+	return &SessionBackend{collection: r.getSubcollectionPath(collection)}
+}
+
+func (b *SessionBackend) Get(ctx context.Context, id string) (*sessionstore.Record, error) {
+	doc, err := b.collection.Doc(id).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var record sessionstore.Record
+	if err := doc.DataTo(&record); err != nil {
+		return nil, err
+	}
+	record.ID = doc.Ref.ID
+	return &record, nil
+}
+
+func (b *SessionBackend) Save(ctx context.Context, record *sessionstore.Record) error {
+	_, err := b.collection.Doc(record.ID).Set(ctx, record)
+	return err
+}
+
+// Touch updates rather than sets, as an update fails when the document does
+// not exist instead of recreating a revoked session
+func (b *SessionBackend) Touch(ctx context.Context, id string, lastSeenAt time.Time) error {
+	_, err := b.collection.Doc(id).Update(ctx, []firestore.Update{{Path: "lastSeenAt", Value: lastSeenAt}})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+func (b *SessionBackend) Delete(ctx context.Context, id string) error {
+	_, err := b.collection.Doc(id).Delete(ctx)
+	return err
+}
+
+func (b *SessionBackend) ListByUser(ctx context.Context, userID string) ([]*sessionstore.Record, error) {
+	docs, err := b.collection.Where("userId", "==", userID).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*sessionstore.Record, 0, len(docs))
+	for _, doc := range docs {
+		var record sessionstore.Record
+		if err := doc.DataTo(&record); err != nil {
+			slog.ErrorContext(ctx, "Error parsing session", "id", doc.Ref.ID, "error", err)
+			continue
+		}
+		record.ID = doc.Ref.ID
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/ratelimit"
+	"ai-india-workshop-backend/internal/sessionstore"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -15,6 +17,16 @@ func TestRepositoryInterfaceCompliance(t *testing.T) {
 	var _ RepositoryInterface = (*Repository)(nil)
 }
 
+// TestLimiterStoreInterfaceCompliance verifies that LimiterStore implements ratelimit.Store
+func TestLimiterStoreInterfaceCompliance(t *testing.T) {
+	var _ ratelimit.Store = (*LimiterStore)(nil)
+}
+
+// TestSessionBackendInterfaceCompliance verifies that SessionBackend implements sessionstore.Backend
+func TestSessionBackendInterfaceCompliance(t *testing.T) {
+	var _ sessionstore.Backend = (*SessionBackend)(nil)
+}
+
 // TestGetSubcollectionPath tests the internal helper method
 // Note: This requires a valid Firestore connection, so we'll test the logic conceptually
 func TestGetSubcollectionPath(t *testing.T) {
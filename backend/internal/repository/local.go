@@ -0,0 +1,1360 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ai-india-workshop-backend/internal/analytics"
+	"ai-india-workshop-backend/internal/ids"
+	"ai-india-workshop-backend/internal/models"
+)
+
+func init() {
+	// Registration answers and audit snapshots hold decoded JSON
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// LocalRepository keeps a workshop's data in a file instead of Firestore,
+// for trying things out and for running workshopctl without a project. It
+// behaves like Repository, outbox included, but the file is read once when
+// it is opened and rewritten after every change, so only one process may
+// use it at a time.
+type LocalRepository struct {
+	mu   sync.Mutex
+	path string
+	data localData
+}
+
+// localData is everything stored for a workshop. It is saved with
+// encoding/gob rather than JSON because the models leave secrets such as
+// password hashes out of their JSON.
+type localData struct {
+	Attendees         map[string]*models.Attendee
+	Speakers          map[string]*models.Speaker
+	Sessions          map[string]*models.Session
+	RegistrationForm  *models.RegistrationForm
+	Designations      *models.DesignationTaxonomy
+	AnalyticsDays     map[string]*models.AnalyticsDay
+	AnalyticsRebuilt  bool
+	AuditLog          map[string]*models.AuditEntry
+	AdminUsers        map[string]*models.AdminUser
+	LoginAttempts     map[string]*models.LoginAttempt
+	APITokens         map[string]*models.APIToken
+	Webhooks          map[string]*models.Webhook
+	WebhookDeliveries map[string]*models.WebhookDelivery
+	Jobs              map[string]*models.Job
+}
+
+// NewLocalRepository opens the workshop's file in dir, <subcollectionID>.gob,
+// creating dir if needed. The file itself is only written on the first change.
+func NewLocalRepository(dir, subcollectionID string) (*LocalRepository, error) {
+	if subcollectionID == "" {
+		return nil, errors.New("a workshop ID is required")
+	}
+	if strings.ContainsAny(subcollectionID, `/\`) {
+		return nil, fmt.Errorf("invalid workshop ID %q", subcollectionID)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	r := &LocalRepository{path: filepath.Join(dir, subcollectionID+".gob")}
+	raw, err := os.ReadFile(r.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&r.data); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", r.path, err)
+		}
+	}
+	r.data.init()
+	return r, nil
+}
+
+// init makes the maps that gob leaves out when they are empty
+func (d *localData) init() {
+	if d.Attendees == nil {
+		d.Attendees = map[string]*models.Attendee{}
+	}
+	if d.Speakers == nil {
+		d.Speakers = map[string]*models.Speaker{}
+	}
+	if d.Sessions == nil {
+		d.Sessions = map[string]*models.Session{}
+	}
+	if d.AnalyticsDays == nil {
+		d.AnalyticsDays = map[string]*models.AnalyticsDay{}
+	}
+	if d.AuditLog == nil {
+		d.AuditLog = map[string]*models.AuditEntry{}
+	}
+	if d.AdminUsers == nil {
+		d.AdminUsers = map[string]*models.AdminUser{}
+	}
+	if d.LoginAttempts == nil {
+		d.LoginAttempts = map[string]*models.LoginAttempt{}
+	}
+	if d.APITokens == nil {
+		d.APITokens = map[string]*models.APIToken{}
+	}
+	if d.Webhooks == nil {
+		d.Webhooks = map[string]*models.Webhook{}
+	}
+	if d.WebhookDeliveries == nil {
+		d.WebhookDeliveries = map[string]*models.WebhookDelivery{}
+	}
+	if d.Jobs == nil {
+		d.Jobs = map[string]*models.Job{}
+	}
+}
+
+// Path is the file the workshop is stored in
+func (r *LocalRepository) Path() string {
+	return r.path
+}
+
+// Close has nothing to release; it is there so that either repository can
+// be closed once done with
+func (r *LocalRepository) Close() error {
+	return nil
+}
+
+// save writes the data to a temporary file and renames it over the old one,
+// so a crash never leaves a half-written file. It is called with r.mu held.
+func (r *LocalRepository) save() error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&r.data); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// change runs fn and saves the data if it succeeds. The data is only
+// changed in memory when fn returns nil, as fn checks before it writes.
+func (r *LocalRepository) change(fn func() error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := fn(); err != nil {
+		return err
+	}
+	return r.save()
+}
+
+// read runs fn with the lock held
+func (r *LocalRepository) read(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn()
+}
+
+// newIDs returns n random IDs for records added together
+func newIDs(n int) ([]string, error) {
+	assigned := make([]string, n)
+	for i := range assigned {
+		id, err := ids.New()
+		if err != nil {
+			return nil, err
+		}
+		assigned[i] = id
+	}
+	return assigned, nil
+}
+
+// clone copies a record so that callers cannot change the stored one
+func clone[T any](v *T) *T {
+	c := *v
+	return &c
+}
+
+// sortedIDs returns a map's keys in order, the order Firestore lists
+// documents in when no other is asked for
+func sortedIDs[T any](m map[string]T) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// countEvent adds delta to the analytics slot an event at t falls in
+func (r *LocalRepository) countEvent(event string, t time.Time, delta int) {
+	date, slot := analytics.Slot(t)
+	day, ok := r.data.AnalyticsDays[date]
+	if !ok {
+		day = &models.AnalyticsDay{Date: date}
+		r.data.AnalyticsDays[date] = day
+	}
+	var counts *map[string]int
+	switch event {
+	case models.AnalyticsRegistrations:
+		counts = &day.Registrations
+	case models.AnalyticsCancellations:
+		counts = &day.Cancellations
+	default:
+		counts = &day.CheckIns
+	}
+	if *counts == nil {
+		*counts = map[string]int{}
+	}
+	(*counts)[slot] += delta
+}
+
+// publish writes an event to the outbox
+func (r *LocalRepository) publish(event models.Event) error {
+	job, err := models.NewJob(models.JobPublishEvent, event, event.OccurredAt)
+	if err != nil {
+		return err
+	}
+	if job.ID, err = ids.New(); err != nil {
+		return err
+	}
+	r.data.Jobs[job.ID] = job
+	return nil
+}
+
+func (r *LocalRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Attendee operations
+func (r *LocalRepository) CreateAttendee(ctx context.Context, attendee *models.Attendee) error {
+	id, err := ids.New()
+	if err != nil {
+		return err
+	}
+	err = r.change(func() error {
+		registered := clone(attendee)
+		registered.ID = id
+		if err := r.publish(models.Event{
+			Type:         models.WebhookAttendeeRegistered,
+			Action:       models.ActionCreated,
+			ResourceType: models.ResourceAttendees,
+			ResourceID:   id,
+			Attendee:     registered,
+			OccurredAt:   attendee.CreatedAt,
+		}); err != nil {
+			return err
+		}
+		r.data.Attendees[id] = clone(registered)
+		r.countEvent(models.AnalyticsRegistrations, attendee.CreatedAt, 1)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	attendee.ID = id
+	return nil
+}
+
+func (r *LocalRepository) GetAllAttendees(ctx context.Context) ([]*models.Attendee, error) {
+	attendees := make([]*models.Attendee, 0)
+	r.read(func() {
+		for _, attendee := range r.data.Attendees {
+			if attendee.DeletedAt == nil {
+				attendees = append(attendees, clone(attendee))
+			}
+		}
+	})
+	sort.SliceStable(attendees, func(i, j int) bool { return attendees[i].CreatedAt.After(attendees[j].CreatedAt) })
+	return attendees, nil
+}
+
+func (r *LocalRepository) GetAttendee(ctx context.Context, id string) (*models.Attendee, error) {
+	var attendee *models.Attendee
+	r.read(func() {
+		if stored, ok := r.data.Attendees[id]; ok && stored.DeletedAt == nil {
+			attendee = clone(stored)
+		}
+	})
+	if attendee == nil {
+		return nil, ErrNotFound
+	}
+	return attendee, nil
+}
+
+func (r *LocalRepository) GetAttendeeCount(ctx context.Context) (int, error) {
+	count := 0
+	r.read(func() {
+		for _, attendee := range r.data.Attendees {
+			if attendee.DeletedAt == nil {
+				count++
+			}
+		}
+	})
+	return count, nil
+}
+
+func (r *LocalRepository) DeleteAttendee(ctx context.Context, id string) error {
+	return r.softDelete(models.ResourceAttendees, id)
+}
+
+func (r *LocalRepository) CheckInAttendee(ctx context.Context, id string) (*models.Attendee, error) {
+	var checkedIn *models.Attendee
+	err := r.change(func() error {
+		attendee, ok := r.data.Attendees[id]
+		if !ok || attendee.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if attendee.CheckedInAt != nil {
+			return ErrAlreadyCheckedIn
+		}
+
+		now := time.Now()
+		checkedIn = clone(attendee)
+		checkedIn.CheckedInAt = &now
+		if err := r.publish(models.Event{
+			Type:         models.WebhookAttendeeCheckedIn,
+			Action:       models.ActionUpdated,
+			ResourceType: models.ResourceAttendees,
+			ResourceID:   id,
+			Attendee:     clone(checkedIn),
+			OccurredAt:   now,
+		}); err != nil {
+			return err
+		}
+		attendee.CheckedInAt = &now
+		r.countEvent(models.AnalyticsCheckIns, now, 1)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return checkedIn, nil
+}
+
+func (r *LocalRepository) SetAttendeeDesignations(ctx context.Context, designations map[string]string) (int, error) {
+	updated := 0
+	err := r.change(func() error {
+		for id, designation := range designations {
+			attendee, ok := r.data.Attendees[id]
+			if !ok {
+				continue
+			}
+			attendee.Designation = designation
+			updated++
+		}
+		return nil
+	})
+	return updated, err
+}
+
+// Speaker operations
+func (r *LocalRepository) CreateSpeaker(ctx context.Context, speaker *models.Speaker) error {
+	id, err := ids.New()
+	if err != nil {
+		return err
+	}
+	err = r.change(func() error {
+		if err := r.publish(speakerEvent(models.ActionCreated, id, speaker)); err != nil {
+			return err
+		}
+		stored := clone(speaker)
+		stored.ID = id
+		r.data.Speakers[id] = stored
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	speaker.ID = id
+	return nil
+}
+
+func (r *LocalRepository) GetAllSpeakers(ctx context.Context) ([]*models.Speaker, error) {
+	speakers := make([]*models.Speaker, 0)
+	r.read(func() {
+		for _, id := range sortedIDs(r.data.Speakers) {
+			if speaker := r.data.Speakers[id]; speaker.DeletedAt == nil {
+				speakers = append(speakers, clone(speaker))
+			}
+		}
+	})
+	return speakers, nil
+}
+
+func (r *LocalRepository) GetSpeaker(ctx context.Context, id string) (*models.Speaker, error) {
+	var speaker *models.Speaker
+	r.read(func() {
+		if stored, ok := r.data.Speakers[id]; ok && stored.DeletedAt == nil {
+			speaker = clone(stored)
+		}
+	})
+	if speaker == nil {
+		return nil, ErrNotFound
+	}
+	return speaker, nil
+}
+
+func (r *LocalRepository) UpdateSpeaker(ctx context.Context, id string, speaker *models.Speaker) error {
+	return r.change(func() error {
+		stored, ok := r.data.Speakers[id]
+		if !ok || stored.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if err := r.publish(speakerEvent(models.ActionUpdated, id, speaker)); err != nil {
+			return err
+		}
+		stored.Name = speaker.Name
+		stored.Bio = speaker.Bio
+		if speaker.Avatar != "" {
+			stored.Avatar = speaker.Avatar
+		}
+		if speaker.LinkedIn != "" {
+			stored.LinkedIn = speaker.LinkedIn
+		}
+		if speaker.Twitter != "" {
+			stored.Twitter = speaker.Twitter
+		}
+		return nil
+	})
+}
+
+func (r *LocalRepository) DeleteSpeaker(ctx context.Context, id string) error {
+	return r.softDelete(models.ResourceSpeakers, id)
+}
+
+// Session operations
+func (r *LocalRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	id, err := ids.New()
+	if err != nil {
+		return err
+	}
+	err = r.change(func() error {
+		if err := r.publish(sessionEvent(models.ActionCreated, id, session)); err != nil {
+			return err
+		}
+		stored := clone(session)
+		stored.ID = id
+		r.data.Sessions[id] = stored
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	session.ID = id
+	return nil
+}
+
+func (r *LocalRepository) GetAllSessions(ctx context.Context) ([]*models.Session, error) {
+	sessions := make([]*models.Session, 0)
+	r.read(func() {
+		for _, id := range sortedIDs(r.data.Sessions) {
+			if session := r.data.Sessions[id]; session.DeletedAt == nil {
+				sessions = append(sessions, clone(session))
+			}
+		}
+	})
+	return sessions, nil
+}
+
+func (r *LocalRepository) GetSession(ctx context.Context, id string) (*models.Session, error) {
+	var session *models.Session
+	r.read(func() {
+		if stored, ok := r.data.Sessions[id]; ok && stored.DeletedAt == nil {
+			session = clone(stored)
+		}
+	})
+	if session == nil {
+		return nil, ErrNotFound
+	}
+	return session, nil
+}
+
+func (r *LocalRepository) UpdateSession(ctx context.Context, id string, session *models.Session) error {
+	return r.change(func() error {
+		stored, ok := r.data.Sessions[id]
+		if !ok || stored.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if err := r.publish(sessionEvent(models.ActionUpdated, id, session)); err != nil {
+			return err
+		}
+		stored.Title = session.Title
+		stored.Description = session.Description
+		stored.Time = session.Time
+		stored.Speakers = session.Speakers
+		return nil
+	})
+}
+
+func (r *LocalRepository) DeleteSession(ctx context.Context, id string) error {
+	return r.softDelete(models.ResourceSessions, id)
+}
+
+// Stats operations
+func (r *LocalRepository) GetDesignationBreakdown(ctx context.Context) ([]models.DesignationCount, error) {
+	designationMap := make(map[string]int)
+	r.read(func() {
+		for _, attendee := range r.data.Attendees {
+			if attendee.DeletedAt == nil {
+				designationMap[attendee.Designation]++
+			}
+		}
+	})
+
+	var breakdown []models.DesignationCount
+	for designation, count := range designationMap {
+		breakdown = append(breakdown, models.DesignationCount{
+			Designation: designation,
+			Count:       count,
+		})
+	}
+	return breakdown, nil
+}
+
+// Registration form operations
+func (r *LocalRepository) GetRegistrationForm(ctx context.Context) (*models.RegistrationForm, error) {
+	form := &models.RegistrationForm{}
+	r.read(func() {
+		if r.data.RegistrationForm != nil {
+			form = clone(r.data.RegistrationForm)
+		}
+	})
+	if form.Fields == nil {
+		form.Fields = []models.FormField{}
+	}
+	return form, nil
+}
+
+func (r *LocalRepository) SaveRegistrationForm(ctx context.Context, form *models.RegistrationForm) error {
+	return r.change(func() error {
+		r.data.RegistrationForm = clone(form)
+		return nil
+	})
+}
+
+func (r *LocalRepository) GetDesignationTaxonomy(ctx context.Context) (*models.DesignationTaxonomy, error) {
+	taxonomy := &models.DesignationTaxonomy{}
+	r.read(func() {
+		if r.data.Designations != nil {
+			taxonomy = clone(r.data.Designations)
+		}
+	})
+	if taxonomy.Designations == nil {
+		taxonomy.Designations = []models.Designation{}
+	}
+	return taxonomy, nil
+}
+
+func (r *LocalRepository) SaveDesignationTaxonomy(ctx context.Context, taxonomy *models.DesignationTaxonomy) error {
+	return r.change(func() error {
+		r.data.Designations = clone(taxonomy)
+		return nil
+	})
+}
+
+// Analytics operations
+func (r *LocalRepository) GetAnalyticsDays(ctx context.Context) ([]*models.AnalyticsDay, error) {
+	var days []*models.AnalyticsDay
+	r.read(func() {
+		if !r.data.AnalyticsRebuilt {
+			return
+		}
+		days = make([]*models.AnalyticsDay, 0, len(r.data.AnalyticsDays))
+		for _, date := range sortedIDs(r.data.AnalyticsDays) {
+			days = append(days, clone(r.data.AnalyticsDays[date]))
+		}
+	})
+	if days == nil {
+		return nil, ErrNotFound
+	}
+	return days, nil
+}
+
+// RebuildAnalytics recounts every attendee, including those in the trash
+func (r *LocalRepository) RebuildAnalytics(ctx context.Context) error {
+	return r.change(func() error {
+		attendees := make([]*models.Attendee, 0, len(r.data.Attendees))
+		for _, attendee := range r.data.Attendees {
+			attendees = append(attendees, attendee)
+		}
+		r.data.AnalyticsDays = map[string]*models.AnalyticsDay{}
+		for _, day := range analytics.Build(attendees) {
+			r.data.AnalyticsDays[day.Date] = day
+		}
+		r.data.AnalyticsRebuilt = true
+		return nil
+	})
+}
+
+// Trash operations
+
+// softDelete marks a live record as deleted and publishes the record as it was
+func (r *LocalRepository) softDelete(resourceType, id string) error {
+	return r.change(func() error {
+		now := time.Now()
+		event := models.Event{Action: models.ActionDeleted, ResourceType: resourceType, ResourceID: id, OccurredAt: now}
+		switch resourceType {
+		case models.ResourceAttendees:
+			attendee, ok := r.data.Attendees[id]
+			if !ok || attendee.DeletedAt != nil {
+				return ErrNotFound
+			}
+			event.Type = models.WebhookAttendeeCancelled
+			event.Attendee = clone(attendee)
+			if err := r.publish(event); err != nil {
+				return err
+			}
+			attendee.DeletedAt = &now
+			r.countEvent(models.AnalyticsCancellations, now, 1)
+		case models.ResourceSpeakers:
+			speaker, ok := r.data.Speakers[id]
+			if !ok || speaker.DeletedAt != nil {
+				return ErrNotFound
+			}
+			event.Type = models.WebhookAgendaChanged
+			event.Speaker = clone(speaker)
+			if err := r.publish(event); err != nil {
+				return err
+			}
+			speaker.DeletedAt = &now
+		case models.ResourceSessions:
+			session, ok := r.data.Sessions[id]
+			if !ok || session.DeletedAt != nil {
+				return ErrNotFound
+			}
+			event.Type = models.WebhookAgendaChanged
+			event.Session = clone(session)
+			if err := r.publish(event); err != nil {
+				return err
+			}
+			session.DeletedAt = &now
+		default:
+			return ErrUnknownResourceType
+		}
+		return nil
+	})
+}
+
+func (r *LocalRepository) GetTrash(ctx context.Context) (*models.Trash, error) {
+	trash := &models.Trash{
+		Attendees: make([]*models.Attendee, 0),
+		Speakers:  make([]*models.Speaker, 0),
+		Sessions:  make([]*models.Session, 0),
+	}
+	r.read(func() {
+		for _, id := range sortedIDs(r.data.Attendees) {
+			if attendee := r.data.Attendees[id]; attendee.DeletedAt != nil {
+				trash.Attendees = append(trash.Attendees, clone(attendee))
+			}
+		}
+		for _, id := range sortedIDs(r.data.Speakers) {
+			if speaker := r.data.Speakers[id]; speaker.DeletedAt != nil {
+				trash.Speakers = append(trash.Speakers, clone(speaker))
+			}
+		}
+		for _, id := range sortedIDs(r.data.Sessions) {
+			if session := r.data.Sessions[id]; session.DeletedAt != nil {
+				trash.Sessions = append(trash.Sessions, clone(session))
+			}
+		}
+	})
+	return trash, nil
+}
+
+func (r *LocalRepository) RestoreFromTrash(ctx context.Context, resourceType, id string) error {
+	return r.change(func() error {
+		event := models.Event{Action: models.ActionRestored, ResourceType: resourceType, ResourceID: id, OccurredAt: time.Now()}
+		switch resourceType {
+		case models.ResourceAttendees:
+			attendee, ok := r.data.Attendees[id]
+			if !ok || attendee.DeletedAt == nil {
+				return ErrNotFound
+			}
+			event.Type = models.WebhookAttendeeRegistered
+			event.Attendee = clone(attendee)
+			event.Attendee.DeletedAt = nil
+			if err := r.publish(event); err != nil {
+				return err
+			}
+			// Restoring an attendee takes back their cancellation
+			r.countEvent(models.AnalyticsCancellations, *attendee.DeletedAt, -1)
+			attendee.DeletedAt = nil
+		case models.ResourceSpeakers:
+			speaker, ok := r.data.Speakers[id]
+			if !ok || speaker.DeletedAt == nil {
+				return ErrNotFound
+			}
+			event.Type = models.WebhookAgendaChanged
+			event.Speaker = clone(speaker)
+			event.Speaker.DeletedAt = nil
+			if err := r.publish(event); err != nil {
+				return err
+			}
+			speaker.DeletedAt = nil
+		case models.ResourceSessions:
+			session, ok := r.data.Sessions[id]
+			if !ok || session.DeletedAt == nil {
+				return ErrNotFound
+			}
+			event.Type = models.WebhookAgendaChanged
+			event.Session = clone(session)
+			event.Session.DeletedAt = nil
+			if err := r.publish(event); err != nil {
+				return err
+			}
+			session.DeletedAt = nil
+		default:
+			return ErrUnknownResourceType
+		}
+		return nil
+	})
+}
+
+func (r *LocalRepository) PurgeFromTrash(ctx context.Context, resourceType, id string) error {
+	return r.change(func() error {
+		switch resourceType {
+		case models.ResourceAttendees:
+			return purgeTrashed(r.data.Attendees, id, func(a *models.Attendee) *time.Time { return a.DeletedAt })
+		case models.ResourceSpeakers:
+			return purgeTrashed(r.data.Speakers, id, func(s *models.Speaker) *time.Time { return s.DeletedAt })
+		case models.ResourceSessions:
+			return purgeTrashed(r.data.Sessions, id, func(s *models.Session) *time.Time { return s.DeletedAt })
+		default:
+			return ErrUnknownResourceType
+		}
+	})
+}
+
+// purgeTrashed removes a record that is in the trash
+func purgeTrashed[T any](records map[string]*T, id string, deletedAt func(*T) *time.Time) error {
+	record, ok := records[id]
+	if !ok || deletedAt(record) == nil {
+		return ErrNotFound
+	}
+	delete(records, id)
+	return nil
+}
+
+// purgeBefore removes the records soft deleted before the cutoff
+func purgeBefore[T any](records map[string]*T, cutoff time.Time, deletedAt func(*T) *time.Time) int {
+	purged := 0
+	for id, record := range records {
+		if at := deletedAt(record); at != nil && at.Before(cutoff) {
+			delete(records, id)
+			purged++
+		}
+	}
+	return purged
+}
+
+func (r *LocalRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	purged := 0
+	err := r.change(func() error {
+		purged += purgeBefore(r.data.Attendees, cutoff, func(a *models.Attendee) *time.Time { return a.DeletedAt })
+		purged += purgeBefore(r.data.Speakers, cutoff, func(s *models.Speaker) *time.Time { return s.DeletedAt })
+		purged += purgeBefore(r.data.Sessions, cutoff, func(s *models.Session) *time.Time { return s.DeletedAt })
+		return nil
+	})
+	return purged, err
+}
+
+// Backup operations
+func (r *LocalRepository) GetWorkshopData(ctx context.Context) (*models.WorkshopData, error) {
+	data := &models.WorkshopData{
+		Speakers:  make([]*models.Speaker, 0),
+		Sessions:  make([]*models.Session, 0),
+		Attendees: make([]*models.Attendee, 0),
+	}
+	r.read(func() {
+		for _, id := range sortedIDs(r.data.Speakers) {
+			data.Speakers = append(data.Speakers, clone(r.data.Speakers[id]))
+		}
+		for _, id := range sortedIDs(r.data.Sessions) {
+			data.Sessions = append(data.Sessions, clone(r.data.Sessions[id]))
+		}
+		for _, attendee := range r.data.Attendees {
+			data.Attendees = append(data.Attendees, clone(attendee))
+		}
+	})
+	sort.SliceStable(data.Attendees, func(i, j int) bool { return data.Attendees[i].CreatedAt.Before(data.Attendees[j].CreatedAt) })
+	return data, nil
+}
+
+func (r *LocalRepository) ReplaceWorkshopData(ctx context.Context, data *models.WorkshopData) error {
+	speakers := map[string]*models.Speaker{}
+	for _, speaker := range data.Speakers {
+		speakers[speaker.ID] = clone(speaker)
+	}
+	sessions := map[string]*models.Session{}
+	for _, session := range data.Sessions {
+		sessions[session.ID] = clone(session)
+	}
+	attendees := map[string]*models.Attendee{}
+	for _, attendee := range data.Attendees {
+		attendees[attendee.ID] = clone(attendee)
+	}
+	if speakers[""] != nil || sessions[""] != nil || attendees[""] != nil {
+		return errors.New("a record has no ID")
+	}
+
+	return r.change(func() error {
+		r.data.Speakers = speakers
+		r.data.Sessions = sessions
+		r.data.Attendees = attendees
+		return nil
+	})
+}
+
+func (r *LocalRepository) ImportAttendees(ctx context.Context, attendees []*models.Attendee) error {
+	assigned, err := newIDs(len(attendees))
+	if err != nil {
+		return err
+	}
+	err = r.change(func() error {
+		for i, attendee := range attendees {
+			imported := clone(attendee)
+			imported.ID = assigned[i]
+			r.data.Attendees[assigned[i]] = imported
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i, attendee := range attendees {
+		attendee.ID = assigned[i]
+	}
+	return nil
+}
+
+func (r *LocalRepository) PurgeAttendees(ctx context.Context, ids []string) error {
+	return r.change(func() error {
+		for _, id := range ids {
+			delete(r.data.Attendees, id)
+		}
+		return nil
+	})
+}
+
+// Audit log operations
+func (r *LocalRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	id, err := ids.New()
+	if err != nil {
+		return err
+	}
+	err = r.change(func() error {
+		stored := clone(entry)
+		stored.ID = id
+		r.data.AuditLog[id] = stored
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	entry.ID = id
+	return nil
+}
+
+func (r *LocalRepository) GetAuditEntries(ctx context.Context, query models.AuditQuery) ([]*models.AuditEntry, error) {
+	entries := make([]*models.AuditEntry, 0)
+	r.read(func() {
+		for _, entry := range r.data.AuditLog {
+			switch {
+			case query.Actor != "" && entry.Actor != query.Actor,
+				query.ResourceType != "" && entry.ResourceType != query.ResourceType,
+				query.ResourceID != "" && entry.ResourceID != query.ResourceID,
+				!query.From.IsZero() && entry.Timestamp.Before(query.From),
+				!query.To.IsZero() && entry.Timestamp.After(query.To):
+				continue
+			}
+			entries = append(entries, clone(entry))
+		}
+	})
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.After(entries[j].Timestamp) })
+	return limit(entries, query.Limit), nil
+}
+
+// limit returns at most n records, or all of them if n is not positive
+func limit[T any](records []T, n int) []T {
+	if n > 0 && len(records) > n {
+		return records[:n]
+	}
+	return records
+}
+
+// Admin user operations
+func (r *LocalRepository) CreateAdminUser(ctx context.Context, user *models.AdminUser) error {
+	id, err := ids.New()
+	if err != nil {
+		return err
+	}
+	err = r.change(func() error {
+		for _, existing := range r.data.AdminUsers {
+			if existing.Email == user.Email {
+				return ErrAlreadyExists
+			}
+		}
+		stored := clone(user)
+		stored.ID = id
+		r.data.AdminUsers[id] = stored
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	user.ID = id
+	return nil
+}
+
+func (r *LocalRepository) GetAllAdminUsers(ctx context.Context) ([]*models.AdminUser, error) {
+	users := make([]*models.AdminUser, 0)
+	r.read(func() {
+		for _, user := range r.data.AdminUsers {
+			users = append(users, clone(user))
+		}
+	})
+	sort.SliceStable(users, func(i, j int) bool { return users[i].Email < users[j].Email })
+	return users, nil
+}
+
+func (r *LocalRepository) GetAdminUser(ctx context.Context, id string) (*models.AdminUser, error) {
+	var user *models.AdminUser
+	r.read(func() {
+		if stored, ok := r.data.AdminUsers[id]; ok {
+			user = clone(stored)
+		}
+	})
+	if user == nil {
+		return nil, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *LocalRepository) GetAdminUserByEmail(ctx context.Context, email string) (*models.AdminUser, error) {
+	var user *models.AdminUser
+	r.read(func() {
+		for _, id := range sortedIDs(r.data.AdminUsers) {
+			if stored := r.data.AdminUsers[id]; stored.Email == email {
+				user = clone(stored)
+				return
+			}
+		}
+	})
+	if user == nil {
+		return nil, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *LocalRepository) UpdateAdminUser(ctx context.Context, id string, user *models.AdminUser) error {
+	return r.change(func() error {
+		stored, ok := r.data.AdminUsers[id]
+		if !ok {
+			return ErrNotFound
+		}
+		stored.DisplayName = user.DisplayName
+		stored.Role = user.Role
+		stored.PasswordHash = user.PasswordHash
+		stored.Disabled = user.Disabled
+		stored.UpdatedAt = user.UpdatedAt
+		stored.TwoFactorEnabled = user.TwoFactorEnabled
+		stored.TOTPSecret = user.TOTPSecret
+		stored.PendingTOTPSecret = user.PendingTOTPSecret
+		stored.RecoveryCodeHashes = user.RecoveryCodeHashes
+		stored.LastTOTPStep = user.LastTOTPStep
+		stored.OIDCSubject = user.OIDCSubject
+		stored.SSOManaged = user.SSOManaged
+		if user.LastLoginAt != nil {
+			stored.LastLoginAt = user.LastLoginAt
+		}
+		return nil
+	})
+}
+
+// Login attempt operations
+func (r *LocalRepository) CreateLoginAttempt(ctx context.Context, attempt *models.LoginAttempt) error {
+	id, err := ids.New()
+	if err != nil {
+		return err
+	}
+	err = r.change(func() error {
+		stored := clone(attempt)
+		stored.ID = id
+		r.data.LoginAttempts[id] = stored
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	attempt.ID = id
+	return nil
+}
+
+func (r *LocalRepository) GetLoginAttempts(ctx context.Context, query models.LoginAttemptQuery) ([]*models.LoginAttempt, error) {
+	attempts := make([]*models.LoginAttempt, 0)
+	r.read(func() {
+		for _, attempt := range r.data.LoginAttempts {
+			switch {
+			case query.Email != "" && attempt.Email != query.Email,
+				query.IP != "" && attempt.IP != query.IP,
+				query.FailedOnly && attempt.Success:
+				continue
+			}
+			attempts = append(attempts, clone(attempt))
+		}
+	})
+	sort.SliceStable(attempts, func(i, j int) bool { return attempts[i].Timestamp.After(attempts[j].Timestamp) })
+	return limit(attempts, query.Limit), nil
+}
+
+// API token operations
+func (r *LocalRepository) CreateAPIToken(ctx context.Context, token *models.APIToken) error {
+	return r.change(func() error {
+		if _, ok := r.data.APITokens[token.ID]; ok {
+			return ErrAlreadyExists
+		}
+		r.data.APITokens[token.ID] = clone(token)
+		return nil
+	})
+}
+
+func (r *LocalRepository) GetAPIToken(ctx context.Context, id string) (*models.APIToken, error) {
+	var token *models.APIToken
+	r.read(func() {
+		if stored, ok := r.data.APITokens[id]; ok {
+			token = clone(stored)
+		}
+	})
+	if token == nil {
+		return nil, ErrNotFound
+	}
+	return token, nil
+}
+
+func (r *LocalRepository) GetAPITokens(ctx context.Context, query models.APITokenQuery) ([]*models.APIToken, error) {
+	tokens := make([]*models.APIToken, 0)
+	r.read(func() {
+		for _, token := range r.data.APITokens {
+			switch {
+			case query.OwnerID != "" && token.OwnerID != query.OwnerID,
+				query.Kind != "" && token.Kind != query.Kind:
+				continue
+			}
+			tokens = append(tokens, clone(token))
+		}
+	})
+	sort.SliceStable(tokens, func(i, j int) bool { return tokens[i].CreatedAt.After(tokens[j].CreatedAt) })
+	return tokens, nil
+}
+
+func (r *LocalRepository) RevokeAPIToken(ctx context.Context, id string, revokedAt time.Time) error {
+	return r.change(func() error {
+		token, ok := r.data.APITokens[id]
+		if !ok {
+			return ErrNotFound
+		}
+		token.RevokedAt = &revokedAt
+		return nil
+	})
+}
+
+func (r *LocalRepository) TouchAPIToken(ctx context.Context, id string, usedAt time.Time, ip string) error {
+	return r.change(func() error {
+		token, ok := r.data.APITokens[id]
+		if !ok {
+			return ErrNotFound
+		}
+		token.LastUsedAt = &usedAt
+		token.LastUsedIP = ip
+		return nil
+	})
+}
+
+// Webhook operations
+func (r *LocalRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	id, err := ids.New()
+	if err != nil {
+		return err
+	}
+	err = r.change(func() error {
+		stored := clone(webhook)
+		stored.ID = id
+		r.data.Webhooks[id] = stored
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	webhook.ID = id
+	return nil
+}
+
+func (r *LocalRepository) GetAllWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	webhooks := make([]*models.Webhook, 0)
+	r.read(func() {
+		for _, webhook := range r.data.Webhooks {
+			webhooks = append(webhooks, clone(webhook))
+		}
+	})
+	sort.SliceStable(webhooks, func(i, j int) bool { return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt) })
+	return webhooks, nil
+}
+
+func (r *LocalRepository) GetWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	var webhook *models.Webhook
+	r.read(func() {
+		if stored, ok := r.data.Webhooks[id]; ok {
+			webhook = clone(stored)
+		}
+	})
+	if webhook == nil {
+		return nil, ErrNotFound
+	}
+	return webhook, nil
+}
+
+func (r *LocalRepository) UpdateWebhook(ctx context.Context, id string, webhook *models.Webhook) error {
+	return r.change(func() error {
+		if _, ok := r.data.Webhooks[id]; !ok {
+			return ErrNotFound
+		}
+		webhook.ID = id
+		r.data.Webhooks[id] = clone(webhook)
+		return nil
+	})
+}
+
+func (r *LocalRepository) DeleteWebhook(ctx context.Context, id string) error {
+	return r.change(func() error {
+		if _, ok := r.data.Webhooks[id]; !ok {
+			return ErrNotFound
+		}
+		delete(r.data.Webhooks, id)
+		return nil
+	})
+}
+
+// Webhook delivery operations
+func (r *LocalRepository) CreateWebhookDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	assigned, err := newIDs(len(deliveries))
+	if err != nil {
+		return err
+	}
+	err = r.change(func() error {
+		for i, delivery := range deliveries {
+			stored := clone(delivery)
+			stored.ID = assigned[i]
+			r.data.WebhookDeliveries[assigned[i]] = stored
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i, delivery := range deliveries {
+		delivery.ID = assigned[i]
+	}
+	return nil
+}
+
+func (r *LocalRepository) GetWebhookDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	var delivery *models.WebhookDelivery
+	r.read(func() {
+		if stored, ok := r.data.WebhookDeliveries[id]; ok {
+			delivery = clone(stored)
+		}
+	})
+	if delivery == nil {
+		return nil, ErrNotFound
+	}
+	return delivery, nil
+}
+
+func (r *LocalRepository) GetWebhookDeliveries(ctx context.Context, query models.WebhookDeliveryQuery) ([]*models.WebhookDelivery, error) {
+	deliveries := make([]*models.WebhookDelivery, 0)
+	r.read(func() {
+		for _, delivery := range r.data.WebhookDeliveries {
+			switch {
+			case query.WebhookID != "" && delivery.WebhookID != query.WebhookID,
+				query.Status != "" && delivery.Status != query.Status:
+				continue
+			}
+			deliveries = append(deliveries, clone(delivery))
+		}
+	})
+	sort.SliceStable(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	return limit(deliveries, query.Limit), nil
+}
+
+func (r *LocalRepository) GetDueWebhookDeliveries(ctx context.Context, now time.Time, n int) ([]*models.WebhookDelivery, error) {
+	deliveries := make([]*models.WebhookDelivery, 0)
+	r.read(func() {
+		for _, delivery := range r.data.WebhookDeliveries {
+			if delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+				deliveries = append(deliveries, clone(delivery))
+			}
+		}
+	})
+	sort.SliceStable(deliveries, func(i, j int) bool { return deliveries[i].NextAttemptAt.Before(*deliveries[j].NextAttemptAt) })
+	return limit(deliveries, n), nil
+}
+
+func (r *LocalRepository) ClaimWebhookDelivery(ctx context.Context, id string, now, until time.Time) (*models.WebhookDelivery, error) {
+	var claimed *models.WebhookDelivery
+	err := r.change(func() error {
+		delivery, ok := r.data.WebhookDeliveries[id]
+		if !ok || delivery.Status != models.WebhookDeliveryPending || delivery.NextAttemptAt == nil || delivery.NextAttemptAt.After(now) {
+			return ErrNotFound
+		}
+		delivery.NextAttemptAt = &until
+		claimed = clone(delivery)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func (r *LocalRepository) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.change(func() error {
+		r.data.WebhookDeliveries[delivery.ID] = clone(delivery)
+		return nil
+	})
+}
+
+// Job operations
+func (r *LocalRepository) CreateJob(ctx context.Context, job *models.Job) error {
+	id, err := ids.New()
+	if err != nil {
+		return err
+	}
+	err = r.change(func() error {
+		stored := clone(job)
+		stored.ID = id
+		r.data.Jobs[id] = stored
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	job.ID = id
+	return nil
+}
+
+func (r *LocalRepository) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	var job *models.Job
+	r.read(func() {
+		if stored, ok := r.data.Jobs[id]; ok {
+			job = clone(stored)
+		}
+	})
+	if job == nil {
+		return nil, ErrNotFound
+	}
+	return job, nil
+}
+
+func (r *LocalRepository) GetJobs(ctx context.Context, query models.JobQuery) ([]*models.Job, error) {
+	jobs := make([]*models.Job, 0)
+	r.read(func() {
+		for _, job := range r.data.Jobs {
+			switch {
+			case query.Type != "" && job.Type != query.Type,
+				query.Status != "" && job.Status != query.Status:
+				continue
+			}
+			jobs = append(jobs, clone(job))
+		}
+	})
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	return limit(jobs, query.Limit), nil
+}
+
+func (r *LocalRepository) GetDueJobs(ctx context.Context, now time.Time, n int) ([]*models.Job, error) {
+	jobs := make([]*models.Job, 0)
+	r.read(func() {
+		for _, job := range r.data.Jobs {
+			if job.RunAt != nil && !job.RunAt.After(now) {
+				jobs = append(jobs, clone(job))
+			}
+		}
+	})
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].RunAt.Before(*jobs[j].RunAt) })
+	return limit(jobs, n), nil
+}
+
+func (r *LocalRepository) ClaimJob(ctx context.Context, id, leaseID string, now, until time.Time) (*models.Job, error) {
+	var claimed *models.Job
+	err := r.change(func() error {
+		job, ok := r.data.Jobs[id]
+		if !ok || job.Status != models.JobPending || job.RunAt == nil || job.RunAt.After(now) {
+			return ErrNotFound
+		}
+		job.RunAt = &until
+		job.LeaseID = leaseID
+		claimed = clone(job)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func (r *LocalRepository) UpdateJob(ctx context.Context, job *models.Job, leaseID string) error {
+	return r.change(func() error {
+		current, ok := r.data.Jobs[job.ID]
+		if !ok {
+			return ErrNotFound
+		}
+		if current.LeaseID != leaseID {
+			return ErrLeaseLost
+		}
+		r.data.Jobs[job.ID] = clone(job)
+		return nil
+	})
+}
+
+func (r *LocalRepository) RetryJob(ctx context.Context, id string, now time.Time) (*models.Job, error) {
+	var retried *models.Job
+	err := r.change(func() error {
+		job, ok := r.data.Jobs[id]
+		if !ok {
+			return ErrNotFound
+		}
+		if job.Status != models.JobFailed {
+			return ErrJobNotFailed
+		}
+		job.Status = models.JobPending
+		job.Attempts = 0
+		job.RunAt = &now
+		job.LeaseID = ""
+		job.CompletedAt = nil
+		job.UpdatedAt = now
+		retried = clone(job)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return retried, nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLocalRepositoryInterfaceCompliance verifies that LocalRepository implements RepositoryInterface
+func TestLocalRepositoryInterfaceCompliance(t *testing.T) {
+	var _ RepositoryInterface = (*LocalRepository)(nil)
+}
+
+func TestNewLocalRepository_RejectsBadWorkshopIDs(t *testing.T) {
+	_, err := NewLocalRepository(t.TempDir(), "")
+	assert.Error(t, err)
+	_, err = NewLocalRepository(t.TempDir(), "../other")
+	assert.Error(t, err)
+}
+
+func TestLocalRepository_PersistsAcrossOpens(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	repo, err := NewLocalRepository(dir, "workshop-1")
+	require.NoError(t, err)
+	registered := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	attendee := &models.Attendee{
+		Name:      "Asha Rao",
+		Email:     "asha@example.com",
+		Answers:   map[string]interface{}{"track": "ml", "topics": []interface{}{"llms", "vision"}},
+		CreatedAt: registered,
+	}
+	require.NoError(t, repo.CreateAttendee(ctx, attendee))
+	require.NotEmpty(t, attendee.ID)
+	user := &models.AdminUser{Email: "owner@example.com", Role: "owner", PasswordHash: "hash", TOTPSecret: "secret"}
+	require.NoError(t, repo.CreateAdminUser(ctx, user))
+
+	reopened, err := NewLocalRepository(dir, "workshop-1")
+	require.NoError(t, err)
+	got, err := reopened.GetAttendee(ctx, attendee.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Asha Rao", got.Name)
+	assert.True(t, registered.Equal(got.CreatedAt))
+	assert.Equal(t, []interface{}{"llms", "vision"}, got.Answers["topics"])
+
+	// Secrets left out of the JSON are kept
+	stored, err := reopened.GetAdminUserByEmail(ctx, "owner@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "hash", stored.PasswordHash)
+	assert.Equal(t, "secret", stored.TOTPSecret)
+
+	// Workshops are kept apart
+	other, err := NewLocalRepository(dir, "workshop-2")
+	require.NoError(t, err)
+	count, err := other.GetAttendeeCount(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestLocalRepository_TrashAndOutbox(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewLocalRepository(t.TempDir(), "workshop-1")
+	require.NoError(t, err)
+
+	attendee := &models.Attendee{Name: "Asha Rao", CreatedAt: time.Now()}
+	require.NoError(t, repo.CreateAttendee(ctx, attendee))
+	require.NoError(t, repo.DeleteAttendee(ctx, attendee.ID))
+
+	_, err = repo.GetAttendee(ctx, attendee.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteAttendee(ctx, attendee.ID), ErrNotFound)
+	trash, err := repo.GetTrash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash.Attendees, 1)
+
+	require.NoError(t, repo.RestoreFromTrash(ctx, models.ResourceAttendees, attendee.ID))
+	_, err = repo.CheckInAttendee(ctx, attendee.ID)
+	require.NoError(t, err)
+	_, err = repo.CheckInAttendee(ctx, attendee.ID)
+	assert.ErrorIs(t, err, ErrAlreadyCheckedIn)
+
+	// Every change was written to the outbox
+	jobs, err := repo.GetDueJobs(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	var types []string
+	for _, job := range jobs {
+		var event models.Event
+		require.NoError(t, json.Unmarshal([]byte(job.Payload), &event))
+		types = append(types, event.Type)
+	}
+	assert.ElementsMatch(t, []string{
+		models.WebhookAttendeeRegistered,
+		models.WebhookAttendeeCancelled,
+		models.WebhookAttendeeRegistered,
+		models.WebhookAttendeeCheckedIn,
+	}, types)
+
+	// Analytics are only served once rebuilt
+	_, err = repo.GetAnalyticsDays(ctx)
+	assert.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, repo.RebuildAnalytics(ctx))
+	days, err := repo.GetAnalyticsDays(ctx)
+	require.NoError(t, err)
+	require.Len(t, days, 1)
+	assert.Len(t, days[0].CheckIns, 1)
+	assert.Empty(t, days[0].Cancellations)
+
+	require.NoError(t, repo.DeleteAttendee(ctx, attendee.ID))
+	require.NoError(t, repo.PurgeFromTrash(ctx, models.ResourceAttendees, attendee.ID))
+	assert.ErrorIs(t, repo.RestoreFromTrash(ctx, models.ResourceAttendees, attendee.ID), ErrNotFound)
+}
+
+func TestLocalRepository_TrashedAgendaCannotBeUpdated(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewLocalRepository(t.TempDir(), "workshop-1")
+	require.NoError(t, err)
+
+	speaker := &models.Speaker{Name: "Asha Rao"}
+	require.NoError(t, repo.CreateSpeaker(ctx, speaker))
+	session := &models.Session{Title: "Keynote", Speakers: []string{speaker.ID}}
+	require.NoError(t, repo.CreateSession(ctx, session))
+	require.NoError(t, repo.DeleteSpeaker(ctx, speaker.ID))
+	require.NoError(t, repo.DeleteSession(ctx, session.ID))
+
+	assert.ErrorIs(t, repo.UpdateSpeaker(ctx, speaker.ID, &models.Speaker{Name: "Renamed"}), ErrNotFound)
+	assert.ErrorIs(t, repo.UpdateSession(ctx, session.ID, &models.Session{Title: "Renamed"}), ErrNotFound)
+
+	trash, err := repo.GetTrash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash.Speakers, 1)
+	assert.Equal(t, "Asha Rao", trash.Speakers[0].Name)
+	require.Len(t, trash.Sessions, 1)
+	assert.Equal(t, "Keynote", trash.Sessions[0].Title)
+
+	// Only the creations and deletions were published
+	jobs, err := repo.GetDueJobs(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 4)
+}
+
+func TestLocalRepository_SetAttendeeDesignationsSkipsPurged(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewLocalRepository(t.TempDir(), "workshop-1")
+	require.NoError(t, err)
+	attendee := &models.Attendee{Name: "Asha Rao", Email: "asha@example.com", Designation: "SDE", CreatedAt: time.Now()}
+	require.NoError(t, repo.CreateAttendee(ctx, attendee))
+
+	updated, err := repo.SetAttendeeDesignations(ctx, map[string]string{attendee.ID: "Software Engineer", "purged": "Student"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	stored, err := repo.GetAttendee(ctx, attendee.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Software Engineer", stored.Designation)
+}
+
+func TestLocalRepository_AdminEmailsAreUnique(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewLocalRepository(t.TempDir(), "workshop-1")
+	require.NoError(t, err)
+
+	require.NoError(t, repo.CreateAdminUser(ctx, &models.AdminUser{Email: "owner@example.com"}))
+	assert.ErrorIs(t, repo.CreateAdminUser(ctx, &models.AdminUser{Email: "owner@example.com"}), ErrAlreadyExists)
+}
+
+func TestLocalRepository_JobLeases(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewLocalRepository(t.TempDir(), "workshop-1")
+	require.NoError(t, err)
+
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	job, err := models.NewJob("test", struct{}{}, now)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateJob(ctx, job))
+
+	claimed, err := repo.ClaimJob(ctx, job.ID, "a/1", now, now.Add(time.Minute))
+	require.NoError(t, err)
+	_, err = repo.ClaimJob(ctx, job.ID, "b/1", now, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	claimed.Status = models.JobFailed
+	assert.ErrorIs(t, repo.UpdateJob(ctx, claimed, "b/1"), ErrLeaseLost)
+	require.NoError(t, repo.UpdateJob(ctx, claimed, "a/1"))
+
+	retried, err := repo.RetryJob(ctx, job.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobPending, retried.Status)
+	_, err = repo.RetryJob(ctx, job.ID, now)
+	assert.ErrorIs(t, err, ErrJobNotFailed)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"ai-india-workshop-backend/internal/models"
 
@@ -13,6 +14,11 @@ type MockRepository struct {
 	mock.Mock
 }
 
+func (m *MockRepository) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 func (m *MockRepository) CreateAttendee(ctx context.Context, attendee *models.Attendee) error {
 	args := m.Called(ctx, attendee)
 	return args.Error(0)
@@ -26,6 +32,14 @@ func (m *MockRepository) GetAllAttendees(ctx context.Context) ([]*models.Attende
 	return args.Get(0).([]*models.Attendee), args.Error(1)
 }
 
+func (m *MockRepository) GetAttendee(ctx context.Context, id string) (*models.Attendee, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attendee), args.Error(1)
+}
+
 func (m *MockRepository) GetAttendeeCount(ctx context.Context) (int, error) {
 	args := m.Called(ctx)
 	return args.Get(0).(int), args.Error(1)
@@ -36,6 +50,19 @@ func (m *MockRepository) DeleteAttendee(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
+func (m *MockRepository) SetAttendeeDesignations(ctx context.Context, designations map[string]string) (int, error) {
+	args := m.Called(ctx, designations)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) CheckInAttendee(ctx context.Context, id string) (*models.Attendee, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Attendee), args.Error(1)
+}
+
 func (m *MockRepository) CreateSpeaker(ctx context.Context, speaker *models.Speaker) error {
 	args := m.Called(ctx, speaker)
 	return args.Error(0)
@@ -106,4 +133,301 @@ func (m *MockRepository) GetDesignationBreakdown(ctx context.Context) ([]models.
 	return args.Get(0).([]models.DesignationCount), args.Error(1)
 }
 
+func (m *MockRepository) GetRegistrationForm(ctx context.Context) (*models.RegistrationForm, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RegistrationForm), args.Error(1)
+}
+
+func (m *MockRepository) SaveRegistrationForm(ctx context.Context, form *models.RegistrationForm) error {
+	args := m.Called(ctx, form)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetDesignationTaxonomy(ctx context.Context) (*models.DesignationTaxonomy, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DesignationTaxonomy), args.Error(1)
+}
+
+func (m *MockRepository) SaveDesignationTaxonomy(ctx context.Context, taxonomy *models.DesignationTaxonomy) error {
+	args := m.Called(ctx, taxonomy)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetAnalyticsDays(ctx context.Context) ([]*models.AnalyticsDay, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AnalyticsDay), args.Error(1)
+}
+
+func (m *MockRepository) RebuildAnalytics(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetTrash(ctx context.Context) (*models.Trash, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Trash), args.Error(1)
+}
+
+func (m *MockRepository) RestoreFromTrash(ctx context.Context, resourceType, id string) error {
+	args := m.Called(ctx, resourceType, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) PurgeFromTrash(ctx context.Context, resourceType, id string) error {
+	args := m.Called(ctx, resourceType, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int), args.Error(1)
+}
+
+func (m *MockRepository) GetWorkshopData(ctx context.Context) (*models.WorkshopData, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WorkshopData), args.Error(1)
+}
+
+func (m *MockRepository) ReplaceWorkshopData(ctx context.Context, data *models.WorkshopData) error {
+	args := m.Called(ctx, data)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ImportAttendees(ctx context.Context, attendees []*models.Attendee) error {
+	args := m.Called(ctx, attendees)
+	return args.Error(0)
+}
+
+func (m *MockRepository) PurgeAttendees(ctx context.Context, ids []string) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetAuditEntries(ctx context.Context, query models.AuditQuery) ([]*models.AuditEntry, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AuditEntry), args.Error(1)
+}
+
+func (m *MockRepository) CreateAdminUser(ctx context.Context, user *models.AdminUser) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetAllAdminUsers(ctx context.Context) ([]*models.AdminUser, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AdminUser), args.Error(1)
+}
+
+func (m *MockRepository) GetAdminUser(ctx context.Context, id string) (*models.AdminUser, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AdminUser), args.Error(1)
+}
+
+func (m *MockRepository) GetAdminUserByEmail(ctx context.Context, email string) (*models.AdminUser, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AdminUser), args.Error(1)
+}
+
+func (m *MockRepository) UpdateAdminUser(ctx context.Context, id string, user *models.AdminUser) error {
+	args := m.Called(ctx, id, user)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateLoginAttempt(ctx context.Context, attempt *models.LoginAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetLoginAttempts(ctx context.Context, query models.LoginAttemptQuery) ([]*models.LoginAttempt, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.LoginAttempt), args.Error(1)
+}
+
+func (m *MockRepository) CreateAPIToken(ctx context.Context, token *models.APIToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetAPIToken(ctx context.Context, id string) (*models.APIToken, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIToken), args.Error(1)
+}
+
+func (m *MockRepository) GetAPITokens(ctx context.Context, query models.APITokenQuery) ([]*models.APIToken, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.APIToken), args.Error(1)
+}
+
+func (m *MockRepository) RevokeAPIToken(ctx context.Context, id string, revokedAt time.Time) error {
+	args := m.Called(ctx, id, revokedAt)
+	return args.Error(0)
+}
+
+func (m *MockRepository) TouchAPIToken(ctx context.Context, id string, usedAt time.Time, ip string) error {
+	args := m.Called(ctx, id, usedAt, ip)
+	return args.Error(0)
+}
 
+func (m *MockRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetAllWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Webhook), args.Error(1)
+}
+
+func (m *MockRepository) GetWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockRepository) UpdateWebhook(ctx context.Context, id string, webhook *models.Webhook) error {
+	args := m.Called(ctx, id, webhook)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteWebhook(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateWebhookDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetWebhookDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockRepository) GetWebhookDeliveries(ctx context.Context, query models.WebhookDeliveryQuery) ([]*models.WebhookDelivery, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockRepository) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockRepository) ClaimWebhookDelivery(ctx context.Context, id string, now, until time.Time) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, id, now, until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockRepository) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateJob(ctx context.Context, job *models.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+func (m *MockRepository) GetJobs(ctx context.Context, query models.JobQuery) ([]*models.Job, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Job), args.Error(1)
+}
+
+func (m *MockRepository) GetDueJobs(ctx context.Context, now time.Time, limit int) ([]*models.Job, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Job), args.Error(1)
+}
+
+func (m *MockRepository) ClaimJob(ctx context.Context, id, leaseID string, now, until time.Time) (*models.Job, error) {
+	args := m.Called(ctx, id, leaseID, now, until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+func (m *MockRepository) UpdateJob(ctx context.Context, job *models.Job, leaseID string) error {
+	args := m.Called(ctx, job, leaseID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) RetryJob(ctx context.Context, id string, now time.Time) (*models.Job, error) {
+	args := m.Called(ctx, id, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Job), args.Error(1)
+}
@@ -2,18 +2,57 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"ai-india-workshop-backend/internal/models"
 )
 
+var (
+	// ErrNotFound is returned when a document does not exist or has been soft deleted
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned when creating a record that must be unique
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrAlreadyCheckedIn is returned when checking in an attendee twice
+	ErrAlreadyCheckedIn = errors.New("attendee already checked in")
+	// ErrUnknownResourceType is returned for trash operations on an unsupported resource type
+	ErrUnknownResourceType = errors.New("unknown resource type")
+	// ErrLeaseLost is returned when recording the outcome of a job whose
+	// lease has expired and passed to another worker
+	ErrLeaseLost = errors.New("job lease lost")
+	// ErrJobNotFailed is returned when retrying a job that has not failed
+	ErrJobNotFailed = errors.New("job has not failed")
+)
+
+// IsExpectedError reports whether err is one of the outcomes above, which
+// callers handle, rather than a failure of the store
+func IsExpectedError(err error) bool {
+	for _, expected := range []error{ErrNotFound, ErrAlreadyExists, ErrAlreadyCheckedIn, ErrUnknownResourceType, ErrLeaseLost, ErrJobNotFailed} {
+		if errors.Is(err, expected) {
+			return true
+		}
+	}
+	return false
+}
+
 // RepositoryInterface defines the interface for repository operations
-// This allows us to mock the repository in tests
+// This allows us to mock the repository in tests. Create methods fill in
+// the ID of the record they add.
 type RepositoryInterface interface {
+	// Ping checks that the store can be reached
+	Ping(ctx context.Context) error
+
 	// Attendee operations
 	CreateAttendee(ctx context.Context, attendee *models.Attendee) error
 	GetAllAttendees(ctx context.Context) ([]*models.Attendee, error)
+	GetAttendee(ctx context.Context, id string) (*models.Attendee, error)
 	GetAttendeeCount(ctx context.Context) (int, error)
 	DeleteAttendee(ctx context.Context, id string) error
+	CheckInAttendee(ctx context.Context, id string) (*models.Attendee, error)
+	// SetAttendeeDesignations changes the designation of each attendee ID in
+	// the map, returning how many were updated. Attendees that no longer
+	// exist are skipped.
+	SetAttendeeDesignations(ctx context.Context, designations map[string]string) (int, error)
 
 	// Speaker operations
 	CreateSpeaker(ctx context.Context, speaker *models.Speaker) error
@@ -31,6 +70,98 @@ type RepositoryInterface interface {
 
 	// Stats operations
 	GetDesignationBreakdown(ctx context.Context) ([]models.DesignationCount, error)
+
+	// Registration form operations. A workshop that has not set up a form
+	// gets one with no fields.
+	GetRegistrationForm(ctx context.Context) (*models.RegistrationForm, error)
+	SaveRegistrationForm(ctx context.Context, form *models.RegistrationForm) error
+
+	// Designation taxonomy operations. A workshop without one gets an empty
+	// taxonomy.
+	GetDesignationTaxonomy(ctx context.Context) (*models.DesignationTaxonomy, error)
+	SaveDesignationTaxonomy(ctx context.Context, taxonomy *models.DesignationTaxonomy) error
+
+	// Analytics operations. Attendee events are counted as they happen;
+	// GetAnalyticsDays returns ErrNotFound until RebuildAnalytics has counted
+	// the attendees that registered before.
+	GetAnalyticsDays(ctx context.Context) ([]*models.AnalyticsDay, error)
+	RebuildAnalytics(ctx context.Context) error
+
+	// Trash operations
+	GetTrash(ctx context.Context) (*models.Trash, error)
+	RestoreFromTrash(ctx context.Context, resourceType, id string) error
+	PurgeFromTrash(ctx context.Context, resourceType, id string) error
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int, error)
+
+	// Backup operations. GetWorkshopData returns every attendee, speaker and
+	// session, including those in the trash. ReplaceWorkshopData permanently
+	// removes them all and writes data in their place under the records'
+	// own IDs. It publishes no events and leaves the analytics to be rebuilt.
+	GetWorkshopData(ctx context.Context) (*models.WorkshopData, error)
+	ReplaceWorkshopData(ctx context.Context, data *models.WorkshopData) error
+
+	// Bulk attendee operations for maintenance. ImportAttendees adds the
+	// attendees under new IDs, which it sets on them. PurgeAttendees
+	// permanently removes attendees whether or not they are in the trash.
+	// Like ReplaceWorkshopData they publish no events and leave the
+	// analytics to be rebuilt.
+	ImportAttendees(ctx context.Context, attendees []*models.Attendee) error
+	PurgeAttendees(ctx context.Context, ids []string) error
+
+	// Audit log operations (append-only, entries are never updated or deleted)
+	CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	GetAuditEntries(ctx context.Context, query models.AuditQuery) ([]*models.AuditEntry, error)
+
+	// Admin user operations
+	CreateAdminUser(ctx context.Context, user *models.AdminUser) error
+	GetAllAdminUsers(ctx context.Context) ([]*models.AdminUser, error)
+	GetAdminUser(ctx context.Context, id string) (*models.AdminUser, error)
+	GetAdminUserByEmail(ctx context.Context, email string) (*models.AdminUser, error)
+	UpdateAdminUser(ctx context.Context, id string, user *models.AdminUser) error
+
+	// Login attempt operations
+	CreateLoginAttempt(ctx context.Context, attempt *models.LoginAttempt) error
+	GetLoginAttempts(ctx context.Context, query models.LoginAttemptQuery) ([]*models.LoginAttempt, error)
+
+	// API token operations. Tokens are looked up by the hash of their value.
+	CreateAPIToken(ctx context.Context, token *models.APIToken) error
+	GetAPIToken(ctx context.Context, id string) (*models.APIToken, error)
+	GetAPITokens(ctx context.Context, query models.APITokenQuery) ([]*models.APIToken, error)
+	RevokeAPIToken(ctx context.Context, id string, revokedAt time.Time) error
+	TouchAPIToken(ctx context.Context, id string, usedAt time.Time, ip string) error
+
+	// Webhook operations
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	GetAllWebhooks(ctx context.Context) ([]*models.Webhook, error)
+	GetWebhook(ctx context.Context, id string) (*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, id string, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, id string) error
+
+	// Webhook delivery operations. Deliveries are queued in one batch per
+	// event and sent by the webhook dispatcher. ClaimWebhookDelivery leases a
+	// due delivery until the given time so that only one server instance
+	// sends it; it returns ErrNotFound if the delivery is no longer due.
+	CreateWebhookDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error
+	GetWebhookDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error)
+	GetWebhookDeliveries(ctx context.Context, query models.WebhookDeliveryQuery) ([]*models.WebhookDelivery, error)
+	GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*models.WebhookDelivery, error)
+	ClaimWebhookDelivery(ctx context.Context, id string, now, until time.Time) (*models.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+
+	// Job operations. Attendee and agenda changes write their own jobs in
+	// the transaction making the change; CreateJob queues other work.
+	// ClaimJob leases a due job to leaseID until the given time so that only
+	// one worker runs it, returning ErrNotFound if it is no longer due.
+	// UpdateJob records an attempt made under leaseID and returns
+	// ErrLeaseLost if the lease has since passed to another worker.
+	// RetryJob makes a failed job due again with its attempts reset.
+	CreateJob(ctx context.Context, job *models.Job) error
+	GetJob(ctx context.Context, id string) (*models.Job, error)
+	GetJobs(ctx context.Context, query models.JobQuery) ([]*models.Job, error)
+	GetDueJobs(ctx context.Context, now time.Time, limit int) ([]*models.Job, error)
+	ClaimJob(ctx context.Context, id, leaseID string, now, until time.Time) (*models.Job, error)
+	UpdateJob(ctx context.Context, job *models.Job, leaseID string) error
+	RetryJob(ctx context.Context, id string, now time.Time) (*models.Job, error)
 }
 
 
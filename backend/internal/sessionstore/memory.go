@@ -0,0 +1,66 @@
+package sessionstore
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps sessions in process memory. Sessions are lost on
+// restart and not shared between instances, so it is only suitable for a
+// single server or for tests.
+type MemoryBackend struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{records: make(map[string]Record)}
+}
+
+func (b *MemoryBackend) Get(ctx context.Context, id string) (*Record, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	record, ok := b.records[id]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (b *MemoryBackend) Save(ctx context.Context, record *Record) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.records[record.ID] = *record
+	return nil
+}
+
+func (b *MemoryBackend) Touch(ctx context.Context, id string, lastSeenAt time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if record, ok := b.records[id]; ok {
+		record.LastSeenAt = lastSeenAt
+		b.records[id] = record
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Delete(ctx context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.records, id)
+	return nil
+}
+
+func (b *MemoryBackend) ListByUser(ctx context.Context, userID string) ([]*Record, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	records := make([]*Record, 0)
+	for _, record := range b.records {
+		if record.UserID == userID {
+			record := record
+			records = append(records, &record)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,326 @@
+// Package sessionstore keeps admin sessions on the server. The cookie only
+// carries a signed random session ID, so a session can be revoked by
+// deleting its record, and sessions expire after a period of inactivity and
+// after a fixed lifetime regardless of what the browser does.
+package sessionstore
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/securecookie"
+	gsessions "github.com/gorilla/sessions"
+)
+
+const (
+	// DefaultIdleTimeout signs a session out after this long without a request
+	DefaultIdleTimeout = 30 * time.Minute
+	// DefaultAbsoluteTimeout signs a session out this long after it was created
+	DefaultAbsoluteTimeout = 12 * time.Hour
+
+	// touchInterval limits how often LastSeenAt is written for an active session
+	touchInterval = time.Minute
+)
+
+// ErrSessionRevoked is returned by Save when the session it was loaded from
+// has since been revoked or has expired
+var ErrSessionRevoked = errors.New("session has been revoked")
+
+// Record is the server-side state of one session. ID is a hash of the
+// session ID in the cookie, so the stored records cannot be used to forge a
+// cookie and can safely be shown to the user to pick a session to revoke.
+type Record struct {
+	ID         string    `json:"id" firestore:"-"`
+	UserID     string    `json:"-" firestore:"userId"`
+	Data       []byte    `json:"-" firestore:"data"`
+	CreatedAt  time.Time `json:"createdAt" firestore:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt" firestore:"lastSeenAt"`
+	// ExpiresAt is the absolute expiry; shared backends can use it for
+	// automatic cleanup (e.g. a Firestore TTL policy)
+	ExpiresAt time.Time `json:"expiresAt" firestore:"expiresAt"`
+	IP        string    `json:"ip" firestore:"ip"`
+	UserAgent string    `json:"userAgent" firestore:"userAgent"`
+}
+
+// Backend persists session records. Get returns nil without an error when
+// the record does not exist. Touch only sets LastSeenAt, and does nothing if
+// the record no longer exists, so that a session revoked while a request was
+// in flight is not written back.
+type Backend interface {
+	Get(ctx context.Context, id string) (*Record, error)
+	Save(ctx context.Context, record *Record) error
+	Touch(ctx context.Context, id string, lastSeenAt time.Time) error
+	Delete(ctx context.Context, id string) error
+	ListByUser(ctx context.Context, userID string) ([]*Record, error)
+}
+
+// Config controls session lifetimes. UserIDKey is the session value holding
+// the signed-in user's ID, used to list and revoke a user's sessions.
+type Config struct {
+	IdleTimeout     time.Duration
+	AbsoluteTimeout time.Duration
+	UserIDKey       string
+}
+
+// Store is a gin-contrib sessions.Store that keeps session values in a Backend
+type Store struct {
+	backend Backend
+	config  Config
+	codecs  []securecookie.Codec
+	options *gsessions.Options
+	now     func() time.Time
+}
+
+// New creates a store. keyPairs sign (and optionally encrypt) the session
+// ID cookie, as for cookie.NewStore.
+func New(backend Backend, config Config, keyPairs ...[]byte) *Store {
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = DefaultIdleTimeout
+	}
+	if config.AbsoluteTimeout <= 0 {
+		config.AbsoluteTimeout = DefaultAbsoluteTimeout
+	}
+
+	codecs := securecookie.CodecsFromPairs(keyPairs...)
+	for _, codec := range codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			sc.MaxAge(int(config.AbsoluteTimeout.Seconds()))
+		}
+	}
+
+	return &Store{
+		backend: backend,
+		config:  config,
+		codecs:  codecs,
+		options: &gsessions.Options{
+			Path:     "/",
+			MaxAge:   int(config.AbsoluteTimeout.Seconds()),
+			HttpOnly: true,
+		},
+		now: time.Now,
+	}
+}
+
+// Options sets the cookie options used for new sessions
+func (s *Store) Options(options sessions.Options) {
+	s.options = options.ToGorillaOptions()
+}
+
+// Get returns the session for the request, loading it at most once per request
+func (s *Store) Get(r *http.Request, name string) (*gsessions.Session, error) {
+	return gsessions.GetRegistry(r).Get(s, name)
+}
+
+// New loads the session named by the request's cookie, or starts an empty
+// one if there is no valid, unexpired session
+func (s *Store) New(r *http.Request, name string) (*gsessions.Session, error) {
+	session := gsessions.NewSession(s, name)
+	options := *s.options
+	session.Options = &options
+	session.IsNew = true
+
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	var id string
+	if err := securecookie.DecodeMulti(name, cookie.Value, &id, s.codecs...); err != nil {
+		// A tampered or expired cookie just starts a new session
+		return session, nil
+	}
+
+	ctx := r.Context()
+	record, err := s.backend.Get(ctx, RecordID(id))
+	if err != nil || record == nil {
+		return session, err
+	}
+	if s.expired(record) {
+		if err := s.backend.Delete(ctx, record.ID); err != nil {
+			slog.ErrorContext(ctx, "Error deleting expired session", "error", err)
+		}
+		return session, nil
+	}
+
+	if err := (securecookie.GobEncoder{}).Deserialize(record.Data, &session.Values); err != nil {
+		return session, err
+	}
+	session.ID = id
+	session.IsNew = false
+
+	if now := s.now(); now.Sub(record.LastSeenAt) >= touchInterval {
+		if err := s.backend.Touch(ctx, record.ID, now); err != nil {
+			slog.ErrorContext(ctx, "Error updating session activity", "error", err)
+		}
+	}
+	return session, nil
+}
+
+// Save writes the session values to the backend and sets the ID cookie. A
+// session with no values, or with a negative MaxAge, is deleted instead, so
+// clearing the session on logout revokes it. A new record is only created
+// for a request that had no session or signs in as another user; if the
+// session the request was loaded with has gone, its cookie is cleared and
+// ErrSessionRevoked returned rather than bringing it back.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *gsessions.Session) error {
+	ctx := r.Context()
+
+	if session.Options.MaxAge < 0 || len(session.Values) == 0 {
+		if session.ID != "" {
+			if err := s.backend.Delete(ctx, RecordID(session.ID)); err != nil {
+				return err
+			}
+			session.ID = ""
+		}
+		options := *session.Options
+		options.MaxAge = -1
+		http.SetCookie(w, gsessions.NewCookie(session.Name(), "", &options))
+		return nil
+	}
+
+	now := s.now()
+	userID, _ := session.Values[s.config.UserIDKey].(string)
+
+	var record *Record
+	if session.ID != "" {
+		existing, err := s.backend.Get(ctx, RecordID(session.ID))
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			session.ID = ""
+			options := *session.Options
+			options.MaxAge = -1
+			http.SetCookie(w, gsessions.NewCookie(session.Name(), "", &options))
+			return ErrSessionRevoked
+		}
+		// Signing in gets a fresh ID, so an ID planted in the browser
+		// before login is useless afterwards
+		if existing.UserID != userID {
+			if err := s.backend.Delete(ctx, existing.ID); err != nil {
+				return err
+			}
+			existing = nil
+		}
+		record = existing
+	}
+	if record == nil {
+		id, err := newSessionID()
+		if err != nil {
+			return err
+		}
+		session.ID = id
+		record = &Record{
+			ID:        RecordID(id),
+			CreatedAt: now,
+			ExpiresAt: now.Add(s.config.AbsoluteTimeout),
+		}
+	}
+
+	data, err := securecookie.GobEncoder{}.Serialize(session.Values)
+	if err != nil {
+		return err
+	}
+	record.UserID = userID
+	record.Data = data
+	record.LastSeenAt = now
+	record.IP = clientIP(r)
+	record.UserAgent = r.UserAgent()
+	if err := s.backend.Save(ctx, record); err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, gsessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+// ListUserSessions returns the user's unexpired sessions
+func (s *Store) ListUserSessions(ctx context.Context, userID string) ([]*Record, error) {
+	records, err := s.backend.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]*Record, 0, len(records))
+	for _, record := range records {
+		if !s.expired(record) {
+			active = append(active, record)
+		}
+	}
+	return active, nil
+}
+
+// Revoke deletes a session by its record ID
+func (s *Store) Revoke(ctx context.Context, recordID string) error {
+	return s.backend.Delete(ctx, recordID)
+}
+
+// RevokeUser deletes every session belonging to the user
+func (s *Store) RevokeUser(ctx context.Context, userID string) error {
+	records, err := s.backend.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := s.backend.Delete(ctx, record.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) expired(record *Record) bool {
+	now := s.now()
+	return now.After(record.ExpiresAt) || now.Sub(record.LastSeenAt) > s.config.IdleTimeout
+}
+
+// RecordID returns the record ID for a session ID
+func RecordID(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:])
+}
+
+func newSessionID() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+type clientIPKey struct{}
+
+// RecordClientIP makes gin's resolved client IP (which honours trusted
+// proxies) available to the store. It must run before the sessions
+// middleware.
+func RecordClientIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), clientIPKey{}, c.ClientIP())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,203 @@
+package sessionstore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time { return c.now }
+
+// setupStoreTestRouter returns a router with routes to sign in as a user,
+// read the signed-in user and sign out
+func setupStoreTestRouter(t *testing.T) (*gin.Engine, *Store, *testClock) {
+	gin.SetMode(gin.TestMode)
+	clock := &testClock{now: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)}
+	store := New(NewMemoryBackend(), Config{
+		IdleTimeout:     30 * time.Minute,
+		AbsoluteTimeout: 2 * time.Hour,
+		UserIDKey:       "userID",
+	}, []byte("test-secret-key"))
+	store.now = clock.Now
+
+	r := gin.New()
+	r.Use(RecordClientIP(), sessions.Sessions("admin-session", store))
+	r.POST("/login/:user", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Clear()
+		session.Set("userID", c.Param("user"))
+		require.NoError(t, session.Save())
+		c.String(http.StatusOK, session.ID())
+	})
+	r.GET("/me", func(c *gin.Context) {
+		userID, _ := sessions.Default(c).Get("userID").(string)
+		c.String(http.StatusOK, userID)
+	})
+	r.POST("/logout", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Clear()
+		require.NoError(t, session.Save())
+	})
+	return r, store, clock
+}
+
+func do(r *gin.Engine, method, path, cookie string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("User-Agent", "store-test")
+	if cookie != "" {
+		req.Header.Set("Cookie", cookie)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestStore_SessionRoundTrip(t *testing.T) {
+	r, store, _ := setupStoreTestRouter(t)
+
+	w := do(r, "POST", "/login/user-1", "")
+	cookie := w.Header().Get("Set-Cookie")
+	require.NotEmpty(t, cookie)
+	assert.Contains(t, cookie, "HttpOnly")
+	// The cookie carries only the signed ID, not the session values
+	assert.NotContains(t, cookie, "user-1")
+
+	assert.Equal(t, "user-1", do(r, "GET", "/me", cookie).Body.String())
+
+	records, err := store.ListUserSessions(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, RecordID(w.Body.String()), records[0].ID)
+	assert.Equal(t, "store-test", records[0].UserAgent)
+	assert.Equal(t, "192.0.2.1", records[0].IP)
+}
+
+func TestStore_LogoutRevokesSession(t *testing.T) {
+	r, store, _ := setupStoreTestRouter(t)
+
+	cookie := do(r, "POST", "/login/user-1", "").Header().Get("Set-Cookie")
+	do(r, "POST", "/logout", cookie)
+
+	// Replaying the old cookie no longer works
+	assert.Empty(t, do(r, "GET", "/me", cookie).Body.String())
+	records, err := store.ListUserSessions(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestStore_Timeouts(t *testing.T) {
+	t.Run("idle", func(t *testing.T) {
+		r, _, clock := setupStoreTestRouter(t)
+		cookie := do(r, "POST", "/login/user-1", "").Header().Get("Set-Cookie")
+
+		// Activity keeps the session alive
+		for i := 0; i < 3; i++ {
+			clock.now = clock.now.Add(20 * time.Minute)
+			assert.Equal(t, "user-1", do(r, "GET", "/me", cookie).Body.String())
+		}
+
+		clock.now = clock.now.Add(31 * time.Minute)
+		assert.Empty(t, do(r, "GET", "/me", cookie).Body.String())
+	})
+
+	t.Run("absolute", func(t *testing.T) {
+		r, _, clock := setupStoreTestRouter(t)
+		cookie := do(r, "POST", "/login/user-1", "").Header().Get("Set-Cookie")
+
+		for i := 0; i < 6; i++ {
+			clock.now = clock.now.Add(20 * time.Minute)
+			assert.Equal(t, "user-1", do(r, "GET", "/me", cookie).Body.String())
+		}
+
+		clock.now = clock.now.Add(time.Minute)
+		assert.Empty(t, do(r, "GET", "/me", cookie).Body.String())
+	})
+}
+
+func TestStore_NewIDWhenUserChanges(t *testing.T) {
+	r, _, _ := setupStoreTestRouter(t)
+
+	first := do(r, "POST", "/login/user-1", "")
+	cookie := first.Header().Get("Set-Cookie")
+	second := do(r, "POST", "/login/user-2", cookie)
+
+	assert.NotEqual(t, first.Body.String(), second.Body.String())
+	assert.Empty(t, do(r, "GET", "/me", cookie).Body.String())
+	assert.Equal(t, "user-2", do(r, "GET", "/me", second.Header().Get("Set-Cookie")).Body.String())
+}
+
+func TestStore_TamperedCookie(t *testing.T) {
+	r, _, _ := setupStoreTestRouter(t)
+
+	assert.Empty(t, do(r, "GET", "/me", "admin-session=forged").Body.String())
+}
+
+func TestStore_RevokeUser(t *testing.T) {
+	r, store, _ := setupStoreTestRouter(t)
+
+	first := do(r, "POST", "/login/user-1", "").Header().Get("Set-Cookie")
+	second := do(r, "POST", "/login/user-1", "").Header().Get("Set-Cookie")
+	other := do(r, "POST", "/login/user-2", "").Header().Get("Set-Cookie")
+
+	require.NoError(t, store.RevokeUser(context.Background(), "user-1"))
+
+	assert.Empty(t, do(r, "GET", "/me", first).Body.String())
+	assert.Empty(t, do(r, "GET", "/me", second).Body.String())
+	assert.Equal(t, "user-2", do(r, "GET", "/me", other).Body.String())
+}
+
+// revokedWhileReading is a backend where each session is revoked just after
+// it has been read, as when an admin revokes it during a request
+type revokedWhileReading struct{ *MemoryBackend }
+
+func (b revokedWhileReading) Get(ctx context.Context, id string) (*Record, error) {
+	record, err := b.MemoryBackend.Get(ctx, id)
+	if record != nil {
+		b.MemoryBackend.Delete(ctx, id)
+	}
+	return record, err
+}
+
+func TestStore_ActivityDoesNotRecreateRevokedSession(t *testing.T) {
+	r, store, clock := setupStoreTestRouter(t)
+	cookie := do(r, "POST", "/login/user-1", "").Header().Get("Set-Cookie")
+
+	backend := store.backend.(*MemoryBackend)
+	store.backend = revokedWhileReading{backend}
+	clock.now = clock.now.Add(2 * touchInterval)
+	do(r, "GET", "/me", cookie)
+
+	records, err := backend.ListByUser(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestStore_SaveDoesNotRecreateRevokedSession(t *testing.T) {
+	r, store, _ := setupStoreTestRouter(t)
+	var saveErr error
+	r.POST("/refresh", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set("csrfToken", "refreshed")
+		saveErr = session.Save()
+	})
+	cookie := do(r, "POST", "/login/user-1", "").Header().Get("Set-Cookie")
+
+	backend := store.backend.(*MemoryBackend)
+	store.backend = revokedWhileReading{backend}
+	w := do(r, "POST", "/refresh", cookie)
+
+	assert.ErrorIs(t, saveErr, ErrSessionRevoked)
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "Max-Age=0")
+	records, err := backend.ListByUser(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
@@ -0,0 +1,86 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 seed from RFC 6238 Appendix B
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8 digit codes; the last 6 digits are the 6 digit codes
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{unix: 59, expected: "287082"},
+		{unix: 1111111109, expected: "081804"},
+		{unix: 1111111111, expected: "050471"},
+		{unix: 1234567890, expected: "005924"},
+		{unix: 2000000000, expected: "279037"},
+		{unix: 20000000000, expected: "353130"},
+	}
+
+	for _, tt := range tests {
+		code, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, code, "at %d", tt.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, err := Code(rfcSecret, now)
+	require.NoError(t, err)
+
+	step, ok := Validate(rfcSecret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	// Accepted one step either side for clock drift
+	_, ok = Validate(rfcSecret, code, now.Add(Period))
+	assert.True(t, ok)
+	_, ok = Validate(rfcSecret, code, now.Add(-Period))
+	assert.True(t, ok)
+
+	// Rejected further out
+	_, ok = Validate(rfcSecret, code, now.Add(3*Period))
+	assert.False(t, ok)
+
+	_, ok = Validate(rfcSecret, "000000", now)
+	assert.False(t, ok)
+	_, ok = Validate(rfcSecret, "12345", now)
+	assert.False(t, ok)
+	_, ok = Validate("not base32!", code, now)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	code, err := Code(secret, time.Now())
+	require.NoError(t, err)
+	_, ok := Validate(secret, code, time.Now())
+	assert.True(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("AI India Workshop", "admin@example.com", "JBSWY3DPEHPK3PXP")
+
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.Equal(t, "/AI India Workshop:admin@example.com", parsed.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", parsed.Query().Get("secret"))
+	assert.Equal(t, "AI India Workshop", parsed.Query().Get("issuer"))
+	assert.Equal(t, "6", parsed.Query().Get("digits"))
+}
@@ -0,0 +1,484 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedRepository starts a span for every repository method. Spans for the
+// Firestore calls a method makes are started by the Firestore client as its
+// children.
+type tracedRepository struct {
+	next   repository.RepositoryInterface
+	tracer trace.Tracer
+}
+
+// InstrumentRepository wraps repo so each of its operations is traced with
+// spans from provider
+func InstrumentRepository(repo repository.RepositoryInterface, provider trace.TracerProvider) repository.RepositoryInterface {
+	return &tracedRepository{next: repo, tracer: provider.Tracer(instrumentationName)}
+}
+
+// start begins the span for method, which works on collection or on several
+// collections when it is empty
+func (r *tracedRepository) start(ctx context.Context, method, collection string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "firestore"),
+		attribute.String("db.operation.name", method),
+	}
+	if collection != "" {
+		attrs = append(attrs, attribute.String("db.collection.name", collection))
+	}
+	return r.tracer.Start(ctx, "repository."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// end finishes span, marking it failed unless err is an expected outcome
+// such as not found
+func end(span trace.Span, err error) {
+	if err != nil && !repository.IsExpectedError(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// returnedDocuments records how many documents a method returned
+func returnedDocuments(n int) attribute.KeyValue {
+	return attribute.Int("db.response.returned_rows", n)
+}
+
+func (r *tracedRepository) Ping(ctx context.Context) (err error) {
+	ctx, span := r.start(ctx, "Ping", "settings")
+	defer func() { end(span, err) }()
+	return r.next.Ping(ctx)
+}
+
+func (r *tracedRepository) CreateAttendee(ctx context.Context, attendee *models.Attendee) (err error) {
+	ctx, span := r.start(ctx, "CreateAttendee", models.ResourceAttendees)
+	defer func() { end(span, err) }()
+	return r.next.CreateAttendee(ctx, attendee)
+}
+
+func (r *tracedRepository) GetAllAttendees(ctx context.Context) (_ []*models.Attendee, err error) {
+	ctx, span := r.start(ctx, "GetAllAttendees", models.ResourceAttendees)
+	defer func() { end(span, err) }()
+	result, err := r.next.GetAllAttendees(ctx)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) GetAttendee(ctx context.Context, id string) (_ *models.Attendee, err error) {
+	ctx, span := r.start(ctx, "GetAttendee", models.ResourceAttendees)
+	defer func() { end(span, err) }()
+	return r.next.GetAttendee(ctx, id)
+}
+
+func (r *tracedRepository) GetAttendeeCount(ctx context.Context) (_ int, err error) {
+	ctx, span := r.start(ctx, "GetAttendeeCount", models.ResourceAttendees)
+	defer func() { end(span, err) }()
+	return r.next.GetAttendeeCount(ctx)
+}
+
+func (r *tracedRepository) DeleteAttendee(ctx context.Context, id string) (err error) {
+	ctx, span := r.start(ctx, "DeleteAttendee", models.ResourceAttendees)
+	defer func() { end(span, err) }()
+	return r.next.DeleteAttendee(ctx, id)
+}
+
+func (r *tracedRepository) CheckInAttendee(ctx context.Context, id string) (_ *models.Attendee, err error) {
+	ctx, span := r.start(ctx, "CheckInAttendee", models.ResourceAttendees)
+	defer func() { end(span, err) }()
+	return r.next.CheckInAttendee(ctx, id)
+}
+
+func (r *tracedRepository) SetAttendeeDesignations(ctx context.Context, designations map[string]string) (_ int, err error) {
+	ctx, span := r.start(ctx, "SetAttendeeDesignations", models.ResourceAttendees)
+	defer func() { end(span, err) }()
+	return r.next.SetAttendeeDesignations(ctx, designations)
+}
+
+func (r *tracedRepository) CreateSpeaker(ctx context.Context, speaker *models.Speaker) (err error) {
+	ctx, span := r.start(ctx, "CreateSpeaker", models.ResourceSpeakers)
+	defer func() { end(span, err) }()
+	return r.next.CreateSpeaker(ctx, speaker)
+}
+
+func (r *tracedRepository) GetAllSpeakers(ctx context.Context) (_ []*models.Speaker, err error) {
+	ctx, span := r.start(ctx, "GetAllSpeakers", models.ResourceSpeakers)
+	defer func() { end(span, err) }()
+	result, err := r.next.GetAllSpeakers(ctx)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) GetSpeaker(ctx context.Context, id string) (_ *models.Speaker, err error) {
+	ctx, span := r.start(ctx, "GetSpeaker", models.ResourceSpeakers)
+	defer func() { end(span, err) }()
+	return r.next.GetSpeaker(ctx, id)
+}
+
+func (r *tracedRepository) UpdateSpeaker(ctx context.Context, id string, speaker *models.Speaker) (err error) {
+	ctx, span := r.start(ctx, "UpdateSpeaker", models.ResourceSpeakers)
+	defer func() { end(span, err) }()
+	return r.next.UpdateSpeaker(ctx, id, speaker)
+}
+
+func (r *tracedRepository) DeleteSpeaker(ctx context.Context, id string) (err error) {
+	ctx, span := r.start(ctx, "DeleteSpeaker", models.ResourceSpeakers)
+	defer func() { end(span, err) }()
+	return r.next.DeleteSpeaker(ctx, id)
+}
+
+func (r *tracedRepository) CreateSession(ctx context.Context, session *models.Session) (err error) {
+	ctx, span := r.start(ctx, "CreateSession", models.ResourceSessions)
+	defer func() { end(span, err) }()
+	return r.next.CreateSession(ctx, session)
+}
+
+func (r *tracedRepository) GetAllSessions(ctx context.Context) (_ []*models.Session, err error) {
+	ctx, span := r.start(ctx, "GetAllSessions", models.ResourceSessions)
+	defer func() { end(span, err) }()
+	result, err := r.next.GetAllSessions(ctx)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) GetSession(ctx context.Context, id string) (_ *models.Session, err error) {
+	ctx, span := r.start(ctx, "GetSession", models.ResourceSessions)
+	defer func() { end(span, err) }()
+	return r.next.GetSession(ctx, id)
+}
+
+func (r *tracedRepository) UpdateSession(ctx context.Context, id string, session *models.Session) (err error) {
+	ctx, span := r.start(ctx, "UpdateSession", models.ResourceSessions)
+	defer func() { end(span, err) }()
+	return r.next.UpdateSession(ctx, id, session)
+}
+
+func (r *tracedRepository) DeleteSession(ctx context.Context, id string) (err error) {
+	ctx, span := r.start(ctx, "DeleteSession", models.ResourceSessions)
+	defer func() { end(span, err) }()
+	return r.next.DeleteSession(ctx, id)
+}
+
+func (r *tracedRepository) GetDesignationBreakdown(ctx context.Context) (_ []models.DesignationCount, err error) {
+	ctx, span := r.start(ctx, "GetDesignationBreakdown", models.ResourceAttendees)
+	defer func() { end(span, err) }()
+	result, err := r.next.GetDesignationBreakdown(ctx)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) GetRegistrationForm(ctx context.Context) (_ *models.RegistrationForm, err error) {
+	ctx, span := r.start(ctx, "GetRegistrationForm", "settings")
+	defer func() { end(span, err) }()
+	return r.next.GetRegistrationForm(ctx)
+}
+
+func (r *tracedRepository) SaveRegistrationForm(ctx context.Context, form *models.RegistrationForm) (err error) {
+	ctx, span := r.start(ctx, "SaveRegistrationForm", "settings")
+	defer func() { end(span, err) }()
+	return r.next.SaveRegistrationForm(ctx, form)
+}
+
+func (r *tracedRepository) GetDesignationTaxonomy(ctx context.Context) (_ *models.DesignationTaxonomy, err error) {
+	ctx, span := r.start(ctx, "GetDesignationTaxonomy", "settings")
+	defer func() { end(span, err) }()
+	return r.next.GetDesignationTaxonomy(ctx)
+}
+
+func (r *tracedRepository) SaveDesignationTaxonomy(ctx context.Context, taxonomy *models.DesignationTaxonomy) (err error) {
+	ctx, span := r.start(ctx, "SaveDesignationTaxonomy", "settings")
+	defer func() { end(span, err) }()
+	return r.next.SaveDesignationTaxonomy(ctx, taxonomy)
+}
+
+func (r *tracedRepository) GetAnalyticsDays(ctx context.Context) (_ []*models.AnalyticsDay, err error) {
+	ctx, span := r.start(ctx, "GetAnalyticsDays", "analyticsDays")
+	defer func() { end(span, err) }()
+	result, err := r.next.GetAnalyticsDays(ctx)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) RebuildAnalytics(ctx context.Context) (err error) {
+	ctx, span := r.start(ctx, "RebuildAnalytics", "analyticsDays")
+	defer func() { end(span, err) }()
+	return r.next.RebuildAnalytics(ctx)
+}
+
+func (r *tracedRepository) GetTrash(ctx context.Context) (_ *models.Trash, err error) {
+	ctx, span := r.start(ctx, "GetTrash", "")
+	defer func() { end(span, err) }()
+	trash, err := r.next.GetTrash(ctx)
+	if trash != nil {
+		span.SetAttributes(returnedDocuments(len(trash.Attendees) + len(trash.Speakers) + len(trash.Sessions)))
+	}
+	return trash, err
+}
+
+func (r *tracedRepository) RestoreFromTrash(ctx context.Context, resourceType, id string) (err error) {
+	ctx, span := r.start(ctx, "RestoreFromTrash", resourceType)
+	defer func() { end(span, err) }()
+	return r.next.RestoreFromTrash(ctx, resourceType, id)
+}
+
+func (r *tracedRepository) PurgeFromTrash(ctx context.Context, resourceType, id string) (err error) {
+	ctx, span := r.start(ctx, "PurgeFromTrash", resourceType)
+	defer func() { end(span, err) }()
+	return r.next.PurgeFromTrash(ctx, resourceType, id)
+}
+
+func (r *tracedRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (_ int, err error) {
+	ctx, span := r.start(ctx, "PurgeDeletedBefore", "")
+	defer func() { end(span, err) }()
+	return r.next.PurgeDeletedBefore(ctx, cutoff)
+}
+
+func (r *tracedRepository) GetWorkshopData(ctx context.Context) (_ *models.WorkshopData, err error) {
+	ctx, span := r.start(ctx, "GetWorkshopData", "")
+	defer func() { end(span, err) }()
+	data, err := r.next.GetWorkshopData(ctx)
+	if data != nil {
+		span.SetAttributes(returnedDocuments(len(data.Attendees) + len(data.Speakers) + len(data.Sessions)))
+	}
+	return data, err
+}
+
+func (r *tracedRepository) ReplaceWorkshopData(ctx context.Context, data *models.WorkshopData) (err error) {
+	ctx, span := r.start(ctx, "ReplaceWorkshopData", "")
+	defer func() { end(span, err) }()
+	return r.next.ReplaceWorkshopData(ctx, data)
+}
+
+func (r *tracedRepository) ImportAttendees(ctx context.Context, attendees []*models.Attendee) (err error) {
+	ctx, span := r.start(ctx, "ImportAttendees", models.ResourceAttendees)
+	defer func() { end(span, err) }()
+	return r.next.ImportAttendees(ctx, attendees)
+}
+
+func (r *tracedRepository) PurgeAttendees(ctx context.Context, ids []string) (err error) {
+	ctx, span := r.start(ctx, "PurgeAttendees", models.ResourceAttendees)
+	defer func() { end(span, err) }()
+	return r.next.PurgeAttendees(ctx, ids)
+}
+
+func (r *tracedRepository) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) (err error) {
+	ctx, span := r.start(ctx, "CreateAuditEntry", "auditLog")
+	defer func() { end(span, err) }()
+	return r.next.CreateAuditEntry(ctx, entry)
+}
+
+func (r *tracedRepository) GetAuditEntries(ctx context.Context, query models.AuditQuery) (_ []*models.AuditEntry, err error) {
+	ctx, span := r.start(ctx, "GetAuditEntries", "auditLog")
+	defer func() { end(span, err) }()
+	result, err := r.next.GetAuditEntries(ctx, query)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) CreateAdminUser(ctx context.Context, user *models.AdminUser) (err error) {
+	ctx, span := r.start(ctx, "CreateAdminUser", "adminUsers")
+	defer func() { end(span, err) }()
+	return r.next.CreateAdminUser(ctx, user)
+}
+
+func (r *tracedRepository) GetAllAdminUsers(ctx context.Context) (_ []*models.AdminUser, err error) {
+	ctx, span := r.start(ctx, "GetAllAdminUsers", "adminUsers")
+	defer func() { end(span, err) }()
+	result, err := r.next.GetAllAdminUsers(ctx)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) GetAdminUser(ctx context.Context, id string) (_ *models.AdminUser, err error) {
+	ctx, span := r.start(ctx, "GetAdminUser", "adminUsers")
+	defer func() { end(span, err) }()
+	return r.next.GetAdminUser(ctx, id)
+}
+
+func (r *tracedRepository) GetAdminUserByEmail(ctx context.Context, email string) (_ *models.AdminUser, err error) {
+	ctx, span := r.start(ctx, "GetAdminUserByEmail", "adminUsers")
+	defer func() { end(span, err) }()
+	return r.next.GetAdminUserByEmail(ctx, email)
+}
+
+func (r *tracedRepository) UpdateAdminUser(ctx context.Context, id string, user *models.AdminUser) (err error) {
+	ctx, span := r.start(ctx, "UpdateAdminUser", "adminUsers")
+	defer func() { end(span, err) }()
+	return r.next.UpdateAdminUser(ctx, id, user)
+}
+
+func (r *tracedRepository) CreateLoginAttempt(ctx context.Context, attempt *models.LoginAttempt) (err error) {
+	ctx, span := r.start(ctx, "CreateLoginAttempt", "loginAttempts")
+	defer func() { end(span, err) }()
+	return r.next.CreateLoginAttempt(ctx, attempt)
+}
+
+func (r *tracedRepository) GetLoginAttempts(ctx context.Context, query models.LoginAttemptQuery) (_ []*models.LoginAttempt, err error) {
+	ctx, span := r.start(ctx, "GetLoginAttempts", "loginAttempts")
+	defer func() { end(span, err) }()
+	result, err := r.next.GetLoginAttempts(ctx, query)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) CreateAPIToken(ctx context.Context, token *models.APIToken) (err error) {
+	ctx, span := r.start(ctx, "CreateAPIToken", "apiTokens")
+	defer func() { end(span, err) }()
+	return r.next.CreateAPIToken(ctx, token)
+}
+
+func (r *tracedRepository) GetAPIToken(ctx context.Context, id string) (_ *models.APIToken, err error) {
+	ctx, span := r.start(ctx, "GetAPIToken", "apiTokens")
+	defer func() { end(span, err) }()
+	return r.next.GetAPIToken(ctx, id)
+}
+
+func (r *tracedRepository) GetAPITokens(ctx context.Context, query models.APITokenQuery) (_ []*models.APIToken, err error) {
+	ctx, span := r.start(ctx, "GetAPITokens", "apiTokens")
+	defer func() { end(span, err) }()
+	result, err := r.next.GetAPITokens(ctx, query)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) RevokeAPIToken(ctx context.Context, id string, revokedAt time.Time) (err error) {
+	ctx, span := r.start(ctx, "RevokeAPIToken", "apiTokens")
+	defer func() { end(span, err) }()
+	return r.next.RevokeAPIToken(ctx, id, revokedAt)
+}
+
+func (r *tracedRepository) TouchAPIToken(ctx context.Context, id string, usedAt time.Time, ip string) (err error) {
+	ctx, span := r.start(ctx, "TouchAPIToken", "apiTokens")
+	defer func() { end(span, err) }()
+	return r.next.TouchAPIToken(ctx, id, usedAt, ip)
+}
+
+func (r *tracedRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) (err error) {
+	ctx, span := r.start(ctx, "CreateWebhook", "webhooks")
+	defer func() { end(span, err) }()
+	return r.next.CreateWebhook(ctx, webhook)
+}
+
+func (r *tracedRepository) GetAllWebhooks(ctx context.Context) (_ []*models.Webhook, err error) {
+	ctx, span := r.start(ctx, "GetAllWebhooks", "webhooks")
+	defer func() { end(span, err) }()
+	result, err := r.next.GetAllWebhooks(ctx)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) GetWebhook(ctx context.Context, id string) (_ *models.Webhook, err error) {
+	ctx, span := r.start(ctx, "GetWebhook", "webhooks")
+	defer func() { end(span, err) }()
+	return r.next.GetWebhook(ctx, id)
+}
+
+func (r *tracedRepository) UpdateWebhook(ctx context.Context, id string, webhook *models.Webhook) (err error) {
+	ctx, span := r.start(ctx, "UpdateWebhook", "webhooks")
+	defer func() { end(span, err) }()
+	return r.next.UpdateWebhook(ctx, id, webhook)
+}
+
+func (r *tracedRepository) DeleteWebhook(ctx context.Context, id string) (err error) {
+	ctx, span := r.start(ctx, "DeleteWebhook", "webhooks")
+	defer func() { end(span, err) }()
+	return r.next.DeleteWebhook(ctx, id)
+}
+
+func (r *tracedRepository) CreateWebhookDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) (err error) {
+	ctx, span := r.start(ctx, "CreateWebhookDeliveries", "webhookDeliveries")
+	defer func() { end(span, err) }()
+	return r.next.CreateWebhookDeliveries(ctx, deliveries)
+}
+
+func (r *tracedRepository) GetWebhookDelivery(ctx context.Context, id string) (_ *models.WebhookDelivery, err error) {
+	ctx, span := r.start(ctx, "GetWebhookDelivery", "webhookDeliveries")
+	defer func() { end(span, err) }()
+	return r.next.GetWebhookDelivery(ctx, id)
+}
+
+func (r *tracedRepository) GetWebhookDeliveries(ctx context.Context, query models.WebhookDeliveryQuery) (_ []*models.WebhookDelivery, err error) {
+	ctx, span := r.start(ctx, "GetWebhookDeliveries", "webhookDeliveries")
+	defer func() { end(span, err) }()
+	result, err := r.next.GetWebhookDeliveries(ctx, query)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) (_ []*models.WebhookDelivery, err error) {
+	ctx, span := r.start(ctx, "GetDueWebhookDeliveries", "webhookDeliveries")
+	defer func() { end(span, err) }()
+	result, err := r.next.GetDueWebhookDeliveries(ctx, now, limit)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) ClaimWebhookDelivery(ctx context.Context, id string, now, until time.Time) (_ *models.WebhookDelivery, err error) {
+	ctx, span := r.start(ctx, "ClaimWebhookDelivery", "webhookDeliveries")
+	defer func() { end(span, err) }()
+	return r.next.ClaimWebhookDelivery(ctx, id, now, until)
+}
+
+func (r *tracedRepository) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) (err error) {
+	ctx, span := r.start(ctx, "UpdateWebhookDelivery", "webhookDeliveries")
+	defer func() { end(span, err) }()
+	return r.next.UpdateWebhookDelivery(ctx, delivery)
+}
+
+func (r *tracedRepository) CreateJob(ctx context.Context, job *models.Job) (err error) {
+	ctx, span := r.start(ctx, "CreateJob", "jobs")
+	defer func() { end(span, err) }()
+	return r.next.CreateJob(ctx, job)
+}
+
+func (r *tracedRepository) GetJob(ctx context.Context, id string) (_ *models.Job, err error) {
+	ctx, span := r.start(ctx, "GetJob", "jobs")
+	defer func() { end(span, err) }()
+	return r.next.GetJob(ctx, id)
+}
+
+func (r *tracedRepository) GetJobs(ctx context.Context, query models.JobQuery) (_ []*models.Job, err error) {
+	ctx, span := r.start(ctx, "GetJobs", "jobs")
+	defer func() { end(span, err) }()
+	result, err := r.next.GetJobs(ctx, query)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) GetDueJobs(ctx context.Context, now time.Time, limit int) (_ []*models.Job, err error) {
+	ctx, span := r.start(ctx, "GetDueJobs", "jobs")
+	defer func() { end(span, err) }()
+	result, err := r.next.GetDueJobs(ctx, now, limit)
+	span.SetAttributes(returnedDocuments(len(result)))
+	return result, err
+}
+
+func (r *tracedRepository) ClaimJob(ctx context.Context, id, leaseID string, now, until time.Time) (_ *models.Job, err error) {
+	ctx, span := r.start(ctx, "ClaimJob", "jobs")
+	defer func() { end(span, err) }()
+	return r.next.ClaimJob(ctx, id, leaseID, now, until)
+}
+
+func (r *tracedRepository) UpdateJob(ctx context.Context, job *models.Job, leaseID string) (err error) {
+	ctx, span := r.start(ctx, "UpdateJob", "jobs")
+	defer func() { end(span, err) }()
+	return r.next.UpdateJob(ctx, job, leaseID)
+}
+
+func (r *tracedRepository) RetryJob(ctx context.Context, id string, now time.Time) (_ *models.Job, err error) {
+	ctx, span := r.start(ctx, "RetryJob", "jobs")
+	defer func() { end(span, err) }()
+	return r.next.RetryJob(ctx, id, now)
+}
@@ -0,0 +1,98 @@
+// Package tracing sets up OpenTelemetry tracing for HTTP routes and
+// repository operations
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Exporters accepted by Setup, named as in OTEL_TRACES_EXPORTER
+const (
+	ExporterNone    = "none"
+	ExporterConsole = "console"
+	ExporterStdout  = "stdout"
+	ExporterOTLP    = "otlp"
+)
+
+// DefaultServiceName is reported when OTEL_SERVICE_NAME is not set
+const DefaultServiceName = "ai-india-workshop-backend"
+
+// instrumentationName identifies the spans started by this package
+const instrumentationName = "ai-india-workshop-backend/internal/tracing"
+
+// Setup installs the global tracer provider and W3C trace context
+// propagation. Spans are written to stderr by the console (or stdout)
+// exporter and sent over OTLP/HTTP by the otlp exporter, which is configured
+// with the standard OTEL_EXPORTER_OTLP_* variables. With none, or an empty
+// exporter, spans are not recorded but incoming trace context is still
+// passed on. The returned function flushes spans that have not been sent yet.
+func Setup(ctx context.Context, exporter string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch strings.ToLower(exporter) {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterConsole, ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown exporter %q, must be none, console or otlp", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s exporter: %w", exporter, err)
+	}
+
+	// Attributes from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take
+	// precedence over the default service name
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", DefaultServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Middleware starts a span for each request, named after the matched route.
+// Requests to the skipped routes are not traced.
+func Middleware(skipRoutes ...string) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName(),
+		otelgin.WithGinFilter(func(c *gin.Context) bool {
+			return !slices.Contains(skipRoutes, c.FullPath())
+		}),
+	)
+}
+
+func serviceName() string {
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		return name
+	}
+	return DefaultServiceName
+}
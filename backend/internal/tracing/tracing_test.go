@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ai-india-workshop-backend/internal/handlers"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// recordSpans installs a global tracer provider that keeps finished spans in
+// the returned recorder for the rest of the test
+func recordSpans(t *testing.T) (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder, provider
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	values := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		values[kv.Key] = kv.Value
+	}
+	return values
+}
+
+func TestSessionsRoute_SpansPerRepositoryRead(t *testing.T) {
+	recorder, provider := recordSpans(t)
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetAllSessions", mock.Anything).Return([]*models.Session{
+		{ID: "ses-1", Speakers: []string{"spk-1"}},
+		{ID: "ses-2"},
+	}, nil)
+	mockRepo.On("GetAllSpeakers", mock.Anything).Return([]*models.Speaker{{ID: "spk-1"}}, nil)
+
+	r := gin.New()
+	r.Use(Middleware("/metrics"))
+	r.GET("/api/sessions", handlers.NewSessionHandler(InstrumentRepository(mockRepo, provider)).GetAll)
+	r.GET("/metrics", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/sessions", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	sessions, speakers, route := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "/api/sessions", route.Name())
+	assert.Equal(t, "repository.GetAllSessions", sessions.Name())
+	assert.Equal(t, "repository.GetAllSpeakers", speakers.Name())
+	for _, span := range []sdktrace.ReadOnlySpan{sessions, speakers} {
+		assert.Equal(t, route.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Equal(t, route.SpanContext().TraceID(), span.SpanContext().TraceID())
+	}
+
+	assert.Equal(t, "sessions", attributes(sessions)["db.collection.name"].AsString())
+	assert.Equal(t, int64(2), attributes(sessions)["db.response.returned_rows"].AsInt64())
+	assert.Equal(t, "speakers", attributes(speakers)["db.collection.name"].AsString())
+	assert.Equal(t, int64(1), attributes(speakers)["db.response.returned_rows"].AsInt64())
+}
+
+func TestInstrumentRepository_Errors(t *testing.T) {
+	recorder, provider := recordSpans(t)
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetSpeaker", mock.Anything, "missing").Return(nil, repository.ErrNotFound)
+	mockRepo.On("RestoreFromTrash", mock.Anything, models.ResourceSessions, "ses-1").Return(errors.New("unavailable"))
+	repo := InstrumentRepository(mockRepo, provider)
+
+	_, err := repo.GetSpeaker(context.Background(), "missing")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.Error(t, repo.RestoreFromTrash(context.Background(), models.ResourceSessions, "ses-1"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code, "not found is an expected outcome")
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "unavailable", spans[1].Status().Description)
+	assert.Equal(t, "sessions", attributes(spans[1])["db.collection.name"].AsString())
+}
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	for _, exporter := range []string{"", ExporterNone, ExporterConsole, ExporterStdout, "OTLP"} {
+		t.Run(exporter, func(t *testing.T) {
+			shutdown, err := Setup(context.Background(), exporter)
+			require.NoError(t, err)
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+
+	_, err := Setup(context.Background(), "zipkin")
+	assert.ErrorContains(t, err, `unknown exporter "zipkin"`)
+}
@@ -0,0 +1,251 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+)
+
+const (
+	// DefaultPollInterval is how often the queue is checked for due deliveries
+	DefaultPollInterval = 10 * time.Second
+	// DefaultTimeout is how long a receiver has to respond
+	DefaultTimeout = 10 * time.Second
+	// DefaultMaxAttempts is how many times a delivery is tried before it
+	// fails; with the backoff below the last try is about four hours after
+	// the first
+	DefaultMaxAttempts = 10
+)
+
+const (
+	// batchSize is the most deliveries sent per poll
+	batchSize = 25
+	// firstRetryDelay doubles after each failed attempt up to maxRetryDelay
+	firstRetryDelay = 30 * time.Second
+	maxRetryDelay   = 6 * time.Hour
+	// leaseMargin is added to the timeout when claiming a delivery, so the
+	// lease outlasts the request
+	leaseMargin = 30 * time.Second
+	// maxResponseExcerpt is how much of a failed response body is recorded
+	maxResponseExcerpt = 200
+)
+
+// Config tunes the Dispatcher. Zero values get the defaults above.
+type Config struct {
+	PollInterval time.Duration
+	Timeout      time.Duration
+	MaxAttempts  int
+}
+
+// Dispatcher sends queued deliveries. Every server instance can run one:
+// a delivery is claimed before it is sent, so only one instance sends it.
+type Dispatcher struct {
+	repo        repository.RepositoryInterface
+	client      *http.Client
+	interval    time.Duration
+	timeout     time.Duration
+	maxAttempts int
+	now         func() time.Time
+}
+
+func NewDispatcher(repo repository.RepositoryInterface, cfg Config) *Dispatcher {
+	d := &Dispatcher{
+		repo:        repo,
+		interval:    cfg.PollInterval,
+		timeout:     cfg.Timeout,
+		maxAttempts: cfg.MaxAttempts,
+		now:         time.Now,
+	}
+	if d.interval <= 0 {
+		d.interval = DefaultPollInterval
+	}
+	if d.timeout <= 0 {
+		d.timeout = DefaultTimeout
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = DefaultMaxAttempts
+	}
+	// Redirects are not followed: they would turn the POST into a GET
+	d.client = &http.Client{
+		Timeout: d.timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return d
+}
+
+// Run sends due deliveries straight away and then once per interval until
+// the context is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Error delivering webhooks", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue makes one attempt at each due delivery and returns how many
+// were attempted. A delivery that could not be recorded is retried once
+// its claim runs out.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	due, err := d.repo.GetDueWebhookDeliveries(ctx, d.now(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	var errs []error
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		sent, err := d.deliver(ctx, delivery.ID)
+		if sent {
+			attempted++
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("delivery %s: %w", delivery.ID, err))
+		}
+	}
+	return attempted, errors.Join(errs...)
+}
+
+// deliver claims a delivery and makes an attempt at it, reporting whether
+// the webhook was called
+func (d *Dispatcher) deliver(ctx context.Context, id string) (bool, error) {
+	now := d.now()
+	delivery, err := d.repo.ClaimWebhookDelivery(ctx, id, now, now.Add(d.timeout+leaseMargin))
+	if errors.Is(err, repository.ErrNotFound) {
+		// Another instance got there first
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	webhook, err := d.repo.GetWebhook(ctx, delivery.WebhookID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		d.finish(delivery, models.WebhookDeliveryFailed, "Webhook was deleted")
+		return false, d.repo.UpdateWebhookDelivery(ctx, delivery)
+	case err != nil:
+		return false, err
+	case !webhook.Active:
+		d.finish(delivery, models.WebhookDeliveryFailed, "Webhook is disabled")
+		return false, d.repo.UpdateWebhookDelivery(ctx, delivery)
+	}
+
+	status, err := d.send(ctx, webhook, delivery)
+	d.record(delivery, status, err)
+	if delivery.Status == models.WebhookDeliveryFailed {
+		slog.WarnContext(ctx, "Webhook delivery failed", "webhookId", webhook.ID, "deliveryId", delivery.ID,
+			"event", delivery.Event, "attempts", delivery.Attempts, "error", delivery.Error)
+	}
+	return true, d.repo.UpdateWebhookDelivery(context.WithoutCancel(ctx), delivery)
+}
+
+// send POSTs the payload and returns the response status. Anything but a
+// 2xx response is an error.
+func (d *Dispatcher) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ai-india-workshop-webhooks/1")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, d.now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseExcerpt))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := resp.Status
+		if text := strings.TrimSpace(string(excerpt)); text != "" {
+			message += ": " + text
+		}
+		return resp.StatusCode, errors.New(message)
+	}
+	return resp.StatusCode, nil
+}
+
+// record notes the outcome of an attempt and schedules the next one
+func (d *Dispatcher) record(delivery *models.WebhookDelivery, status int, err error) {
+	now := d.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = status
+
+	switch {
+	case err == nil:
+		d.finish(delivery, models.WebhookDeliverySucceeded, "")
+	case delivery.Attempts >= d.maxAttempts:
+		d.finish(delivery, models.WebhookDeliveryFailed, err.Error())
+	default:
+		next := now.Add(retryDelay(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		delivery.Error = err.Error()
+	}
+}
+
+// finish takes a delivery off the queue
+func (d *Dispatcher) finish(delivery *models.WebhookDelivery, status, message string) {
+	now := d.now()
+	delivery.Status = status
+	delivery.Error = message
+	delivery.NextAttemptAt = nil
+	delivery.CompletedAt = &now
+}
+
+// retryDelay is how long to wait after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// Replay queues a copy of a delivery to be sent again straight away. The
+// payload, including the event ID, is unchanged.
+func Replay(ctx context.Context, repo repository.RepositoryInterface, delivery *models.WebhookDelivery, now time.Time) (*models.WebhookDelivery, error) {
+	replay := &models.WebhookDelivery{
+		WebhookID:     delivery.WebhookID,
+		EventID:       delivery.EventID,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		ReplayOf:      delivery.ID,
+		CreatedAt:     now,
+	}
+	if err := repo.CreateWebhookDeliveries(ctx, []*models.WebhookDelivery{replay}); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
@@ -0,0 +1,221 @@
+package webhook_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+	"ai-india-workshop-backend/internal/webhook"
+	"ai-india-workshop-backend/internal/webhook/webhooktest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "whsec_dispatcher-test"
+
+// fakeQueue holds one delivery, as the dispatcher would find it in the
+// store, and the webhook it is for
+type fakeQueue struct {
+	*repository.MockRepository
+	webhook  *models.Webhook
+	delivery *models.WebhookDelivery
+}
+
+// queue stores a delivery; a nil webhook is one that has been deleted
+func queue(hook *models.Webhook, delivery *models.WebhookDelivery) *fakeQueue {
+	return &fakeQueue{MockRepository: new(repository.MockRepository), webhook: hook, delivery: delivery}
+}
+
+func (q *fakeQueue) GetDueWebhookDeliveries(context.Context, time.Time, int) ([]*models.WebhookDelivery, error) {
+	if q.delivery.Status != models.WebhookDeliveryPending {
+		return nil, nil
+	}
+	return []*models.WebhookDelivery{q.delivery}, nil
+}
+
+func (q *fakeQueue) ClaimWebhookDelivery(_ context.Context, _ string, _, until time.Time) (*models.WebhookDelivery, error) {
+	claimed := *q.delivery
+	claimed.NextAttemptAt = &until
+	return &claimed, nil
+}
+
+func (q *fakeQueue) GetWebhook(context.Context, string) (*models.Webhook, error) {
+	if q.webhook == nil {
+		return nil, repository.ErrNotFound
+	}
+	return q.webhook, nil
+}
+
+func (q *fakeQueue) UpdateWebhookDelivery(_ context.Context, delivery *models.WebhookDelivery) error {
+	*q.delivery = *delivery
+	return nil
+}
+
+func pendingDelivery(webhookID string) *models.WebhookDelivery {
+	now := time.Now()
+	return &models.WebhookDelivery{
+		ID:            "d1",
+		WebhookID:     webhookID,
+		EventID:       "evt_1",
+		Event:         models.WebhookAttendeeRegistered,
+		Payload:       `{"id":"evt_1","type":"attendee.registered","text":"Asha Rao registered","data":{}}`,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	receiver := webhooktest.NewReceiver(t, testSecret)
+	hook := &models.Webhook{ID: "crm", URL: receiver.URL, Secret: testSecret, Active: true}
+	delivery := pendingDelivery(hook.ID)
+
+	attempted, err := webhook.NewDispatcher(queue(hook, delivery), webhook.Config{}).DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+
+	requests := receiver.Requests()
+	require.Len(t, requests, 1)
+	assert.NoError(t, requests[0].SignatureErr)
+	assert.Equal(t, models.WebhookAttendeeRegistered, requests[0].Event)
+	assert.Equal(t, "d1", requests[0].DeliveryID)
+	assert.Equal(t, "evt_1", requests[0].Payload.ID)
+	assert.Equal(t, "Asha Rao registered", requests[0].Payload.Text)
+
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.NotNil(t, delivery.CompletedAt)
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	receiver := webhooktest.NewReceiver(t, testSecret)
+	receiver.RespondWith(http.StatusInternalServerError, http.StatusServiceUnavailable)
+	hook := &models.Webhook{ID: "crm", URL: receiver.URL, Secret: testSecret, Active: true}
+	delivery := pendingDelivery(hook.ID)
+	dispatcher := webhook.NewDispatcher(queue(hook, delivery), webhook.Config{})
+
+	_, err := dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+	assert.Equal(t, "500 Internal Server Error", delivery.Error)
+	require.NotNil(t, delivery.NextAttemptAt)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), *delivery.NextAttemptAt, 5*time.Second)
+
+	_, err = dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *delivery.NextAttemptAt, 5*time.Second)
+
+	_, err = dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Empty(t, delivery.Error)
+
+	// Every attempt carried the same event
+	requests := receiver.Requests()
+	require.Len(t, requests, 3)
+	for _, request := range requests {
+		assert.NoError(t, request.SignatureErr)
+		assert.Equal(t, "evt_1", request.Payload.ID)
+	}
+}
+
+func TestDispatcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	receiver := webhooktest.NewReceiver(t, testSecret)
+	receiver.RespondWith(http.StatusBadGateway, http.StatusBadGateway)
+	hook := &models.Webhook{ID: "crm", URL: receiver.URL, Secret: testSecret, Active: true}
+	delivery := pendingDelivery(hook.ID)
+	dispatcher := webhook.NewDispatcher(queue(hook, delivery), webhook.Config{MaxAttempts: 2})
+
+	for range 3 {
+		_, err := dispatcher.DeliverDue(context.Background())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Len(t, receiver.Requests(), 2)
+}
+
+func TestDispatcher_WrongSecretIsRejected(t *testing.T) {
+	receiver := webhooktest.NewReceiver(t, testSecret)
+	hook := &models.Webhook{ID: "crm", URL: receiver.URL, Secret: "whsec_rotated", Active: true}
+	delivery := pendingDelivery(hook.ID)
+
+	_, err := webhook.NewDispatcher(queue(hook, delivery), webhook.Config{}).DeliverDue(context.Background())
+	require.NoError(t, err)
+
+	requests := receiver.Requests()
+	require.Len(t, requests, 1)
+	assert.ErrorIs(t, requests[0].SignatureErr, webhook.ErrInvalidSignature)
+	assert.Equal(t, http.StatusUnauthorized, delivery.ResponseStatus)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+}
+
+func TestDispatcher_FailsDeliveriesOfMissingOrDisabledWebhooks(t *testing.T) {
+	receiver := webhooktest.NewReceiver(t, testSecret)
+
+	t.Run("deleted", func(t *testing.T) {
+		delivery := pendingDelivery("gone")
+		_, err := webhook.NewDispatcher(queue(nil, delivery), webhook.Config{}).DeliverDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+		assert.Equal(t, "Webhook was deleted", delivery.Error)
+		assert.Equal(t, 0, delivery.Attempts)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		hook := &models.Webhook{ID: "crm", URL: receiver.URL, Secret: testSecret, Active: false}
+		delivery := pendingDelivery(hook.ID)
+		_, err := webhook.NewDispatcher(queue(hook, delivery), webhook.Config{}).DeliverDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+		assert.Equal(t, "Webhook is disabled", delivery.Error)
+	})
+
+	assert.Empty(t, receiver.Requests())
+}
+
+func TestDispatcher_SkipsDeliveriesClaimedElsewhere(t *testing.T) {
+	delivery := pendingDelivery("crm")
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetDueWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything).Return([]*models.WebhookDelivery{delivery}, nil)
+	mockRepo.On("ClaimWebhookDelivery", mock.Anything, "d1", mock.Anything, mock.Anything).Return(nil, repository.ErrNotFound)
+
+	attempted, err := webhook.NewDispatcher(mockRepo, webhook.Config{}).DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, attempted)
+	mockRepo.AssertNotCalled(t, "GetWebhook", mock.Anything, mock.Anything)
+}
+
+func TestDispatcher_RunStopsOnCancel(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetDueWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	dispatcher := webhook.NewDispatcher(mockRepo, webhook.Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("dispatcher did not stop after context cancellation")
+	}
+}
@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ai-india-workshop-backend/internal/jobs"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+)
+
+// Publisher queues the deliveries of the events written to the outbox. It
+// is the handler of models.JobPublishEvent jobs.
+type Publisher struct {
+	repo repository.RepositoryInterface
+}
+
+func NewPublisher(repo repository.RepositoryInterface) *Publisher {
+	return &Publisher{repo: repo}
+}
+
+// Handle queues one delivery of the job's event per subscribed webhook,
+// due straight away. The event ID is derived from the job, so a job that
+// is run again after queuing its deliveries sends the same event, which
+// receivers ignore.
+func (p *Publisher) Handle(ctx context.Context, job *models.Job) error {
+	var event models.Event
+	if err := json.Unmarshal([]byte(job.Payload), &event); err != nil {
+		return jobs.Permanent(fmt.Errorf("decoding event: %w", err))
+	}
+
+	webhooks, err := p.repo.GetAllWebhooks(ctx)
+	if err != nil {
+		return fmt.Errorf("loading webhooks: %w", err)
+	}
+	var subscribed []*models.Webhook
+	for _, webhook := range webhooks {
+		if webhook.Subscribed(event.Type) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	text, data := describe(event)
+	id := "evt_" + job.ID
+	body, err := json.Marshal(Payload{ID: id, Type: event.Type, CreatedAt: event.OccurredAt, Text: text, Data: data})
+	if err != nil {
+		return jobs.Permanent(fmt.Errorf("encoding payload: %w", err))
+	}
+
+	now := time.Now()
+	deliveries := make([]*models.WebhookDelivery, len(subscribed))
+	for i, webhook := range subscribed {
+		deliveries[i] = &models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       id,
+			Event:         event.Type,
+			Payload:       string(body),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+			CreatedAt:     now,
+		}
+	}
+	return p.repo.CreateWebhookDeliveries(ctx, deliveries)
+}
+
+// describe returns an event's text and the data sent with it
+func describe(event models.Event) (string, any) {
+	switch event.Type {
+	case models.WebhookAttendeeRegistered, models.WebhookAttendeeCancelled, models.WebhookAttendeeCheckedIn:
+		attendee := event.Attendee
+		if attendee == nil {
+			attendee = &models.Attendee{ID: event.ResourceID}
+		}
+		var text string
+		switch {
+		case event.Type == models.WebhookAttendeeCancelled:
+			text = attendee.Name + " cancelled their registration"
+		case event.Type == models.WebhookAttendeeCheckedIn:
+			text = attendee.Name + " checked in"
+		case event.Action == models.ActionRestored:
+			text = attendee.Name + "'s registration was restored"
+		default:
+			text = attendee.Name + " registered"
+		}
+		return text, AttendeeData{Attendee: attendee}
+	}
+
+	change := AgendaData{
+		Action:       event.Action,
+		ResourceType: event.ResourceType,
+		ID:           event.ResourceID,
+		Speaker:      event.Speaker,
+		Session:      event.Session,
+	}
+	switch {
+	case change.Speaker != nil:
+		return fmt.Sprintf("Speaker %s was %s", change.Speaker.Name, change.Action), change
+	case change.Session != nil:
+		return fmt.Sprintf("Session %q was %s", change.Session.Title, change.Action), change
+	default:
+		return fmt.Sprintf("%s %s was %s", change.ResourceType, change.ID, change.Action), change
+	}
+}
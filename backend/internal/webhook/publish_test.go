@@ -0,0 +1,193 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/jobs"
+	"ai-india-workshop-backend/internal/models"
+	"ai-india-workshop-backend/internal/repository"
+	"ai-india-workshop-backend/internal/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testWebhooks() []*models.Webhook {
+	return []*models.Webhook{
+		{ID: "crm", Active: true, Events: []string{models.WebhookAttendeeRegistered, models.WebhookAttendeeCancelled}},
+		{ID: "slack", Active: true, Events: models.WebhookEvents},
+		{ID: "paused", Active: false, Events: models.WebhookEvents},
+	}
+}
+
+// publishJob is the outbox job of an event
+func publishJob(t *testing.T, id string, event models.Event) *models.Job {
+	job, err := models.NewJob(models.JobPublishEvent, event, event.OccurredAt)
+	require.NoError(t, err)
+	job.ID = id
+	return job
+}
+
+// queued returns the deliveries passed to CreateWebhookDeliveries
+func queued(t *testing.T, mockRepo *repository.MockRepository) []*models.WebhookDelivery {
+	for _, call := range mockRepo.Calls {
+		if call.Method == "CreateWebhookDeliveries" {
+			return call.Arguments.Get(1).([]*models.WebhookDelivery)
+		}
+	}
+	t.Fatal("no deliveries were queued")
+	return nil
+}
+
+func TestPublisher_AttendeeRegistered(t *testing.T) {
+	occurred := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetAllWebhooks", mock.Anything).Return(testWebhooks(), nil)
+	mockRepo.On("CreateWebhookDeliveries", mock.Anything, mock.Anything).Return(nil)
+
+	job := publishJob(t, "j1", models.Event{
+		Type:         models.WebhookAttendeeRegistered,
+		Action:       models.ActionCreated,
+		ResourceType: models.ResourceAttendees,
+		ResourceID:   "a1",
+		Attendee:     &models.Attendee{ID: "a1", Name: "Asha Rao", Email: "asha@example.com"},
+		OccurredAt:   occurred,
+	})
+	require.NoError(t, webhook.NewPublisher(mockRepo).Handle(context.Background(), job))
+
+	deliveries := queued(t, mockRepo)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, "crm", deliveries[0].WebhookID)
+	assert.Equal(t, "slack", deliveries[1].WebhookID)
+	assert.Equal(t, "evt_j1", deliveries[0].EventID)
+	assert.Equal(t, "evt_j1", deliveries[1].EventID)
+	assert.Equal(t, models.WebhookDeliveryPending, deliveries[0].Status)
+	assert.NotNil(t, deliveries[0].NextAttemptAt)
+
+	var payload struct {
+		webhook.Payload
+		Data webhook.AttendeeData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+	assert.Equal(t, "evt_j1", payload.ID)
+	assert.Equal(t, models.WebhookAttendeeRegistered, payload.Type)
+	assert.True(t, occurred.Equal(payload.CreatedAt))
+	assert.Equal(t, "Asha Rao registered", payload.Text)
+	assert.Equal(t, "a1", payload.Data.Attendee.ID)
+	assert.Equal(t, "asha@example.com", payload.Data.Attendee.Email)
+}
+
+func TestPublisher_Texts(t *testing.T) {
+	asha := &models.Attendee{ID: "a1", Name: "Asha Rao"}
+	tests := []struct {
+		name  string
+		event models.Event
+		text  string
+	}{
+		{
+			name:  "cancelled",
+			event: models.Event{Type: models.WebhookAttendeeCancelled, Action: models.ActionDeleted, Attendee: asha},
+			text:  "Asha Rao cancelled their registration",
+		},
+		{
+			name:  "checked in",
+			event: models.Event{Type: models.WebhookAttendeeCheckedIn, Action: models.ActionUpdated, Attendee: asha},
+			text:  "Asha Rao checked in",
+		},
+		{
+			name:  "restored",
+			event: models.Event{Type: models.WebhookAttendeeRegistered, Action: models.ActionRestored, Attendee: asha},
+			text:  "Asha Rao's registration was restored",
+		},
+		{
+			name: "speaker",
+			event: models.Event{Type: models.WebhookAgendaChanged, Action: models.ActionDeleted, ResourceType: models.ResourceSpeakers,
+				ResourceID: "sp1", Speaker: &models.Speaker{ID: "sp1", Name: "Ravi"}},
+			text: "Speaker Ravi was deleted",
+		},
+		{
+			name: "session",
+			event: models.Event{Type: models.WebhookAgendaChanged, Action: models.ActionUpdated, ResourceType: models.ResourceSessions,
+				ResourceID: "s1", Session: &models.Session{ID: "s1", Title: "Keynote"}},
+			text: `Session "Keynote" was updated`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(repository.MockRepository)
+			mockRepo.On("GetAllWebhooks", mock.Anything).Return(testWebhooks(), nil)
+			mockRepo.On("CreateWebhookDeliveries", mock.Anything, mock.Anything).Return(nil)
+
+			require.NoError(t, webhook.NewPublisher(mockRepo).Handle(context.Background(), publishJob(t, "j1", tt.event)))
+
+			var payload webhook.Payload
+			require.NoError(t, json.Unmarshal([]byte(queued(t, mockRepo)[0].Payload), &payload))
+			assert.Equal(t, tt.text, payload.Text)
+		})
+	}
+}
+
+func TestPublisher_AgendaChangeOnlyGoesToSubscribers(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetAllWebhooks", mock.Anything).Return(testWebhooks(), nil)
+	mockRepo.On("CreateWebhookDeliveries", mock.Anything, mock.Anything).Return(nil)
+
+	job := publishJob(t, "j1", models.Event{
+		Type:         models.WebhookAgendaChanged,
+		Action:       models.ActionUpdated,
+		ResourceType: models.ResourceSessions,
+		ResourceID:   "s1",
+		Session:      &models.Session{ID: "s1", Title: "Keynote"},
+	})
+	require.NoError(t, webhook.NewPublisher(mockRepo).Handle(context.Background(), job))
+
+	// Only the webhook subscribed to every event wants agenda changes
+	deliveries := queued(t, mockRepo)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "slack", deliveries[0].WebhookID)
+
+	var payload struct {
+		webhook.Payload
+		Data webhook.AgendaData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+	assert.Equal(t, models.ActionUpdated, payload.Data.Action)
+	assert.Equal(t, models.ResourceSessions, payload.Data.ResourceType)
+	assert.Equal(t, "s1", payload.Data.ID)
+}
+
+func TestPublisher_NoSubscribers(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("GetAllWebhooks", mock.Anything).Return([]*models.Webhook{}, nil)
+
+	job := publishJob(t, "j1", models.Event{Type: models.WebhookAgendaChanged, Action: models.ActionDeleted})
+	require.NoError(t, webhook.NewPublisher(mockRepo).Handle(context.Background(), job))
+	mockRepo.AssertNotCalled(t, "CreateWebhookDeliveries", mock.Anything, mock.Anything)
+}
+
+func TestPublisher_Errors(t *testing.T) {
+	t.Run("queue failure is retried", func(t *testing.T) {
+		mockRepo := new(repository.MockRepository)
+		mockRepo.On("GetAllWebhooks", mock.Anything).Return(testWebhooks(), nil)
+		mockRepo.On("CreateWebhookDeliveries", mock.Anything, mock.Anything).Return(assert.AnError)
+
+		job := publishJob(t, "j1", models.Event{Type: models.WebhookAttendeeRegistered, Attendee: &models.Attendee{Name: "Asha Rao"}})
+		err := webhook.NewPublisher(mockRepo).Handle(context.Background(), job)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.False(t, jobs.IsPermanent(err))
+	})
+
+	t.Run("bad payload is permanent", func(t *testing.T) {
+		mockRepo := new(repository.MockRepository)
+		job := &models.Job{ID: "j1", Type: models.JobPublishEvent, Payload: "not json"}
+
+		err := webhook.NewPublisher(mockRepo).Handle(context.Background(), job)
+		assert.True(t, jobs.IsPermanent(err))
+		mockRepo.AssertNotCalled(t, "GetAllWebhooks", mock.Anything)
+	})
+}
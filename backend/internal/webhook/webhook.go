@@ -0,0 +1,165 @@
+// Package webhook tells admin-configured URLs about attendee and agenda
+// changes. The Publisher turns each event taken from the outbox into a
+// signed delivery per subscribed webhook, which the Dispatcher sends,
+// retrying failures with exponential backoff.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ai-india-workshop-backend/internal/models"
+)
+
+// Request headers sent with every delivery
+const (
+	// SignatureHeader holds t=<unix seconds>,v1=<hex HMAC-SHA256 of
+	// "<t>.<body>" keyed with the webhook's secret>
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// MinSecretLength is the shortest secret an admin can choose
+const MinSecretLength = 16
+
+var (
+	// ErrInvalidSignature is returned by Verify when the signature header is
+	// malformed or does not match the body
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrSignatureExpired is returned by Verify for a correctly signed
+	// request that is too old, e.g. one being replayed by an attacker
+	ErrSignatureExpired = errors.New("webhook signature has expired")
+)
+
+// Payload is the JSON body of every delivery. ID identifies the event and
+// is the same for every webhook and every attempt, so receivers can ignore
+// repeats. Text summarises the event for chat tools; Slack incoming
+// webhooks post it as the message.
+type Payload struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+	Text      string    `json:"text"`
+	Data      any       `json:"data"`
+}
+
+// AttendeeData is the data of the attendee events
+type AttendeeData struct {
+	Attendee *models.Attendee `json:"attendee"`
+}
+
+// AgendaData is the data of agenda.changed events. Action is one of the
+// models.Action constants. Deleted speakers and sessions are included as
+// they were before deletion.
+type AgendaData struct {
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resourceType"`
+	ID           string          `json:"id"`
+	Speaker      *models.Speaker `json:"speaker,omitempty"`
+	Session      *models.Session `json:"session,omitempty"`
+}
+
+// ValidEvent reports whether event is one of models.WebhookEvents
+func ValidEvent(event string) bool {
+	for _, known := range models.WebhookEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidURL reports whether deliveries may be sent to rawURL: it must use
+// HTTPS, except that plain HTTP is allowed to a loopback address for
+// testing a receiver locally
+func ValidURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := u.Hostname()
+		if host == "localhost" {
+			return true
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	}
+	return false
+}
+
+// NewSecret generates a random signing secret
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value for a body sent at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+func mac(secret, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// Verify checks a signature header against the body, as a receiver would.
+// The signature must be no more than tolerance away from now; a tolerance
+// of zero skips that check.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if sig, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, sig)
+			}
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	expected := mac(secret, timestamp, body)
+	valid := false
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			valid = true
+		}
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	if age := now.Sub(time.Unix(unix, 0)).Abs(); tolerance > 0 && age > tolerance {
+		return fmt.Errorf("%w: signed %s ago", ErrSignatureExpired, age.Round(time.Second))
+	}
+	return nil
+}
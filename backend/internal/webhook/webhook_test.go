@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := "whsec_test-secret"
+	body := []byte(`{"id":"evt_1","type":"attendee.registered"}`)
+	signedAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	header := Sign(secret, signedAt, body)
+	assert.True(t, strings.HasPrefix(header, "t=1792314000,v1="), header)
+
+	tests := []struct {
+		name     string
+		secret   string
+		header   string
+		body     []byte
+		now      time.Time
+		expected error
+	}{
+		{name: "valid", secret: secret, header: header, body: body, now: signedAt.Add(time.Minute)},
+		{name: "wrong secret", secret: "whsec_other", header: header, body: body, now: signedAt, expected: ErrInvalidSignature},
+		{name: "tampered body", secret: secret, header: header, body: []byte(`{"id":"evt_2"}`), now: signedAt, expected: ErrInvalidSignature},
+		{name: "too old", secret: secret, header: header, body: body, now: signedAt.Add(10 * time.Minute), expected: ErrSignatureExpired},
+		{name: "no timestamp", secret: secret, header: header[strings.Index(header, ",")+1:], body: body, now: signedAt, expected: ErrInvalidSignature},
+		{name: "empty", secret: secret, header: "", body: body, now: signedAt, expected: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, tt.now, 5*time.Minute)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVerify_ZeroToleranceSkipsAgeCheck(t *testing.T) {
+	body := []byte(`{}`)
+	header := Sign("secret", time.Unix(0, 0), body)
+	assert.NoError(t, Verify("secret", header, body, time.Now(), 0))
+}
+
+func TestValidURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{url: "https://hooks.slack.com/services/T000/B000/XXXX", expected: true},
+		{url: "https://crm.example.com:8443/webhooks?source=workshop", expected: true},
+		{url: "http://localhost:9000/hook", expected: true},
+		{url: "http://127.0.0.1:9000/hook", expected: true},
+		{url: "http://[::1]/hook", expected: true},
+		{url: "http://crm.example.com/hook", expected: false},
+		{url: "ftp://crm.example.com/hook", expected: false},
+		{url: "https:///hook", expected: false},
+		{url: "crm.example.com/hook", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ValidURL(tt.url))
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	first, err := NewSecret()
+	require.NoError(t, err)
+	second, err := NewSecret()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, "whsec_"))
+	assert.GreaterOrEqual(t, len(first), MinSecretLength)
+	assert.NotEqual(t, first, second)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, retryDelay(1))
+	assert.Equal(t, time.Minute, retryDelay(2))
+	assert.Equal(t, 128*time.Minute, retryDelay(9))
+	assert.Equal(t, 6*time.Hour, retryDelay(20))
+}
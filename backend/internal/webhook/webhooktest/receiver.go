@@ -0,0 +1,86 @@
+// Package webhooktest runs a local webhook receiver for tests. It checks the
+// signature of every request it gets and records them.
+package webhooktest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/webhook"
+)
+
+// Request is one delivery the receiver got. SignatureErr is the result of
+// checking the signature; a request with a bad signature is answered 401.
+type Request struct {
+	Event        string
+	DeliveryID   string
+	Body         []byte
+	Payload      webhook.Payload
+	SignatureErr error
+}
+
+// Receiver is an HTTP server that accepts webhook deliveries signed with
+// its secret
+type Receiver struct {
+	URL string
+
+	secret   string
+	mu       sync.Mutex
+	statuses []int
+	requests []Request
+}
+
+// NewReceiver starts a receiver that is closed when the test ends
+func NewReceiver(t testing.TB, secret string) *Receiver {
+	r := &Receiver{secret: secret}
+	server := httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(server.Close)
+	r.URL = server.URL
+	return r
+}
+
+// RespondWith answers the next requests with the given statuses, in order,
+// before going back to 204 No Content
+func (r *Receiver) RespondWith(statuses ...int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, statuses...)
+}
+
+// Requests returns every request received so far
+func (r *Receiver) Requests() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Request(nil), r.requests...)
+}
+
+func (r *Receiver) serve(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	received := Request{
+		Event:      req.Header.Get(webhook.EventHeader),
+		DeliveryID: req.Header.Get(webhook.DeliveryHeader),
+		Body:       body,
+		// Tests move the dispatcher's clock, so the signature's age is not
+		// checked
+		SignatureErr: webhook.Verify(r.secret, req.Header.Get(webhook.SignatureHeader), body, time.Now(), 0),
+	}
+	_ = json.Unmarshal(body, &received.Payload)
+
+	r.mu.Lock()
+	r.requests = append(r.requests, received)
+	status := http.StatusNoContent
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	r.mu.Unlock()
+
+	if received.SignatureErr != nil {
+		status = http.StatusUnauthorized
+	}
+	w.WriteHeader(status)
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"ai-india-workshop-backend/internal/repository"
+)
+
+// DefaultPurgeInterval is how often the trash is checked for expired items
+const DefaultPurgeInterval = time.Hour
+
+// TrashPurger permanently removes soft-deleted records once they have been
+// in the trash for longer than the retention period
+type TrashPurger struct {
+	repo      repository.RepositoryInterface
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+func NewTrashPurger(repo repository.RepositoryInterface, retention time.Duration) *TrashPurger {
+	return &TrashPurger{
+		repo:      repo,
+		retention: retention,
+		interval:  DefaultPurgeInterval,
+		now:       time.Now,
+	}
+}
+
+// Run purges expired items straight away and then once per interval until
+// the context is cancelled. A purge interrupted by the cancellation is not
+// reported as an error.
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.PurgeExpired(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Error purging trash", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired removes every item deleted before the retention cutoff
+func (p *TrashPurger) PurgeExpired(ctx context.Context) (int, error) {
+	cutoff := p.now().Add(-p.retention)
+	purged, err := p.repo.PurgeDeletedBefore(ctx, cutoff)
+	if purged > 0 {
+		slog.InfoContext(ctx, "Purged trash", "purged", purged, "deletedBefore", cutoff.Format(time.RFC3339))
+	}
+	return purged, err
+}
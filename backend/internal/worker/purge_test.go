@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ai-india-workshop-backend/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTrashPurger_PurgeExpired(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("PurgeDeletedBefore", mock.Anything, now.Add(-retention)).Return(3, nil)
+
+	purger := NewTrashPurger(mockRepo, retention)
+	purger.now = func() time.Time { return now }
+
+	purged, err := purger.PurgeExpired(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, purged)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTrashPurger_RunStopsOnCancel(t *testing.T) {
+	mockRepo := new(repository.MockRepository)
+	mockRepo.On("PurgeDeletedBefore", mock.Anything, mock.Anything).Return(0, assert.AnError)
+
+	purger := NewTrashPurger(mockRepo, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		purger.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("purger did not stop after context cancellation")
+	}
+	mockRepo.AssertCalled(t, "PurgeDeletedBefore", mock.Anything, mock.Anything)
+}
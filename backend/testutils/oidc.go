@@ -0,0 +1,191 @@
+package testutils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// MockOIDCProvider is a minimal OpenID Connect provider for tests. Its
+// /authorize endpoint approves every login straight away as the user
+// described by the exported fields, so a test can follow the redirect chain
+// without a browser.
+type MockOIDCProvider struct {
+	Server   *httptest.Server
+	ClientID string
+
+	// The user signing in
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]mockAuthRequest
+}
+
+type mockAuthRequest struct {
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+}
+
+const mockOIDCKeyID = "mock-key"
+
+// NewMockOIDCProvider starts a provider that is shut down when the test ends
+func NewMockOIDCProvider(t testing.TB, clientID string) *MockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating mock OIDC key: %v", err)
+	}
+
+	m := &MockOIDCProvider{
+		ClientID:      clientID,
+		Subject:       "mock-subject",
+		Email:         "admin@example.com",
+		EmailVerified: true,
+		Name:          "Mock Admin",
+		key:           key,
+		codes:         map[string]mockAuthRequest{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.handleDiscovery)
+	mux.HandleFunc("/jwks", m.handleJWKS)
+	mux.HandleFunc("/authorize", m.handleAuthorize)
+	mux.HandleFunc("/token", m.handleToken)
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// Issuer is the provider's issuer URL
+func (m *MockOIDCProvider) Issuer() string {
+	return m.Server.URL
+}
+
+func (m *MockOIDCProvider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 m.Issuer(),
+		"authorization_endpoint": m.Issuer() + "/authorize",
+		"token_endpoint":         m.Issuer() + "/token",
+		"jwks_uri":               m.Issuer() + "/jwks",
+	})
+}
+
+func (m *MockOIDCProvider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": mockOIDCKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}},
+	})
+}
+
+// handleAuthorize issues a code and redirects back to the client
+func (m *MockOIDCProvider) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != m.ClientID || query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	m.mu.Lock()
+	m.codes[code] = mockAuthRequest{
+		redirectURI:   query.Get("redirect_uri"),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+	}
+	m.mu.Unlock()
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirect.RawQuery = values.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// handleToken redeems a code once, checking the PKCE verifier
+func (m *MockOIDCProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	m.mu.Lock()
+	request, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || r.PostForm.Get("client_id") != m.ClientID || r.PostForm.Get("redirect_uri") != request.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != request.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            m.Issuer(),
+		"aud":            m.ClientID,
+		"sub":            m.Subject,
+		"email":          m.Email,
+		"email_verified": m.EmailVerified,
+		"name":           m.Name,
+		"nonce":          request.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+	if m.Groups != nil {
+		claims["groups"] = m.Groups
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = mockOIDCKeyID
+	idToken, err := token.SignedString(m.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
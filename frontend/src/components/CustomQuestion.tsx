@@ -0,0 +1,131 @@
+import type { Answer, FormField } from '../services/attendeeService';
+
+const inputClassName =
+  'w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500 focus:border-transparent transition-all';
+
+interface CustomQuestionProps {
+  field: FormField;
+  value: Answer | undefined;
+  onChange: (value: Answer | undefined) => void;
+}
+
+// Renders one of the workshop's custom registration questions
+const CustomQuestion = ({ field, value, onChange }: CustomQuestionProps) => {
+  const id = `answer-${field.id}`;
+  const label = `${field.label}${field.required ? ' *' : ''}`;
+  const help = field.helpText && <p className="text-sm text-gray-500 mt-1">{field.helpText}</p>;
+
+  if (field.type === 'checkbox') {
+    return (
+      <div>
+        <label htmlFor={id} className="flex items-start gap-3 text-sm text-gray-700">
+          <input
+            type="checkbox"
+            id={id}
+            checked={value === true}
+            onChange={(e) => onChange(e.target.checked)}
+            required={field.required}
+            className="mt-1 h-4 w-4 text-primary-600 border-gray-300 rounded focus:ring-primary-500"
+          />
+          <span className="font-semibold">{label}</span>
+        </label>
+        {help}
+      </div>
+    );
+  }
+
+  if (field.type === 'multiselect') {
+    const selected = Array.isArray(value) ? value : [];
+    return (
+      <fieldset>
+        <legend className="block text-sm font-semibold text-gray-700 mb-2">{label}</legend>
+        <div className="flex flex-wrap gap-4">
+          {(field.options ?? []).map((option) => (
+            <label key={option} className="flex items-center gap-2 text-sm text-gray-700">
+              <input
+                type="checkbox"
+                checked={selected.includes(option)}
+                onChange={(e) =>
+                  onChange(e.target.checked ? [...selected, option] : selected.filter((item) => item !== option))
+                }
+                className="h-4 w-4 text-primary-600 border-gray-300 rounded focus:ring-primary-500"
+              />
+              {option}
+            </label>
+          ))}
+        </div>
+        {help}
+      </fieldset>
+    );
+  }
+
+  let input;
+  switch (field.type) {
+    case 'textarea':
+      input = (
+        <textarea
+          id={id}
+          value={typeof value === 'string' ? value : ''}
+          onChange={(e) => onChange(e.target.value)}
+          required={field.required}
+          rows={3}
+          className={inputClassName}
+        />
+      );
+      break;
+    case 'number':
+      input = (
+        <input
+          type="number"
+          id={id}
+          value={typeof value === 'number' ? value : ''}
+          onChange={(e) => onChange(e.target.value === '' ? undefined : Number(e.target.value))}
+          required={field.required}
+          className={inputClassName}
+        />
+      );
+      break;
+    case 'select':
+      input = (
+        <select
+          id={id}
+          value={typeof value === 'string' ? value : ''}
+          onChange={(e) => onChange(e.target.value)}
+          required={field.required}
+          className={`${inputClassName} bg-white`}
+        >
+          <option value="">Select an option</option>
+          {(field.options ?? []).map((option) => (
+            <option key={option} value={option}>
+              {option}
+            </option>
+          ))}
+        </select>
+      );
+      break;
+    default:
+      input = (
+        <input
+          type="text"
+          id={id}
+          value={typeof value === 'string' ? value : ''}
+          onChange={(e) => onChange(e.target.value)}
+          required={field.required}
+          pattern={field.pattern || undefined}
+          className={inputClassName}
+        />
+      );
+  }
+
+  return (
+    <div>
+      <label htmlFor={id} className="block text-sm font-semibold text-gray-700 mb-2">
+        {label}
+      </label>
+      {input}
+      {help}
+    </div>
+  );
+};
+
+export default CustomQuestion;
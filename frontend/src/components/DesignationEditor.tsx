@@ -0,0 +1,205 @@
+import { useState, useEffect } from 'react';
+import { adminService, type Designation, type RemapResult } from '../services/adminService';
+import { errorMessage } from '../services/api';
+
+const inputClassName = 'w-full px-3 py-2 border border-gray-300 rounded-lg text-sm';
+
+interface DesignationEditorProps {
+  // Called after attendees are remapped so the stats can be refreshed
+  onRemapped: () => void;
+}
+
+// Lets organisers edit the canonical designations and their aliases, and
+// rewrite existing attendees to match them
+const DesignationEditor = ({ onRemapped }: DesignationEditorProps) => {
+  const [designations, setDesignations] = useState<Designation[]>([]);
+  // Aliases are edited as comma separated text and split when saving
+  const [aliasText, setAliasText] = useState<string[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [saving, setSaving] = useState(false);
+  const [remap, setRemap] = useState<RemapResult | null>(null);
+  const [message, setMessage] = useState<{ error: boolean; text: string } | null>(null);
+
+  const show = (list: Designation[]) => {
+    setDesignations(list);
+    setAliasText(list.map((designation) => designation.aliases.join(', ')));
+  };
+
+  useEffect(() => {
+    const load = async () => {
+      try {
+        const taxonomy = await adminService.getDesignations();
+        show(taxonomy.designations ?? []);
+      } catch (err) {
+        console.error('Error loading designations:', err);
+        setMessage({ error: true, text: 'Failed to load designations' });
+      } finally {
+        setLoading(false);
+      }
+    };
+    load();
+  }, []);
+
+  const handleSave = async () => {
+    setSaving(true);
+    setMessage(null);
+    setRemap(null);
+    try {
+      const list = designations.map((designation, index) => ({
+        name: designation.name,
+        aliases: aliasText[index]
+          .split(',')
+          .map((alias) => alias.trim())
+          .filter(Boolean),
+      }));
+      const taxonomy = await adminService.updateDesignations(list);
+      show(taxonomy.designations);
+      setMessage({ error: false, text: 'Designations saved. Preview the remap to update existing attendees.' });
+    } catch (err: any) {
+      setMessage({ error: true, text: errorMessage(err, 'Failed to save designations') });
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const handleRemap = async (dryRun: boolean) => {
+    if (!dryRun && !confirm(`Update the designation of ${remap?.updated ?? 0} attendees?`)) return;
+    setMessage(null);
+    try {
+      const result = await adminService.remapDesignations(dryRun);
+      setRemap(dryRun ? result : null);
+      if (!dryRun) {
+        setMessage({ error: false, text: `Updated ${result.updated} attendees` });
+        onRemapped();
+      }
+    } catch (err: any) {
+      setMessage({ error: true, text: errorMessage(err, 'Failed to remap designations') });
+    }
+  };
+
+  if (loading) {
+    return <p className="text-center text-gray-500 py-8">Loading...</p>;
+  }
+
+  return (
+    <div>
+      <div className="mb-4 flex justify-between items-center">
+        <h3 className="text-xl font-bold text-gray-900">Designations ({designations.length})</h3>
+        <button
+          onClick={() => {
+            setDesignations([...designations, { name: '', aliases: [] }]);
+            setAliasText([...aliasText, '']);
+          }}
+          className="px-4 py-2 bg-primary-600 text-white rounded-lg hover:bg-primary-700 transition-colors"
+        >
+          Add Designation
+        </button>
+      </div>
+      <p className="text-sm text-gray-500 mb-4">
+        Registrations matching a designation or one of its aliases, ignoring case and spacing, are stored under the
+        designation. Everything else is counted as Other in the stats.
+      </p>
+
+      <div className="space-y-3">
+        {designations.map((designation, index) => (
+          <div key={index} className="grid md:grid-cols-[1fr_2fr_auto] gap-3 items-center">
+            <input
+              type="text"
+              placeholder="Designation"
+              value={designation.name}
+              onChange={(e) =>
+                setDesignations(designations.map((d, i) => (i === index ? { ...d, name: e.target.value } : d)))
+              }
+              className={inputClassName}
+            />
+            <input
+              type="text"
+              placeholder="Aliases, comma separated"
+              value={aliasText[index]}
+              onChange={(e) => setAliasText(aliasText.map((text, i) => (i === index ? e.target.value : text)))}
+              className={inputClassName}
+            />
+            <button
+              onClick={() => {
+                setDesignations(designations.filter((_, i) => i !== index));
+                setAliasText(aliasText.filter((_, i) => i !== index));
+              }}
+              className="text-red-600 hover:text-red-800 font-semibold text-sm"
+            >
+              Remove
+            </button>
+          </div>
+        ))}
+        {designations.length === 0 && (
+          <p className="text-center text-gray-500 py-8">No designations, the registration form offers its defaults</p>
+        )}
+      </div>
+
+      {message && (
+        <div
+          className={`mt-4 px-4 py-3 rounded-lg border ${
+            message.error ? 'bg-red-50 border-red-200 text-red-700' : 'bg-green-50 border-green-200 text-green-700'
+          }`}
+        >
+          {message.text}
+        </div>
+      )}
+
+      <div className="mt-4 flex justify-end gap-3">
+        <button
+          onClick={() => handleRemap(true)}
+          className="px-6 py-2 border border-gray-300 text-gray-700 rounded-lg hover:bg-gray-50 transition-colors"
+        >
+          Preview Remap
+        </button>
+        <button
+          onClick={handleSave}
+          disabled={saving}
+          className="px-6 py-2 bg-primary-600 text-white rounded-lg hover:bg-primary-700 transition-colors disabled:opacity-50"
+        >
+          {saving ? 'Saving...' : 'Save Designations'}
+        </button>
+      </div>
+
+      {remap && (
+        <div className="mt-6 border border-gray-200 rounded-lg p-4">
+          <h4 className="font-semibold text-gray-900 mb-2">
+            {remap.updated === 0 ? 'All attendees already match the designations' : `${remap.updated} attendees would change`}
+          </h4>
+          {remap.changes.length > 0 && (
+            <>
+              <table className="w-full text-sm mb-4">
+                <thead className="bg-gray-50">
+                  <tr>
+                    <th className="px-3 py-2 text-left text-xs font-semibold text-gray-700 uppercase">From</th>
+                    <th className="px-3 py-2 text-left text-xs font-semibold text-gray-700 uppercase">To</th>
+                    <th className="px-3 py-2 text-right text-xs font-semibold text-gray-700 uppercase">Attendees</th>
+                  </tr>
+                </thead>
+                <tbody className="divide-y divide-gray-200">
+                  {remap.changes.map((change) => (
+                    <tr key={`${change.from}->${change.to}`}>
+                      <td className="px-3 py-2 text-gray-600 whitespace-pre">{change.from}</td>
+                      <td className="px-3 py-2 text-gray-900">{change.to}</td>
+                      <td className="px-3 py-2 text-right text-gray-600">{change.count}</td>
+                    </tr>
+                  ))}
+                </tbody>
+              </table>
+              <div className="flex justify-end">
+                <button
+                  onClick={() => handleRemap(false)}
+                  className="px-6 py-2 bg-primary-600 text-white rounded-lg hover:bg-primary-700 transition-colors"
+                >
+                  Apply Remap
+                </button>
+              </div>
+            </>
+          )}
+        </div>
+      )}
+    </div>
+  );
+};
+
+export default DesignationEditor;
@@ -1,36 +1,76 @@
-import { useState } from 'react';
-import { useNavigate } from 'react-router-dom';
+import { useEffect, useState } from 'react';
+import { useNavigate, useSearchParams } from 'react-router-dom';
 import { motion, AnimatePresence } from 'framer-motion';
-import { adminService } from '../services/adminService';
+import { adminService, type SSOStatus } from '../services/adminService';
+import { errorMessage } from '../services/api';
+
+const SSO_ERRORS: Record<string, string> = {
+  expired: 'Single sign-on timed out. Please try again.',
+  not_allowed: 'Your account is not allowed to access the admin panel.',
+  disabled: 'Account disabled',
+  failed: 'Single sign-on failed. Please try again.',
+};
 
 const Footer = () => {
   const [showLoginModal, setShowLoginModal] = useState(false);
+  const [email, setEmail] = useState('');
   const [password, setPassword] = useState('');
+  const [code, setCode] = useState('');
+  const [twoFactorRequired, setTwoFactorRequired] = useState(false);
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
+  const [sso, setSso] = useState<SSOStatus>({ enabled: false });
+  const [searchParams, setSearchParams] = useSearchParams();
   const navigate = useNavigate();
 
+  useEffect(() => {
+    adminService.getSSOStatus().then(setSso).catch(() => setSso({ enabled: false }));
+  }, []);
+
+  // A failed single sign-on redirects back here with an error code
+  useEffect(() => {
+    const ssoError = searchParams.get('ssoError');
+    if (ssoError) {
+      setError(SSO_ERRORS[ssoError] || SSO_ERRORS.failed);
+      setShowLoginModal(true);
+      searchParams.delete('ssoError');
+      setSearchParams(searchParams, { replace: true });
+    }
+  }, [searchParams, setSearchParams]);
+
   const handleLogin = async (e: React.FormEvent) => {
     e.preventDefault();
     setError(null);
     setLoading(true);
 
     try {
-      const result = await adminService.login(password);
-      if (result.success) {
-        setShowLoginModal(false);
+      const result = twoFactorRequired
+        ? await adminService.verifyTwoFactor(code)
+        : await adminService.login(email, password);
+      if (result.twoFactorRequired) {
+        setTwoFactorRequired(true);
         setPassword('');
+      } else if (result.user) {
+        closeLoginModal();
         navigate('/admin');
       } else {
-        setError('Invalid password');
+        setError('Invalid email or password');
       }
     } catch (err: any) {
-      setError(err.response?.data?.error || 'Login failed. Please try again.');
+      setError(errorMessage(err, 'Login failed. Please try again.'));
     } finally {
       setLoading(false);
     }
   };
 
+  const closeLoginModal = () => {
+    setShowLoginModal(false);
+    setPassword('');
+    setCode('');
+    setTwoFactorRequired(false);
+    setError(null);
+  };
+
   return (
     <>
       <footer className="bg-gray-900 text-white py-12">
@@ -61,11 +101,7 @@ const Footer = () => {
             animate={{ opacity: 1 }}
             exit={{ opacity: 0 }}
             className="fixed inset-0 bg-black bg-opacity-50 flex items-center justify-center z-50 p-4"
-            onClick={() => {
-              setShowLoginModal(false);
-              setPassword('');
-              setError(null);
-            }}
+            onClick={closeLoginModal}
           >
             <motion.div
               initial={{ scale: 0.8, opacity: 0 }}
@@ -75,24 +111,76 @@ const Footer = () => {
               className="bg-white rounded-xl shadow-2xl p-8 max-w-md w-full"
             >
               <h3 className="text-2xl font-bold text-gray-900 mb-6">Admin Login</h3>
-              <form onSubmit={handleLogin} className="space-y-4">
-                <div>
-                  <label
-                    htmlFor="password"
-                    className="block text-sm font-semibold text-gray-700 mb-2"
+              {sso.enabled && !twoFactorRequired && (
+                <div className="mb-6">
+                  <a
+                    href={adminService.ssoLoginUrl()}
+                    className="block w-full px-4 py-3 text-center border border-gray-300 rounded-lg font-semibold text-gray-700 hover:bg-gray-50 transition-colors"
                   >
-                    Password
-                  </label>
-                  <input
-                    type="password"
-                    id="password"
-                    value={password}
-                    onChange={(e) => setPassword(e.target.value)}
-                    required
-                    className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500 focus:border-transparent transition-all"
-                    placeholder="Enter admin password"
-                  />
+                    Sign in with {sso.name}
+                  </a>
+                  <div className="mt-6 text-center text-sm text-gray-500">or use your password</div>
                 </div>
+              )}
+              <form onSubmit={handleLogin} className="space-y-4">
+                {twoFactorRequired ? (
+                  <div>
+                    <label
+                      htmlFor="code"
+                      className="block text-sm font-semibold text-gray-700 mb-2"
+                    >
+                      Authentication Code
+                    </label>
+                    <input
+                      type="text"
+                      id="code"
+                      value={code}
+                      onChange={(e) => setCode(e.target.value)}
+                      required
+                      autoFocus
+                      autoComplete="one-time-code"
+                      className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500 focus:border-transparent transition-all"
+                      placeholder="6-digit code or recovery code"
+                    />
+                  </div>
+                ) : (
+                  <>
+                    <div>
+                      <label
+                        htmlFor="email"
+                        className="block text-sm font-semibold text-gray-700 mb-2"
+                      >
+                        Email
+                      </label>
+                      <input
+                        type="email"
+                        id="email"
+                        value={email}
+                        onChange={(e) => setEmail(e.target.value)}
+                        required
+                        className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500 focus:border-transparent transition-all"
+                        placeholder="Enter admin email"
+                      />
+                    </div>
+                    <div>
+                      <label
+                        htmlFor="password"
+                        className="block text-sm font-semibold text-gray-700 mb-2"
+                      >
+                        Password
+                      </label>
+                      <input
+                        type="password"
+                        id="password"
+                        value={password}
+                        onChange={(e) => setPassword(e.target.value)}
+                        required
+                        className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500 focus:border-transparent transition-all"
+                        placeholder="Enter admin password"
+                      />
+                    </div>
+                  </>
+                )}
                 {error && (
                   <div className="bg-red-50 border border-red-200 text-red-700 px-4 py-3 rounded-lg text-sm">
                     {error}
@@ -101,11 +189,7 @@ const Footer = () => {
                 <div className="flex gap-3 justify-end">
                   <button
                     type="button"
-                    onClick={() => {
-                      setShowLoginModal(false);
-                      setPassword('');
-                      setError(null);
-                    }}
+                    onClick={closeLoginModal}
                     className="px-4 py-2 text-gray-700 hover:bg-gray-100 rounded-lg transition-colors"
                   >
                     Cancel
@@ -115,7 +199,7 @@ const Footer = () => {
                     disabled={loading}
                     className="px-6 py-2 bg-primary-600 text-white rounded-lg hover:bg-primary-700 transition-colors disabled:opacity-50 disabled:cursor-not-allowed"
                   >
-                    {loading ? 'Logging in...' : 'Login'}
+                    {loading ? 'Logging in...' : twoFactorRequired ? 'Verify' : 'Login'}
                   </button>
                 </div>
               </form>
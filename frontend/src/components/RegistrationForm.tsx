@@ -1,6 +1,13 @@
 import { useState, useEffect } from 'react';
 import { motion, AnimatePresence } from 'framer-motion';
-import { attendeeService } from '../services/attendeeService';
+import {
+  attendeeService,
+  solveProofOfWork,
+  type Answer,
+  type RegistrationForm as RegistrationFormToken,
+} from '../services/attendeeService';
+import { errorMessage } from '../services/api';
+import CustomQuestion from './CustomQuestion';
 
 const DESIGNATIONS = [
   'Software Engineer',
@@ -26,6 +33,23 @@ const RegistrationForm = () => {
   const [showSuccess, setShowSuccess] = useState(false);
   const [error, setError] = useState<string | null>(null);
   const [countLoading, setCountLoading] = useState(true);
+  const [form, setForm] = useState<RegistrationFormToken>({});
+  const [website, setWebsite] = useState('');
+  const [answers, setAnswers] = useState<Record<string, Answer>>({});
+
+  // The form token records when the form was served, so fetch a new one
+  // whenever the form is shown afresh
+  const loadForm = async () => {
+    try {
+      setForm(await attendeeService.getForm());
+    } catch (err) {
+      console.error('Error loading registration form:', err);
+    }
+  };
+
+  useEffect(() => {
+    loadForm();
+  }, []);
 
   useEffect(() => {
     const fetchCount = async () => {
@@ -51,10 +75,26 @@ const RegistrationForm = () => {
     setLoading(true);
 
     try {
+      let challengeResponse: string | undefined;
+      const challenge = form.challenge;
+      if (challenge?.type === 'pow' && form.formToken) {
+        challengeResponse = await solveProofOfWork(
+          form.formToken,
+          formData.email.trim().toLowerCase(),
+          challenge.difficulty ?? 0
+        );
+      } else if (challenge?.type === 'fake') {
+        challengeResponse = challenge.response;
+      }
+
       await attendeeService.register({
         name: formData.name,
         email: formData.email,
         designation: formData.designation,
+        answers,
+        formToken: form.formToken,
+        challengeResponse,
+        website,
       });
 
       // Update count immediately
@@ -63,19 +103,34 @@ const RegistrationForm = () => {
 
       // Reset form
       setFormData({ name: '', email: '', designation: '' });
+      setAnswers({});
       setShowSuccess(true);
+      loadForm();
 
       // Hide success popup after 3 seconds
       setTimeout(() => {
         setShowSuccess(false);
       }, 3000);
     } catch (err: any) {
-      setError(err.response?.data?.error || 'Registration failed. Please try again.');
+      setError(errorMessage(err, 'Registration failed. Please try again.'));
     } finally {
       setLoading(false);
     }
   };
 
+  // The workshop's own designations replace the defaults when it has some
+  const designations = form.designations?.length ? [...form.designations, 'Other'] : DESIGNATIONS;
+
+  const handleAnswerChange = (id: string, value: Answer | undefined) => {
+    const next = { ...answers };
+    if (value === undefined) {
+      delete next[id];
+    } else {
+      next[id] = value;
+    }
+    setAnswers(next);
+  };
+
   const handleChange = (e: React.ChangeEvent<HTMLInputElement | HTMLSelectElement>) => {
     setFormData({
       ...formData,
@@ -158,6 +213,20 @@ const RegistrationForm = () => {
             >
               <div className="bg-white rounded-xl shadow-lg p-8">
                 <form onSubmit={handleSubmit} className="space-y-6">
+                  {/* Honeypot: hidden from people, filled in by bots */}
+                  <div aria-hidden="true" className="absolute -left-[9999px] w-px h-px overflow-hidden">
+                    <label htmlFor="website">Website</label>
+                    <input
+                      type="text"
+                      id="website"
+                      name="website"
+                      tabIndex={-1}
+                      autoComplete="off"
+                      value={website}
+                      onChange={(e) => setWebsite(e.target.value)}
+                    />
+                  </div>
+
                   <div>
                     <label htmlFor="name" className="block text-sm font-semibold text-gray-700 mb-2">
                       Full Name *
@@ -209,7 +278,7 @@ const RegistrationForm = () => {
                       className="w-full px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500 focus:border-transparent transition-all bg-white"
                     >
                       <option value="">Select your designation</option>
-                      {DESIGNATIONS.map((designation) => (
+                      {designations.map((designation) => (
                         <option key={designation} value={designation}>
                           {designation}
                         </option>
@@ -217,6 +286,15 @@ const RegistrationForm = () => {
                     </select>
                   </div>
 
+                  {(form.fields ?? []).map((field) => (
+                    <CustomQuestion
+                      key={field.id}
+                      field={field}
+                      value={answers[field.id]}
+                      onChange={(value) => handleAnswerChange(field.id, value)}
+                    />
+                  ))}
+
                   {error && (
                     <div className="bg-red-50 border border-red-200 text-red-700 px-4 py-3 rounded-lg">
                       {error}
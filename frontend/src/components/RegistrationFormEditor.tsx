@@ -0,0 +1,242 @@
+import { useState, useEffect } from 'react';
+import { adminService } from '../services/adminService';
+import { errorMessage } from '../services/api';
+import type { FormField, FormFieldType } from '../services/attendeeService';
+
+const FIELD_TYPES: { value: FormFieldType; label: string }[] = [
+  { value: 'text', label: 'Short text' },
+  { value: 'textarea', label: 'Long text' },
+  { value: 'number', label: 'Number' },
+  { value: 'select', label: 'Single choice' },
+  { value: 'multiselect', label: 'Multiple choice' },
+  { value: 'checkbox', label: 'Checkbox' },
+];
+
+const hasOptions = (type: FormFieldType) => type === 'select' || type === 'multiselect';
+const hasPattern = (type: FormFieldType) => type === 'text' || type === 'textarea';
+
+// Derives a field ID from its label, e.g. "T-shirt size" -> "t_shirt_size"
+const slugify = (label: string) =>
+  label
+    .toLowerCase()
+    .replace(/[^a-z0-9]+/g, '_')
+    .replace(/^[^a-z]+|_+$/g, '')
+    .slice(0, 40);
+
+const inputClassName = 'w-full px-3 py-2 border border-gray-300 rounded-lg text-sm';
+
+// Lets organisers edit the custom questions asked on the registration form.
+// IDs are fixed once saved, as answers are stored under them.
+const RegistrationFormEditor = () => {
+  const [fields, setFields] = useState<FormField[]>([]);
+  const [savedIds, setSavedIds] = useState<Set<string>>(new Set());
+  const [loading, setLoading] = useState(true);
+  const [saving, setSaving] = useState(false);
+  const [message, setMessage] = useState<{ error: boolean; text: string } | null>(null);
+
+  useEffect(() => {
+    const load = async () => {
+      try {
+        const form = await adminService.getRegistrationForm();
+        setFields(form.fields ?? []);
+        setSavedIds(new Set((form.fields ?? []).map((field) => field.id)));
+      } catch (err) {
+        console.error('Error loading registration form:', err);
+        setMessage({ error: true, text: 'Failed to load the registration form' });
+      } finally {
+        setLoading(false);
+      }
+    };
+    load();
+  }, []);
+
+  const updateField = (index: number, changes: Partial<FormField>) => {
+    setFields(fields.map((field, i) => (i === index ? { ...field, ...changes } : field)));
+  };
+
+  const moveField = (index: number, offset: number) => {
+    const target = index + offset;
+    if (target < 0 || target >= fields.length) return;
+    const next = [...fields];
+    [next[index], next[target]] = [next[target], next[index]];
+    setFields(next);
+  };
+
+  const addField = () => {
+    setFields([...fields, { id: '', label: '', type: 'text', required: false }]);
+  };
+
+  const handleSave = async () => {
+    setSaving(true);
+    setMessage(null);
+    try {
+      const cleaned = fields.map((field) => ({
+        ...field,
+        id: field.id || slugify(field.label),
+        options: hasOptions(field.type) ? field.options : undefined,
+        pattern: hasPattern(field.type) ? field.pattern : undefined,
+      }));
+      const form = await adminService.updateRegistrationForm(cleaned);
+      setFields(form.fields);
+      setSavedIds(new Set(form.fields.map((field) => field.id)));
+      setMessage({ error: false, text: 'Registration form saved' });
+    } catch (err: any) {
+      const field = err.response?.data?.errors?.[0]?.field ?? err.response?.data?.field;
+      const detail = errorMessage(err, 'Failed to save registration form');
+      const text = field ? `${field}: ${detail}` : detail;
+      setMessage({ error: true, text });
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  if (loading) {
+    return <p className="text-center text-gray-500 py-8">Loading...</p>;
+  }
+
+  return (
+    <div>
+      <div className="mb-4 flex justify-between items-center">
+        <h3 className="text-xl font-bold text-gray-900">Registration Questions ({fields.length})</h3>
+        <button
+          onClick={addField}
+          className="px-4 py-2 bg-primary-600 text-white rounded-lg hover:bg-primary-700 transition-colors"
+        >
+          Add Question
+        </button>
+      </div>
+      <p className="text-sm text-gray-500 mb-4">
+        Asked after name, email and designation. Removing a question keeps the answers already given in the export.
+      </p>
+
+      <div className="space-y-4">
+        {fields.map((field, index) => (
+          <div key={index} className="border border-gray-200 rounded-lg p-4 space-y-3">
+            <div className="grid md:grid-cols-3 gap-3">
+              <div>
+                <label className="block text-xs font-semibold text-gray-700 mb-1">Question</label>
+                <input
+                  type="text"
+                  value={field.label}
+                  onChange={(e) => updateField(index, { label: e.target.value })}
+                  className={inputClassName}
+                />
+              </div>
+              <div>
+                <label className="block text-xs font-semibold text-gray-700 mb-1">ID</label>
+                <input
+                  type="text"
+                  value={field.id}
+                  placeholder={slugify(field.label)}
+                  disabled={savedIds.has(field.id)}
+                  onChange={(e) => updateField(index, { id: e.target.value })}
+                  className={`${inputClassName} disabled:bg-gray-100`}
+                />
+              </div>
+              <div>
+                <label className="block text-xs font-semibold text-gray-700 mb-1">Type</label>
+                <select
+                  value={field.type}
+                  onChange={(e) => updateField(index, { type: e.target.value as FormFieldType })}
+                  className={`${inputClassName} bg-white`}
+                >
+                  {FIELD_TYPES.map((type) => (
+                    <option key={type.value} value={type.value}>
+                      {type.label}
+                    </option>
+                  ))}
+                </select>
+              </div>
+            </div>
+
+            {hasOptions(field.type) && (
+              <div>
+                <label className="block text-xs font-semibold text-gray-700 mb-1">Options (one per line)</label>
+                <textarea
+                  rows={3}
+                  value={(field.options ?? []).join('\n')}
+                  onChange={(e) => updateField(index, { options: e.target.value.split('\n') })}
+                  onBlur={() => updateField(index, { options: (field.options ?? []).filter((option) => option.trim()) })}
+                  className={inputClassName}
+                />
+              </div>
+            )}
+
+            <div className="grid md:grid-cols-2 gap-3">
+              {hasPattern(field.type) && (
+                <div>
+                  <label className="block text-xs font-semibold text-gray-700 mb-1">Pattern (regular expression)</label>
+                  <input
+                    type="text"
+                    value={field.pattern ?? ''}
+                    onChange={(e) => updateField(index, { pattern: e.target.value })}
+                    className={`${inputClassName} font-mono`}
+                  />
+                </div>
+              )}
+              <div>
+                <label className="block text-xs font-semibold text-gray-700 mb-1">Help text</label>
+                <input
+                  type="text"
+                  value={field.helpText ?? ''}
+                  onChange={(e) => updateField(index, { helpText: e.target.value })}
+                  className={inputClassName}
+                />
+              </div>
+            </div>
+
+            <div className="flex justify-between items-center">
+              <label className="flex items-center gap-2 text-sm text-gray-700">
+                <input
+                  type="checkbox"
+                  checked={field.required}
+                  onChange={(e) => updateField(index, { required: e.target.checked })}
+                />
+                Required
+              </label>
+              <div className="flex gap-3 text-sm font-semibold">
+                <button onClick={() => moveField(index, -1)} className="text-gray-600 hover:text-gray-800">
+                  Up
+                </button>
+                <button onClick={() => moveField(index, 1)} className="text-gray-600 hover:text-gray-800">
+                  Down
+                </button>
+                <button
+                  onClick={() => setFields(fields.filter((_, i) => i !== index))}
+                  className="text-red-600 hover:text-red-800"
+                >
+                  Remove
+                </button>
+              </div>
+            </div>
+          </div>
+        ))}
+        {fields.length === 0 && (
+          <p className="text-center text-gray-500 py-8">No custom questions, only name, email and designation are asked</p>
+        )}
+      </div>
+
+      {message && (
+        <div
+          className={`mt-4 px-4 py-3 rounded-lg border ${
+            message.error ? 'bg-red-50 border-red-200 text-red-700' : 'bg-green-50 border-green-200 text-green-700'
+          }`}
+        >
+          {message.text}
+        </div>
+      )}
+
+      <div className="mt-4 flex justify-end">
+        <button
+          onClick={handleSave}
+          disabled={saving}
+          className="px-6 py-2 bg-primary-600 text-white rounded-lg hover:bg-primary-700 transition-colors disabled:opacity-50"
+        >
+          {saving ? 'Saving...' : 'Save Questions'}
+        </button>
+      </div>
+    </div>
+  );
+};
+
+export default RegistrationFormEditor;
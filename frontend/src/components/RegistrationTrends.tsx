@@ -0,0 +1,98 @@
+import { useState, useEffect } from 'react';
+import { ComposedChart, Bar, Line, XAxis, YAxis, CartesianGrid, ResponsiveContainer, Legend, Tooltip } from 'recharts';
+import { adminService, type Analytics, type AnalyticsGranularity } from '../services/adminService';
+import { errorMessage } from '../services/api';
+
+// Shows registrations, cancellations and check-ins over time, bucketed in the
+// workshop timezone
+const RegistrationTrends = () => {
+  const [granularity, setGranularity] = useState<AnalyticsGranularity>('day');
+  const [analytics, setAnalytics] = useState<Analytics | null>(null);
+  const [error, setError] = useState('');
+
+  useEffect(() => {
+    const load = async () => {
+      setError('');
+      try {
+        setAnalytics(await adminService.getAnalytics(granularity));
+      } catch (err: any) {
+        console.error('Error loading analytics:', err);
+        setError(errorMessage(err, 'Failed to load analytics'));
+      }
+    };
+    load();
+  }, [granularity]);
+
+  // Bucket starts are labelled in the workshop timezone, not the browser's
+  const formatStart = (start: string) =>
+    new Date(start).toLocaleString(undefined, {
+      timeZone: analytics?.timezone,
+      day: 'numeric',
+      month: 'short',
+      ...(granularity === 'hour' ? { hour: '2-digit', minute: '2-digit' } : {}),
+    });
+
+  const totals = analytics?.totals;
+
+  return (
+    <div>
+      <div className="flex justify-between items-center mb-4">
+        <h2 className="text-2xl font-bold text-gray-900">Registrations Over Time</h2>
+        <div className="flex gap-2">
+          {(['hour', 'day'] as const).map((value) => (
+            <button
+              key={value}
+              onClick={() => setGranularity(value)}
+              className={`px-3 py-1 rounded-lg text-sm font-semibold ${
+                granularity === value ? 'bg-primary-600 text-white' : 'border border-gray-300 text-gray-700'
+              }`}
+            >
+              {value === 'hour' ? 'Hourly' : 'Daily'}
+            </button>
+          ))}
+        </div>
+      </div>
+
+      {error && <p className="text-red-600 text-center py-8">{error}</p>}
+
+      {totals && (
+        <div className="grid grid-cols-2 md:grid-cols-5 gap-4 mb-6 text-center">
+          {[
+            ['Registrations', totals.registrations],
+            ['Cancellations', totals.cancellations],
+            ['Active', totals.active],
+            ['Checked In', totals.checkIns],
+            ['Check-in Rate', `${(totals.conversionRate * 100).toFixed(0)}%`],
+          ].map(([label, value]) => (
+            <div key={label} className="bg-gray-50 rounded-lg p-3">
+              <p className="text-xs text-gray-500 uppercase">{label}</p>
+              <p className="text-xl font-bold text-gray-900">{value}</p>
+            </div>
+          ))}
+        </div>
+      )}
+
+      {analytics && (
+        <>
+          <ResponsiveContainer width="100%" height={320}>
+            <ComposedChart data={analytics.buckets}>
+              <CartesianGrid strokeDasharray="3 3" />
+              <XAxis dataKey="start" tickFormatter={formatStart} minTickGap={20} />
+              <YAxis yAxisId="count" allowDecimals={false} />
+              <YAxis yAxisId="cumulative" orientation="right" allowDecimals={false} />
+              <Tooltip labelFormatter={(label) => formatStart(String(label))} />
+              <Legend />
+              <Bar yAxisId="count" dataKey="registrations" name="Registrations" fill="#0ea5e9" />
+              <Bar yAxisId="count" dataKey="cancellations" name="Cancellations" fill="#f43f5e" />
+              <Bar yAxisId="count" dataKey="checkIns" name="Check-ins" fill="#22c55e" />
+              <Line yAxisId="cumulative" dataKey="cumulative" name="Total registered" stroke="#6366f1" dot={false} />
+            </ComposedChart>
+          </ResponsiveContainer>
+          <p className="text-xs text-gray-500 mt-2">Times are in {analytics.timezone}</p>
+        </>
+      )}
+    </div>
+  );
+};
+
+export default RegistrationTrends;
@@ -5,17 +5,23 @@ import { PieChart, Pie, Cell, ResponsiveContainer, Legend, Tooltip } from 'recha
 import { attendeeService, type Attendee } from '../services/attendeeService';
 import { speakerService, type Speaker } from '../services/speakerService';
 import { sessionService, type Session } from '../services/sessionService';
-import { adminService } from '../services/adminService';
+import { adminService, type AnswerBreakdown } from '../services/adminService';
+import RegistrationFormEditor from '../components/RegistrationFormEditor';
+import DesignationEditor from '../components/DesignationEditor';
+import RegistrationTrends from '../components/RegistrationTrends';
 
 const COLORS = ['#0ea5e9', '#3b82f6', '#6366f1', '#8b5cf6', '#a855f7', '#d946ef', '#ec4899', '#f43f5e', '#ef4444', '#f59e0b'];
 
 const AdminPanel = () => {
   const navigate = useNavigate();
-  const [activeTab, setActiveTab] = useState<'attendees' | 'speakers' | 'sessions'>('attendees');
+  const [activeTab, setActiveTab] = useState<'attendees' | 'speakers' | 'sessions' | 'form' | 'designations'>(
+    'attendees'
+  );
   const [attendees, setAttendees] = useState<Attendee[]>([]);
   const [speakers, setSpeakers] = useState<Speaker[]>([]);
   const [sessions, setSessions] = useState<Session[]>([]);
   const [stats, setStats] = useState<{ designation: string; count: number }[]>([]);
+  const [answerStats, setAnswerStats] = useState<AnswerBreakdown[]>([]);
   const [loading, setLoading] = useState(true);
   const [showSpeakerModal, setShowSpeakerModal] = useState(false);
   const [showSessionModal, setShowSessionModal] = useState(false);
@@ -52,6 +58,7 @@ const AdminPanel = () => {
       setSpeakers(Array.isArray(speakersData) ? speakersData : []);
       setSessions(Array.isArray(sessionsData) ? sessionsData : []);
       setStats(Array.isArray(statsData?.designationBreakdown) ? statsData.designationBreakdown : []);
+      setAnswerStats(Array.isArray(statsData?.answerBreakdown) ? statsData.answerBreakdown : []);
     } catch (err: any) {
       if (err.response?.status === 401) {
         navigate('/');
@@ -62,6 +69,7 @@ const AdminPanel = () => {
       setSpeakers([]);
       setSessions([]);
       setStats([]);
+      setAnswerStats([]);
     } finally {
       setLoading(false);
     }
@@ -77,6 +85,21 @@ const AdminPanel = () => {
     }
   };
 
+  const handleExportAttendees = async () => {
+    try {
+      const blob = await attendeeService.exportCsv();
+      const url = URL.createObjectURL(blob);
+      const link = document.createElement('a');
+      link.href = url;
+      link.download = 'attendees.csv';
+      link.click();
+      URL.revokeObjectURL(url);
+    } catch (err) {
+      console.error('Error exporting attendees:', err);
+      alert('Failed to export attendees');
+    }
+  };
+
   const handleDeleteAttendee = async (id: string) => {
     if (!confirm('Are you sure you want to delete this attendee?')) return;
     try {
@@ -231,13 +254,51 @@ const AdminPanel = () => {
           ) : (
             <p className="text-gray-500 text-center py-8">No data available</p>
           )}
+
+          {answerStats.length > 0 && (
+            <div className="grid md:grid-cols-2 gap-6 mt-6">
+              {answerStats.map((question) => {
+                const total = question.counts.reduce((sum, item) => sum + item.count, 0);
+                return (
+                  <div key={question.fieldId}>
+                    <h3 className="font-semibold text-gray-900 mb-2">{question.label}</h3>
+                    <div className="space-y-2">
+                      {question.counts.map((item) => (
+                        <div key={item.answer} className="text-sm">
+                          <div className="flex justify-between text-gray-600">
+                            <span>{item.answer}</span>
+                            <span>{item.count}</span>
+                          </div>
+                          <div className="h-2 bg-gray-100 rounded">
+                            <div
+                              className="h-2 bg-primary-500 rounded"
+                              style={{ width: total ? `${(item.count / total) * 100}%` : 0 }}
+                            />
+                          </div>
+                        </div>
+                      ))}
+                    </div>
+                  </div>
+                );
+              })}
+            </div>
+          )}
+        </motion.div>
+
+        {/* Registration Trends */}
+        <motion.div
+          initial={{ opacity: 0, y: 20 }}
+          animate={{ opacity: 1, y: 0 }}
+          className="bg-white rounded-xl shadow-lg p-6 mb-8"
+        >
+          <RegistrationTrends />
         </motion.div>
 
         {/* Tabs */}
         <div className="bg-white rounded-xl shadow-lg mb-8">
           <div className="border-b border-gray-200">
             <nav className="flex -mb-px">
-              {(['attendees', 'speakers', 'sessions'] as const).map((tab) => (
+              {(['attendees', 'speakers', 'sessions', 'form', 'designations'] as const).map((tab) => (
                 <button
                   key={tab}
                   onClick={() => setActiveTab(tab)}
@@ -259,6 +320,12 @@ const AdminPanel = () => {
               <div>
                 <div className="mb-4 flex justify-between items-center">
                   <h3 className="text-xl font-bold text-gray-900">Attendees ({attendees.length})</h3>
+                  <button
+                    onClick={handleExportAttendees}
+                    className="px-4 py-2 bg-primary-600 text-white rounded-lg hover:bg-primary-700 transition-colors"
+                  >
+                    Export CSV
+                  </button>
                 </div>
                 <div className="overflow-x-auto">
                   <table className="w-full">
@@ -301,6 +368,12 @@ const AdminPanel = () => {
               </div>
             )}
 
+            {/* Registration Form Tab */}
+            {activeTab === 'form' && <RegistrationFormEditor />}
+
+            {/* Designations Tab */}
+            {activeTab === 'designations' && <DesignationEditor onRemapped={fetchAllData} />}
+
             {/* Speakers Tab */}
             {activeTab === 'speakers' && (
               <div>
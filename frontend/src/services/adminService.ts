@@ -1,26 +1,154 @@
-import api from './api';
+import api, { setCsrfToken } from './api';
+import type { FormField } from './attendeeService';
+
+export interface AnswerBreakdown {
+  fieldId: string;
+  label: string;
+  counts: Array<{
+    answer: string;
+    count: number;
+  }>;
+}
 
 export interface AdminStats {
   designationBreakdown: Array<{
     designation: string;
     count: number;
   }>;
+  answerBreakdown?: AnswerBreakdown[];
+}
+
+export type AnalyticsGranularity = 'hour' | 'day';
+
+export interface AnalyticsBucket {
+  start: string;
+  registrations: number;
+  cancellations: number;
+  checkIns: number;
+  cumulative: number;
+}
+
+export interface Analytics {
+  granularity: AnalyticsGranularity;
+  timezone: string;
+  buckets: AnalyticsBucket[];
+  totals: {
+    registrations: number;
+    cancellations: number;
+    checkIns: number;
+    active: number;
+    conversionRate: number;
+  };
+}
+
+export interface Designation {
+  name: string;
+  aliases: string[];
+}
+
+export interface DesignationTaxonomy {
+  designations: Designation[];
+  updatedAt?: string;
+  updatedBy?: string;
+}
+
+export interface RemapResult {
+  dryRun: boolean;
+  updated: number;
+  changes: Array<{ from: string; to: string; count: number }>;
+}
+
+export interface RegistrationFormSchema {
+  fields: FormField[];
+  updatedAt?: string;
+  updatedBy?: string;
+}
+
+export interface AdminUser {
+  id: string;
+  email: string;
+  displayName: string;
+  disabled: boolean;
+  createdAt: string;
+  updatedAt: string;
+  lastLoginAt?: string;
+  twoFactorEnabled: boolean;
+}
+
+export interface LoginResult {
+  message: string;
+  twoFactorRequired?: boolean;
+  user?: AdminUser;
+  csrfToken?: string;
+}
+
+export interface SSOStatus {
+  enabled: boolean;
+  name?: string;
 }
 
 export const adminService = {
-  login: async (password: string): Promise<{ success: boolean }> => {
-    const response = await api.post<{ success: boolean }>('/admin/login', { password });
+  login: async (email: string, password: string): Promise<LoginResult> => {
+    const response = await api.post<LoginResult>('/admin/login', { email, password });
+    return response.data;
+  },
+
+  verifyTwoFactor: async (code: string): Promise<LoginResult> => {
+    const response = await api.post<LoginResult>('/admin/login/2fa', { code });
+    return response.data;
+  },
+
+  getSSOStatus: async (): Promise<SSOStatus> => {
+    const response = await api.get<SSOStatus>('/admin/sso');
     return response.data;
   },
 
+  // Single sign-on is a full-page redirect through the identity provider
+  ssoLoginUrl: (): string => `${api.defaults.baseURL}/admin/sso/login`,
+
   logout: async (): Promise<void> => {
     await api.post('/admin/logout');
+    setCsrfToken(null);
   },
 
   getStats: async (): Promise<AdminStats> => {
     const response = await api.get<AdminStats>('/admin/stats');
     return response.data;
   },
+
+  // from and to are inclusive dates (YYYY-MM-DD) in the workshop timezone;
+  // the server picks a recent range when they are left out
+  getAnalytics: async (granularity: AnalyticsGranularity, from?: string, to?: string): Promise<Analytics> => {
+    const response = await api.get<Analytics>('/admin/analytics', { params: { granularity, from, to } });
+    return response.data;
+  },
+
+  getRegistrationForm: async (): Promise<RegistrationFormSchema> => {
+    const response = await api.get<RegistrationFormSchema>('/admin/registration-form');
+    return response.data;
+  },
+
+  getDesignations: async (): Promise<DesignationTaxonomy> => {
+    const response = await api.get<DesignationTaxonomy>('/admin/designations');
+    return response.data;
+  },
+
+  updateDesignations: async (designations: Designation[]): Promise<DesignationTaxonomy> => {
+    const response = await api.put<DesignationTaxonomy>('/admin/designations', { designations });
+    return response.data;
+  },
+
+  // Rewrites existing attendees' designations to the taxonomy; a dry run
+  // only reports what would change
+  remapDesignations: async (dryRun: boolean): Promise<RemapResult> => {
+    const response = await api.post<RemapResult>('/admin/designations/remap', null, { params: { dryRun } });
+    return response.data;
+  },
+
+  updateRegistrationForm: async (fields: FormField[]): Promise<RegistrationFormSchema> => {
+    const response = await api.put<RegistrationFormSchema>('/admin/registration-form', { fields });
+    return response.data;
+  },
 };
 
 
@@ -1,7 +1,7 @@
 import axios from 'axios';
 
 // Use relative URL for production (same domain), or VITE_API_BASE_URL if set, or localhost for dev
-const API_BASE_URL = import.meta.env.VITE_API_BASE_URL || '/api';
+const API_BASE_URL = import.meta.env.VITE_API_BASE_URL || '/api/v1';
 
 const api = axios.create({
   baseURL: API_BASE_URL,
@@ -11,9 +11,31 @@ const api = axios.create({
   withCredentials: true,
 });
 
-// Request interceptor for adding auth token if needed
+// CSRF token issued at login; admin writes must send it back in a header.
+// Kept in sessionStorage so it survives a reload of the admin panel.
+const CSRF_TOKEN_KEY = 'csrfToken';
+const SAFE_METHODS = ['get', 'head', 'options'];
+
+// Errors are RFC 7807 problem details with the message in `detail`; the
+// deprecated /api routes put it in `error`
+export const errorMessage = (err: any, fallback: string): string =>
+  err.response?.data?.detail || err.response?.data?.error || fallback;
+
+export const setCsrfToken = (token: string | null) => {
+  if (token) {
+    sessionStorage.setItem(CSRF_TOKEN_KEY, token);
+  } else {
+    sessionStorage.removeItem(CSRF_TOKEN_KEY);
+  }
+};
+
+// Request interceptor for adding the CSRF token to state-changing requests
 api.interceptors.request.use(
   (config) => {
+    const token = sessionStorage.getItem(CSRF_TOKEN_KEY);
+    if (token && !SAFE_METHODS.includes((config.method || 'get').toLowerCase())) {
+      config.headers['X-CSRF-Token'] = token;
+    }
     return config;
   },
   (error) => {
@@ -23,12 +45,24 @@ api.interceptors.request.use(
 
 // Response interceptor for handling errors
 api.interceptors.response.use(
-  (response) => response,
-  (error) => {
+  (response) => {
+    if (response.data?.csrfToken) {
+      setCsrfToken(response.data.csrfToken);
+    }
+    return response;
+  },
+  async (error) => {
     if (error.response?.status === 401) {
       // Handle unauthorized access
+      setCsrfToken(null);
       window.location.href = '/';
     }
+    // The token is missing (e.g. in a new tab): fetch it and retry once
+    if (error.response?.status === 403 && errorMessage(error, '') === 'Invalid CSRF token' && !error.config._csrfRetried) {
+      error.config._csrfRetried = true;
+      await api.get('/admin/me');
+      return api.request(error.config);
+    }
     return Promise.reject(error);
   }
 );
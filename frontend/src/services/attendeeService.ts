@@ -6,10 +6,76 @@ export interface Attendee {
   email: string;
   designation: string;
   createdAt?: string;
+  answers?: Record<string, Answer>;
 }
 
+export type Answer = string | number | boolean | string[];
+
+export type FormFieldType = 'text' | 'textarea' | 'number' | 'select' | 'multiselect' | 'checkbox';
+
+export interface FormField {
+  id: string;
+  label: string;
+  type: FormFieldType;
+  required: boolean;
+  options?: string[];
+  pattern?: string;
+  helpText?: string;
+}
+
+export interface RegistrationChallenge {
+  type: 'pow' | 'captcha' | 'fake';
+  difficulty?: number;
+  siteKey?: string;
+  response?: string;
+}
+
+export interface RegistrationForm {
+  designations?: string[];
+  fields?: FormField[];
+  formToken?: string;
+  challenge?: RegistrationChallenge;
+}
+
+export interface RegistrationRequest extends Omit<Attendee, 'id' | 'createdAt'> {
+  answers?: Record<string, Answer>;
+  formToken?: string;
+  challengeResponse?: string;
+  // Honeypot, hidden from people and left empty
+  website?: string;
+}
+
+const leadingZeroBits = (bytes: Uint8Array): number => {
+  let bits = 0;
+  for (const byte of bytes) {
+    if (byte === 0) {
+      bits += 8;
+      continue;
+    }
+    return bits + Math.clz32(byte) - 24;
+  }
+  return bits;
+};
+
+// Finds a nonce such that SHA-256(formToken:subject:nonce) starts with
+// `difficulty` zero bits, matching the server's proof-of-work check
+export const solveProofOfWork = async (formToken: string, subject: string, difficulty: number): Promise<string> => {
+  const encoder = new TextEncoder();
+  for (let nonce = 0; ; nonce++) {
+    const digest = await crypto.subtle.digest('SHA-256', encoder.encode(`${formToken}:${subject}:${nonce}`));
+    if (leadingZeroBits(new Uint8Array(digest)) >= difficulty) {
+      return String(nonce);
+    }
+  }
+};
+
 export const attendeeService = {
-  register: async (attendee: Omit<Attendee, 'id' | 'createdAt'>): Promise<Attendee> => {
+  getForm: async (): Promise<RegistrationForm> => {
+    const response = await api.get<RegistrationForm>('/attendees/form');
+    return response.data;
+  },
+
+  register: async (attendee: RegistrationRequest): Promise<Attendee> => {
     const response = await api.post<Attendee>('/attendees', attendee);
     return response.data;
   },
@@ -24,6 +90,12 @@ export const attendeeService = {
     return Array.isArray(response.data) ? response.data : [];
   },
 
+  // Downloads the attendee list, with answers to the custom questions, as CSV
+  exportCsv: async (): Promise<Blob> => {
+    const response = await api.get<Blob>('/attendees/export', { responseType: 'blob' });
+    return response.data;
+  },
+
   delete: async (id: string): Promise<void> => {
     await api.delete(`/attendees/${id}`);
   },